@@ -10,26 +10,41 @@ package main
 
 import (
 	"context"
+	"feedsystem_video_go/internal/auth"
 	"feedsystem_video_go/internal/config"
 	"feedsystem_video_go/internal/db"
+	"feedsystem_video_go/internal/invalidation"
 	apphttp "feedsystem_video_go/internal/http"
 	rabbitmq "feedsystem_video_go/internal/middleware/rabbitmq"
 	rediscache "feedsystem_video_go/internal/middleware/redis"
+	"feedsystem_video_go/internal/startup"
+	"feedsystem_video_go/internal/video"
 	"log"
 	"strconv"
 	"time"
+
+	"gorm.io/gorm"
 )
 
 func main() {
 	// ========== 1. 加载配置 ==========
-	log.Printf("Loading config from configs/config.yaml")
-	cfg, err := config.Load("configs/config.yaml")
+	const configPath = "configs/config.yaml"
+	log.Printf("Loading config from %s", configPath)
+	cfg, err := config.Load(configPath)
 	if err != nil {
 		log.Fatalf("Failed to load config: %v", err)
 	}
+	auth.SetSecret(cfg.Auth.JWTSecret)
+	// 上传目录必须可写：卷未挂载或权限不对时直接退出，而不是等到第一次上传才失败
+	if err := video.CheckUploadDir(cfg.Storage.UploadRoot()); err != nil {
+		log.Fatalf("Upload directory %q is not writable: %v", cfg.Storage.UploadRoot(), err)
+	}
 
 	// ========== 2. 连接数据库 ==========
-	sqlDB, err := db.NewDB(cfg.Database)
+	// 编排启动时 MySQL 可能稍晚就绪，在 startup.wait_timeout 内重试
+	sqlDB, err := startup.Wait("mysql", cfg.Startup.WaitTimeout, func() (*gorm.DB, error) {
+		return db.NewDB(cfg.Database)
+	})
 	if err != nil {
 		log.Fatalf("Failed to connect database: %v", err)
 	}
@@ -73,10 +88,41 @@ func main() {
 		log.Printf("RabbitMQ connected")
 	}
 
-	// ========== 5. 设置路由并启动服务器 ==========
+	// ========== 5. 启动后台任务 ==========
+	// 已删除视频清理任务：超过保留期后删除文件并物理删除记录
+	// 上传文件保存在 API 进程本地，因此由 API 进程负责清理
+	bgCtx, bgCancel := context.WithCancel(context.Background())
+	defer bgCancel()
+
+	// 运行时配置：收到 SIGHUP 时重新加载日志级别、Feed 缓存 TTL、热榜窗口、举报限流阈值
+	live := config.NewLive(configPath, cfg)
+	live.OnReload(func(t config.Tunables) { db.SetLogLevel(sqlDB, t.LogLevel) })
+	live.WatchSIGHUP(bgCtx)
+	purger := video.NewVideoPurger(video.NewVideoRepository(sqlDB), cfg.Storage, time.Hour)
+	go func() { _ = purger.Run(bgCtx) }()
+	// 未发布上传文件清理任务：删除上传后长时间没有被视频引用的文件
+	if cleaner := video.NewOrphanUploadCleaner(video.NewVideoRepository(sqlDB), cfg.Storage, cfg.Video); cleaner != nil {
+		go func() { _ = cleaner.Run(bgCtx) }()
+	}
+	// 视频实时计数推送：订阅 Redis 频道，把点赞数/评论数/热度变化推送给 WebSocket 客户端（Redis 不可用时不启用）
+	countsHub := video.NewCountsHub(cache)
+	if countsHub != nil {
+		go func() { _ = countsHub.Run(bgCtx) }()
+	}
+	// 跨实例缓存失效：订阅失效事件，按模式删除 Feed 缓存（Redis 不可用时不启用）
+	if invalidator := invalidation.NewListener(cache); invalidator != nil {
+		go func() { _ = invalidator.Run(bgCtx) }()
+	}
+
+	// ========== 6. 设置路由并启动服务器 ==========
 	// SetRouter 会初始化所有模块的 Service，并把 RMQ 注入进去
 	// 这样 Service 就可以通过 MQ 发送消息了
-	r := apphttp.SetRouter(sqlDB, cache, rmq)
+	// 评论敏感词过滤器（启动时加载词表）
+	commentFilter, err := video.NewCommentFilter(cfg.Comment)
+	if err != nil {
+		log.Fatalf("Failed to load comment filter: %v", err)
+	}
+	r := apphttp.SetRouter(sqlDB, cache, rmq, live, commentFilter, countsHub)
 	log.Printf("Server is running on port %d", cfg.Server.Port)
 	if err := r.Run(":" + strconv.Itoa(cfg.Server.Port)); err != nil {
 		log.Fatalf("Failed to run server: %v", err)
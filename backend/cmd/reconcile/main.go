@@ -0,0 +1,97 @@
+// Package main 是数据对账（Reconcile）命令的入口
+// 用于重新计算冗余计数字段，修复异步处理（MQ + Fallback）导致的计数漂移
+//
+// 用法：
+//   go run ./cmd/reconcile -target=follow_counts
+//   go run ./cmd/reconcile -target=likes_count -from=1 -to=100000
+//   go run ./cmd/reconcile -target=orphan_uploads -dry-run
+//
+// 支持的 target：
+//   follow_counts  - 根据 socials 表回填 accounts.followers_count / following_count
+//   likes_count    - 根据 likes 表分批修正 videos.likes_count（可用 -from/-to 限定视频ID范围），记录每条差异
+//   orphan_uploads - 立即执行一轮未发布上传文件清理（与 API 进程中的定时任务相同，使用 storage 配置，可用 -uploads 覆盖上传目录）
+//
+// 所有 target 都支持 -dry-run：只记录将要修改的数量和具体的ID/文件，不写入数据库、不删除文件
+package main
+
+import (
+	"context"
+	"feedsystem_video_go/internal/config"
+	"feedsystem_video_go/internal/db"
+	"feedsystem_video_go/internal/social"
+	"feedsystem_video_go/internal/video"
+	"flag"
+	"log"
+	"time"
+)
+
+func main() {
+	configPath := flag.String("config", "configs/config.yaml", "config file path")
+	target := flag.String("target", "follow_counts", "reconcile target: follow_counts, likes_count, orphan_uploads")
+	fromID := flag.Uint("from", 0, "likes_count: first video id (inclusive, 0 = from the beginning)")
+	toID := flag.Uint("to", 0, "likes_count: last video id (inclusive, 0 = no limit)")
+	batch := flag.Int("batch", 500, "likes_count: videos per batch")
+	uploads := flag.String("uploads", "", "orphan_uploads: upload root directory (default: storage.upload_dir)")
+	dryRun := flag.Bool("dry-run", false, "log what would change without writing")
+	timeout := flag.Duration("timeout", 10*time.Minute, "overall timeout")
+	flag.Parse()
+
+	// ========== 1. 加载配置 ==========
+	log.Printf("Loading config from %s", *configPath)
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	// ========== 2. 连接数据库 ==========
+	sqlDB, err := db.NewDB(cfg.Database)
+	if err != nil {
+		log.Fatalf("Failed to connect database: %v", err)
+	}
+	// 自动迁移：确保冗余计数字段已存在
+	if err := db.AutoMigrate(sqlDB); err != nil {
+		log.Fatalf("Failed to auto migrate database: %v", err)
+	}
+	defer db.CloseDB(sqlDB)
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	// ========== 3. 执行对账 ==========
+	// dry run 时日志中的 "updated/removed" 表示将要修改的数量
+	mode := ""
+	if *dryRun {
+		mode = " (dry run)"
+		log.Printf("Dry run: no changes will be written")
+	}
+	switch *target {
+	case "follow_counts":
+		changed, err := social.NewSocialRepository(sqlDB).ReconcileFollowCounts(ctx, *dryRun)
+		if err != nil {
+			log.Fatalf("Failed to reconcile follow counts: %v", err)
+		}
+		log.Printf("follow counts reconciled%s, %d accounts updated", mode, changed)
+	case "likes_count":
+		res, err := video.NewVideoRepository(sqlDB).ReconcileLikesCounts(ctx, uint(*fromID), uint(*toID), *batch, *dryRun)
+		if err != nil {
+			log.Fatalf("Failed to reconcile likes count (%d videos scanned, %d updated): %v", res.Scanned, res.Updated, err)
+		}
+		log.Printf("likes count reconciled%s, %d videos scanned, %d updated", mode, res.Scanned, res.Updated)
+	case "orphan_uploads":
+		storage := cfg.Storage
+		if *uploads != "" {
+			storage.UploadDir = *uploads
+		}
+		cleaner := video.NewOrphanUploadCleaner(video.NewVideoRepository(sqlDB), storage, cfg.Video)
+		if cleaner == nil {
+			log.Fatalf("orphan upload cleanup is disabled (video.orphan_upload_interval < 0)")
+		}
+		removed, err := cleaner.CleanOnce(ctx, *dryRun)
+		if err != nil {
+			log.Fatalf("Failed to clean orphan uploads (%d files removed): %v", removed, err)
+		}
+		log.Printf("orphan uploads cleaned%s, %d files removed", mode, removed)
+	default:
+		log.Fatalf("unknown reconcile target %q", *target)
+	}
+}
@@ -5,10 +5,14 @@ package main
 
 import (
 	"context"
+	"feedsystem_video_go/internal/account"
 	"feedsystem_video_go/internal/config"
 	"feedsystem_video_go/internal/db"
+	"feedsystem_video_go/internal/middleware/rabbitmq"
 	rediscache "feedsystem_video_go/internal/middleware/redis"
+	"feedsystem_video_go/internal/notification"
 	"feedsystem_video_go/internal/social"
+	"feedsystem_video_go/internal/startup"
 	"feedsystem_video_go/internal/video"
 	"feedsystem_video_go/internal/worker"
 	"log"
@@ -19,6 +23,7 @@ import (
 	"time"
 
 	amqp "github.com/rabbitmq/amqp091-go"
+	"gorm.io/gorm"
 )
 
 // RabbitMQ 拓扑结构常量定义
@@ -55,18 +60,42 @@ const (
 	popularityBindingKey = "video.popularity.*"
 )
 
+// ============ Account 账户模块 ============
+const (
+	accountExchange   = "account.events"
+	accountQueue      = "account.events"
+	accountBindingKey = "account.*"
+)
+
+// ============ Account Lifecycle 账户生命周期模块 ============
+const (
+	accountLifecycleExchange   = "account.lifecycle"
+	accountLifecycleQueue      = "account.lifecycle"
+	accountLifecycleBindingKey = "account.lifecycle.*"
+)
+
+// ============ Notification 通知模块 ============
+// 通知队列不单独声明交换机，而是绑定到点赞/评论/关注交换机的部分路由键上，各自收到一份事件副本
+const (
+	notificationQueue = "notification.events"
+)
+
 func main() {
 	// ========== 1. 初始化配置和基础连接 ==========
 
 	// 加载配置文件
-	log.Printf("Loading config from configs/config.yaml")
-	cfg, err := config.Load("configs/config.yaml")
+	const configPath = "configs/config.yaml"
+	log.Printf("Loading config from %s", configPath)
+	cfg, err := config.Load(configPath)
 	if err != nil {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
 	// 连接 MySQL 数据库
-	sqlDB, err := db.NewDB(cfg.Database)
+	// 编排启动时 MySQL 可能稍晚就绪，在 startup.wait_timeout 内重试
+	sqlDB, err := startup.Wait("mysql", cfg.Startup.WaitTimeout, func() (*gorm.DB, error) {
+		return db.NewDB(cfg.Database)
+	})
 	if err != nil {
 		log.Fatalf("Failed to connect database: %v", err)
 	}
@@ -99,15 +128,18 @@ func main() {
 
 	// 建立连接（底层 TCP 连接）
 	// 注意：conn 是长期连接，整个程序运行期间保持打开
-	conn, err := amqp.Dial(url)
+	// Worker 离不开 RabbitMQ，与 MySQL 一样在 startup.wait_timeout 内重试
+	conn, err := startup.Wait("rabbitmq", cfg.Startup.WaitTimeout, func() (*amqp.Connection, error) {
+		return amqp.Dial(url)
+	})
 	if err != nil {
 		log.Fatalf("Failed to connect rabbitmq: %v", err)
 	}
 	defer conn.Close()
 
-	// 创建通道（Channel）
+	// 创建通道（Channel），只用于声明拓扑
 	// 通道是轻量级的连接，可以创建多个，推荐每个 goroutine 使用一个通道
-	// 注意：通道不是线程安全的，不要在多个 goroutine 中共享
+	// 注意：通道不是线程安全的，不要在多个 goroutine 中共享（各 Worker 的通道见下方 openWorkerChannel）
 	ch, err := conn.Channel()
 	if err != nil {
 		log.Fatalf("Failed to open rabbitmq channel: %v", err)
@@ -121,57 +153,102 @@ func main() {
 	// 这一步相当于"初始化"RabbitMQ 的基础设施，确保队列和交换机存在
 
 	// 声明 Social 关注模块的拓扑（交换机+队列+绑定）
-	if err := declareSocialTopology(ch); err != nil {
+	if err := declareSocialTopology(ch, &cfg.RabbitMQ); err != nil {
 		log.Fatalf("Failed to declare social topology: %v", err)
 	}
 
 	// 声明 Like 点赞模块的拓扑
-	if err := declareLikeTopology(ch); err != nil {
+	if err := declareLikeTopology(ch, &cfg.RabbitMQ); err != nil {
 		log.Fatalf("Failed to declare like topology: %v", err)
 	}
 
 	// 声明 Comment 评论模块的拓扑
-	if err := declareCommentTopology(ch); err != nil {
+	if err := declareCommentTopology(ch, &cfg.RabbitMQ); err != nil {
 		log.Fatalf("Failed to declare comment topology: %v", err)
 	}
 
+	// 声明 Account 账户模块的拓扑
+	if err := declareAccountTopology(ch, &cfg.RabbitMQ); err != nil {
+		log.Fatalf("Failed to declare account topology: %v", err)
+	}
+
+	// 声明 Account Lifecycle 账户生命周期模块的拓扑
+	if err := declareAccountLifecycleTopology(ch, &cfg.RabbitMQ); err != nil {
+		log.Fatalf("Failed to declare account lifecycle topology: %v", err)
+	}
+
+	// 声明 Notification 通知模块的拓扑（必须在点赞/评论/关注交换机声明之后）
+	if err := declareNotificationTopology(ch, &cfg.RabbitMQ); err != nil {
+		log.Fatalf("Failed to declare notification topology: %v", err)
+	}
+
 	// 声明 Popularity 热度模块的拓扑（需要 Redis）
 	if cache != nil {
-		if err := declarePopularityTopology(ch); err != nil {
+		if err := declarePopularityTopology(ch, &cfg.RabbitMQ); err != nil {
 			log.Fatalf("Failed to declare popularity topology: %v", err)
 		}
 	}
 
-	// 设置 QoS（服务质量）
-	// 参数说明：
-	//   50  - 预取消息数量：消费者一次性最多从队列取 50 条消息
-	//   0   - 预取大小（字节数）：0 表示不限制
-	//   false - 是否应用到所有连接：false 表示只应用到当前通道
-	// 作用：防止消息堆积在内存中，实现消息的公平分发
-	if err := ch.Qos(50, 0, false); err != nil {
-		log.Fatalf("Failed to set qos: %v", err)
-	}
+	// 为每个 Worker 创建独立的通道（通道不是线程安全的，各 Worker 的 Consume 和 ACK/NACK 并发执行）
+	// 每个通道单独设置 QoS（预取数量见配置 worker.<name>.prefetch），一个 Worker 处理变慢不会占用其他 Worker 的预取额度
+	socialCh := openWorkerChannel(conn, cfg.Worker.Social.PrefetchCount())
+	defer socialCh.Close()
+	likeCh := openWorkerChannel(conn, cfg.Worker.Like.PrefetchCount())
+	defer likeCh.Close()
+	commentCh := openWorkerChannel(conn, cfg.Worker.Comment.PrefetchCount())
+	defer commentCh.Close()
+	accountCh := openWorkerChannel(conn, cfg.Worker.Account.PrefetchCount())
+	defer accountCh.Close()
+	onboardingCh := openWorkerChannel(conn, cfg.Worker.Onboarding.PrefetchCount())
+	defer onboardingCh.Close()
+	notificationCh := openWorkerChannel(conn, cfg.Worker.Notification.PrefetchCount())
+	defer notificationCh.Close()
 
 	// ========== 4. 创建 Worker 实例 ==========
 
+	// 创建通知服务（通知 Worker 为点赞/评论/关注通知内容所有者，引导 Worker 发送欢迎通知）
+	notificationService := notification.NewNotificationService(notification.NewNotificationRepository(sqlDB), cache)
+
+	// 各 Worker 的消费者标签传空字符串，使用默认的 <worker 名>-<主机名>-<进程ID>
+
 	// 创建关注 Worker（处理用户关注/取关事件）
 	repo := social.NewSocialRepository(sqlDB)
-	videoRepo:=video.NewVideoRepository(sqlDB)
-	socialWorker := worker.NewSocialWorker(ch, repo, videoRepo,socialQueue)
+	videoRepo := video.NewVideoRepository(sqlDB)
+	socialWorker := worker.NewSocialWorker(socialCh, repo, videoRepo, cache, socialQueue, "")
+
+	// 创建热度写入合并器（点赞/评论 Worker 共用，按间隔批量写入数据库热度）
+	popularityFlusher := video.NewPopularityFlusher(videoRepo, cfg.Video.PopularityFlushInterval)
 
 	// 创建点赞 Worker（处理点赞/取消点赞事件）
 	//videoRepo := video.NewVideoRepository(sqlDB)
 	likeRepo := video.NewLikeRepository(sqlDB)
-	likeWorker := worker.NewLikeWorker(ch, likeRepo, videoRepo, likeQueue)
+	likeWorker := worker.NewLikeWorker(likeCh, likeRepo, videoRepo, popularityFlusher, cache, likeQueue, "")
 
-	// 创建评论 Worker（处理发布/删除评论事件）
+	// 创建评论 Worker（处理发布/删除/点赞评论事件）
 	commentRepo := video.NewCommentRepository(sqlDB)
-	commentWorker := worker.NewCommentWorker(ch, commentRepo, videoRepo, commentQueue)
+	commentFilter, err := video.NewCommentFilter(cfg.Comment)
+	if err != nil {
+		log.Fatalf("Failed to load comment filter: %v", err)
+	}
+	commentLikeRepo := video.NewCommentLikeRepository(sqlDB)
+	commentWorker := worker.NewCommentWorker(commentCh, commentRepo, commentLikeRepo, videoRepo, popularityFlusher, commentFilter, cfg.Comment.MaxPerVideo, cache, commentQueue, "")
+
+	// 创建账户 Worker（处理改名事件，同步冗余用户名）
+	accountRepo := account.NewAccountRepository(sqlDB)
+	accountWorker := worker.NewAccountWorker(accountCh, accountRepo, accountQueue, "")
+
+	// 创建引导 Worker（处理账户创建事件，发送欢迎通知）
+	onboardingWorker := worker.NewOnboardingWorker(onboardingCh, accountRepo, notificationService, accountLifecycleQueue, "")
+
+	// 创建通知 Worker（处理点赞/评论/关注事件，通知内容所有者）
+	notificationWorker := worker.NewNotificationWorker(notificationCh, videoRepo, commentFilter, commentRepo, cfg.Comment.MaxPerVideo, notificationService, notificationQueue, "")
 
 	// 创建热度 Worker（处理视频热度更新事件，需要 Redis）
 	var popularityWorker *worker.PopularityWorker
 	if cache != nil {
-		popularityWorker = worker.NewPopularityWorker(ch, cache, popularityQueue)
+		popularityCh := openWorkerChannel(conn, cfg.Worker.Popularity.PrefetchCount())
+		defer popularityCh.Close()
+		popularityWorker = worker.NewPopularityWorker(popularityCh, cache, videoRepo, popularityQueue, "")
 	}
 
 	// ========== 5. 启动所有 Worker ==========
@@ -180,37 +257,104 @@ func main() {
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
+	// 运行时配置：收到 SIGHUP 时重新加载日志级别（Worker 只使用其中的 GORM 日志级别）
+	live := config.NewLive(configPath, cfg)
+	live.OnReload(func(t config.Tunables) { db.SetLogLevel(sqlDB, t.LogLevel) })
+	live.WatchSIGHUP(ctx)
+
 	// 错误通道：用于接收 Worker 的错误
-	errCh := make(chan error, 4)
+	errCh := make(chan error, 8)
+
+	// 已启动的 Worker 数量（停止时等待全部返回）
+	running := 0
 
 	// 启动 Social Worker（并发）
-	log.Printf("Worker started, consuming queue=%s", socialQueue)
+	log.Printf("Worker started, consuming queue=%s tag=%s", socialQueue, socialWorker.Tag())
+	running++
 	go func() { errCh <- socialWorker.Run(ctx) }()
 
 	// 启动 Like Worker（并发）
-	log.Printf("Worker started, consuming queue=%s", likeQueue)
+	log.Printf("Worker started, consuming queue=%s tag=%s", likeQueue, likeWorker.Tag())
+	running++
 	go func() { errCh <- likeWorker.Run(ctx) }()
 
 	// 启动 Comment Worker（并发）
-	log.Printf("Worker started, consuming queue=%s", commentQueue)
+	log.Printf("Worker started, consuming queue=%s tag=%s", commentQueue, commentWorker.Tag())
+	running++
 	go func() { errCh <- commentWorker.Run(ctx) }()
 
+	// 启动 Account Worker（并发）
+	log.Printf("Worker started, consuming queue=%s tag=%s", accountQueue, accountWorker.Tag())
+	running++
+	go func() { errCh <- accountWorker.Run(ctx) }()
+
+	// 启动 Onboarding Worker（并发）
+	log.Printf("Worker started, consuming queue=%s tag=%s", accountLifecycleQueue, onboardingWorker.Tag())
+	running++
+	go func() { errCh <- onboardingWorker.Run(ctx) }()
+
+	// 启动 Notification Worker（并发）
+	log.Printf("Worker started, consuming queue=%s tag=%s", notificationQueue, notificationWorker.Tag())
+	running++
+	go func() { errCh <- notificationWorker.Run(ctx) }()
+
 	// 启动 Popularity Worker（并发，如果 Redis 可用）
 	if popularityWorker != nil {
-		log.Printf("Worker started, consuming queue=%s", popularityQueue)
+		log.Printf("Worker started, consuming queue=%s tag=%s", popularityQueue, popularityWorker.Tag())
+		running++
 		go func() { errCh <- popularityWorker.Run(ctx) }()
 	}
 
+	// 启动热度写入合并器（使用单独的上下文：所有 Worker 返回后才停止，并写入剩余的热度变化量）
+	flushCtx, stopFlush := context.WithCancel(context.Background())
+	defer stopFlush()
+	flushDone := make(chan struct{})
+	go func() {
+		defer close(flushDone)
+		_ = popularityFlusher.Run(flushCtx)
+	}()
+
+	// 启动定时发布任务：每分钟把到期的 scheduled 视频改为 public（条件更新，多实例运行也安全）
+	scheduler := video.NewVideoScheduler(videoRepo, cache, time.Minute)
+	log.Printf("Video scheduler started")
+	running++
+	go func() { errCh <- scheduler.Run(ctx) }()
+
 	// ========== 6. 等待任意一个 Worker 停止 ==========
 
 	// 阻塞等待任意一个 Worker 返回错误
 	err = <-errCh
+	// 停止其余 Worker：各消费者先取消订阅，在宽限期内处理完已接收的消息后返回
+	stop()
+	for i := 1; i < running; i++ {
+		<-errCh
+	}
+	// 所有 Worker 已返回，停止合并器并等待写入剩余的热度变化量
+	stopFlush()
+	<-flushDone
 	if err != nil && err != context.Canceled {
 		log.Fatalf("Worker stopped: %v", err)
 	}
 	log.Printf("Worker stopped")
 }
 
+// openWorkerChannel 为一个 Worker 创建独立的通道并设置 QoS（失败时直接退出）
+// QoS 参数说明：
+//   prefetch - 预取消息数量：消费者一次性最多从队列取 prefetch 条未确认的消息
+//   0        - 预取大小（字节数）：0 表示不限制
+//   false    - 是否应用到所有连接：false 表示只应用到当前通道
+// 作用：防止消息堆积在内存中，实现消息的公平分发
+func openWorkerChannel(conn *amqp.Connection, prefetch int) *amqp.Channel {
+	ch, err := conn.Channel()
+	if err != nil {
+		log.Fatalf("Failed to open rabbitmq channel: %v", err)
+	}
+	if err := ch.Qos(prefetch, 0, false); err != nil {
+		log.Fatalf("Failed to set qos: %v", err)
+	}
+	return ch
+}
+
 // declareSocialTopology 声明 Social 模块的 RabbitMQ 拓扑
 // 拓扑 = Exchange + Queue + Binding（交换机 + 队列 + 绑定关系）
 //
@@ -218,7 +362,7 @@ func main() {
 //   Producer → Exchange("social.events") → Queue("social.events") → Consumer
 //                ↓
 //            Routing Key: "social.*"
-func declareSocialTopology(ch *amqp.Channel) error {
+func declareSocialTopology(ch *amqp.Channel, cfg *config.RabbitMQConfig) error {
 	// 1. 声明交换机（Exchange）
 	// 参数说明：
 	//   socialExchange - 交换机名称："social.events"
@@ -240,14 +384,19 @@ func declareSocialTopology(ch *amqp.Channel) error {
 		return err
 	}
 
-	// 2. 声明队列（Queue）
+	// 2. 声明死信交换机和死信队列（配置了 dead_letter_exchange 时）
+	if err := rabbitmq.DeclareDeadLetter(ch, cfg, socialQueue); err != nil {
+		return err
+	}
+
+	// 3. 声明队列（Queue）
 	// 参数说明：
 	//   socialQueue - 队列名称："social.events"
 	//   true        - durable：持久化，RabbitMQ 重启后队列仍然存在
 	//   false       - exclusive：独占队列，仅限当前连接使用
 	//   false       - auto-delete：自动删除，没有消费者时是否删除队列
 	//   false       - no-wait：是否等待服务器响应
-	//   nil         - arguments：额外参数
+	//   args        - arguments：队列参数（x-message-ttl、x-max-length、死信交换机，来自配置）
 	// 返回值：q 是队列对象，包含队列名称等信息
 	q, err := ch.QueueDeclare(
 		socialQueue,
@@ -255,13 +404,13 @@ func declareSocialTopology(ch *amqp.Channel) error {
 		false,
 		false,
 		false,
-		nil,
+		rabbitmq.QueueArgs(cfg, socialQueue),
 	)
 	if err != nil {
 		return err
 	}
 
-	// 3. 绑定队列到交换机（Binding）
+	// 4. 绑定队列到交换机（Binding）
 	// 参数说明：
 	//   q.Name          - 队列名称（从 QueueDeclare 返回）
 	//   socialBindingKey - 绑定键："social.*"（通配符，匹配所有以 social. 开头的路由键）
@@ -283,7 +432,7 @@ func declareSocialTopology(ch *amqp.Channel) error {
 
 // declarePopularityTopology 声明热度模块的拓扑
 // 专门用于处理视频热度更新事件（如点赞+1、评论+1）
-func declarePopularityTopology(ch *amqp.Channel) error {
+func declarePopularityTopology(ch *amqp.Channel, cfg *config.RabbitMQConfig) error {
 	// 声明热度交换机
 	if err := ch.ExchangeDeclare(
 		popularityExchange,
@@ -297,14 +446,19 @@ func declarePopularityTopology(ch *amqp.Channel) error {
 		return err
 	}
 
-	// 声明热度队列
+	// 声明死信队列（配置了 dead_letter_exchange 时）
+	if err := rabbitmq.DeclareDeadLetter(ch, cfg, popularityQueue); err != nil {
+		return err
+	}
+
+	// 声明热度队列（队列参数来自配置）
 	q, err := ch.QueueDeclare(
 		popularityQueue,
 		true,
 		false,
 		false,
 		false,
-		nil,
+		rabbitmq.QueueArgs(cfg, popularityQueue),
 	)
 	if err != nil {
 		return err
@@ -322,7 +476,7 @@ func declarePopularityTopology(ch *amqp.Channel) error {
 
 // declareLikeTopology 声明点赞模块的拓扑
 // 处理用户点赞/取消点赞事件
-func declareLikeTopology(ch *amqp.Channel) error {
+func declareLikeTopology(ch *amqp.Channel, cfg *config.RabbitMQConfig) error {
 	// 声明点赞交换机
 	if err := ch.ExchangeDeclare(
 		likeExchange,
@@ -336,14 +490,19 @@ func declareLikeTopology(ch *amqp.Channel) error {
 		return err
 	}
 
-	// 声明点赞队列
+	// 声明死信队列（配置了 dead_letter_exchange 时）
+	if err := rabbitmq.DeclareDeadLetter(ch, cfg, likeQueue); err != nil {
+		return err
+	}
+
+	// 声明点赞队列（队列参数来自配置）
 	q, err := ch.QueueDeclare(
 		likeQueue,
 		true,
 		false,
 		false,
 		false,
-		nil,
+		rabbitmq.QueueArgs(cfg, likeQueue),
 	)
 	if err != nil {
 		return err
@@ -361,7 +520,7 @@ func declareLikeTopology(ch *amqp.Channel) error {
 
 // declareCommentTopology 声明评论模块的拓扑
 // 处理用户发布/删除评论事件
-func declareCommentTopology(ch *amqp.Channel) error {
+func declareCommentTopology(ch *amqp.Channel, cfg *config.RabbitMQConfig) error {
 	// 声明评论交换机
 	if err := ch.ExchangeDeclare(
 		commentExchange,
@@ -375,14 +534,19 @@ func declareCommentTopology(ch *amqp.Channel) error {
 		return err
 	}
 
-	// 声明评论队列
+	// 声明死信队列（配置了 dead_letter_exchange 时）
+	if err := rabbitmq.DeclareDeadLetter(ch, cfg, commentQueue); err != nil {
+		return err
+	}
+
+	// 声明评论队列（队列参数来自配置）
 	q, err := ch.QueueDeclare(
 		commentQueue,
 		true,
 		false,
 		false,
 		false,
-		nil,
+		rabbitmq.QueueArgs(cfg, commentQueue),
 	)
 	if err != nil {
 		return err
@@ -397,3 +561,125 @@ func declareCommentTopology(ch *amqp.Channel) error {
 		nil,
 	)
 }
+
+// declareAccountTopology 声明账户模块的拓扑
+// 处理账户改名等事件
+func declareAccountTopology(ch *amqp.Channel, cfg *config.RabbitMQConfig) error {
+	// 声明账户交换机
+	if err := ch.ExchangeDeclare(
+		accountExchange,
+		"topic",
+		true,
+		false,
+		false,
+		false,
+		nil,
+	); err != nil {
+		return err
+	}
+
+	// 声明死信队列（配置了 dead_letter_exchange 时）
+	if err := rabbitmq.DeclareDeadLetter(ch, cfg, accountQueue); err != nil {
+		return err
+	}
+
+	// 声明账户队列（队列参数来自配置）
+	q, err := ch.QueueDeclare(
+		accountQueue,
+		true,
+		false,
+		false,
+		false,
+		rabbitmq.QueueArgs(cfg, accountQueue),
+	)
+	if err != nil {
+		return err
+	}
+
+	// 绑定：所有 Routing Key 为 "account.*" 的消息都路由到这里
+	return ch.QueueBind(
+		q.Name,
+		accountBindingKey,
+		accountExchange,
+		false,
+		nil,
+	)
+}
+
+// declareAccountLifecycleTopology 声明账户生命周期模块的拓扑
+// 处理账户创建等事件
+func declareAccountLifecycleTopology(ch *amqp.Channel, cfg *config.RabbitMQConfig) error {
+	// 声明账户生命周期交换机
+	if err := ch.ExchangeDeclare(
+		accountLifecycleExchange,
+		"topic",
+		true,
+		false,
+		false,
+		false,
+		nil,
+	); err != nil {
+		return err
+	}
+
+	// 声明死信队列（配置了 dead_letter_exchange 时）
+	if err := rabbitmq.DeclareDeadLetter(ch, cfg, accountLifecycleQueue); err != nil {
+		return err
+	}
+
+	// 声明账户生命周期队列（队列参数来自配置）
+	q, err := ch.QueueDeclare(
+		accountLifecycleQueue,
+		true,
+		false,
+		false,
+		false,
+		rabbitmq.QueueArgs(cfg, accountLifecycleQueue),
+	)
+	if err != nil {
+		return err
+	}
+
+	// 绑定：所有 Routing Key 为 "account.lifecycle.*" 的消息都路由到这里
+	return ch.QueueBind(
+		q.Name,
+		accountLifecycleBindingKey,
+		accountLifecycleExchange,
+		false,
+		nil,
+	)
+}
+
+// declareNotificationTopology 声明 Notification 模块的 RabbitMQ 拓扑
+// 通知队列绑定到已有的点赞/评论/关注交换机，只接收需要产生通知的事件：
+//   Exchange("like.events")    --"like.like"------→
+//   Exchange("comment.events") --"comment.publish"→ Queue("notification.events") → NotificationWorker
+//   Exchange("social.events")  --"social.follow"--→
+func declareNotificationTopology(ch *amqp.Channel, cfg *config.RabbitMQConfig) error {
+	// 声明死信队列（配置了 dead_letter_exchange 时）
+	if err := rabbitmq.DeclareDeadLetter(ch, cfg, notificationQueue); err != nil {
+		return err
+	}
+
+	// 声明通知队列（队列参数来自配置）
+	q, err := ch.QueueDeclare(
+		notificationQueue,
+		true,
+		false,
+		false,
+		false,
+		rabbitmq.QueueArgs(cfg, notificationQueue),
+	)
+	if err != nil {
+		return err
+	}
+
+	// 绑定：一个队列绑定三个交换机，每个交换机只绑定一个路由键
+	if err := rabbitmq.BindQueue(ch, q.Name, likeExchange, worker.NotificationLikeRoutingKey); err != nil {
+		return err
+	}
+	if err := rabbitmq.BindQueue(ch, q.Name, commentExchange, worker.NotificationCommentRoutingKey); err != nil {
+		return err
+	}
+	return rabbitmq.BindQueue(ch, q.Name, socialExchange, worker.NotificationFollowRoutingKey)
+}
@@ -0,0 +1,159 @@
+package account
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// 邮箱验证 token：保存在 Redis 中（account:email_verify:{token}），24 小时有效，验证成功后删除
+const (
+	emailVerifyKeyPrefix = "account:email_verify:"
+	emailVerifyTTL       = 24 * time.Hour
+	maxEmailLength       = 255 // 与 email 列 varchar(255) 一致
+)
+
+var (
+	ErrInvalidEmail              = errors.New("invalid email address")                         // 邮箱格式不合法
+	ErrEmailTaken                = errors.New("email already in use")                          // 邮箱已被其他账户使用
+	ErrInvalidVerificationToken  = errors.New("invalid or expired verification token")         // 验证 token 不存在、已过期或邮箱已变更
+	ErrEmailVerificationDisabled = errors.New("email verification is temporarily unavailable") // Redis 不可用，无法保存验证 token
+)
+
+// emailVerification 验证 token 对应的数据
+type emailVerification struct {
+	AccountID uint   `json:"account_id"`
+	Email     string `json:"email"`
+}
+
+// normalizeEmail 校验并规范化邮箱（去掉首尾空白、转小写）
+// 只接受纯地址（不接受 "Name <a@b.com>" 形式）
+func normalizeEmail(raw string) (string, error) {
+	email := strings.ToLower(strings.TrimSpace(raw))
+	if email == "" || len(email) > maxEmailLength {
+		return "", ErrInvalidEmail
+	}
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email || !strings.Contains(email[strings.LastIndex(email, "@")+1:], ".") {
+		return "", ErrInvalidEmail
+	}
+	return email, nil
+}
+
+// SetEmail 设置（或更换）当前用户的邮箱，并发送验证 token
+// 业务流程：
+// 1. 校验并规范化邮箱，消费二次验证 token（先调用 /account/verifyPassword 获取）
+// 2. 写入数据库并重置验证状态（邮箱唯一，已被占用返回 ErrEmailTaken）
+// 3. 生成验证 token 存入 Redis，并投递给用户（目前没有邮件服务，写入日志）
+// 参数：
+//   - ctx: 上下文
+//   - accountID: 当前登录用户ID
+//   - rawEmail: 邮箱
+//   - reauthToken: 二次验证 token
+func (as *AccountService) SetEmail(ctx context.Context, accountID uint, rawEmail string, reauthToken string) error {
+	email, err := normalizeEmail(rawEmail)
+	if err != nil {
+		return err
+	}
+	// 没有 Redis 时无法保存验证 token，直接拒绝，避免写入一个永远无法验证的邮箱
+	if as.cache == nil {
+		return ErrEmailVerificationDisabled
+	}
+	if err := as.consumeReauthToken(ctx, accountID, reauthToken); err != nil {
+		return err
+	}
+
+	if err := as.accountRepository.SetEmail(ctx, accountID, email); err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
+			return ErrEmailTaken
+		}
+		return err
+	}
+
+	token, err := newVerificationToken()
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(emailVerification{AccountID: accountID, Email: email})
+	if err != nil {
+		return err
+	}
+	opCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	if err := as.cache.SetBytes(opCtx, emailVerifyKeyPrefix+token, b, emailVerifyTTL); err != nil {
+		// 邮箱已保存但 token 未保存：用户重新设置邮箱即可重新获取 token
+		return ErrEmailVerificationDisabled
+	}
+
+	as.sendEmailVerification(accountID, email, token)
+	return nil
+}
+
+// VerifyEmail 使用验证 token 完成邮箱验证
+// token 只能使用一次；生成 token 后又更换了邮箱时，旧 token 失效
+// 参数：
+//   - ctx: 上下文
+//   - token: 验证 token
+func (as *AccountService) VerifyEmail(ctx context.Context, token string) error {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return ErrInvalidVerificationToken
+	}
+	if as.cache == nil {
+		return ErrEmailVerificationDisabled
+	}
+
+	key := emailVerifyKeyPrefix + token
+	opCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	b, err := as.cache.GetBytes(opCtx, key)
+	if err != nil {
+		return ErrInvalidVerificationToken
+	}
+	var v emailVerification
+	if err := json.Unmarshal(b, &v); err != nil || v.AccountID == 0 || v.Email == "" {
+		return ErrInvalidVerificationToken
+	}
+
+	// 只有邮箱仍是 token 对应的邮箱时才标记为已验证
+	verified, err := as.accountRepository.MarkEmailVerified(ctx, v.AccountID, v.Email)
+	if err != nil {
+		return err
+	}
+	_ = as.cache.Del(opCtx, key)
+	if !verified {
+		return ErrInvalidVerificationToken
+	}
+	return nil
+}
+
+// newVerificationToken 生成 32 字节随机验证 token（十六进制）
+func newVerificationToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// sendEmailVerification 投递邮箱验证 token
+// 目前没有接入邮件服务，只写入日志：token 是可直接使用的凭据，默认只记录其 SHA-256 前缀（便于排查，不能用于验证），
+// 邮箱地址不写入日志；开发环境开启 auth.dev_log_email_tokens 后才记录 token 原文
+func (as *AccountService) sendEmailVerification(accountID uint, email, token string) {
+	if as.logEmailTokens {
+		log.Printf("account: [dev] email verification token for account %d (%s): %s", accountID, email, token)
+		return
+	}
+	sum := sha256.Sum256([]byte(token))
+	log.Printf("account: email verification token issued for account %d (sha256 prefix %s)", accountID, hex.EncodeToString(sum[:])[:12])
+}
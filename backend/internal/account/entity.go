@@ -1,15 +1,26 @@
 package account
 
+import "time"
+
 type Account struct {
 	ID       uint   `gorm:"primaryKey" json:"id"`
 	Username string `gorm:"unique" json:"username"`
 	Password string `json:"-"`
 	Token    string `json:"-"`
+	IsAdmin  bool   `gorm:"not null;default:false" json:"-"`
+
+	FollowersCount int64 `gorm:"not null;default:0" json:"followers_count"` // 粉丝数（冗余字段，由关注事件维护）
+	FollowingCount int64 `gorm:"not null;default:0" json:"following_count"` // 关注数（冗余字段，由关注事件维护）
+
+	LastLoginAt *time.Time `json:"-"` // 最近一次登录时间（从未登录为 NULL）；只在 /account/me 中返回，不出现在公开资料和资料缓存中
+
+	Email         *string `gorm:"type:varchar(255);uniqueIndex" json:"-"` // 邮箱（可选，未设置为 NULL；唯一索引允许多个 NULL）；只在 /account/me 中返回
+	EmailVerified bool    `gorm:"not null;default:false" json:"-"`        // 邮箱是否已验证（更换邮箱后重置为 false）
 }
 
 type CreateAccountRequest struct {
-	Username string `json:"username"`
-	Password string `json:"password"`
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
 }
 
 type RenameRequest struct {
@@ -17,7 +28,7 @@ type RenameRequest struct {
 }
 
 type FindByIDRequest struct {
-	ID uint `json:"id"`
+	ID uint `json:"id" binding:"required,gt=0"`
 }
 
 type FindByIDResponse struct {
@@ -25,8 +36,12 @@ type FindByIDResponse struct {
 	Username string `json:"username"`
 }
 
+type FindByIDsRequest struct {
+	IDs []uint `json:"ids"`
+}
+
 type FindByUsernameRequest struct {
-	Username string `json:"username"`
+	Username string `json:"username" binding:"required"`
 }
 
 type FindByUsernameResponse struct {
@@ -34,13 +49,44 @@ type FindByUsernameResponse struct {
 	Username string `json:"username"`
 }
 
+// MeResponse 当前登录用户的账户信息（/account/me）
+type MeResponse struct {
+	ID             uint       `json:"id"`
+	Username       string     `json:"username"`
+	FollowersCount int64      `json:"followers_count"`
+	FollowingCount int64      `json:"following_count"`
+	LastLoginAt    *time.Time `json:"last_login_at"` // 最近一次登录时间（从未记录时为 null）
+	Email          *string    `json:"email"`          // 邮箱（未设置时为 null）
+	EmailVerified  bool       `json:"email_verified"` // 邮箱是否已验证
+}
+
+type SetEmailRequest struct {
+	Email       string `json:"email"`
+	ReauthToken string `json:"reauth_token"` // 二次验证 token（/account/verifyPassword 返回）
+}
+
+// VerifyPasswordRequest 二次验证请求（敏感操作前重新输入密码）
+type VerifyPasswordRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+// VerifyPasswordResponse 二次验证响应
+type VerifyPasswordResponse struct {
+	ReauthToken string    `json:"reauth_token"` // 二次验证 token（只能使用一次）
+	ExpiresAt   time.Time `json:"expires_at"`   // 过期时间（5 分钟后）
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token"`
+}
+
 type ChangePasswordRequest struct {
-	Username    string `json:"username"`
-	OldPassword string `json:"old_password"`
-	NewPassword string `json:"new_password"`
+	Username    string `json:"username" binding:"required"`
+	OldPassword string `json:"old_password" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
 }
 
 type LoginRequest struct {
-	Username string `json:"username"`
-	Password string `json:"password"`
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
 }
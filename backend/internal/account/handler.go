@@ -2,11 +2,36 @@ package account
 
 import (
 	"errors"
+	"net/http"
+
+	"feedsystem_video_go/internal/apierror"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
+// NotFoundMapping 账户不存在（gorm.ErrRecordNotFound）的错误映射
+// 其他包的处理器查询账户失败时也使用它，保证错误码一致且不泄露 GORM 原始信息
+var NotFoundMapping = apierror.Mapping{Err: gorm.ErrRecordNotFound, Status: http.StatusNotFound, Code: "ACCOUNT_NOT_FOUND", Message: "account not found"}
+
+// accountErrors 账户服务层错误到错误码/HTTP状态码的映射
+var accountErrors = []apierror.Mapping{
+	{Err: ErrNewUsernameRequired, Status: http.StatusBadRequest, Code: "USERNAME_REQUIRED"},
+	{Err: ErrTooManyIDs, Status: http.StatusBadRequest, Code: "TOO_MANY_IDS"},
+	{Err: ErrInvalidCredentials, Status: http.StatusUnauthorized, Code: "INVALID_CREDENTIALS"},
+	{Err: ErrUsernameTaken, Status: http.StatusConflict, Code: "USERNAME_TAKEN"},
+	{Err: ErrInvalidEmail, Status: http.StatusBadRequest, Code: "INVALID_EMAIL"},
+	{Err: ErrEmailTaken, Status: http.StatusConflict, Code: "EMAIL_TAKEN"},
+	{Err: ErrInvalidVerificationToken, Status: http.StatusBadRequest, Code: "INVALID_VERIFICATION_TOKEN"},
+	{Err: ErrEmailVerificationDisabled, Status: http.StatusServiceUnavailable, Code: "EMAIL_VERIFICATION_UNAVAILABLE"},
+	{Err: ErrTooManyLookups, Status: http.StatusTooManyRequests, Code: apierror.CodeRateLimited},
+	{Err: ErrReauthRequired, Status: http.StatusForbidden, Code: "REAUTH_REQUIRED"},
+	{Err: ErrWrongPassword, Status: http.StatusUnauthorized, Code: "WRONG_PASSWORD"},
+	{Err: ErrTooManyReauth, Status: http.StatusTooManyRequests, Code: apierror.CodeRateLimited},
+	{Err: ErrReauthUnavailable, Status: http.StatusServiceUnavailable, Code: "REAUTH_UNAVAILABLE"},
+	NotFoundMapping,
+}
+
 type AccountHandler struct {
 	accountService *AccountService
 }
@@ -23,7 +48,7 @@ func (h *AccountHandler) CreateAccount(c *gin.Context) {
 	var req CreateAccountRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		 // 解析失败，返回400错误
-		c.JSON(400, gin.H{"error": err.Error()})
+		apierror.BadRequest(c, err)
 		return
 	}
 	  // 2. 调用Service层创建账号
@@ -35,8 +60,8 @@ func (h *AccountHandler) CreateAccount(c *gin.Context) {
 		Username: req.Username,
 		Password: req.Password,
 	}); err != nil {
-		// 注册失败（用户名已存在），返回500错误
-		c.JSON(500, gin.H{"error": err.Error()})
+		// 注册失败（用户名已存在返回409，其他错误返回500）
+		apierror.FromError(c, err, accountErrors...)
 		return
 	}
 	// 注册成功，返回成功消息
@@ -52,14 +77,14 @@ func (h *AccountHandler) Rename(c *gin.Context) {
 	var req RenameRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		// 解析失败，返回400错误
-		c.JSON(400, gin.H{"error": err.Error()})
+		apierror.BadRequest(c, err)
 		return
 	}
 	// 2. 从Gin上下文中获取当前用户ID
 	accountID, err := getAccountID(c)
 	if err != nil {
-		  // 未登录，返回400错误
-		c.JSON(400, gin.H{"error": err.Error()})
+		  // 未登录，返回401错误
+		apierror.Unauthorized(c)
 		return
 	
 	}
@@ -71,24 +96,8 @@ func (h *AccountHandler) Rename(c *gin.Context) {
     // - 更新Redis缓存中的Token
 	token, err := h.accountService.Rename(c.Request.Context(), accountID, req.NewUsername)
 	if err != nil {
-		 // 根据不同的错误类型返回不同的HTTP状态码
-		if errors.Is(err, ErrNewUsernameRequired) {
-			 // 新用户名为空，返回400错误
-			c.JSON(400, gin.H{"error": err.Error()})
-			return
-		}
-		if errors.Is(err, ErrUsernameTaken) {
-			// 用户名已被占用，返回409错误
-			c.JSON(409, gin.H{"error": err.Error()})
-			return
-		}
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			// 用户不存在，返回404错误
-			c.JSON(404, gin.H{"error": "account not found"})
-			return
-		}
-		// 其他错误，返回500错误
-		c.JSON(500, gin.H{"error": err.Error()})
+		// 新用户名为空返回400，用户名已被占用返回409，用户不存在返回404，其他错误返回500
+		apierror.FromError(c, err, accountErrors...)
 		return
 	}
 	 // 改名成功，返回新的Token
@@ -104,7 +113,7 @@ func (h *AccountHandler) ChangePassword(c *gin.Context) {
 	var req ChangePasswordRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		 // 解析失败，返回400错误
-		c.JSON(400, gin.H{"error": err.Error()})
+		apierror.BadRequest(c, err)
 		return
 	}
 	 // 2. 调用Service层处理修改密码逻辑
@@ -115,7 +124,7 @@ func (h *AccountHandler) ChangePassword(c *gin.Context) {
     // - 清空Token（强制所有设备下线）
     // - 删除Redis缓存中的Token
 	if err := h.accountService.ChangePassword(c.Request.Context(), req.Username, req.OldPassword, req.NewPassword); err != nil {
-		c.JSON(400, gin.H{"error": "unsuccessfully password changed"})
+		apierror.FromError(c, err, accountErrors...)
 		return
 	}
 	c.JSON(200, gin.H{"message": "successfully password changed"})
@@ -128,13 +137,13 @@ func (h *AccountHandler) FindByID(c *gin.Context) {
 	 // 1. 解析请求体到 FindByIDRequest 结构体
 	var req FindByIDRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(400, gin.H{"error": err.Error()})
+		apierror.BadRequest(c, err)
 		return
 	}
 	 // 2. 调用Service层查询用户
 	if account, err := h.accountService.FindByID(c.Request.Context(), req.ID); err != nil {
 		// 查询失败，返回500错误
-		c.JSON(500, gin.H{"error": err.Error()})
+		apierror.FromError(c, err, accountErrors...)
 		return
 	} else {
 		// 查询成功，返回用户信息（不包含密码和Token）
@@ -142,20 +151,41 @@ func (h *AccountHandler) FindByID(c *gin.Context) {
 	}
 }
 
+// FindByIDs 处理批量按ID查询用户请求
+// 前端请求：POST /account/findByIDs
+// 请求体：{"ids": [1, 2, 3]}（最多 100 个，不存在的ID会被跳过）
+func (h *AccountHandler) FindByIDs(c *gin.Context) {
+	// 1. 解析请求体到 FindByIDsRequest 结构体
+	var req FindByIDsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.BadRequest(c, err)
+		return
+	}
+	// 2. 调用Service层批量查询用户
+	accounts, err := h.accountService.FindByIDs(c.Request.Context(), req.IDs)
+	if err != nil {
+		apierror.FromError(c, err, accountErrors...)
+		return
+	}
+	// 3. 返回用户列表（按请求顺序，不包含密码和Token）
+	c.JSON(200, accounts)
+}
+
 // FindByUsername 处理按用户名查询用户请求
 // 前端请求：POST /account/findByUsername
 // 请求体：{"username": "alice"}
+// 公开接口，按客户端IP限流（超出返回 429）；用户不存在返回 404 ACCOUNT_NOT_FOUND
 func (h *AccountHandler) FindByUsername(c *gin.Context) {
 	// 1. 解析请求体到 FindByUsernameRequest 结构体
 	var req FindByUsernameRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(400, gin.H{"error": err.Error()})
+		apierror.BadRequest(c, err)
 		return
 	}
 	// 2. 调用Service层查询用户
-	if account, err := h.accountService.FindByUsername(c.Request.Context(), req.Username); err != nil {
-		  // 查询失败，返回500错误
-		c.JSON(500, gin.H{"error": err.Error()})
+	if account, err := h.accountService.LookupByUsername(c.Request.Context(), req.Username, c.ClientIP()); err != nil {
+		// 查询失败：限流返回429，不存在返回404，其余返回500（不透出数据库错误）
+		apierror.FromError(c, err, accountErrors...)
 		return
 	} else {
 		// 查询成功，返回用户信息
@@ -171,7 +201,7 @@ func (h *AccountHandler) Login(c *gin.Context) {
 	var req LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		// 解析失败，返回400错误
-		c.JSON(400, gin.H{"error": err.Error()})
+		apierror.BadRequest(c, err)
 		return
 	}
 	
@@ -182,8 +212,8 @@ func (h *AccountHandler) Login(c *gin.Context) {
   // - 生成JWT Token
   // - 将Token存入数据库和Redis缓存
 	if token, err := h.accountService.Login(c.Request.Context(), req.Username, req.Password); err != nil {
-		 // 登录失败（用户不存在或密码错误），返回500错误
-		c.JSON(500, gin.H{"error": err.Error()})
+		 // 登录失败（用户不存在或密码错误返回401，其他错误返回500）
+		apierror.FromError(c, err, accountErrors...)
 		return
 	} else {
 		 // 登录成功，返回Token给前端
@@ -199,8 +229,8 @@ func (h *AccountHandler) Logout(c *gin.Context) {
   // 这个ID是由JWTAuth中间件验证Token后设置的
 	accountID, err := getAccountID(c)
 	if err != nil {
-		// 未登录（上下文中没有accountID），返回400错误
-		c.JSON(400, gin.H{"error": err.Error()})
+		// 未登录（上下文中没有accountID），返回401错误
+		apierror.Unauthorized(c)
 		return
 	}
 	// 2. 调用Service层处理登出逻辑
@@ -209,13 +239,106 @@ func (h *AccountHandler) Logout(c *gin.Context) {
   // - 删除Redis缓存中的Token
 	if err := h.accountService.Logout(c.Request.Context(), accountID); err != nil {
 		 // 登出失败，返回500错误
-		c.JSON(500, gin.H{"error": err.Error()})
+		apierror.FromError(c, err, accountErrors...)
 		return
 	}
 	 // 登出成功，返回成功消息
 	c.JSON(200, gin.H{"message": "account logged out"})
 }
 
+// Me 处理查询当前登录用户信息请求
+// 前端请求：POST /account/me
+// 请求头：Authorization: Bearer eyJhbGc...
+// 响应：{"id": 1, "username": "alice", "followers_count": 0, "following_count": 0, "last_login_at": "...", "email": null, "email_verified": false}
+func (h *AccountHandler) Me(c *gin.Context) {
+	// 1. 从Gin上下文中获取当前用户ID
+	accountID, err := getAccountID(c)
+	if err != nil {
+		apierror.Unauthorized(c)
+		return
+	}
+	// 2. 查询账户信息（包含最近登录时间，公开资料接口不返回该字段）
+	me, err := h.accountService.Me(c.Request.Context(), accountID)
+	if err != nil {
+		apierror.FromError(c, err, accountErrors...)
+		return
+	}
+	c.JSON(200, me)
+}
+
+// SetEmail 处理设置邮箱请求
+// 前端请求：POST /account/setEmail
+// 请求体：{"email": "alice@example.com", "reauth_token": "..."}（reauth_token 由 /account/verifyPassword 获取）
+// 请求头：Authorization: Bearer eyJhbGc...
+// 设置后邮箱处于未验证状态，需要使用验证 token 调用 /account/verifyEmail
+func (h *AccountHandler) SetEmail(c *gin.Context) {
+	// 1. 解析请求体
+	var req SetEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.BadRequest(c, err)
+		return
+	}
+	// 2. 从Gin上下文中获取当前用户ID
+	accountID, err := getAccountID(c)
+	if err != nil {
+		apierror.Unauthorized(c)
+		return
+	}
+	// 3. 保存邮箱并发送验证 token
+	if err := h.accountService.SetEmail(c.Request.Context(), accountID, req.Email, req.ReauthToken); err != nil {
+		apierror.FromError(c, err, accountErrors...)
+		return
+	}
+	c.JSON(200, gin.H{"message": "verification sent"})
+}
+
+// VerifyPassword 处理二次验证请求
+// 前端请求：POST /account/verifyPassword
+// 请求体：{"password": "123456"}
+// 请求头：Authorization: Bearer eyJhbGc...
+// 密码正确时返回 5 分钟内有效的一次性 reauth_token，敏感操作（如 /account/setEmail）需要携带；
+// 每个账户每 15 分钟最多尝试 5 次
+func (h *AccountHandler) VerifyPassword(c *gin.Context) {
+	// 1. 解析请求体
+	var req VerifyPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.BadRequest(c, err)
+		return
+	}
+	// 2. 从Gin上下文中获取当前用户ID
+	accountID, err := getAccountID(c)
+	if err != nil {
+		apierror.Unauthorized(c)
+		return
+	}
+	// 3. 校验密码并生成二次验证 token
+	token, expiresAt, err := h.accountService.VerifyPassword(c.Request.Context(), accountID, req.Password)
+	if err != nil {
+		apierror.FromError(c, err, accountErrors...)
+		return
+	}
+	c.JSON(200, VerifyPasswordResponse{ReauthToken: token, ExpiresAt: expiresAt})
+}
+
+// VerifyEmail 处理邮箱验证请求
+// 前端请求：POST /account/verifyEmail
+// 请求体：{"token": "..."}
+// 不需要登录：token 本身即凭证（用户可能在其他设备上打开验证链接）
+func (h *AccountHandler) VerifyEmail(c *gin.Context) {
+	// 1. 解析请求体
+	var req VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.BadRequest(c, err)
+		return
+	}
+	// 2. 校验 token 并标记邮箱已验证
+	if err := h.accountService.VerifyEmail(c.Request.Context(), req.Token); err != nil {
+		apierror.FromError(c, err, accountErrors...)
+		return
+	}
+	c.JSON(200, gin.H{"message": "email verified"})
+}
+
 // getAccountID 从Gin上下文中获取当前用户ID
 // 这个ID是由JWTAuth中间件验证Token后设置的
 func getAccountID(c *gin.Context) (uint, error) {
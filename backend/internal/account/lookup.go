@@ -0,0 +1,55 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// 按用户名查询的限流：每个客户端IP每分钟最多查询 usernameLookupLimit 次
+// 用户名本身会出现在视频作者、评论等公开内容中，接口保持公开；限流只用于提高批量枚举用户名的成本
+const (
+	usernameLookupLimit  = 30
+	usernameLookupWindow = time.Minute
+)
+
+// ErrTooManyLookups 按用户名查询过于频繁
+var ErrTooManyLookups = errors.New("too many username lookups, try again later")
+
+// LookupByUsername 按用户名查询账户（公开接口 /account/findByUsername 使用）
+// 与 FindByUsername 的区别：先按客户端IP限流；账户不存在时返回 gorm.ErrRecordNotFound，由处理器统一映射为 404
+// 参数：
+//   - ctx: 上下文
+//   - username: 用户名
+//   - clientIP: 客户端IP（限流维度）
+// 返回：
+//   - *Account: 账户信息指针
+//   - error: 错误信息
+func (as *AccountService) LookupByUsername(ctx context.Context, username string, clientIP string) (*Account, error) {
+	if err := as.checkLookupRateLimit(ctx, clientIP); err != nil {
+		return nil, err
+	}
+	return as.FindByUsername(ctx, username)
+}
+
+// checkLookupRateLimit 按客户端IP的固定窗口计数限流
+// Redis 不可用时不限流（只记录日志），避免缓存故障导致接口不可用
+func (as *AccountService) checkLookupRateLimit(ctx context.Context, clientIP string) error {
+	if as.cache == nil || clientIP == "" {
+		return nil
+	}
+	opCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	key := fmt.Sprintf("account:lookup:rate:ip=%s", clientIP)
+	n, err := as.cache.IncrWithTTL(opCtx, key, usernameLookupWindow)
+	if err != nil {
+		log.Printf("username lookup rate limit: redis unavailable, skip: %v", err)
+		return nil
+	}
+	if n > usernameLookupLimit {
+		return ErrTooManyLookups
+	}
+	return nil
+}
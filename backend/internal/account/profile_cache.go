@@ -0,0 +1,36 @@
+package account
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	rediscache "feedsystem_video_go/internal/middleware/redis"
+)
+
+// profileCacheTTL 账户公开资料缓存有效期
+// 粉丝数/关注数也在资料中，关注变化时会主动失效，TTL 只作为兜底
+const profileCacheTTL = 10 * time.Minute
+
+// ProfileCacheKey 账户公开资料缓存键（与 token 缓存键 account:{id} 区分）
+func ProfileCacheKey(id uint) string {
+	return fmt.Sprintf("account:profile:%d", id)
+}
+
+// InvalidateProfileCache 删除账户公开资料缓存（改名、粉丝数/关注数变化时调用）
+// 缓存不可用或删除失败只记录日志，最迟在 TTL 到期后恢复一致
+func InvalidateProfileCache(ctx context.Context, cache *rediscache.Client, ids ...uint) {
+	if cache == nil || len(ids) == 0 {
+		return
+	}
+	keys := make([]string, 0, len(ids))
+	for _, id := range ids {
+		keys = append(keys, ProfileCacheKey(id))
+	}
+	opCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	if err := cache.DelMany(opCtx, keys); err != nil {
+		log.Printf("failed to invalidate account profile cache %v: %v", ids, err)
+	}
+}
@@ -0,0 +1,105 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// 二次验证（step-up）：敏感操作（如更换邮箱）前重新输入密码，换取一个短期有效的一次性 token
+// token 保存在 Redis 中（account:reauth:{token} -> 账户ID），5 分钟有效，使用后删除
+const (
+	reauthKeyPrefix = "account:reauth:"
+	reauthTTL       = 5 * time.Minute
+
+	// 密码校验限流：每个账户每 15 分钟最多尝试 reauthAttemptLimit 次（防止拿到登录 token 后猜测密码）
+	reauthAttemptLimit  = 5
+	reauthAttemptWindow = 15 * time.Minute
+)
+
+var (
+	ErrReauthRequired    = errors.New("password re-verification required")                   // 缺少二次验证 token、已过期或不属于当前账户
+	ErrWrongPassword     = errors.New("wrong password")                                      // 密码错误
+	ErrTooManyReauth     = errors.New("too many password attempts, try again later")         // 密码校验过于频繁
+	ErrReauthUnavailable = errors.New("password re-verification is temporarily unavailable") // Redis 不可用，无法保存二次验证 token
+)
+
+// VerifyPassword 校验当前用户的密码，成功后返回二次验证 token
+// 参数：
+//   - ctx: 上下文
+//   - accountID: 当前登录用户ID
+//   - password: 当前密码
+//
+// 返回：
+//   - string: 二次验证 token（5 分钟内有效，只能使用一次）
+//   - time.Time: token 过期时间
+//   - error: 错误信息
+func (as *AccountService) VerifyPassword(ctx context.Context, accountID uint, password string) (string, time.Time, error) {
+	// 没有 Redis 时既无法限流也无法保存 token，直接拒绝
+	if as.cache == nil {
+		return "", time.Time{}, ErrReauthUnavailable
+	}
+
+	// 1. 限流（按账户计数，成功和失败都计入）
+	// 每次 Redis 操作使用独立的超时：中间的数据库查询和 bcrypt 校验耗时较长，不能占用 Redis 操作的时间
+	rateCtx, rateCancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	n, err := as.cache.IncrWithTTL(rateCtx, fmt.Sprintf("account:reauth:rate:account=%d", accountID), reauthAttemptWindow)
+	rateCancel()
+	if err != nil {
+		return "", time.Time{}, ErrReauthUnavailable
+	}
+	if n > reauthAttemptLimit {
+		return "", time.Time{}, ErrTooManyReauth
+	}
+
+	// 2. 校验密码（以数据库为准，资料缓存中不包含密码）
+	account, err := as.accountRepository.FindByID(ctx, accountID)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(account.Password), []byte(password)); err != nil {
+		return "", time.Time{}, ErrWrongPassword
+	}
+
+	// 3. 生成 token 存入 Redis
+	token, err := newVerificationToken()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	expiresAt := time.Now().Add(reauthTTL)
+	setCtx, setCancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer setCancel()
+	if err := as.cache.SetBytes(setCtx, reauthKeyPrefix+token, []byte(strconv.FormatUint(uint64(accountID), 10)), reauthTTL); err != nil {
+		return "", time.Time{}, ErrReauthUnavailable
+	}
+	return token, expiresAt, nil
+}
+
+// consumeReauthToken 校验并消费二次验证 token（敏感操作执行前调用）
+// token 必须属于当前账户；校验和删除在 Redis 中原子完成（值等于账户ID时才删除），并发请求只有一个能用掉同一个 token
+func (as *AccountService) consumeReauthToken(ctx context.Context, accountID uint, token string) error {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return ErrReauthRequired
+	}
+	if as.cache == nil {
+		return ErrReauthUnavailable
+	}
+
+	opCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	consumed, err := as.cache.DelIfEqual(opCtx, reauthKeyPrefix+token, strconv.FormatUint(uint64(accountID), 10))
+	if err != nil {
+		return ErrReauthUnavailable
+	}
+	if !consumed {
+		// token 不存在、已过期、已被使用或属于其他账户
+		return ErrReauthRequired
+	}
+	return nil
+}
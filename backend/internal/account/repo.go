@@ -2,6 +2,7 @@ package account
 
 import (
 	"context"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -48,6 +49,22 @@ func (ar *AccountRepository) RenameWithToken(ctx context.Context, id uint, newUs
 	})
 }
 
+// SyncUsername 将视频、评论表中冗余存储的用户名同步为最新值
+// 只更新与目标用户名不一致的行，重复执行结果不变（幂等）；视频同时更新 updated_at（详情接口的 ETag 随之变化）
+// 注意：account 包不能引用 video 包（会产生循环依赖），因此直接按表名更新
+func (ar *AccountRepository) SyncUsername(ctx context.Context, id uint, username string) error {
+	return ar.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Table("videos").
+			Where("author_id = ? AND username <> ?", id, username).
+			UpdateColumns(map[string]any{"username": username, "updated_at": time.Now()}).Error; err != nil {
+			return err
+		}
+		return tx.Table("comments").
+			Where("author_id = ? AND username <> ?", id, username).
+			UpdateColumn("username", username).Error
+	})
+}
+
 func (ar *AccountRepository) ChangePassword(ctx context.Context, id uint, newPassword string) error {
 	if err := ar.db.WithContext(ctx).Model(&Account{}).Where("id = ?", id).Update("password", newPassword).Error; err != nil {
 		return err
@@ -63,6 +80,18 @@ func (ar *AccountRepository) FindByID(ctx context.Context, id uint) (*Account, e
 	return &account, nil
 }
 
+// FindByIDs 批量查询账户（一次 WHERE id IN 查询，不存在的ID不返回）
+func (ar *AccountRepository) FindByIDs(ctx context.Context, ids []uint) ([]Account, error) {
+	var accounts []Account
+	if len(ids) == 0 {
+		return accounts, nil
+	}
+	if err := ar.db.WithContext(ctx).Where("id IN ?", ids).Find(&accounts).Error; err != nil {
+		return nil, err
+	}
+	return accounts, nil
+}
+
 func (ar *AccountRepository) FindByUsername(ctx context.Context, username string) (*Account, error) {
 	var account Account
 	if err := ar.db.WithContext(ctx).Where("username = ?", username).First(&account).Error; err != nil {
@@ -71,13 +100,34 @@ func (ar *AccountRepository) FindByUsername(ctx context.Context, username string
 	return &account, nil
 }
 
-func (ar *AccountRepository) Login(ctx context.Context, id uint, token string) error {
-	if err := ar.db.WithContext(ctx).Model(&Account{}).Where("id = ?", id).Update("token", token).Error; err != nil {
+// Login 保存登录 token 并记录登录时间（同一条 UPDATE）
+func (ar *AccountRepository) Login(ctx context.Context, id uint, token string, loginAt time.Time) error {
+	if err := ar.db.WithContext(ctx).Model(&Account{}).Where("id = ?", id).
+		Updates(map[string]any{"token": token, "last_login_at": loginAt}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// SetEmail 设置邮箱并重置验证状态
+func (ar *AccountRepository) SetEmail(ctx context.Context, id uint, email string) error {
+	if err := ar.db.WithContext(ctx).Model(&Account{}).Where("id = ?", id).
+		Updates(map[string]any{"email": email, "email_verified": false}).Error; err != nil {
 		return err
 	}
 	return nil
 }
 
+// MarkEmailVerified 把邮箱标记为已验证（只有邮箱仍为 email 时才更新），返回是否更新
+func (ar *AccountRepository) MarkEmailVerified(ctx context.Context, id uint, email string) (bool, error) {
+	result := ar.db.WithContext(ctx).Model(&Account{}).Where("id = ? AND email = ?", id, email).
+		Update("email_verified", true)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
 func (ar *AccountRepository) Logout(ctx context.Context, id uint) error {
 	if err := ar.db.WithContext(ctx).Model(&Account{}).Where("id = ?", id).Update("token", "").Error; err != nil {
 		return err
@@ -2,12 +2,14 @@ package account
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"feedsystem_video_go/internal/auth"
 	"fmt"
 	"log"
 	"time"
 
+	"feedsystem_video_go/internal/middleware/rabbitmq"
 	rediscache "feedsystem_video_go/internal/middleware/redis"
 
 	"github.com/go-sql-driver/mysql"
@@ -21,25 +23,37 @@ import (
 type AccountService struct {
 	accountRepository *AccountRepository // 账户仓储层，负责数据库操作
 	cache             *rediscache.Client // Redis缓存客户端，用于缓存账户token信息
+	accountMQ         *rabbitmq.AccountMQ // 账户消息队列，异步同步冗余用户名
+	lifecycleMQ       *rabbitmq.AccountLifecycleMQ // 账户生命周期消息队列，注册后异步执行引导操作（可能为 nil）
+	logEmailTokens    bool               // 仅开发环境：邮箱验证 token 原文写入日志（auth.dev_log_email_tokens）
 }
 
 var (
-	ErrUsernameTaken       = errors.New("username already exists") // 用户名已被占用
-	ErrNewUsernameRequired = errors.New("new_username is required") // 新用户名不能为空
+	ErrUsernameTaken       = errors.New("username already exists")                       // 用户名已被占用
+	ErrInvalidCredentials  = errors.New("invalid username or password")                  // 用户名或密码错误
+	ErrNewUsernameRequired = errors.New("new_username is required")                      // 新用户名不能为空
+	ErrTooManyIDs          = fmt.Errorf("at most %d ids per request", maxFindByIDsBatch) // 批量查询的ID数量超过上限
 )
 
+// maxFindByIDsBatch 批量查询账户的单次最大ID数量
+const maxFindByIDsBatch = 100
+
 // NewAccountService 创建账户服务实例
 // 参数：
 //   - accountRepository: 账户仓储层，用于数据库操作
 //   - cache: Redis缓存客户端，用于缓存token等数据
-func NewAccountService(accountRepository *AccountRepository, cache *rediscache.Client) *AccountService {
-	return &AccountService{accountRepository: accountRepository, cache: cache}
+//   - accountMQ: 账户消息队列（可能为 nil）
+//   - lifecycleMQ: 账户生命周期消息队列（可能为 nil）
+//   - logEmailTokens: 是否把邮箱验证 token 原文写入日志（仅开发环境）
+func NewAccountService(accountRepository *AccountRepository, cache *rediscache.Client, accountMQ *rabbitmq.AccountMQ, lifecycleMQ *rabbitmq.AccountLifecycleMQ, logEmailTokens bool) *AccountService {
+	return &AccountService{accountRepository: accountRepository, cache: cache, accountMQ: accountMQ, lifecycleMQ: lifecycleMQ, logEmailTokens: logEmailTokens}
 }
 
 // CreateAccount 创建新账户
 // 业务流程：
 // 1. 使用bcrypt对密码进行哈希加密（ bcrypt.DefaultCost = 10 ）
 // 2. 调用Repository层将账户信息存入数据库
+// 3. 发送 account.created 事件，由Worker执行引导操作（如欢迎通知）
 // 参数：
 //   - ctx: 上下文，用于控制请求超时和取消
 //   - account: 待创建的账户信息（包含明文密码）
@@ -53,10 +67,21 @@ func (as *AccountService) CreateAccount(ctx context.Context, account *Account) e
 	// 将哈希后的密码赋值回account对象
 	account.Password = string(passwordHash)
 
-	// 调用Repository层将账户信息存入数据库
+	// 调用Repository层将账户信息存入数据库（用户名唯一索引冲突说明已被占用）
 	if err := as.accountRepository.CreateAccount(ctx, account); err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
+			return ErrUsernameTaken
+		}
 		return err
 	}
+
+	// 引导操作只是锦上添花：MQ不可用时跳过，发送失败只记录日志，不影响注册结果
+	if as.lifecycleMQ != nil {
+		if err := as.lifecycleMQ.Created(ctx, account.ID, account.Username); err != nil {
+			log.Printf("failed to publish account.created for account %d: %v", account.ID, err)
+		}
+	}
 	return nil
 }
 
@@ -66,6 +91,7 @@ func (as *AccountService) CreateAccount(ctx context.Context, account *Account) e
 // 2. 基于新用户名生成新的JWT token
 // 3. 在数据库事务中更新用户名和token
 // 4. 将新token存入Redis缓存（24小时过期）
+// 5. 同步视频、评论中冗余存储的用户名（优先发送MQ事件，失败时直接更新数据库）
 // 参数：
 //   - ctx: 上下文
 //   - accountID: 账户ID
@@ -108,6 +134,20 @@ func (as *AccountService) Rename(ctx context.Context, accountID uint, newUsernam
 			log.Printf("failed to set cache: %v", err)
 		}
 	}
+
+	// 用户名已变化，删除公开资料缓存
+	InvalidateProfileCache(ctx, as.cache, accountID)
+
+	// 同步冗余用户名：优先交给Worker异步处理
+	if as.accountMQ != nil {
+		if err := as.accountMQ.Renamed(ctx, accountID, newUsername); err == nil {
+			return token, nil
+		}
+	}
+	// Fallback: MQ不可用时直接更新数据库（改名已成功，同步失败只记录日志）
+	if err := as.accountRepository.SyncUsername(ctx, accountID, newUsername); err != nil {
+		log.Printf("failed to sync username for account %d: %v", accountID, err)
+	}
 	return token, nil
 }
 
@@ -124,16 +164,19 @@ func (as *AccountService) Rename(ctx context.Context, accountID uint, newUsernam
 //   - oldPassword: 旧密码（明文）
 //   - newPassword: 新密码（明文）
 func (as *AccountService) ChangePassword(ctx context.Context, username, oldPassword, newPassword string) error {
-	// 根据用户名查询账户信息
+	// 根据用户名查询账户信息（用户不存在与密码错误返回同一个错误，避免暴露用户名是否存在）
 	account, err := as.FindByUsername(ctx, username)
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidCredentials
+		}
 		return err
 	}
 
 	// 验证旧密码是否正确（bcrypt对比）
 	// CompareHashAndPassword会自动处理bcrypt的salt，无需手动处理
 	if err := bcrypt.CompareHashAndPassword([]byte(account.Password), []byte(oldPassword)); err != nil {
-		return err
+		return ErrInvalidCredentials
 	}
 
 	// 使用bcrypt对新密码进行哈希加密
@@ -154,7 +197,9 @@ func (as *AccountService) ChangePassword(ctx context.Context, username, oldPassw
 	return nil
 }
 
-// FindByID 根据账户ID查询账户信息
+// FindByID 根据账户ID查询账户公开信息
+// 优先读取 Redis 缓存（account:profile:{id}），未命中时查询数据库并回填
+// 注意：缓存中不包含密码和token，需要这些字段时请直接使用 Repository
 // 参数：
 //   - ctx: 上下文
 //   - id: 账户ID
@@ -162,11 +207,83 @@ func (as *AccountService) ChangePassword(ctx context.Context, username, oldPassw
 //   - *Account: 账户信息指针
 //   - error: 错误信息
 func (as *AccountService) FindByID(ctx context.Context, id uint) (*Account, error) {
-	if account, err := as.accountRepository.FindByID(ctx, id); err != nil {
+	cacheKey := ProfileCacheKey(id)
+
+	// 1. 优先读取缓存
+	if as.cache != nil {
+		opCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		b, err := as.cache.GetBytes(opCtx, cacheKey)
+		cancel()
+		if err == nil {
+			var cached Account
+			if err := json.Unmarshal(b, &cached); err == nil {
+				return &cached, nil
+			}
+		}
+	}
+
+	// 2. 缓存未命中，查询数据库
+	account, err := as.accountRepository.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. 回填缓存（只包含公开字段，密码和token不会被序列化）
+	if as.cache != nil {
+		if b, err := json.Marshal(account); err == nil {
+			opCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+			if err := as.cache.SetBytes(opCtx, cacheKey, b, profileCacheTTL); err != nil {
+				log.Printf("failed to set account profile cache: %v", err)
+			}
+			cancel()
+		}
+	}
+	return account, nil
+}
+
+// FindByIDs 批量查询账户信息（用于渲染点赞用户、@提及等ID列表）
+// 业务流程：
+// 1. 去重并忽略 0，ID数量超过 100 时返回 ErrTooManyIDs
+// 2. 一次 WHERE id IN 查询
+// 3. 按请求顺序返回，不存在的ID直接跳过
+// 参数：
+//   - ctx: 上下文
+//   - ids: 账户ID列表
+// 返回：
+//   - []*Account: 账户列表（按请求顺序）
+//   - error: 错误信息
+func (as *AccountService) FindByIDs(ctx context.Context, ids []uint) ([]*Account, error) {
+	unique := make([]uint, 0, len(ids))
+	seen := make(map[uint]struct{}, len(ids))
+	for _, id := range ids {
+		if id == 0 {
+			continue
+		}
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		unique = append(unique, id)
+	}
+	if len(unique) > maxFindByIDsBatch {
+		return nil, ErrTooManyIDs
+	}
+
+	accounts, err := as.accountRepository.FindByIDs(ctx, unique)
+	if err != nil {
 		return nil, err
-	} else {
-		return account, nil
 	}
+	byID := make(map[uint]*Account, len(accounts))
+	for i := range accounts {
+		byID[accounts[i].ID] = &accounts[i]
+	}
+	result := make([]*Account, 0, len(accounts))
+	for _, id := range unique {
+		if account, ok := byID[id]; ok {
+			result = append(result, account)
+		}
+	}
+	return result, nil
 }
 
 // FindByUsername 根据用户名查询账户信息
@@ -184,6 +301,22 @@ func (as *AccountService) FindByUsername(ctx context.Context, username string) (
 	}
 }
 
+// IsAdmin 查询账户是否为管理员
+// 以数据库中的 is_admin 为准（资料缓存中不包含该字段）
+// 参数：
+//   - ctx: 上下文
+//   - id: 账户ID
+// 返回：
+//   - bool: 是否为管理员
+//   - error: 错误信息
+func (as *AccountService) IsAdmin(ctx context.Context, id uint) (bool, error) {
+	account, err := as.accountRepository.FindByID(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	return account.IsAdmin, nil
+}
+
 // Login 用户登录
 // 业务流程：
 // 1. 根据用户名查询账户信息
@@ -199,15 +332,18 @@ func (as *AccountService) FindByUsername(ctx context.Context, username string) (
 //   - string: JWT token
 //   - error: 错误信息
 func (as *AccountService) Login(ctx context.Context, username, password string) (string, error) {
-	// 根据用户名查询账户信息
+	// 根据用户名查询账户信息（用户不存在与密码错误返回同一个错误，避免暴露用户名是否存在）
 	account, err := as.FindByUsername(ctx, username)
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", ErrInvalidCredentials
+		}
 		return "", err
 	}
 
 	// 使用bcrypt验证密码是否正确
 	if err := bcrypt.CompareHashAndPassword([]byte(account.Password), []byte(password)); err != nil {
-		return "", err
+		return "", ErrInvalidCredentials
 	}
 
 	// 生成JWT token（包含账户ID和用户名）
@@ -216,8 +352,8 @@ func (as *AccountService) Login(ctx context.Context, username, password string)
 		return "", err
 	}
 
-	// 将token存入数据库（用于后续的软鉴权和登出操作）
-	if err := as.accountRepository.Login(ctx, account.ID, token); err != nil {
+	// 将token存入数据库（用于后续的软鉴权和登出操作），同时记录登录时间
+	if err := as.accountRepository.Login(ctx, account.ID, token, time.Now()); err != nil {
 		return "", err
 	}
 
@@ -233,6 +369,30 @@ func (as *AccountService) Login(ctx context.Context, username, password string)
 	return token, nil
 }
 
+// Me 查询当前登录用户的账户信息（包含最近登录时间等非公开字段）
+// 直接查询数据库：资料缓存只包含公开字段
+// 参数：
+//   - ctx: 上下文
+//   - accountID: 当前登录用户ID
+// 返回：
+//   - *MeResponse: 账户信息
+//   - error: 错误信息
+func (as *AccountService) Me(ctx context.Context, accountID uint) (*MeResponse, error) {
+	account, err := as.accountRepository.FindByID(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	return &MeResponse{
+		ID:             account.ID,
+		Username:       account.Username,
+		FollowersCount: account.FollowersCount,
+		FollowingCount: account.FollowingCount,
+		LastLoginAt:    account.LastLoginAt,
+		Email:          account.Email,
+		EmailVerified:  account.EmailVerified,
+	}, nil
+}
+
 // Logout 用户登出
 // 业务流程：
 // 1. 查询账户信息，检查是否已登录（token是否为空）
@@ -242,8 +402,8 @@ func (as *AccountService) Login(ctx context.Context, username, password string)
 //   - ctx: 上下文
 //   - accountID: 账户ID
 func (as *AccountService) Logout(ctx context.Context, accountID uint) error {
-	// 查询账户信息
-	account, err := as.FindByID(ctx, accountID)
+	// 查询账户信息（需要 token 字段，不能走资料缓存）
+	account, err := as.accountRepository.FindByID(ctx, accountID)
 	if err != nil {
 		return err
 	}
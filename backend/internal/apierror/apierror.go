@@ -0,0 +1,106 @@
+// Package apierror 定义统一的错误响应格式
+// 所有接口的错误响应都是 {"code": "VIDEO_NOT_FOUND", "message": "video not found"}
+// code 是稳定的机器可读错误码，客户端应按 code 判断错误类型，message 仅用于展示和排查
+package apierror
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"feedsystem_video_go/internal/middleware/trace"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 通用错误码（各业务包的专属错误码在各自的 handler 中定义）
+const (
+	CodeInvalidArgument = "INVALID_ARGUMENT"  // 请求参数错误
+	CodeUnauthorized    = "UNAUTHORIZED"      // 未登录或 token 无效
+	CodeForbidden       = "FORBIDDEN"         // 无权限
+	CodeNotFound        = "NOT_FOUND"         // 资源不存在
+	CodeRateLimited     = "RATE_LIMITED"      // 请求过于频繁
+	CodeTooLarge        = "REQUEST_TOO_LARGE" // 请求体过大
+	CodeInternal        = "INTERNAL_ERROR"    // 服务器内部错误
+)
+
+// internalMessage 内部错误对外展示的信息（真实错误只写日志，不返回给客户端）
+const internalMessage = "internal server error"
+
+// Response 统一错误响应体
+// 参数校验失败时 Fields 给出每个字段的错误（JSON 字段名 -> 错误信息），其余错误不返回该字段
+type Response struct {
+	Code    string            `json:"code"`
+	Message string            `json:"message"`
+	Fields  map[string]string `json:"fields,omitempty"`
+}
+
+// Mapping 哨兵错误到 HTTP 状态码和错误码的映射
+// Message 为空时使用 err.Error()（哨兵错误的信息是面向用户编写的，可以直接返回）
+type Mapping struct {
+	Err     error
+	Status  int
+	Code    string
+	Message string
+}
+
+// Write 写入错误响应
+func Write(c *gin.Context, status int, code, message string) {
+	c.JSON(status, Response{Code: code, Message: message})
+}
+
+// Abort 写入错误响应并中止后续处理（用于中间件）
+func Abort(c *gin.Context, status int, code, message string) {
+	c.AbortWithStatusJSON(status, Response{Code: code, Message: message})
+}
+
+// AbortInternal 写入 500 INTERNAL_ERROR 并中止后续处理（用于 panic 恢复等拿不到具体错误的场景）
+func AbortInternal(c *gin.Context) {
+	Abort(c, http.StatusInternalServerError, CodeInternal, internalMessage)
+}
+
+// BadRequest 请求体解析/参数校验失败，返回 400 INVALID_ARGUMENT
+// 请求体超过大小上限导致的解析失败返回 413 REQUEST_TOO_LARGE
+// binding 标签校验失败和 JSON 字段类型错误按字段返回（见 validationFields），不返回 gin/validator 的原始错误信息
+func BadRequest(c *gin.Context, err error) {
+	if IsTooLarge(err) {
+		TooLarge(c)
+		return
+	}
+	message, fields := describeBindError(err)
+	c.JSON(http.StatusBadRequest, Response{Code: CodeInvalidArgument, Message: message, Fields: fields})
+}
+
+// TooLarge 请求体过大，返回 413 REQUEST_TOO_LARGE
+func TooLarge(c *gin.Context) {
+	Write(c, http.StatusRequestEntityTooLarge, CodeTooLarge, "request body too large")
+}
+
+// IsTooLarge 判断错误是否由请求体超过 http.MaxBytesReader 上限引起
+func IsTooLarge(err error) bool {
+	var mbe *http.MaxBytesError
+	return errors.As(err, &mbe)
+}
+
+// Unauthorized 未登录，返回 401 UNAUTHORIZED
+func Unauthorized(c *gin.Context) {
+	Write(c, http.StatusUnauthorized, CodeUnauthorized, "unauthorized")
+}
+
+// FromError 按映射表把错误转换为错误响应
+// 按顺序用 errors.Is 匹配，第一个命中的映射生效；
+// 都不匹配时视为内部错误：记录日志（带追踪ID），对外只返回 500 INTERNAL_ERROR，避免泄露 SQL/GORM 等内部信息
+func FromError(c *gin.Context, err error, mappings ...Mapping) {
+	for _, m := range mappings {
+		if errors.Is(err, m.Err) {
+			message := m.Message
+			if message == "" {
+				message = err.Error()
+			}
+			Write(c, m.Status, m.Code, message)
+			return
+		}
+	}
+	log.Printf("internal error: %s %s trace_id=%s: %v", c.Request.Method, c.FullPath(), trace.FromContext(c.Request.Context()), err)
+	Write(c, http.StatusInternalServerError, CodeInternal, internalMessage)
+}
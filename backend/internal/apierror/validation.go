@@ -0,0 +1,115 @@
+package apierror
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// 注册 JSON 字段名：校验错误中的字段名使用 json 标签（如 video_id），而不是 Go 结构体字段名（VideoID）
+func init() {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return f.Name
+		}
+		return name
+	})
+}
+
+// describeBindError 把 ShouldBindJSON 的错误转换为对外展示的信息和字段错误
+//   - binding 标签校验失败：每个字段一条错误信息
+//   - JSON 字段类型错误（如 id 传了字符串）：该字段的期望类型
+//   - 其他解析错误：只返回通用信息，不透出解析器的原始错误
+func describeBindError(err error) (string, map[string]string) {
+	var verrs validator.ValidationErrors
+	if errors.As(err, &verrs) {
+		return "invalid request", validationFields(verrs)
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return "invalid request", map[string]string{typeErr.Field: "must be " + jsonKind(typeErr.Type)}
+	}
+
+	var syntaxErr *json.SyntaxError
+	switch {
+	case errors.Is(err, io.EOF):
+		return "request body is required", nil
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		return "malformed JSON", nil
+	default:
+		return "invalid request body", nil
+	}
+}
+
+// validationFields 每个校验失败的字段生成一条错误信息（字段名为 JSON 字段名）
+func validationFields(verrs validator.ValidationErrors) map[string]string {
+	fields := make(map[string]string, len(verrs))
+	for _, fe := range verrs {
+		fields[fe.Field()] = validationMessage(fe)
+	}
+	return fields
+}
+
+// validationMessage 按校验标签生成错误信息
+func validationMessage(fe validator.FieldError) string {
+	unit := ""
+	if fe.Kind() == reflect.String {
+		unit = " characters"
+	}
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "gt":
+		return fmt.Sprintf("must be greater than %s", fe.Param())
+	case "gte":
+		return fmt.Sprintf("must be at least %s", fe.Param())
+	case "min":
+		return fmt.Sprintf("must be at least %s%s", fe.Param(), unit)
+	case "max", "lte":
+		return fmt.Sprintf("must be at most %s%s", fe.Param(), unit)
+	case "oneof":
+		return "must be one of: " + strings.ReplaceAll(fe.Param(), " ", ", ")
+	default:
+		return "is invalid"
+	}
+}
+
+// jsonKind 字段期望的 JSON 类型
+func jsonKind(t reflect.Type) string {
+	if t == nil {
+		return "a valid value"
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "an integer"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "a non-negative integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	case reflect.Map, reflect.Struct:
+		return "an object"
+	default:
+		return "a valid value"
+	}
+}
@@ -3,17 +3,25 @@ package auth
 
 import (
 	"errors"
-	"os"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
-func jwtSecret() []byte {
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
-		secret = "change-me-in-env"
+// defaultSecret 未配置密钥时使用的默认值（仅用于本地开发）
+const defaultSecret = "change-me-in-env"
+
+var secret = defaultSecret
+
+// SetSecret 设置 JWT 签名密钥（来自 config.Auth.JWTSecret，环境变量 JWT_SECRET 优先于配置文件）
+// 为空时保留默认值
+func SetSecret(s string) {
+	if s != "" {
+		secret = s
 	}
+}
+
+func jwtSecret() []byte {
 	return []byte(secret)
 }
 
@@ -0,0 +1,194 @@
+package config
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// 运行时可调参数的默认值（配置未指定时使用）
+const (
+	DefaultFeedCacheTTL     = 5 * time.Second
+	DefaultFeedLimit        = 10 // Feed 接口默认返回数量
+	DefaultFeedMaxLimit     = 50 // Feed 接口单次最多返回数量
+	DefaultPopularityWindow = 60 // 热榜聚合的分钟数
+	DefaultReportRateLimit  = 10 // 每个用户每小时最多举报次数
+)
+
+// Tunables 可在运行时热更新的配置子集（收到 SIGHUP 时重新读取配置文件）
+// 只包含修改后无需重建连接、可以安全生效的参数
+type Tunables struct {
+	LogLevel         string        // GORM 日志级别
+	FeedCacheTTL     time.Duration // Feed 缓存过期时间
+	FeedLatestTTL    time.Duration // 最新视频流缓存过期时间（0 表示不缓存）
+	FeedFollowingTTL time.Duration // 关注流缓存过期时间（0 表示不缓存）
+	FeedStaleWindow  time.Duration // 缓存过期后仍返回旧数据并后台刷新的时长（0 表示不返回旧数据）
+	FeedDefaultLimit int           // Feed 接口默认返回数量
+	FeedMaxLimit     int           // Feed 接口单次最多返回数量
+	PopularityWindow int           // 热榜聚合的分钟数
+	ReportRateLimit  int           // 举报限流阈值（每小时）
+}
+
+// tunablesFrom 从完整配置中提取可热更新的参数（未配置的使用默认值）
+func tunablesFrom(cfg Config) Tunables {
+	t := Tunables{
+		LogLevel:         cfg.Database.LogLevel,
+		FeedCacheTTL:     cfg.Feed.CacheTTL,
+		FeedDefaultLimit: cfg.Feed.DefaultLimit,
+		FeedMaxLimit:     cfg.Feed.MaxLimit,
+		FeedStaleWindow:  cfg.Feed.StaleWindow,
+		PopularityWindow: cfg.Feed.PopularityWindow,
+		ReportRateLimit:  cfg.Report.RateLimit,
+	}
+	if t.FeedCacheTTL <= 0 {
+		t.FeedCacheTTL = DefaultFeedCacheTTL
+	}
+	// 按 Feed 类型的过期时间：未配置时使用 cache_ttl，显式配置为 0 时关闭该 Feed 的缓存
+	t.FeedLatestTTL = feedTTL(cfg.Feed.LatestTTL, t.FeedCacheTTL)
+	t.FeedFollowingTTL = feedTTL(cfg.Feed.FollowingTTL, t.FeedCacheTTL)
+	if t.FeedMaxLimit <= 0 {
+		t.FeedMaxLimit = DefaultFeedMaxLimit
+	}
+	if t.FeedDefaultLimit <= 0 {
+		t.FeedDefaultLimit = DefaultFeedLimit
+	}
+	// 默认值不能超过上限（只调小 max_limit 时默认值跟着收紧）
+	if t.FeedDefaultLimit > t.FeedMaxLimit {
+		t.FeedDefaultLimit = t.FeedMaxLimit
+	}
+	if t.PopularityWindow <= 0 {
+		t.PopularityWindow = DefaultPopularityWindow
+	}
+	if t.ReportRateLimit <= 0 {
+		t.ReportRateLimit = DefaultReportRateLimit
+	}
+	return t
+}
+
+// feedTTL 单个 Feed 的缓存过期时间（未配置时使用 fallback，负数按 0 处理）
+func feedTTL(v *time.Duration, fallback time.Duration) time.Duration {
+	if v == nil {
+		return fallback
+	}
+	if *v < 0 {
+		return 0
+	}
+	return *v
+}
+
+// Live 运行时配置：启动时的完整配置 + 可热更新的参数
+// 可热更新的参数存放在 atomic.Pointer 中，读取无锁；nil 的 *Live 返回默认值
+type Live struct {
+	path     string
+	cfg      Config
+	tunables atomic.Pointer[Tunables]
+
+	mu       sync.Mutex
+	onReload []func(Tunables)
+}
+
+// NewLive 创建运行时配置
+// 参数：
+//   - path: 配置文件路径（SIGHUP 时重新读取）
+//   - cfg: 启动时加载的完整配置
+func NewLive(path string, cfg Config) *Live {
+	l := &Live{path: path, cfg: cfg}
+	t := tunablesFrom(cfg)
+	l.tunables.Store(&t)
+	return l
+}
+
+// Config 返回启动时加载的完整配置（不随热更新变化）
+func (l *Live) Config() Config {
+	if l == nil {
+		return Config{}
+	}
+	return l.cfg
+}
+
+// Tunables 返回当前生效的可热更新参数
+func (l *Live) Tunables() Tunables {
+	if l == nil {
+		return tunablesFrom(Config{})
+	}
+	return *l.tunables.Load()
+}
+
+// OnReload 注册热更新回调（用于需要主动应用新值的组件，如 GORM 日志级别）
+func (l *Live) OnReload(fn func(Tunables)) {
+	if l == nil || fn == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.onReload = append(l.onReload, fn)
+}
+
+// Reload 重新读取配置文件并更新可热更新的参数
+// 不可热更新的配置（端口、数据库/Redis/RabbitMQ 连接信息等）发生变化时只记录警告，需要重启才会生效
+func (l *Live) Reload() error {
+	cfg, err := Load(l.path)
+	if err != nil {
+		return err
+	}
+
+	warnStatic("server", l.cfg.Server, cfg.Server)
+	warnStatic("database", staticDatabase(l.cfg.Database), staticDatabase(cfg.Database))
+	warnStatic("redis", l.cfg.Redis, cfg.Redis)
+	warnStatic("rabbitmq", l.cfg.RabbitMQ, cfg.RabbitMQ)
+	warnStatic("video", l.cfg.Video, cfg.Video)
+	warnStatic("comment", l.cfg.Comment, cfg.Comment)
+	warnStatic("auth", l.cfg.Auth, cfg.Auth)
+	warnStatic("storage", l.cfg.Storage, cfg.Storage)
+
+	t := tunablesFrom(cfg)
+	l.tunables.Store(&t)
+
+	l.mu.Lock()
+	callbacks := append([]func(Tunables){}, l.onReload...)
+	l.mu.Unlock()
+	for _, fn := range callbacks {
+		fn(t)
+	}
+	log.Printf("config reloaded: log_level=%q feed_latest_ttl=%s feed_following_ttl=%s feed_stale_window=%s feed_limit=%d/%d popularity_window=%d report_rate_limit=%d",
+		t.LogLevel, t.FeedLatestTTL, t.FeedFollowingTTL, t.FeedStaleWindow, t.FeedDefaultLimit, t.FeedMaxLimit, t.PopularityWindow, t.ReportRateLimit)
+	return nil
+}
+
+// WatchSIGHUP 收到 SIGHUP 信号时重新加载配置，直到 ctx 取消
+func (l *Live) WatchSIGHUP(ctx context.Context) {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGHUP)
+	go func() {
+		defer signal.Stop(sigCh)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-sigCh:
+				if err := l.Reload(); err != nil {
+					log.Printf("config reload failed (keeping current values): %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// staticDatabase 去掉数据库配置中可热更新的字段（日志级别），只保留需要重启才能生效的部分
+func staticDatabase(c DatabaseConfig) DatabaseConfig {
+	c.LogLevel = ""
+	return c
+}
+
+// warnStatic 不可热更新的配置发生变化时记录警告
+func warnStatic(section string, old, new interface{}) {
+	if !reflect.DeepEqual(old, new) {
+		log.Printf("config reload: changes to %q are ignored until restart", section)
+	}
+}
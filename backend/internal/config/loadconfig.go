@@ -1,7 +1,13 @@
 package config
 
 import (
+	"fmt"
 	"io/ioutil"
+	"path"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -11,34 +17,196 @@ type Config struct {
 	Database DatabaseConfig `yaml:"database"`
 	Redis    RedisConfig    `yaml:"redis"`
 	RabbitMQ RabbitMQConfig `yaml:"rabbitmq"`
+	Video    VideoConfig    `yaml:"video"`
+	Comment  CommentConfig  `yaml:"comment"`
+	Feed     FeedConfig     `yaml:"feed"`
+	Report   ReportConfig   `yaml:"report"`
+	Auth     AuthConfig     `yaml:"auth"`
+	Worker   WorkerConfig   `yaml:"worker"`
+	Startup  StartupConfig  `yaml:"startup"`
+	Storage  StorageConfig  `yaml:"storage"`
+}
+
+// 环境变量覆盖：带 env 标签的字段在 Load 时会被同名环境变量覆盖（环境变量 > YAML），
+// 便于容器部署时通过环境变量注入密码等敏感信息
+
+// StartupConfig 启动时等待依赖服务的配置
+type StartupConfig struct {
+	WaitTimeout time.Duration `yaml:"wait_timeout" env:"STARTUP_WAIT_TIMEOUT"` // MySQL（及 Worker 的 RabbitMQ）未就绪时最长等待时间（如 60s），0 表示不等待、连接失败立即退出
+}
+
+// StorageConfig 上传文件存储配置（上传接口、/static 访问、已删除视频清理和未发布文件清理共用）
+type StorageConfig struct {
+	UploadDir    string `yaml:"upload_dir" env:"STORAGE_UPLOAD_DIR"` // 上传文件根目录（默认 .run/uploads），启动时校验可写
+	StaticPrefix string `yaml:"static_prefix"`                       // 上传文件的访问路径前缀（默认 /static），修改后已发布视频的地址不会随之改变
+}
+
+// 上传文件存储的默认值
+const (
+	defaultUploadDir    = ".run/uploads"
+	defaultStaticPrefix = "/static"
+)
+
+// UploadRoot 返回上传文件根目录（未配置时使用默认值 .run/uploads）
+func (c StorageConfig) UploadRoot() string {
+	dir := strings.TrimSpace(c.UploadDir)
+	if dir == "" {
+		dir = defaultUploadDir
+	}
+	return filepath.Clean(dir)
+}
+
+// URLPrefix 返回规范化的访问路径前缀：以 / 开头、不以 / 结尾（未配置时使用默认值 /static）
+func (c StorageConfig) URLPrefix() string {
+	prefix := strings.Trim(strings.TrimSpace(c.StaticPrefix), "/")
+	if prefix == "" {
+		return defaultStaticPrefix
+	}
+	return "/" + path.Clean(prefix)
 }
 
 type ServerConfig struct {
-	Port int `yaml:"port"`
+	Port int        `yaml:"port" env:"SERVER_PORT"`
+	Gzip GzipConfig `yaml:"gzip"`
+}
+
+// GzipConfig Feed/列表接口响应压缩配置（由反向代理负责压缩时可以关闭）
+type GzipConfig struct {
+	Enabled bool `yaml:"enabled"`  // 是否启用 gzip 压缩
+	MinSize int  `yaml:"min_size"` // 最小压缩长度（字节，默认 1024），更小的响应体不压缩
 }
 
 type DatabaseConfig struct {
-	Host     string `yaml:"host"`
-	Port     int    `yaml:"port"`
-	User     string `yaml:"user"`
-	Password string `yaml:"password"`
-	DBName   string `yaml:"dbname"`
+	Host     string `yaml:"host" env:"DB_HOST"`
+	Port     int    `yaml:"port" env:"DB_PORT"`
+	User     string `yaml:"user" env:"DB_USER"`
+	Password string `yaml:"password" env:"DB_PASSWORD"`
+	DBName   string `yaml:"dbname" env:"DB_NAME"`
+
+	// 连接池配置（为 0 时使用 db 包中的默认值）
+	MaxOpenConns    int           `yaml:"max_open_conns"`     // 最大打开连接数
+	MaxIdleConns    int           `yaml:"max_idle_conns"`     // 最大空闲连接数
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime"` // 连接最大存活时间（如 30m）
+
+	// 日志与监控配置
+	LogLevel      string        `yaml:"log_level"`      // GORM 日志级别：silent/error/warn/info（默认 warn，支持 SIGHUP 热更新）
+	SlowThreshold time.Duration `yaml:"slow_threshold"` // 慢查询阈值（如 200ms）
+	EnableMetrics bool          `yaml:"enable_metrics"` // 是否统计 SQL 耗时
 }
 
 type RedisConfig struct {
-	Host     string `yaml:"host"`
-	Port     int    `yaml:"port"`
-	Password string `yaml:"password"`
-	DB       int    `yaml:"db"`
+	Host     string `yaml:"host" env:"REDIS_HOST"`
+	Port     int    `yaml:"port" env:"REDIS_PORT"`
+	Password string `yaml:"password" env:"REDIS_PASSWORD"`
+	DB       int    `yaml:"db" env:"REDIS_DB"`
+
+	// 读穿缓存的击穿保护（Feed 流、视频详情共用）：未命中时只有拿到分布式锁的请求查询数据库，其余请求等待回填
+	LockTTL          time.Duration `yaml:"lock_ttl"`           // 分布式锁过期时间（默认 2s，应大于一次回源查询的耗时）
+	LockWaitAttempts int           `yaml:"lock_wait_attempts"` // 没拿到锁时检查缓存是否已回填的次数（默认 5）
+	LockWaitInterval time.Duration `yaml:"lock_wait_interval"` // 每次检查前的等待间隔（默认 20ms）
 }
 
 type RabbitMQConfig struct {
-	Host     string `yaml:"host"`
-	Port     int    `yaml:"port"`
-	Username string `yaml:"username"`
-	Password string `yaml:"password"`
+	Host     string `yaml:"host" env:"RABBITMQ_HOST"`
+	Port     int    `yaml:"port" env:"RABBITMQ_PORT"`
+	Username string `yaml:"username" env:"RABBITMQ_USERNAME"`
+	Password string `yaml:"password" env:"RABBITMQ_PASSWORD"`
+
+	// 队列积压保护（默认关闭；API 与 Worker 必须使用相同配置，否则声明队列时参数不一致会报错）
+	// 已存在的队列不能直接加上这些参数（PRECONDITION_FAILED），需要先删除旧队列，或改用 RabbitMQ 策略，见 config.yaml
+	DeadLetterExchange string                 `yaml:"dead_letter_exchange"` // 死信交换机（过期/溢出的消息转入 <队列名>.dlq），为空表示直接丢弃
+	Queues             map[string]QueueConfig `yaml:"queues"`               // 按队列名配置的消息 TTL 和长度上限
+}
+
+// QueueConfig 单个队列的积压保护配置（为 0 表示不限制）
+type QueueConfig struct {
+	MessageTTL time.Duration `yaml:"message_ttl"` // 消息过期时间（如 24h），对应 x-message-ttl
+	MaxLength  int           `yaml:"max_length"`  // 队列最大消息数，对应 x-max-length（超出时丢弃最旧的消息）
+}
+
+// VideoConfig 视频配置
+type VideoConfig struct {
+	MaxTitleLength       int `yaml:"max_title_length"`       // 标题最大字符数（按 rune 计算，默认 100）
+	MaxDescriptionLength int `yaml:"max_description_length"` // 描述最大字符数（按 rune 计算，默认 255）
+	DailyUploadCount     int `yaml:"daily_upload_count"`     // 每个账户每天最多上传视频数（默认 20，负数表示不限制）
+	DailyUploadMB        int `yaml:"daily_upload_mb"`        // 每个账户每天最多上传视频总大小，单位 MB（默认 2048，负数表示不限制）
+
+	PopularityFlushInterval time.Duration `yaml:"popularity_flush_interval"` // Worker 合并写入数据库热度的间隔（默认 200ms）
+
+	NormalizeCovers   bool   `yaml:"normalize_covers"`    // 是否在上传时把封面转码为统一格式（默认关闭）
+	CoverFormat       string `yaml:"cover_format"`        // 封面目标格式：jpeg / png（默认 jpeg）
+	CoverQuality      int    `yaml:"cover_quality"`       // JPEG 质量 1-100（默认 80）
+	CoverMaxDimension int    `yaml:"cover_max_dimension"` // 封面长边最大像素，超过时按比例缩小（默认 1280）
+
+	MediaHosts           []string `yaml:"media_hosts"`            // 上传文件的访问域名（host[:port]），发布时 play_url/cover_url 必须使用这些域名；为空时使用发布请求的 Host
+	AllowedMediaPrefixes []string `yaml:"allowed_media_prefixes"` // 额外允许的外部地址前缀（如 "https://cdn.example.com/"），默认不允许外部地址
+
+	OrphanUploadMaxAge   time.Duration `yaml:"orphan_upload_max_age"`  // 上传后超过该时间仍未被视频引用的文件会被清理（默认 24h）
+	OrphanUploadInterval time.Duration `yaml:"orphan_upload_interval"` // 未发布上传文件的扫描间隔（默认 1h，负数表示不清理）
+}
+
+// CommentConfig 评论配置
+type CommentConfig struct {
+	MaxLength        int           `yaml:"max_length"`         // 评论最大字符数（按 rune 计算，默认 500）
+	FilterMode       string        `yaml:"filter_mode"`        // 敏感词处理方式：reject（拒绝）/ mask（打码）/ 空（关闭）
+	BlockedWords     []string      `yaml:"blocked_words"`      // 敏感词列表（不区分大小写）
+	BlockedWordsFile string        `yaml:"blocked_words_file"` // 敏感词文件（每行一个，# 开头为注释）
+	RateInterval     time.Duration `yaml:"rate_interval"`      // 同一用户两次发布评论的最小间隔（默认 3s）
+	MaxPerVideo      int           `yaml:"max_per_video"`      // 每个视频最多保留的评论数，达到后拒绝新评论（0 表示不限制）
+}
+
+// FeedConfig Feed 流配置（支持 SIGHUP 热更新）
+type FeedConfig struct {
+	CacheTTL         time.Duration  `yaml:"cache_ttl"`         // Feed 缓存过期时间（默认 5s，实际会再加 0-2 秒随机偏移）
+	LatestTTL        *time.Duration `yaml:"latest_ttl"`        // 最新视频流缓存过期时间（未配置时使用 cache_ttl，0s 表示不缓存）
+	FollowingTTL     *time.Duration `yaml:"following_ttl"`     // 关注流缓存过期时间（未配置时使用 cache_ttl，0s 表示不缓存）
+	PopularityWindow int            `yaml:"popularity_window"` // 热榜聚合最近多少分钟的热度（默认 60，最大 120）
+	DefaultLimit     int            `yaml:"default_limit"`     // Feed 接口未传或传入不合法的 limit 时的返回数量（默认 10，不超过 max_limit）
+	MaxLimit         int            `yaml:"max_limit"`         // Feed 接口单次最多返回数量（默认 50），超过时按 default_limit 返回
+	StaleWindow      time.Duration  `yaml:"stale_window"`      // 最新视频流/关注流缓存过期后仍可返回旧数据的时长（同时后台刷新，默认 0 表示不返回旧数据）
+}
+
+// ReportConfig 举报配置（支持 SIGHUP 热更新）
+type ReportConfig struct {
+	RateLimit int `yaml:"rate_limit"` // 每个用户每小时最多提交的举报数（默认 10）
+}
+
+// AuthConfig 鉴权配置
+type AuthConfig struct {
+	JWTSecret string `yaml:"jwt_secret" env:"JWT_SECRET"` // JWT 签名密钥（生产环境请通过环境变量注入）
+
+	// 仅开发环境：把邮箱验证 token 原文写入日志（未接入邮件服务时手动验证用），生产环境必须关闭
+	DevLogEmailTokens bool `yaml:"dev_log_email_tokens" env:"AUTH_DEV_LOG_EMAIL_TOKENS"`
+}
+
+// WorkerConfig Worker 进程配置（每个 Worker 使用独立的通道，可以分别设置预取数量）
+type WorkerConfig struct {
+	Social       WorkerQueueConfig `yaml:"social"`
+	Like         WorkerQueueConfig `yaml:"like"`
+	Comment      WorkerQueueConfig `yaml:"comment"`
+	Account      WorkerQueueConfig `yaml:"account"`
+	Popularity   WorkerQueueConfig `yaml:"popularity"`
+	Onboarding   WorkerQueueConfig `yaml:"onboarding"`
+	Notification WorkerQueueConfig `yaml:"notification"`
 }
 
+// WorkerQueueConfig 单个 Worker 的消费配置
+type WorkerQueueConfig struct {
+	Prefetch int `yaml:"prefetch"` // 通道预取消息数量（QoS prefetch count，默认 50）：越大吞吐越高，未确认消息占用的内存也越多
+}
+
+// defaultWorkerPrefetch Worker 通道默认预取消息数量
+const defaultWorkerPrefetch = 50
+
+// PrefetchCount 返回预取消息数量（未配置或不合法时使用默认值 50）
+func (c WorkerQueueConfig) PrefetchCount() int {
+	if c.Prefetch <= 0 {
+		return defaultWorkerPrefetch
+	}
+	return c.Prefetch
+}
+
+// Load 读取 YAML 配置文件，并用环境变量覆盖带 env 标签的字段
 func Load(filename string) (Config, error) {
 	data, err := ioutil.ReadFile(filename)
 	if err != nil {
@@ -49,6 +217,45 @@ func Load(filename string) (Config, error) {
 	if err := yaml.Unmarshal(data, &cfg); err != nil {
 		return Config{}, err
 	}
- 
+
+	// 环境变量优先于配置文件
+	if err := applyEnv(reflect.ValueOf(&cfg).Elem()); err != nil {
+		return Config{}, err
+	}
+	if err := validate(cfg); err != nil {
+		return Config{}, err
+	}
 	return cfg, nil
 }
+
+// validate 校验配置取值（热更新时校验失败会保留原配置）
+func validate(cfg Config) error {
+	if cfg.Feed.CacheTTL < 0 {
+		return fmt.Errorf("feed.cache_ttl must be non-negative, got %s", cfg.Feed.CacheTTL)
+	}
+	if cfg.Feed.LatestTTL != nil && *cfg.Feed.LatestTTL < 0 {
+		return fmt.Errorf("feed.latest_ttl must be non-negative, got %s", *cfg.Feed.LatestTTL)
+	}
+	if cfg.Feed.FollowingTTL != nil && *cfg.Feed.FollowingTTL < 0 {
+		return fmt.Errorf("feed.following_ttl must be non-negative, got %s", *cfg.Feed.FollowingTTL)
+	}
+	if cfg.Feed.DefaultLimit < 0 {
+		return fmt.Errorf("feed.default_limit must be non-negative, got %d", cfg.Feed.DefaultLimit)
+	}
+	if cfg.Feed.MaxLimit < 0 {
+		return fmt.Errorf("feed.max_limit must be non-negative, got %d", cfg.Feed.MaxLimit)
+	}
+	if cfg.Feed.StaleWindow < 0 {
+		return fmt.Errorf("feed.stale_window must be non-negative, got %s", cfg.Feed.StaleWindow)
+	}
+	if cfg.Comment.MaxPerVideo < 0 {
+		return fmt.Errorf("comment.max_per_video must be non-negative, got %d", cfg.Comment.MaxPerVideo)
+	}
+	if strings.Contains(cfg.Storage.URLPrefix(), "..") {
+		return fmt.Errorf("storage.static_prefix must not contain '..', got %q", cfg.Storage.StaticPrefix)
+	}
+	if cfg.Startup.WaitTimeout < 0 {
+		return fmt.Errorf("startup.wait_timeout must be non-negative, got %s", cfg.Startup.WaitTimeout)
+	}
+	return nil
+}
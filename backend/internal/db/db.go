@@ -3,28 +3,79 @@ package db
 import (
 	"feedsystem_video_go/internal/account"
 	"feedsystem_video_go/internal/config"
+	"feedsystem_video_go/internal/notification"
+	"feedsystem_video_go/internal/report"
 	"feedsystem_video_go/internal/social"
 	"feedsystem_video_go/internal/video"
 	"fmt"
+	"time"
 
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 )
 
+// 连接池默认值（配置未指定时使用）
+const (
+	defaultMaxOpenConns    = 50
+	defaultMaxIdleConns    = 10
+	defaultConnMaxLifetime = 30 * time.Minute
+)
+
+// NewDB 连接 MySQL 并配置连接池
+// API 进程与 Worker 进程并发访问数据库，连接池必须有上限，否则高峰期可能耗尽 MySQL 连接
 func NewDB(dbcfg config.DatabaseConfig) (*gorm.DB, error) {
 	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&parseTime=True&loc=Local",
 		dbcfg.User, dbcfg.Password, dbcfg.Host, dbcfg.Port, dbcfg.DBName)
 
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{
+		Logger: newGormLogger(dbcfg), // 慢查询日志
+	})
 	if err != nil {
 		return nil, err
 	}
 
+	// 可选：注册 SQL 耗时统计回调
+	if dbcfg.EnableMetrics {
+		if err := registerMetricsCallbacks(db, dbcfg.SlowThreshold); err != nil {
+			return nil, err
+		}
+	}
+
+	// 配置底层 sql.DB 连接池
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	maxOpen := dbcfg.MaxOpenConns
+	if maxOpen <= 0 {
+		maxOpen = defaultMaxOpenConns
+	}
+	maxIdle := dbcfg.MaxIdleConns
+	if maxIdle <= 0 {
+		maxIdle = defaultMaxIdleConns
+	}
+	if maxIdle > maxOpen {
+		maxIdle = maxOpen
+	}
+	lifetime := dbcfg.ConnMaxLifetime
+	if lifetime <= 0 {
+		lifetime = defaultConnMaxLifetime
+	}
+	sqlDB.SetMaxOpenConns(maxOpen)
+	sqlDB.SetMaxIdleConns(maxIdle)
+	sqlDB.SetConnMaxLifetime(lifetime)
+
 	return db, nil
 }
 
 func AutoMigrate(db *gorm.DB) error {
-	return db.AutoMigrate(&account.Account{}, &video.Video{}, &video.Like{}, &video.Comment{}, &social.Social{})
+	if err := db.AutoMigrate(&account.Account{}, &video.Video{}, &video.Like{}, &video.Comment{}, &video.CommentLike{}, &social.Social{}, &report.Report{}, &notification.Notification{}); err != nil {
+		return err
+	}
+	// 新增的 videos.updated_at 列对旧数据为 NULL，用创建时间回填（只影响 NULL 行，重复执行无副作用）
+	return db.Model(&video.Video{}).Unscoped().
+		Where("updated_at IS NULL").
+		UpdateColumn("updated_at", gorm.Expr("create_time")).Error
 }
 
 func CloseDB(db *gorm.DB) error {
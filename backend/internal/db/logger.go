@@ -0,0 +1,171 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"feedsystem_video_go/internal/config"
+	"feedsystem_video_go/internal/metrics"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// 慢查询阈值默认值（配置未指定时使用）
+const defaultSlowThreshold = 200 * time.Millisecond
+
+// newGormLogger 根据配置创建 GORM 日志器
+// - 日志级别：silent/error/warn/info（默认 warn，慢查询在 warn 级别输出），可通过 SetLogLevel 运行时切换
+// - 慢查询阈值：超过该耗时的 SQL 会被记录
+// - 输出目标：标准库 log 的默认输出（与项目其他日志保持一致）
+func newGormLogger(dbcfg config.DatabaseConfig) logger.Interface {
+	threshold := dbcfg.SlowThreshold
+	if threshold <= 0 {
+		threshold = defaultSlowThreshold
+	}
+	l := &reloadableLogger{loggers: make(map[logger.LogLevel]logger.Interface)}
+	for _, level := range []logger.LogLevel{logger.Silent, logger.Error, logger.Warn, logger.Info} {
+		l.loggers[level] = logger.New(
+			log.New(log.Writer(), "[gorm] ", log.LstdFlags),
+			logger.Config{
+				SlowThreshold:             threshold,
+				LogLevel:                  level,
+				IgnoreRecordNotFoundError: true, // 记录不存在属于正常业务分支，不打印
+				Colorful:                  false,
+			},
+		)
+	}
+	l.level.Store(int32(parseLogLevel(dbcfg.LogLevel)))
+	return l
+}
+
+// reloadableLogger 支持运行时切换日志级别的 GORM 日志器
+// 为每个级别预先创建一个日志器，按当前级别（原子变量）转发，切换时无需加锁
+type reloadableLogger struct {
+	level   atomic.Int32
+	loggers map[logger.LogLevel]logger.Interface
+}
+
+func (l *reloadableLogger) current() logger.Interface {
+	return l.loggers[logger.LogLevel(l.level.Load())]
+}
+
+// LogMode 返回固定级别的日志器（供 db.Debug() 等会话级调用使用）
+func (l *reloadableLogger) LogMode(level logger.LogLevel) logger.Interface {
+	if fixed, ok := l.loggers[level]; ok {
+		return fixed
+	}
+	return l.current().LogMode(level)
+}
+
+func (l *reloadableLogger) Info(ctx context.Context, msg string, data ...interface{}) {
+	l.current().Info(ctx, msg, data...)
+}
+
+func (l *reloadableLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	l.current().Warn(ctx, msg, data...)
+}
+
+func (l *reloadableLogger) Error(ctx context.Context, msg string, data ...interface{}) {
+	l.current().Error(ctx, msg, data...)
+}
+
+func (l *reloadableLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	l.current().Trace(ctx, begin, fc, err)
+}
+
+// SetLogLevel 运行时切换 GORM 日志级别（配置热更新时调用）
+// 只对 NewDB 创建的连接生效
+func SetLogLevel(db *gorm.DB, level string) {
+	if db == nil {
+		return
+	}
+	if l, ok := db.Config.Logger.(*reloadableLogger); ok {
+		l.level.Store(int32(parseLogLevel(level)))
+	}
+}
+
+// parseLogLevel 将配置中的字符串转换为 GORM 日志级别
+// 无法识别的值按 warn 处理
+func parseLogLevel(level string) logger.LogLevel {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "silent":
+		return logger.Silent
+	case "error":
+		return logger.Error
+	case "info":
+		return logger.Info
+	default:
+		return logger.Warn
+	}
+}
+
+// metricsStartKey 用于在 gorm.Statement 上保存 SQL 开始时间
+const metricsStartKey = "metrics:start"
+
+// registerMetricsCallbacks 注册 GORM 回调，统计每条 SQL 的耗时
+// 覆盖 create/query/update/delete/row/raw 六类操作，结果按操作类型计入 metrics 包的计数器（通过 /metrics 暴露）
+func registerMetricsCallbacks(db *gorm.DB, slowThreshold time.Duration) error {
+	if slowThreshold <= 0 {
+		slowThreshold = defaultSlowThreshold
+	}
+
+	before := func(tx *gorm.DB) {
+		tx.InstanceSet(metricsStartKey, time.Now())
+	}
+	after := func(op string) func(tx *gorm.DB) {
+		return func(tx *gorm.DB) {
+			v, ok := tx.InstanceGet(metricsStartKey)
+			if !ok {
+				return
+			}
+			start, ok := v.(time.Time)
+			if !ok {
+				return
+			}
+			elapsed := time.Since(start)
+			failed := tx.Error != nil && !errors.Is(tx.Error, gorm.ErrRecordNotFound)
+			metrics.ObserveDBQuery(op, elapsed, elapsed >= slowThreshold, failed)
+		}
+	}
+
+	cb := db.Callback()
+	if err := cb.Create().Before("gorm:create").Register("metrics:before_create", before); err != nil {
+		return err
+	}
+	if err := cb.Create().After("gorm:create").Register("metrics:after_create", after(metrics.DBOpCreate)); err != nil {
+		return err
+	}
+	if err := cb.Query().Before("gorm:query").Register("metrics:before_query", before); err != nil {
+		return err
+	}
+	if err := cb.Query().After("gorm:query").Register("metrics:after_query", after(metrics.DBOpQuery)); err != nil {
+		return err
+	}
+	if err := cb.Update().Before("gorm:update").Register("metrics:before_update", before); err != nil {
+		return err
+	}
+	if err := cb.Update().After("gorm:update").Register("metrics:after_update", after(metrics.DBOpUpdate)); err != nil {
+		return err
+	}
+	if err := cb.Delete().Before("gorm:delete").Register("metrics:before_delete", before); err != nil {
+		return err
+	}
+	if err := cb.Delete().After("gorm:delete").Register("metrics:after_delete", after(metrics.DBOpDelete)); err != nil {
+		return err
+	}
+	if err := cb.Row().Before("gorm:row").Register("metrics:before_row", before); err != nil {
+		return err
+	}
+	if err := cb.Row().After("gorm:row").Register("metrics:after_row", after(metrics.DBOpRow)); err != nil {
+		return err
+	}
+	if err := cb.Raw().Before("gorm:raw").Register("metrics:before_raw", before); err != nil {
+		return err
+	}
+	return cb.Raw().After("gorm:raw").Register("metrics:after_raw", after(metrics.DBOpRaw))
+}
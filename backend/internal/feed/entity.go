@@ -4,6 +4,17 @@ package feed
 
 import "time"
 
+// Feed 响应中的 algo 取值：标识本页结果由哪种排序产生（客户端据此归因互动数据、对比排序实验）
+// 调整某个 Feed 的排序逻辑时应换一个新值（如 home_v2），不要复用旧值
+const (
+	AlgoLatest               = "latest"                 // 最新视频（按发布时间倒序）
+	AlgoLikesCount           = "likes_count"            // 按点赞数倒序
+	AlgoFollowing            = "following"              // 关注的作者的视频（按发布时间倒序）
+	AlgoPopularityZSet       = "popularity_zset"        // Redis 热榜快照（窗口内热度）
+	AlgoPopularityDBFallback = "popularity_db_fallback" // 热榜降级：数据库累计热度
+	AlgoHome                 = "home_v1"                // 首页：关注流 + 热榜补足
+)
+
 // FeedAuthor 视频作者信息
 type FeedAuthor struct {
 	ID       uint   `json:"id"`       // 作者 ID
@@ -20,32 +31,68 @@ type FeedVideoItem struct {
 	PlayURL     string     `json:"play_url"`     // 视频播放地址
 	CoverURL    string     `json:"cover_url"`    // 视频封面地址
 	CreateTime  int64      `json:"create_time"`  // 创建时间（Unix 时间戳）
+	UpdatedAt   int64      `json:"updated_at"`   // 更新时间（Unix 时间戳，客户端据此判断本地缓存是否过期）
 	LikesCount  int64      `json:"likes_count"`  // 点赞数
 	IsLiked     bool       `json:"is_liked"`    // 当前用户是否已点赞
+	IsFollowing bool       `json:"is_following"` // 当前用户是否已关注作者（未登录时为 false）
 }
 
 // ============ 最新视频 Feed ============
 
 // ListLatestRequest 查询最新视频的请求
 type ListLatestRequest struct {
-	Limit      int   `json:"limit"`       // 返回的视频数量（1-50）
-	LatestTime int64 `json:"latest_time"` // 游标：上一页最后一条视频的创建时间（第一页传 0）
+	Limit      int   `json:"limit"`       // 返回的视频数量（默认 10，最大 50；由 feed.default_limit / feed.max_limit 配置）
+	LatestTime   int64 `json:"latest_time"`    // 游标：上一页最后一条视频的创建时间（秒，第一页传 0；兼容旧客户端）
+	LatestTimeMs int64 `json:"latest_time_ms"` // 游标：上一页最后一条视频的创建时间（毫秒，优先于 latest_time，第一页传 0）
+	LatestID     uint  `json:"latest_id"`      // 游标：上一页最后一条视频的 ID（与创建时间组成复合游标，第一页传 0）
+	AuthorID   uint  `json:"author_id"`   // 可选：只返回该作者的视频（0 表示不过滤）
+	Since      int64 `json:"since"`       // 可选：只返回该时间之后（含）发布的视频（Unix 时间戳，0 表示不过滤）
+	UpdatedSince int64 `json:"updated_since"` // 可选：只返回该时间之后（含）有变化的视频（Unix 时间戳，0 表示不过滤），用于客户端增量同步
+	MinLikes      int64 `json:"min_likes"`      // 可选：只返回点赞数不少于该值的视频（0 表示不过滤）
+	MinPopularity int64 `json:"min_popularity"` // 可选：只返回热度不少于该值的视频（0 表示不过滤）
+}
+
+// TimeCursor 创建时间游标（内部使用）
+// 使用复合游标（创建时间 + ID）解决多个视频创建时间相同的情况
+// create_time 精确到毫秒：毫秒游标（Exact）按相等比较；旧客户端只传秒级时间戳时，"相等"按同一秒处理
+type TimeCursor struct {
+	Time  time.Time // 上一页最后一条视频的创建时间（零值表示第一页）
+	ID    uint      // 上一页最后一条视频的 ID（0 表示旧客户端只传了时间）
+	Exact bool      // Time 是否为毫秒精度（来自 latest_time_ms）
+}
+
+// LatestFilter 最新视频的可选过滤条件（内部使用）
+type LatestFilter struct {
+	AuthorID uint      // 作者 ID（0 表示不过滤）
+	Since    time.Time // 起始时间（零值表示不过滤）
+	UpdatedSince time.Time // 更新时间下限（零值表示不过滤）
+	EngagementFilter       // 互动量下限
+}
+
+// EngagementFilter 互动量过滤条件（内部使用）：隐藏没有任何互动的新视频
+type EngagementFilter struct {
+	MinLikes      int64 // 点赞数下限（0 表示不过滤）
+	MinPopularity int64 // 热度下限（0 表示不过滤）
 }
 
 // ListLatestResponse 查询最新视频的响应
 type ListLatestResponse struct {
 	VideoList []FeedVideoItem `json:"video_list"` // 视频列表
-	NextTime  int64           `json:"next_time"`  // 游标：用于下一页的时间戳
+	NextTime   int64           `json:"next_time"`    // 游标：用于下一页的时间戳（秒，兼容旧客户端）
+	NextTimeMs int64           `json:"next_time_ms"` // 游标：用于下一页的时间戳（毫秒，与 create_time 精度一致）
+	NextID     uint            `json:"next_id"`      // 游标：用于下一页的视频 ID
 	HasMore   bool            `json:"has_more"`   // 是否还有更多数据
+	Algo      string          `json:"algo"`       // 排序算法（固定为 latest）
 }
 
 // ============ 点赞排行 Feed ============
 
 // ListLikesCountRequest 按点赞数查询视频的请求
 type ListLikesCountRequest struct {
-	Limit            int    `json:"limit"`                  // 返回的视频数量（1-50）
+	Limit            int    `json:"limit"`                  // 返回的视频数量（默认 10，最大 50；由 feed.default_limit / feed.max_limit 配置）
 	LikesCountBefore *int64 `json:"likes_count_before"` // 游标：上一页最后一条视频的点赞数（可选）
 	IDBefore         *uint  `json:"id_before"`           // 游标：上一页最后一条视频的 ID（可选）
+	MinLikes         int64  `json:"min_likes"`           // 可选：只返回点赞数不少于该值的视频（0 表示不过滤）
 	// 注意：LikesCountBefore 和 IDBefore 必须同时提供或同时为空（复合游标）
 }
 
@@ -62,28 +109,34 @@ type ListLikesCountResponse struct {
 	NextLikesCountBefore *int64          `json:"next_likes_count_before"` // 游标：用于下一页的点赞数
 	NextIDBefore         *uint           `json:"next_id_before"`          // 游标：用于下一页的 ID
 	HasMore              bool            `json:"has_more"`                // 是否还有更多数据
+	Algo                 string          `json:"algo"`                    // 排序算法（固定为 likes_count）
 }
 
 // ============ 关注列表 Feed ============
 
 // ListByFollowingRequest 查询关注列表视频的请求（需要登录）
 type ListByFollowingRequest struct {
-	Limit      int   `json:"limit"`       // 返回的视频数量（1-50）
-	LatestTime int64 `json:"latest_time"` // 游标：上一页最后一条视频的创建时间（第一页传 0）
+	Limit      int   `json:"limit"`       // 返回的视频数量（默认 10，最大 50；由 feed.default_limit / feed.max_limit 配置）
+	LatestTime   int64 `json:"latest_time"`    // 游标：上一页最后一条视频的创建时间（秒，第一页传 0；兼容旧客户端）
+	LatestTimeMs int64 `json:"latest_time_ms"` // 游标：上一页最后一条视频的创建时间（毫秒，优先于 latest_time，第一页传 0）
+	LatestID     uint  `json:"latest_id"`      // 游标：上一页最后一条视频的 ID（与创建时间组成复合游标，第一页传 0）
 }
 
 // ListByFollowingResponse 查询关注列表视频的响应
 type ListByFollowingResponse struct {
 	VideoList []FeedVideoItem `json:"video_list"` // 视频列表
-	NextTime  int64           `json:"next_time"`  // 游标：用于下一页的时间戳
+	NextTime   int64           `json:"next_time"`    // 游标：用于下一页的时间戳（秒，兼容旧客户端）
+	NextTimeMs int64           `json:"next_time_ms"` // 游标：用于下一页的时间戳（毫秒，与 create_time 精度一致）
+	NextID     uint            `json:"next_id"`      // 游标：用于下一页的视频 ID
 	HasMore   bool            `json:"has_more"`   // 是否还有更多数据
+	Algo      string          `json:"algo"`       // 排序算法（固定为 following）
 }
 
 // ============ 热门视频 Feed ============
 
 // ListByPopularityRequest 按热度查询视频的请求
 type ListByPopularityRequest struct {
-	Limit          int   `json:"limit"`                   // 返回的视频数量（1-50）
+	Limit          int   `json:"limit"`                   // 返回的视频数量（默认 10，最大 50；由 feed.default_limit / feed.max_limit 配置）
 	AsOf           int64 `json:"as_of"`                 // 热榜快照时间（服务器返回的分钟时间戳，第一页传 0）
 	Offset         int   `json:"offset"`                 // 分页偏移量（第一页传 0）
 	LatestIDBefore *uint `json:"latest_id_before,omitempty"` // DB fallback 用：游标 ID
@@ -91,6 +144,9 @@ type ListByPopularityRequest struct {
 	// DB fallback 用（可选）：当 Redis 热榜不可用时，降级到数据库查询
 	LatestPopularity int64     `json:"latest_popularity"` // 游标：上一页最后一条视频的热度
 	LatestBefore     time.Time `json:"latest_before"`     // 游标：上一页最后一条视频的创建时间
+
+	MinLikes      int64 `json:"min_likes"`      // 可选：只返回点赞数不少于该值的视频（0 表示不过滤）
+	MinPopularity int64 `json:"min_popularity"` // 可选：只返回热度不少于该值的视频（0 表示不过滤）
 }
 
 // ListByPopularityResponse 按热度查询视频的响应
@@ -99,9 +155,40 @@ type ListByPopularityResponse struct {
 	AsOf       int64           `json:"as_of"`                     // 热榜快照时间（用于下一页）
 	NextOffset int             `json:"next_offset"`               // 下一页的偏移量
 	HasMore    bool            `json:"has_more"`                  // 是否还有更多数据
+	Algo       string          `json:"algo"`                      // 排序算法：popularity_zset（Redis 热榜）或 popularity_db_fallback（数据库降级）
 
 	// DB fallback 用：当 Redis 热榜不可用时，返回这些游标
 	NextLatestPopularity *int64     `json:"next_latest_popularity,omitempty"` // 游标：用于下一页的热度
 	NextLatestBefore     *time.Time `json:"next_latest_before,omitempty"`     // 游标：用于下一页的时间
 	NextLatestIDBefore   *uint      `json:"next_latest_id_before,omitempty"`   // 游标：用于下一页的 ID
 }
+
+// ============ 首页混合 Feed ============
+
+// HomeRequest 首页 Feed 请求
+// 已登录：先按时间返回关注的作者的视频，关注流取完后用热榜补足；未登录：等同于最新视频
+type HomeRequest struct {
+	Limit         int   `json:"limit"`          // 返回的视频数量（默认 10，最大 50；由 feed.default_limit / feed.max_limit 配置）
+	LatestTimeMs  int64 `json:"latest_time_ms"` // 关注流/最新视频游标：上一页响应的 next_time_ms（第一页传 0）
+	LatestID      uint  `json:"latest_id"`      // 关注流/最新视频游标：上一页响应的 next_id（第一页传 0）
+	FollowingDone bool  `json:"following_done"` // 上一页响应的 following_done（关注流已取完，之后只从热榜补足）
+	AsOf          int64 `json:"as_of"`          // 热榜快照时间：上一页响应的 as_of（第一页传 0）
+	Offset        int   `json:"offset"`         // 热榜偏移量：上一页响应的 next_offset（第一页传 0）
+}
+
+// HomeResponse 首页 Feed 响应
+type HomeResponse struct {
+	VideoList      []FeedVideoItem `json:"video_list"`      // 视频列表（关注流在前，热榜补足在后，已去重）
+	FollowingCount int             `json:"following_count"` // 来自关注流的视频数
+	TrendingCount  int             `json:"trending_count"`  // 来自热榜的视频数
+	LatestCount    int             `json:"latest_count"`    // 来自最新视频的视频数（仅未登录）
+
+	NextTimeMs    int64 `json:"next_time_ms"`   // 关注流/最新视频游标（毫秒）
+	NextID        uint  `json:"next_id"`        // 关注流/最新视频游标 ID
+	FollowingDone bool  `json:"following_done"` // 关注流是否已取完（下一页原样传回）
+	AsOf          int64 `json:"as_of"`          // 热榜快照时间（下一页原样传回）
+	NextOffset    int   `json:"next_offset"`    // 热榜偏移量（下一页原样传回）
+	HasMore       bool  `json:"has_more"`       // 是否还有更多数据
+
+	Algo string `json:"algo"` // 排序算法：home_v1（已登录）或 latest（未登录）
+}
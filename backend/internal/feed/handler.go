@@ -3,7 +3,9 @@
 package feed
 
 import (
+	"feedsystem_video_go/internal/apierror"
 	"feedsystem_video_go/internal/middleware/jwt"
+	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -30,18 +32,25 @@ func NewFeedHandler(service *FeedService) *FeedHandler {
 // 请求示例：
 //   {
 //     "limit": 10,
-//     "latest_time": 0  // 第一页传 0
+//     "latest_time_ms": 0, // 第一页传 0，之后传上一页响应的 next_time_ms（旧客户端可传秒级 latest_time）
+//     "latest_id": 0,      // 游标 ID：上一页响应的 next_id（第一页传 0）
+//     "author_id": 0,    // 可选：只看某个作者
+//     "since": 0,        // 可选：只看该时间之后发布的视频
+//     "min_likes": 0,    // 可选：点赞数下限
+//     "min_popularity": 0 // 可选：热度下限
 //   }
 //
 // 响应示例：
 //   {
 //     "video_list": [...],
 //     "next_time": 1640000000,
+//     "next_time_ms": 1640000000123,
+//     "next_id": 123,
 //     "has_more": true
 //   }
 //
 // 业务流程：
-//   1. 解析请求参数（limit、latest_time）
+//   1. 解析请求参数（limit、latest_time_ms / latest_time、latest_id）
 //   2. 获取当前用户 ID（可选，用于查询点赞状态）
 //   3. 调用 Service 层查询视频
 //   4. 返回响应
@@ -52,20 +61,15 @@ func (f *FeedHandler) ListLatest(c *gin.Context) {
 	// 1. 解析请求参数
 	var req ListLatestRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(400, gin.H{"error": err.Error()})
+		apierror.BadRequest(c, err)
 		return
 	}
 
 	// 2. 校验并限制 limit（防止一次查询过多数据）
-	if req.Limit <= 0 || req.Limit > 50 {
-		req.Limit = 10 // 默认值
-	}
+	req.Limit = f.service.normalizeLimit(req.Limit)
 
-	// 3. 转换游标时间戳（Unix 时间戳 → time.Time）
-	var latestTime time.Time
-	if req.LatestTime > 0 {
-		latestTime = time.Unix(req.LatestTime, 0)
-	}
+	// 3. 转换复合游标（Unix 时间戳 → time.Time，附带上一页最后一条视频的 ID）
+	cursor := newTimeCursor(req.LatestTimeMs, req.LatestTime, req.LatestID)
 
 	// 4. 获取当前用户 ID（用于查询点赞状态）
 	// 注意：这个接口可以匿名访问，未登录时 viewerAccountID = 0
@@ -75,9 +79,23 @@ func (f *FeedHandler) ListLatest(c *gin.Context) {
 	}
 
 	// 5. 调用 Service 层查询视频
-	feedItems, err := f.service.ListLatest(c.Request.Context(), req.Limit, latestTime, viewerAccountID)
+	if req.MinLikes < 0 || req.MinPopularity < 0 {
+		apierror.Write(c, http.StatusBadRequest, apierror.CodeInvalidArgument, "min_likes and min_popularity must be >= 0")
+		return
+	}
+	filter := LatestFilter{
+		AuthorID:         req.AuthorID,
+		EngagementFilter: EngagementFilter{MinLikes: req.MinLikes, MinPopularity: req.MinPopularity},
+	}
+	if req.Since > 0 {
+		filter.Since = time.Unix(req.Since, 0)
+	}
+	if req.UpdatedSince > 0 {
+		filter.UpdatedSince = time.Unix(req.UpdatedSince, 0)
+	}
+	feedItems, err := f.service.ListLatest(c.Request.Context(), req.Limit, cursor, filter, viewerAccountID)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		apierror.FromError(c, err)
 		return
 	}
 
@@ -97,7 +115,8 @@ func (f *FeedHandler) ListLatest(c *gin.Context) {
 //   {
 //     "limit": 10,
 //     "likes_count_before": 1000,  // 上一页最后一条视频的点赞数
-//     "id_before": 123              // 上一页最后一条视频的 ID
+//     "id_before": 123,             // 上一页最后一条视频的 ID
+//     "min_likes": 100              // 可选：点赞数下限
 //   }
 //
 // 响应示例：
@@ -118,21 +137,19 @@ func (f *FeedHandler) ListLikesCount(c *gin.Context) {
 	// 1. 解析请求参数
 	var req ListLikesCountRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(400, gin.H{"error": err.Error()})
+		apierror.BadRequest(c, err)
 		return
 	}
 
 	// 2. 校验并限制 limit
-	if req.Limit <= 0 || req.Limit > 50 {
-		req.Limit = 10 // 默认值
-	}
+	req.Limit = f.service.normalizeLimit(req.Limit)
 
 	// 3. 解析复合游标（点赞数 + ID）
 	var cursor *LikesCountCursor
 	if req.LikesCountBefore != nil || req.IDBefore != nil {
 		// 校验：两个字段必须同时提供或同时为空
 		if req.LikesCountBefore == nil || req.IDBefore == nil {
-			c.JSON(400, gin.H{"error": "likes_count_before and id_before must be provided together"})
+			apierror.Write(c, http.StatusBadRequest, apierror.CodeInvalidArgument, "likes_count_before and id_before must be provided together")
 			return
 		}
 
@@ -141,14 +158,14 @@ func (f *FeedHandler) ListLikesCount(c *gin.Context) {
 
 		// 校验：点赞数不能为负数
 		if likesCountBefore < 0 {
-			c.JSON(400, gin.H{"error": "invalid cursor: likes_count_before must be >= 0"})
+			apierror.Write(c, http.StatusBadRequest, apierror.CodeInvalidArgument, "invalid cursor: likes_count_before must be >= 0")
 			return
 		}
 
 		// 校验：ID 必须大于 0（除非点赞数也是 0）
 		if idBefore == 0 {
 			if likesCountBefore != 0 {
-				c.JSON(400, gin.H{"error": "invalid cursor: id_before must be > 0"})
+				apierror.Write(c, http.StatusBadRequest, apierror.CodeInvalidArgument, "invalid cursor: id_before must be > 0")
 				return
 			}
 		} else {
@@ -160,6 +177,12 @@ func (f *FeedHandler) ListLikesCount(c *gin.Context) {
 		}
 	}
 
+	// 校验：点赞数下限不能为负数
+	if req.MinLikes < 0 {
+		apierror.Write(c, http.StatusBadRequest, apierror.CodeInvalidArgument, "min_likes must be >= 0")
+		return
+	}
+
 	// 4. 获取当前用户 ID（用于查询点赞状态）
 	viewerAccountID, err := jwt.GetAccountID(c)
 	if err != nil {
@@ -167,9 +190,9 @@ func (f *FeedHandler) ListLikesCount(c *gin.Context) {
 	}
 
 	// 5. 调用 Service 层查询视频
-	feedItems, err := f.service.ListLikesCount(c.Request.Context(), req.Limit, cursor, viewerAccountID)
+	feedItems, err := f.service.ListLikesCount(c.Request.Context(), req.Limit, cursor, req.MinLikes, viewerAccountID)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		apierror.FromError(c, err)
 		return
 	}
 
@@ -188,13 +211,16 @@ func (f *FeedHandler) ListLikesCount(c *gin.Context) {
 // 请求示例：
 //   {
 //     "limit": 10,
-//     "latest_time": 1640000000  // 游标：上一页最后一条视频的时间
+//     "latest_time_ms": 1640000000123, // 游标：上一页最后一条视频的时间（毫秒）
+//     "latest_id": 123                 // 游标：上一页最后一条视频的 ID
 //   }
 //
 // 响应示例：
 //   {
 //     "video_list": [...],
 //     "next_time": 1639999500,
+//     "next_time_ms": 1639999500456,
+//     "next_id": 98,
 //     "has_more": true
 //   }
 //
@@ -209,33 +235,28 @@ func (f *FeedHandler) ListByFollowing(c *gin.Context) {
 	// 1. 解析请求参数
 	var req ListByFollowingRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(400, gin.H{"error": err.Error()})
+		apierror.BadRequest(c, err)
 		return
 	}
 
 	// 2. 校验并限制 limit
-	if req.Limit <= 0 || req.Limit > 50 {
-		req.Limit = 10 // 默认值
-	}
+	req.Limit = f.service.normalizeLimit(req.Limit)
 
 	// 3. 获取当前用户 ID（必须登录）
 	viewerAccountID, err := jwt.GetAccountID(c)
 	if err != nil {
 		// 未登录，返回 401
-		c.JSON(401, gin.H{"error": "unauthorized"})
+		apierror.Unauthorized(c)
 		return
 	}
 
-	// 4. 转换游标时间戳
-	var latestTime time.Time
-	if req.LatestTime > 0 {
-		latestTime = time.Unix(req.LatestTime, 0)
-	}
+	// 4. 转换复合游标（时间 + ID）
+	cursor := newTimeCursor(req.LatestTimeMs, req.LatestTime, req.LatestID)
 
 	// 5. 调用 Service 层查询视频
-	feedItems, err := f.service.ListByFollowing(c.Request.Context(), req.Limit, latestTime, viewerAccountID)
+	feedItems, err := f.service.ListByFollowing(c.Request.Context(), req.Limit, cursor, viewerAccountID)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		apierror.FromError(c, err)
 		return
 	}
 
@@ -288,14 +309,12 @@ func (f *FeedHandler) ListByPopularity(c *gin.Context) {
 	// 1. 解析请求参数
 	var req ListByPopularityRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(400, gin.H{"error": err.Error()})
+		apierror.BadRequest(c, err)
 		return
 	}
 
 	// 2. 校验并限制 limit
-	if req.Limit <= 0 || req.Limit > 50 {
-		req.Limit = 10 // 默认值
-	}
+	req.Limit = f.service.normalizeLimit(req.Limit)
 
 	// 3. 获取当前用户 ID（用于查询点赞状态，可选）
 	viewerAccountID, err := jwt.GetAccountID(c)
@@ -310,7 +329,11 @@ func (f *FeedHandler) ListByPopularity(c *gin.Context) {
 
 	// 校验：热度不能为负数
 	if req.LatestPopularity < 0 {
-		c.JSON(400, gin.H{"error": "latest_popularity must be >= 0"})
+		apierror.Write(c, http.StatusBadRequest, apierror.CodeInvalidArgument, "latest_popularity must be >= 0")
+		return
+	}
+	if req.MinLikes < 0 || req.MinPopularity < 0 {
+		apierror.Write(c, http.StatusBadRequest, apierror.CodeInvalidArgument, "min_likes and min_popularity must be >= 0")
 		return
 	}
 
@@ -319,7 +342,7 @@ func (f *FeedHandler) ListByPopularity(c *gin.Context) {
 	if anyCursor {
 		// 校验：两个字段必须同时提供
 		if req.LatestBefore.IsZero() || req.LatestIDBefore == nil || *req.LatestIDBefore == 0 {
-			c.JSON(400, gin.H{"error": "latest_before and latest_id_before must be provided together"})
+			apierror.Write(c, http.StatusBadRequest, apierror.CodeInvalidArgument, "latest_before and latest_id_before must be provided together")
 			return
 		}
 		// 解析游标
@@ -338,12 +361,92 @@ func (f *FeedHandler) ListByPopularity(c *gin.Context) {
 		latestPopularity, // DB Fallback 用游标
 		latestBefore,     // DB Fallback 用游标
 		latestIDBefore,    // DB Fallback 用游标
+		EngagementFilter{MinLikes: req.MinLikes, MinPopularity: req.MinPopularity}, // 互动量下限
 	)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		apierror.FromError(c, err)
 		return
 	}
 
 	// 6. 返回响应
 	c.JSON(200, resp)
 }
+
+// ============ 首页混合接口 ============
+
+// Home 首页 Feed（可选登录）
+//
+// 路由：POST /feed/home
+// 功能：已登录时返回关注的作者的视频，不足 limit 条时用热榜补足（去重）；未登录时返回最新视频
+// 场景：新用户关注的人很少，关注流为空时首页仍然有内容
+//
+// 请求示例：
+//   {
+//     "limit": 10,
+//     "latest_time_ms": 0,     // 上一页响应的 next_time_ms（第一页传 0）
+//     "latest_id": 0,          // 上一页响应的 next_id
+//     "following_done": false, // 上一页响应的 following_done
+//     "as_of": 0,              // 上一页响应的 as_of
+//     "offset": 0              // 上一页响应的 next_offset
+//   }
+//
+// 响应示例：
+//   {
+//     "video_list": [...],
+//     "following_count": 3, // 来自关注流
+//     "trending_count": 7,  // 来自热榜
+//     "latest_count": 0,    // 来自最新视频（仅未登录）
+//     "next_time_ms": 1640000000123,
+//     "next_id": 98,
+//     "following_done": true,
+//     "as_of": 1640000000,
+//     "next_offset": 7,
+//     "has_more": true
+//   }
+//
+// 参数：
+//   c - Gin 上下文
+func (f *FeedHandler) Home(c *gin.Context) {
+	// 1. 解析请求参数
+	var req HomeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.BadRequest(c, err)
+		return
+	}
+
+	// 2. 校验并限制 limit
+	req.Limit = f.service.normalizeLimit(req.Limit)
+	if req.Offset < 0 {
+		apierror.Write(c, http.StatusBadRequest, apierror.CodeInvalidArgument, "offset must be >= 0")
+		return
+	}
+
+	// 3. 获取当前用户 ID（可选登录，未登录时返回最新视频）
+	viewerAccountID, err := jwt.GetAccountID(c)
+	if err != nil {
+		viewerAccountID = 0
+	}
+
+	// 4. 调用 Service 层查询视频
+	cursor := newTimeCursor(req.LatestTimeMs, 0, req.LatestID)
+	resp, err := f.service.Home(c.Request.Context(), req.Limit, cursor, req.FollowingDone, req.AsOf, req.Offset, viewerAccountID)
+	if err != nil {
+		apierror.FromError(c, err)
+		return
+	}
+
+	// 5. 返回响应
+	c.JSON(200, resp)
+}
+
+// newTimeCursor 根据请求参数构造创建时间复合游标
+// 优先使用毫秒时间戳（与 create_time 精度一致）；只传秒级时间戳时兼容旧客户端
+func newTimeCursor(latestTimeMs, latestTime int64, latestID uint) TimeCursor {
+	if latestTimeMs > 0 {
+		return TimeCursor{Time: time.UnixMilli(latestTimeMs), ID: latestID, Exact: true}
+	}
+	if latestTime > 0 {
+		return TimeCursor{Time: time.Unix(latestTime, 0), ID: latestID}
+	}
+	return TimeCursor{}
+}
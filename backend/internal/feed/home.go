@@ -0,0 +1,101 @@
+package feed
+
+import (
+	"context"
+	"time"
+)
+
+// Home 首页 Feed：关注流 + 热榜补足
+// 业务流程：
+//  1. 未登录：直接返回最新视频（ListLatest）
+//  2. 关注流未取完：按游标查询关注流（ListByFollowing）
+//  3. 关注流不足 limit 条（已取完）：从热榜补足剩余数量，
+//     跳过本页已有的视频和已关注作者的视频（这些视频已经或将会出现在关注流中，避免跨页重复）
+//
+// 热榜补足依赖 Redis 热榜快照翻页；Redis 不可用时热榜降级为数据库查询，只补足第一页
+//
+// 参数：
+//
+//	ctx - 上下文
+//	limit - 返回的视频数量
+//	cursor - 关注流/最新视频的复合游标
+//	followingDone - 关注流是否已取完（上一页响应的 following_done）
+//	asOf - 热榜快照时间（第一页传 0）
+//	offset - 热榜偏移量（第一页传 0）
+//	viewerAccountID - 当前用户 ID（未登录为 0）
+func (f *FeedService) Home(ctx context.Context, limit int, cursor TimeCursor, followingDone bool, asOf int64, offset int, viewerAccountID uint) (HomeResponse, error) {
+	// 1. 未登录：最新视频
+	if viewerAccountID == 0 {
+		latest, err := f.ListLatest(ctx, limit, cursor, LatestFilter{}, 0)
+		if err != nil {
+			return HomeResponse{}, err
+		}
+		return HomeResponse{
+			VideoList:   latest.VideoList,
+			LatestCount: len(latest.VideoList),
+			NextTimeMs:  latest.NextTimeMs,
+			NextID:      latest.NextID,
+			HasMore:     latest.HasMore,
+			Algo:        AlgoLatest,
+		}, nil
+	}
+
+	resp := HomeResponse{
+		VideoList:     []FeedVideoItem{},
+		NextTimeMs:    cursor.Time.UnixMilli(),
+		NextID:        cursor.ID,
+		FollowingDone: followingDone,
+		AsOf:          asOf,
+		NextOffset:    offset,
+		Algo:          AlgoHome,
+	}
+	if cursor.Time.IsZero() {
+		resp.NextTimeMs = 0
+	}
+
+	// 2. 关注流
+	if !followingDone {
+		following, err := f.ListByFollowing(ctx, limit, cursor, viewerAccountID)
+		if err != nil {
+			return HomeResponse{}, err
+		}
+		resp.VideoList = append(resp.VideoList, following.VideoList...)
+		resp.FollowingCount = len(following.VideoList)
+		resp.NextTimeMs = following.NextTimeMs
+		resp.NextID = following.NextID
+		resp.FollowingDone = !following.HasMore
+		if following.HasMore {
+			resp.HasMore = true
+			return resp, nil
+		}
+	}
+
+	// 3. 热榜补足
+	need := limit - len(resp.VideoList)
+	if need <= 0 {
+		// 关注流恰好取完：下一页从热榜开始
+		resp.HasMore = true
+		return resp, nil
+	}
+	trending, err := f.ListByPopularity(ctx, need, asOf, offset, viewerAccountID, 0, time.Time{}, 0, EngagementFilter{})
+	if err != nil {
+		return HomeResponse{}, err
+	}
+	seen := make(map[uint]bool, len(resp.VideoList))
+	for _, item := range resp.VideoList {
+		seen[item.ID] = true
+	}
+	for _, item := range trending.VideoList {
+		if seen[item.ID] || item.IsFollowing || item.Author.ID == viewerAccountID {
+			continue
+		}
+		seen[item.ID] = true
+		resp.VideoList = append(resp.VideoList, item)
+		resp.TrendingCount++
+	}
+	resp.AsOf = trending.AsOf
+	resp.NextOffset = trending.NextOffset
+	// AsOf 为 0 表示热榜降级为数据库查询，无法按快照翻页
+	resp.HasMore = trending.HasMore && trending.AsOf != 0
+	return resp, nil
+}
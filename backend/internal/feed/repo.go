@@ -28,29 +28,51 @@ func NewFeedRepository(db *gorm.DB) *FeedRepository {
 //
 // SQL 等价查询：
 //   SELECT * FROM videos
-//   WHERE create_time < ?
-//   ORDER BY create_time DESC
+//   WHERE visibility = 'public' AND (
+//     (create_time < ?) OR
+//     (create_time = ? AND id < ?))
+//     [AND author_id = ?] [AND create_time >= ?] [AND updated_at >= ?]
+//     [AND likes_count >= ?] [AND popularity >= ?]
+//   ORDER BY create_time DESC, id DESC
 //   LIMIT ?;
 //
+// 索引：idx_video_create_time (create_time)
+//   EXPLAIN 结果应为 type=range / key=idx_video_create_time，Extra 中不出现 Using filesort
+//   （MySQL 8 对 DESC 排序使用 Backward index scan）
+//   按作者过滤时使用 idx_video_author_time (author_id, create_time)
+//   按更新时间过滤时优化器可能改用 idx_video_updated_at（近期变化的视频通常很少）
+//
 // 参数：
 //   ctx - 上下文
 //   limit - 返回的视频数量
-//   latestBefore - 游标：上一页最后一条视频的创建时间（零值表示第一页）
+//   cursor - 复合游标（创建时间 + ID），时间为零值表示第一页
+//   filter - 可选过滤条件（作者、起始时间、更新时间、互动量下限）
 //
 // 返回：
 //   []*video.Video - 视频列表
 //   error - 错误信息
-func (repo *FeedRepository) ListLatest(ctx context.Context, limit int, latestBefore time.Time) ([]*video.Video, error) {
+func (repo *FeedRepository) ListLatest(ctx context.Context, limit int, cursor TimeCursor, filter LatestFilter) ([]*video.Video, error) {
 	var videos []*video.Video
 
-	// 构建查询：按创建时间降序
+	// 构建查询：先按创建时间降序，再按 ID 降序
 	query := repo.db.WithContext(ctx).Model(&video.Video{}).
-		Order("create_time DESC")
+		Where("visibility = ?", video.VisibilityPublic).
+		Order("create_time DESC, id DESC")
+
+	// 游标分页：创建时间 + ID
+	query = whereTimeCursor(query, cursor)
 
-	// 游标分页：只查询小于游标时间的数据
-	if !latestBefore.IsZero() {
-		query = query.Where("create_time < ?", latestBefore)
+	// 可选过滤：作者、起始时间
+	if filter.AuthorID != 0 {
+		query = query.Where("author_id = ?", filter.AuthorID)
+	}
+	if !filter.Since.IsZero() {
+		query = query.Where("create_time >= ?", filter.Since)
 	}
+	if !filter.UpdatedSince.IsZero() {
+		query = query.Where("updated_at >= ?", filter.UpdatedSince)
+	}
+	query = whereEngagement(query, filter.EngagementFilter)
 
 	// 执行查询
 	if err := query.Limit(limit).Find(&videos).Error; err != nil {
@@ -66,9 +88,9 @@ func (repo *FeedRepository) ListLatest(ctx context.Context, limit int, latestBef
 //
 // SQL 等价查询：
 //   SELECT * FROM videos
-//   WHERE
+//   WHERE visibility = 'public' AND likes_count >= ? AND (
 //     (likes_count < ?) OR
-//     (likes_count = ? AND id < ?)
+//     (likes_count = ? AND id < ?))
 //   ORDER BY likes_count DESC, id DESC
 //   LIMIT ?;
 //
@@ -76,19 +98,29 @@ func (repo *FeedRepository) ListLatest(ctx context.Context, limit int, latestBef
 //   当多个视频点赞数相同时，使用 ID 作为第二排序字段
 //   确保分页时数据不重复、不遗漏
 //
+// 点赞数下限：
+//   按点赞数降序分页时，下限就是列表的终点：游标降到下限以下后不会再有数据，直接返回空列表
+//
 // 参数：
 //   ctx - 上下文
 //   limit - 返回的视频数量
 //   cursor - 复合游标（点赞数 + ID），nil 表示第一页
+//   minLikes - 点赞数下限（0 表示不过滤）
 //
 // 返回：
 //   []*video.Video - 视频列表
 //   error - 错误信息
-func (repo *FeedRepository) ListLikesCountWithCursor(ctx context.Context, limit int, cursor *LikesCountCursor) ([]*video.Video, error) {
+func (repo *FeedRepository) ListLikesCountWithCursor(ctx context.Context, limit int, cursor *LikesCountCursor, minLikes int64) ([]*video.Video, error) {
 	var videos []*video.Video
 
+	// 游标已低于点赞数下限：后面的视频都不满足条件，不需要查询数据库
+	if cursor != nil && cursor.LikesCount < minLikes {
+		return videos, nil
+	}
+
 	// 构建查询：先按点赞数降序，再按 ID 降序
 	query := repo.db.WithContext(ctx).Model(&video.Video{}).
+		Where("visibility = ?", video.VisibilityPublic).
 		Order("likes_count DESC, id DESC")
 
 	// 复合游标：点赞数 + ID
@@ -97,12 +129,17 @@ func (repo *FeedRepository) ListLikesCountWithCursor(ctx context.Context, limit
 	//   2. likes_count = cursor.LikesCount AND id < cursor.ID：点赞数相等但 ID 小于游标值
 	if cursor != nil {
 		query = query.Where(
-			"(likes_count < ?) OR (likes_count = ? AND id < ?)",
+			"((likes_count < ?) OR (likes_count = ? AND id < ?))",
 			cursor.LikesCount,              // 点赞数小于游标值
 			cursor.LikesCount, cursor.ID,  // 点赞数相等但 ID 小于游标值
 		)
 	}
 
+	// 点赞数下限：与游标条件是 AND 关系，仍然使用 likes_count 上的索引做范围扫描
+	if minLikes > 0 {
+		query = query.Where("likes_count >= ?", minLikes)
+	}
+
 	// 执行查询
 	if err := query.Limit(limit).Find(&videos).Error; err != nil {
 		return nil, err
@@ -117,28 +154,35 @@ func (repo *FeedRepository) ListLikesCountWithCursor(ctx context.Context, limit
 //
 // SQL 等价查询：
 //   SELECT * FROM videos
-//   WHERE author_id IN (
+//   WHERE visibility = 'public' AND author_id IN (
 //     SELECT vlogger_id FROM socials
 //     WHERE follower_id = ?
 //   )
-//   ORDER BY create_time DESC
+//   AND ((create_time < ?) OR (create_time = ? AND id < ?))
+//   ORDER BY create_time DESC, id DESC
 //   LIMIT ?;
 //
+// 索引：
+//   - socials.idx_social_follower (follower_id)：子查询按关注者过滤
+//   - videos.idx_video_author_time (author_id, create_time)：按作者 + 时间范围扫描
+//   EXPLAIN 中 videos 表 key 应为 idx_video_author_time 或 idx_video_create_time（取决于关注人数）
+//
 // 参数：
 //   ctx - 上下文
 //   limit - 返回的视频数量
 //   viewerAccountID - 当前用户的 ID（0 表示未登录，返回空列表）
-//   latestBefore - 游标：上一页最后一条视频的创建时间（零值表示第一页）
+//   cursor - 复合游标（创建时间 + ID），时间为零值表示第一页
 //
 // 返回：
 //   []*video.Video - 视频列表
 //   error - 错误信息
-func (repo *FeedRepository) ListByFollowing(ctx context.Context, limit int, viewerAccountID uint, latestBefore time.Time) ([]*video.Video, error) {
+func (repo *FeedRepository) ListByFollowing(ctx context.Context, limit int, viewerAccountID uint, cursor TimeCursor) ([]*video.Video, error) {
 	var videos []*video.Video
 
-	// 构建查询：按创建时间降序
+	// 构建查询：先按创建时间降序，再按 ID 降序
 	query := repo.db.WithContext(ctx).Model(&video.Video{}).
-		Order("create_time DESC")
+		Where("visibility = ?", video.VisibilityPublic).
+		Order("create_time DESC, id DESC")
 
 	// 使用子查询：只查询用户关注的作者的视频
 	if viewerAccountID > 0 {
@@ -152,10 +196,8 @@ func (repo *FeedRepository) ListByFollowing(ctx context.Context, limit int, view
 		query = query.Where("author_id IN (?)", followingSubQuery)
 	}
 
-	// 游标分页：只查询小于游标时间的数据
-	if !latestBefore.IsZero() {
-		query = query.Where("create_time < ?", latestBefore)
-	}
+	// 游标分页：创建时间 + ID
+	query = whereTimeCursor(query, cursor)
 
 	// 执行查询
 	if err := query.Limit(limit).Find(&videos).Error; err != nil {
@@ -164,6 +206,47 @@ func (repo *FeedRepository) ListByFollowing(ctx context.Context, limit int, view
 	return videos, nil
 }
 
+// whereTimeCursor 追加创建时间复合游标条件
+// 毫秒游标（与 create_time 精度一致），按相等比较：
+//   1. create_time < cursor.Time：早于游标时间
+//   2. create_time = cursor.Time AND id < cursor.ID：时间相同，ID 小于游标值
+// 秒级游标（旧客户端）无法精确比较，"时间相等"按同一秒处理：
+//   1. create_time < cursor.Time：早于游标所在的秒
+//   2. create_time < cursor.Time + 1s AND id < cursor.ID：与游标在同一秒内，ID 小于游标值
+//   同一秒内毫秒时间顺序与自增 ID 顺序不一致时（如定时发布的视频）仍可能重复或遗漏
+// 只传时间（ID 为 0）时退化为 create_time < cursor.Time
+func whereTimeCursor(query *gorm.DB, cursor TimeCursor) *gorm.DB {
+	if cursor.Time.IsZero() {
+		return query
+	}
+	if cursor.ID == 0 {
+		return query.Where("create_time < ?", cursor.Time)
+	}
+	if cursor.Exact {
+		return query.Where(
+			"((create_time < ?) OR (create_time = ? AND id < ?))",
+			cursor.Time,            // 早于游标时间
+			cursor.Time, cursor.ID, // 时间相同但 ID 小于游标值
+		)
+	}
+	return query.Where(
+		"((create_time < ?) OR (create_time < ? AND id < ?))",
+		cursor.Time,                             // 早于游标所在的秒
+		cursor.Time.Add(time.Second), cursor.ID, // 同一秒内 ID 小于游标值
+	)
+}
+
+// whereEngagement 追加互动量下限条件（下限为 0 时不过滤）
+func whereEngagement(query *gorm.DB, f EngagementFilter) *gorm.DB {
+	if f.MinLikes > 0 {
+		query = query.Where("likes_count >= ?", f.MinLikes)
+	}
+	if f.MinPopularity > 0 {
+		query = query.Where("popularity >= ?", f.MinPopularity)
+	}
+	return query
+}
+
 // ============ 按热度查询视频（DB Fallback） ============
 
 // ListByPopularity 按热度降序查询视频（DB Fallback 方式）
@@ -171,10 +254,11 @@ func (repo *FeedRepository) ListByFollowing(ctx context.Context, limit int, view
 //
 // SQL 等价查询：
 //   SELECT * FROM videos
-//   WHERE
+//   WHERE visibility = 'public' AND (
 //     (popularity < ?) OR
 //     (popularity = ? AND create_time < ?) OR
-//     (popularity = ? AND create_time = ? AND id < ?)
+//     (popularity = ? AND create_time = ? AND id < ?))
+//     [AND likes_count >= ?] [AND popularity >= ?]
 //   ORDER BY popularity DESC, create_time DESC, id DESC
 //   LIMIT ?;
 //
@@ -188,30 +272,35 @@ func (repo *FeedRepository) ListByFollowing(ctx context.Context, limit int, view
 //   popularityBefore - 游标：上一页最后一条视频的热度
 //   timeBefore - 游标：上一页最后一条视频的创建时间
 //   idBefore - 游标：上一页最后一条视频的 ID
+//   engagement - 互动量下限（零值表示不过滤）
 //
 // 返回：
 //   []*video.Video - 视频列表
 //   error - 错误信息
-func (repo *FeedRepository) ListByPopularity(ctx context.Context, limit int, popularityBefore int64, timeBefore time.Time, idBefore uint) ([]*video.Video, error) {
+func (repo *FeedRepository) ListByPopularity(ctx context.Context, limit int, popularityBefore int64, timeBefore time.Time, idBefore uint, engagement EngagementFilter) ([]*video.Video, error) {
 	var videos []*video.Video
 
 	// 构建查询：先按热度降序，再按时间降序，最后按 ID 降序
 	query := repo.db.WithContext(ctx).Model(&video.Video{}).
+		Where("visibility = ?", video.VisibilityPublic).
 		Order("popularity DESC, create_time DESC, id DESC")
 
 	// 三重复合游标：热度 + 时间 + ID
 	// 只有当游标完整提供时才加过滤（popularity 允许为 0）
 	if !timeBefore.IsZero() && idBefore > 0 {
 		query = query.Where(
-			"(popularity < ?) OR "+
+			"((popularity < ?) OR "+
 			"(popularity = ? AND create_time < ?) OR "+
-			"(popularity = ? AND create_time = ? AND id < ?)",
+			"(popularity = ? AND create_time = ? AND id < ?))",
 			popularityBefore,                       // 热度小于游标值
 			popularityBefore, timeBefore,           // 热度相等但时间小于游标值
 			popularityBefore, timeBefore, idBefore, // 热度、时间都相等但 ID 小于游标值
 		)
 	}
 
+	// 可选过滤：互动量下限
+	query = whereEngagement(query, engagement)
+
 	// 执行查询
 	if err := query.Limit(limit).Find(&videos).Error; err != nil {
 		return nil, err
@@ -226,7 +315,7 @@ func (repo *FeedRepository) ListByPopularity(ctx context.Context, limit int, pop
 //
 // SQL 等价查询：
 //   SELECT * FROM videos
-//   WHERE id IN (?, ?, ?, ...)
+//   WHERE id IN (?, ?, ?, ...) AND visibility = 'public'  -- 热榜中可能残留改为非公开的视频
 //   ORDER BY FIELD(id, ?, ?, ?, ...)  -- 保持传入顺序
 //
 // 注意：本方法只负责查询，排序由 Service 层处理
@@ -248,7 +337,7 @@ func (repo *FeedRepository) GetByIDs(ctx context.Context, ids []uint) ([]*video.
 
 	// 批量查询
 	if err := repo.db.WithContext(ctx).Model(&video.Video{}).
-		Where("id IN ?", ids).Find(&videos).Error; err != nil {
+		Where("id IN ? AND visibility = ?", ids, video.VisibilityPublic).Find(&videos).Error; err != nil {
 		return nil, err
 	}
 	return videos, nil
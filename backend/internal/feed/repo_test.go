@@ -0,0 +1,200 @@
+package feed
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"feedsystem_video_go/internal/social"
+	"feedsystem_video_go/internal/video"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("sqlite conn: %v", err)
+	}
+	// 内存数据库只在同一连接内可见
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	if err := db.AutoMigrate(&video.Video{}, &social.Social{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return db
+}
+
+// 多个视频的 create_time 落在同一秒内（含毫秒完全相同的视频）时，
+// 按毫秒游标和旧客户端的秒级游标翻页，都应不重复、不遗漏
+func TestListLatestPagesThroughSameSecond(t *testing.T) {
+	db := newTestDB(t)
+	base := time.Date(2026, 1, 1, 10, 0, 1, 0, time.Local)
+	rows := []struct {
+		id         uint
+		offset     time.Duration
+		visibility string
+	}{
+		{1, -500 * time.Millisecond, video.VisibilityPublic}, // 前一秒
+		{2, 0, video.VisibilityPublic},
+		{3, 120 * time.Millisecond, video.VisibilityPublic},
+		{4, 120 * time.Millisecond, video.VisibilityPublic}, // 与 3 的毫秒时间相同
+		{5, 480 * time.Millisecond, video.VisibilityPublic},
+		{6, 999 * time.Millisecond, video.VisibilityPublic},
+		{7, time.Second + 10*time.Millisecond, video.VisibilityPublic}, // 后一秒
+		{8, 300 * time.Millisecond, video.VisibilityPrivate},           // 非公开视频不出现在 Feed 中
+	}
+	for _, r := range rows {
+		v := &video.Video{ID: r.id, AuthorID: 1, Title: fmt.Sprintf("v%d", r.id), CreateTime: base.Add(r.offset), Visibility: r.visibility}
+		if err := db.Create(v).Error; err != nil {
+			t.Fatalf("insert video %d: %v", r.id, err)
+		}
+	}
+	want := []uint{7, 6, 5, 4, 3, 2, 1}
+
+	repo := NewFeedRepository(db)
+	cursors := map[string]func(last *video.Video) TimeCursor{
+		"millisecond": func(last *video.Video) TimeCursor {
+			return newTimeCursor(last.CreateTime.UnixMilli(), 0, last.ID)
+		},
+		"second": func(last *video.Video) TimeCursor {
+			return newTimeCursor(0, last.CreateTime.Unix(), last.ID)
+		},
+	}
+	for name, next := range cursors {
+		for _, limit := range []int{1, 2, 3} {
+			t.Run(fmt.Sprintf("%s/limit=%d", name, limit), func(t *testing.T) {
+				var got []uint
+				var cursor TimeCursor
+				for page := 0; page <= len(want); page++ {
+					videos, err := repo.ListLatest(context.Background(), limit, cursor, LatestFilter{})
+					if err != nil {
+						t.Fatalf("ListLatest: %v", err)
+					}
+					if len(videos) == 0 {
+						break
+					}
+					for _, v := range videos {
+						got = append(got, v.ID)
+					}
+					cursor = next(videos[len(videos)-1])
+				}
+				if fmt.Sprint(got) != fmt.Sprint(want) {
+					t.Fatalf("paged ids = %v, want %v", got, want)
+				}
+			})
+		}
+	}
+}
+
+// videoQueryPlans 执行 run 中的查询，返回每条 videos 表查询的 EXPLAIN QUERY PLAN 结果（按执行顺序，不含子查询）
+func videoQueryPlans(t *testing.T, db *gorm.DB, run func(repo *FeedRepository)) []string {
+	t.Helper()
+	type captured struct {
+		sql  string
+		vars []any
+	}
+	var queries []captured
+	if err := db.Callback().Query().After("gorm:query").Register("test:capture_sql", func(tx *gorm.DB) {
+		queries = append(queries, captured{sql: tx.Statement.SQL.String(), vars: append([]any(nil), tx.Statement.Vars...)})
+	}); err != nil {
+		t.Fatalf("register callback: %v", err)
+	}
+	run(NewFeedRepository(db))
+	_ = db.Callback().Query().Remove("test:capture_sql")
+
+	plans := make([]string, 0, len(queries))
+	for _, q := range queries {
+		if !strings.HasPrefix(q.sql, "SELECT * FROM `videos`") {
+			continue
+		}
+		rows, err := db.Raw("EXPLAIN QUERY PLAN "+q.sql, q.vars...).Rows()
+		if err != nil {
+			t.Fatalf("explain %s: %v", q.sql, err)
+		}
+		var details []string
+		for rows.Next() {
+			var id, parent, notUsed int
+			var detail string
+			if err := rows.Scan(&id, &parent, &notUsed, &detail); err != nil {
+				t.Fatalf("scan plan: %v", err)
+			}
+			details = append(details, detail)
+		}
+		_ = rows.Close()
+		plans = append(plans, strings.Join(details, "; "))
+	}
+	return plans
+}
+
+// AutoMigrate 应创建 create_time 和 (author_id, create_time) 索引，最新视频流 / 关注流的查询计划使用这两个索引，且不需要额外排序
+// SQLite 的 EXPLAIN QUERY PLAN 只用来确认索引能覆盖 WHERE + ORDER BY；MySQL 下的预期结果见 FeedRepository.ListLatest 的注释
+func TestLatestAndFollowingUseCreateTimeIndexes(t *testing.T) {
+	db := newTestDB(t)
+	for _, name := range []string{"idx_video_create_time", "idx_video_author_time"} {
+		if !db.Migrator().HasIndex(&video.Video{}, name) {
+			t.Fatalf("AutoMigrate did not create index %s", name)
+		}
+	}
+
+	// 数据分布接近线上：几乎全部是公开视频，visibility 没有区分度；ANALYZE 后优化器按统计信息选择索引
+	base := time.Now().Add(-48 * time.Hour)
+	videos := make([]*video.Video, 0, 2000)
+	for i := 1; i <= 2000; i++ {
+		videos = append(videos, &video.Video{ID: uint(i), AuthorID: uint(i%50 + 1), Title: "v", CreateTime: base.Add(time.Duration(i) * time.Minute), Visibility: video.VisibilityPublic})
+	}
+	if err := db.CreateInBatches(videos, 200).Error; err != nil {
+		t.Fatalf("insert videos: %v", err)
+	}
+	for author := uint(1); author <= 20; author++ {
+		if err := db.Create(&social.Social{FollowerID: 7, VloggerID: author}).Error; err != nil {
+			t.Fatalf("insert social: %v", err)
+		}
+	}
+	if err := db.Exec("ANALYZE").Error; err != nil {
+		t.Fatalf("analyze: %v", err)
+	}
+
+	cursor := TimeCursor{Time: base.Add(24 * time.Hour), ID: 1440, Exact: true}
+	ctx := context.Background()
+	plans := videoQueryPlans(t, db, func(repo *FeedRepository) {
+		_, _ = repo.ListLatest(ctx, 10, cursor, LatestFilter{})
+		_, _ = repo.ListLatest(ctx, 10, cursor, LatestFilter{AuthorID: 3})
+		_, _ = repo.ListByFollowing(ctx, 10, 7, cursor)
+	})
+	if len(plans) != 3 {
+		t.Fatalf("captured %d queries, want 3: %v", len(plans), plans)
+	}
+	cases := []struct {
+		name  string
+		plan  string
+		index []string
+	}{
+		{"latest", plans[0], []string{"idx_video_create_time"}},
+		{"latest by author", plans[1], []string{"idx_video_author_time"}},
+		{"following", plans[2], []string{"idx_video_author_time", "idx_video_create_time"}},
+	}
+	for _, c := range cases {
+		used := false
+		for _, index := range c.index {
+			used = used || strings.Contains(c.plan, "INDEX "+index)
+		}
+		if !used {
+			t.Errorf("%s: plan %q does not use %v", c.name, c.plan, c.index)
+		}
+		if strings.Contains(c.plan, "TEMP B-TREE FOR ORDER BY") {
+			t.Errorf("%s: plan %q sorts in a temp b-tree (filesort)", c.name, c.plan)
+		}
+	}
+}
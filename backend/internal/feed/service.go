@@ -2,14 +2,18 @@
 // 职责：
 //  1. 整合数据库查询和 Redis 缓存
 //  2. 实现分布式锁防止缓存击穿
-//  3. 批量查询点赞状态
+//  3. 批量查询点赞状态、关注状态
 //  4. 构建 FeedVideoItem 响应对象
 package feed
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
+	"feedsystem_video_go/internal/config"
+	"feedsystem_video_go/internal/invalidation"
+	"feedsystem_video_go/internal/metrics"
 	rediscache "feedsystem_video_go/internal/middleware/redis"
+	"feedsystem_video_go/internal/social"
 	"feedsystem_video_go/internal/video"
 	"fmt"
 	"math/rand"
@@ -21,25 +25,70 @@ import (
 type FeedService struct {
 	repo     *FeedRepository          // Feed 仓储（查询视频数据）
 	likeRepo *video.LikeRepository   // 点赞仓储（查询点赞状态）
+	socialRepo *social.SocialRepository // 关注仓储（查询关注状态）
 	cache    *rediscache.Client      // Redis 缓存客户端
-	cacheTTL time.Duration          // 缓存过期时间
+	live     *config.Live            // 运行时配置（缓存过期时间、热榜窗口支持 SIGHUP 热更新）
+	ttlJitter time.Duration          // 缓存过期时间的随机偏移（0-2 秒，避免同时过期）
 }
 
+// maxPopularityWindow 热榜聚合窗口上限（分钟级 ZSET 只保留 2 小时）
+const maxPopularityWindow = 120
+
 // NewFeedService 创建 Feed 服务实例
 // 参数：
 //   repo - Feed 仓储
 //   likeRepo - 点赞仓储
+//   socialRepo - 关注仓储
 //   cache - Redis 缓存客户端（可能为 nil）
+//   live - 运行时配置（可能为 nil，使用默认值）
 // 返回：
 //   *FeedService - Feed 服务实例
-func NewFeedService(repo *FeedRepository, likeRepo *video.LikeRepository, cache *rediscache.Client) *FeedService {
-	// 默认缓存过期时间：5 秒
+func NewFeedService(repo *FeedRepository, likeRepo *video.LikeRepository, socialRepo *social.SocialRepository, cache *rediscache.Client, live *config.Live) *FeedService {
 	return &FeedService{
-		repo:     repo,
-		likeRepo: likeRepo,
-		cache:    cache,
-		cacheTTL: 5*time.Second + time.Duration(rand.Intn(3))*time.Second,
+		repo:       repo,
+		likeRepo:   likeRepo,
+		socialRepo: socialRepo,
+		cache:     cache,
+		live:      live,
+		ttlJitter: time.Duration(rand.Intn(3)) * time.Second,
+	}
+}
+
+// withJitter 在配置的缓存过期时间上加随机偏移（默认 5-7 秒）；ttl 为 0 表示不缓存，返回 0
+func (f *FeedService) withJitter(ttl time.Duration) time.Duration {
+	if ttl <= 0 {
+		return 0
+	}
+	return ttl + f.ttlJitter
+}
+
+// latestTTL 当前最新视频流的缓存过期时间（0 表示不缓存）
+func (f *FeedService) latestTTL() time.Duration {
+	return f.withJitter(f.live.Tunables().FeedLatestTTL)
+}
+
+// followingTTL 当前关注流的缓存过期时间（0 表示不缓存）
+func (f *FeedService) followingTTL() time.Duration {
+	return f.withJitter(f.live.Tunables().FeedFollowingTTL)
+}
+
+// popularityWindow 当前的热榜聚合窗口（分钟，默认 60，最大 120）
+func (f *FeedService) popularityWindow() int {
+	win := f.live.Tunables().PopularityWindow
+	if win > maxPopularityWindow {
+		win = maxPopularityWindow
+	}
+	return win
+}
+
+// normalizeLimit 规范化客户端请求的返回数量（所有 Feed 接口共用）
+// 未传、<= 0 或超过上限时使用默认值；默认值和上限来自配置 feed.default_limit / feed.max_limit（支持热更新）
+func (f *FeedService) normalizeLimit(requested int) int {
+	t := f.live.Tunables()
+	if requested <= 0 || requested > t.FeedMaxLimit {
+		return t.FeedDefaultLimit
 	}
+	return requested
 }
 
 // ============================================================================
@@ -59,40 +108,45 @@ func NewFeedService(repo *FeedRepository, likeRepo *video.LikeRepository, cache
 //   8. 构建响应并返回
 //
 // 缓存策略：
-//   - 缓存键格式：feed:listLatest:limit=10:before=0
-//   - 缓存过期时间：5 秒
+//   - 缓存键格式：feed:listLatest:limit=10:before=0:id=0
+//     （毫秒游标为 before={毫秒时间戳}ms）
+//     （带过滤条件时追加 :author={作者ID}:since={起始时间}、:updated={更新时间}、:minLikes={点赞数}:minPopularity={热度}）
+//   - 缓存过期时间：5 秒；之后的 feed.stale_window 内仍返回旧数据，并在后台刷新（stale-while-revalidate）
 //   - 仅对匿名用户缓存（viewerAccountID = 0）
 //
 // 分布式锁：
-//   - 锁键格式：lock:feed:listLatest:limit=10:before=0
-//   - 锁过期时间：500 毫秒
+//   - 锁键格式：lock:feed:listLatest:limit=10:before=0:id=0
+//   - 锁过期时间：LockPolicy().TTL（默认 2 秒，由 redis.lock_ttl 配置）
+//   - 没拿到锁时按 redis.lock_wait_attempts / redis.lock_wait_interval 等待回填（默认最多 5 次，每次约 20ms 并带随机抖动）
 //   - 防止缓存击穿（大量并发同时查询数据库）
 //
 // 参数：
 //   ctx - 上下文
 //   limit - 返回的视频数量
-//   latestBefore - 游标：上一页最后一条视频的创建时间
+//   cursor - 复合游标：上一页最后一条视频的创建时间 + ID
+//   filter - 可选过滤条件（作者、起始时间）
 //   viewerAccountID - 当前用户 ID（0 表示匿名用户）
 //
 // 返回：
 //   ListLatestResponse - 响应对象
 //   error - 错误信息
-func (f *FeedService) ListLatest(ctx context.Context, limit int, latestBefore time.Time, viewerAccountID uint) (ListLatestResponse, error) {
+func (f *FeedService) ListLatest(ctx context.Context, limit int, cursor TimeCursor, filter LatestFilter, viewerAccountID uint) (ListLatestResponse, error) {
 	// 定义数据库查询函数（闭包）
 	// 职责：从数据库查询视频，构建响应对象
-	doListLatestFromDB := func() (ListLatestResponse, error) {
+	doListLatestFromDB := func(ctx context.Context) (ListLatestResponse, error) {
 		// 1. 从数据库查询视频
-		videos, err := f.repo.ListLatest(ctx, limit, latestBefore)
+		videos, err := f.repo.ListLatest(ctx, limit, cursor, filter)
 		if err != nil {
 			return ListLatestResponse{}, err
 		}
 
-		// 2. 计算下一页游标（最后一条视频的创建时间）
-		var nextTime int64
+		// 2. 计算下一页游标（最后一条视频的创建时间 + ID）
+		var nextTime, nextTimeMs int64
+		var nextID uint
 		if len(videos) > 0 {
 			nextTime = videos[len(videos)-1].CreateTime.Unix()
-		} else {
-			nextTime = 0
+			nextTimeMs = videos[len(videos)-1].CreateTime.UnixMilli()
+			nextID = videos[len(videos)-1].ID
 		}
 
 		// 3. 判断是否还有更多数据
@@ -107,23 +161,39 @@ func (f *FeedService) ListLatest(ctx context.Context, limit int, latestBefore ti
 		// 5. 构建响应对象
 		resp := ListLatestResponse{
 			VideoList: feedVideos,
-			NextTime:  nextTime,
+			NextTime:   nextTime,
+			NextTimeMs: nextTimeMs,
+			NextID:     nextID,
 			HasMore:   hasMore,
+			Algo:       AlgoLatest,
 		}
 		return resp, nil
 	}
 
 	// ========== Redis 缓存逻辑 ==========
 
-	// 缓存键格式：feed:listLatest:limit=10:before=0
+	// 缓存键格式：feed:listLatest:limit=10:before=0:id=0
 	// 注意：仅对匿名用户缓存（viewerAccountID = 0）
+	// 过期时间在请求开始时读取一次（热更新不影响进行中的请求），为 0 时不缓存
 	var cacheKey string
-	if viewerAccountID == 0 && f.cache != nil {
-		before := int64(0)
-		if !latestBefore.IsZero() {
-			before = latestBefore.Unix()
+	ttl := f.latestTTL()
+	stale := f.staleWindow()
+	if viewerAccountID == 0 && f.cache != nil && ttl > 0 {
+		cacheKey = fmt.Sprintf("feed:listLatest:limit=%d:before=%s:id=%d", limit, timeCursorKey(cursor), cursor.ID)
+		// 带过滤条件时追加到缓存键，避免不同作者/时间范围的结果互相污染
+		if filter.AuthorID != 0 || !filter.Since.IsZero() {
+			since := int64(0)
+			if !filter.Since.IsZero() {
+				since = filter.Since.Unix()
+			}
+			cacheKey += fmt.Sprintf(":author=%d:since=%d", filter.AuthorID, since)
+		}
+		if !filter.UpdatedSince.IsZero() {
+			cacheKey += fmt.Sprintf(":updated=%d", filter.UpdatedSince.Unix())
+		}
+		if filter.MinLikes > 0 || filter.MinPopularity > 0 {
+			cacheKey += fmt.Sprintf(":minLikes=%d:minPopularity=%d", filter.MinLikes, filter.MinPopularity)
 		}
-		cacheKey = fmt.Sprintf("feed:listLatest:limit=%d:before=%d", limit, before)
 
 		// 设置缓存查询超时：50 毫秒
 		cacheCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
@@ -134,15 +204,26 @@ func (f *FeedService) ListLatest(ctx context.Context, limit int, latestBefore ti
 		if err == nil {
 			// 缓存命中：反序列化并返回
 			var cached ListLatestResponse
-			if err := json.Unmarshal(b, &cached); err == nil {
+			if fresh, err := decodeFeedPage(b, &cached); err == nil {
+				if fresh {
+					metrics.ObserveCache(metrics.CacheFeedLatest, metrics.CacheHit)
+				} else {
+					// 已软过期：返回旧数据，同时在后台刷新（stale-while-revalidate）
+					metrics.ObserveCache(metrics.CacheFeedLatest, metrics.CacheStaleHit)
+					f.revalidate(ctx, cacheKey, ttl, stale, func(ctx context.Context) (any, error) {
+						return doListLatestFromDB(ctx)
+					})
+				}
 				return cached, nil
 			}
+			metrics.ObserveCache(metrics.CacheFeedLatest, metrics.CacheError)
 		} else if rediscache.IsMiss(err) { // 缓存未命中
-			// 分布式锁键：lock:feed:listLatest:limit=10:before=0
+			metrics.ObserveCache(metrics.CacheFeedLatest, metrics.CacheMiss)
+			// 分布式锁键：lock:feed:listLatest:limit=10:before=0:id=0
 			lockKey := "lock:" + cacheKey
 
 			// 2. 尝试获取分布式锁（防止缓存击穿）
-			token, locked, _ := f.cache.Lock(cacheCtx, lockKey, 500*time.Millisecond)
+			token, locked, _ := f.cache.Lock(cacheCtx, lockKey, f.cache.LockPolicy().TTL)
 			if locked {
 				// 获取锁成功：再次检查缓存（双重检查）
 				defer func() { _ = f.cache.Unlock(context.Background(), lockKey, token) }()
@@ -150,53 +231,56 @@ func (f *FeedService) ListLatest(ctx context.Context, limit int, latestBefore ti
 				if b, err := f.cache.GetBytes(cacheCtx, cacheKey); err == nil {
 					// 缓存已存在（其他 goroutine 已写入）
 					var cached ListLatestResponse
-					if err := json.Unmarshal(b, &cached); err == nil {
+					if _, err := decodeFeedPage(b, &cached); err == nil {
 						return cached, nil
 					}
 				} else {
 					// 缓存仍然未命中：查询数据库
-					resp, err := doListLatestFromDB()
+					resp, err := doListLatestFromDB(ctx)
 					if err != nil {
 						return ListLatestResponse{}, err
 					}
-					// 写入缓存
-					if b, err := json.Marshal(resp); err == nil {
-						_ = f.cache.SetBytes(cacheCtx, cacheKey, b, f.cacheTTL)
-					}
+					// 写入缓存（查询数据库后 cacheCtx 可能已超时，使用新的超时上下文，保证等待中的请求能读到回填）
+					f.storeFeedPage(ctx, cacheKey, resp, ttl, stale)
 					return resp, nil
 				}
 			} else {
 				// 获取锁失败：其他 goroutine 正在查询数据库
-				// 短暂等待后重试（最多 5 次，每次 20 毫秒）
-				for i := 0; i < 5; i++ {
-					time.Sleep(20 * time.Millisecond)
-					if b, err := f.cache.GetBytes(cacheCtx, cacheKey); err == nil {
-						var cached ListLatestResponse
-						if err := json.Unmarshal(b, &cached); err == nil {
-							return cached, nil
-						}
+				// 按击穿保护策略等待回填（默认最多 5 次，每次约 20 毫秒并带随机抖动；每次读取使用独立的超时）
+				var cached ListLatestResponse
+				if f.cache.LockWait(ctx, func() bool {
+					getCtx, getCancel := context.WithTimeout(ctx, 50*time.Millisecond)
+					defer getCancel()
+					b, err := f.cache.GetBytes(getCtx, cacheKey)
+					if err != nil {
+						return false
 					}
+					_, err = decodeFeedPage(b, &cached)
+					return err == nil
+				}) {
+					metrics.ObserveCache(metrics.CacheFeedLatest, metrics.CacheLockWaitHit)
+					return cached, nil
 				}
 				// 等待超时：直接查询数据库
+				metrics.ObserveCache(metrics.CacheFeedLatest, metrics.CacheLockWaitTimeout)
 			}
+		} else {
+			// Redis 出错：直接查询数据库
+			metrics.ObserveCache(metrics.CacheFeedLatest, metrics.CacheError)
 		}
 	}
 
 	// ========== 数据库查询逻辑 ==========
 
 	// 缓存中没有查询到结果，从数据库中查询
-	resp, err := doListLatestFromDB()
+	resp, err := doListLatestFromDB(ctx)
 	if err != nil {
 		return ListLatestResponse{}, err
 	}
 
-	// 异步写入缓存（不阻塞响应）
+	// 写入缓存（软过期时间为 ttl，实际过期时间再加 stale 窗口）
 	if cacheKey != "" {
-		if b, err := json.Marshal(resp); err == nil {
-			cacheCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
-			defer cancel()
-			_ = f.cache.SetBytes(cacheCtx, cacheKey, b, f.cacheTTL)
-		}
+		f.storeFeedPage(ctx, cacheKey, resp, ttl, stale)
 	}
 
 	return resp, nil
@@ -221,14 +305,15 @@ func (f *FeedService) ListLatest(ctx context.Context, limit int, latestBefore ti
 //   ctx - 上下文
 //   limit - 返回的视频数量
 //   cursor - 复合游标（点赞数 + ID），nil 表示第一页
+//   minLikes - 点赞数下限（0 表示不过滤；游标低于下限时返回空列表，has_more 为 false）
 //   viewerAccountID - 当前用户 ID（0 表示匿名用户）
 //
 // 返回：
 //   ListLikesCountResponse - 响应对象
 //   error - 错误信息
-func (f *FeedService) ListLikesCount(ctx context.Context, limit int, cursor *LikesCountCursor, viewerAccountID uint) (ListLikesCountResponse, error) {
-	// 1. 从数据库查询视频（复合游标分页）
-	videos, err := f.repo.ListLikesCountWithCursor(ctx, limit, cursor)
+func (f *FeedService) ListLikesCount(ctx context.Context, limit int, cursor *LikesCountCursor, minLikes int64, viewerAccountID uint) (ListLikesCountResponse, error) {
+	// 1. 从数据库查询视频（复合游标分页，点赞数不少于 minLikes）
+	videos, err := f.repo.ListLikesCountWithCursor(ctx, limit, cursor, minLikes)
 	if err != nil {
 		return ListLikesCountResponse{}, err
 	}
@@ -246,6 +331,7 @@ func (f *FeedService) ListLikesCount(ctx context.Context, limit int, cursor *Lik
 	resp := ListLikesCountResponse{
 		VideoList: feedVideos,
 		HasMore:   hasMore,
+		Algo:      AlgoLikesCount,
 	}
 
 	// 5. 计算下一页游标（复合游标：点赞数 + ID）
@@ -275,34 +361,37 @@ func (f *FeedService) ListLikesCount(ctx context.Context, limit int, cursor *Lik
 //   6. 构建响应并返回
 //
 // 缓存策略：
-//   - 缓存键格式：feed:listByFollowing:limit=10:accountID=123:before=0
-//   - 缓存过期时间：5 秒
+//   - 缓存键格式：feed:listByFollowing:limit=10:accountID=123:before=0:id=0:v={关注流版本号}
+//     （毫秒游标为 before={毫秒时间戳}ms）
+//   - 缓存过期时间：5 秒；之后的 feed.stale_window 内仍返回旧数据，并在后台刷新（stale-while-revalidate）
+//   - 关注的作者发布/删除视频时递增版本号，新视频立即出现在关注流中
 //   - 仅对已登录用户缓存（viewerAccountID > 0）
 //
 // 参数：
 //   ctx - 上下文
 //   limit - 返回的视频数量
-//   latestBefore - 游标：上一页最后一条视频的创建时间
+//   cursor - 复合游标：上一页最后一条视频的创建时间 + ID
 //   viewerAccountID - 当前用户 ID
 //
 // 返回：
 //   ListByFollowingResponse - 响应对象
 //   error - 错误信息
-func (f *FeedService) ListByFollowing(ctx context.Context, limit int, latestBefore time.Time, viewerAccountID uint) (ListByFollowingResponse, error) {
+func (f *FeedService) ListByFollowing(ctx context.Context, limit int, cursor TimeCursor, viewerAccountID uint) (ListByFollowingResponse, error) {
 	// 定义数据库查询函数（闭包）
-	doListByFollowingFromDB := func() (ListByFollowingResponse, error) {
+	doListByFollowingFromDB := func(ctx context.Context) (ListByFollowingResponse, error) {
 		// 1. 从数据库查询视频（使用子查询获取关注的作者）
-		videos, err := f.repo.ListByFollowing(ctx, limit, viewerAccountID, latestBefore)
+		videos, err := f.repo.ListByFollowing(ctx, limit, viewerAccountID, cursor)
 		if err != nil {
 			return ListByFollowingResponse{}, err
 		}
 
-		// 2. 计算下一页游标
-		var nextTime int64
+		// 2. 计算下一页游标（最后一条视频的创建时间 + ID）
+		var nextTime, nextTimeMs int64
+		var nextID uint
 		if len(videos) > 0 {
 			nextTime = videos[len(videos)-1].CreateTime.Unix()
-		} else {
-			nextTime = 0
+			nextTimeMs = videos[len(videos)-1].CreateTime.UnixMilli()
+			nextID = videos[len(videos)-1].ID
 		}
 
 		// 3. 判断是否还有更多数据
@@ -317,42 +406,60 @@ func (f *FeedService) ListByFollowing(ctx context.Context, limit int, latestBefo
 		// 5. 构建响应对象
 		resp := ListByFollowingResponse{
 			VideoList: feedVideos,
-			NextTime:  nextTime,
+			NextTime:   nextTime,
+			NextTimeMs: nextTimeMs,
+			NextID:     nextID,
 			HasMore:   hasMore,
+			Algo:       AlgoFollowing,
 		}
 		return resp, nil
 	}
 
 	// ========== Redis 缓存逻辑 ==========
 
-	// 缓存键格式：feed:listByFollowing:limit=10:accountID=123:before=0
+	// 缓存键格式：feed:listByFollowing:limit=10:accountID=123:before=0:id=0:v=3
 	// 注意：仅对已登录用户缓存（viewerAccountID > 0）
+	// v 为该用户的关注流版本号（关注的作者发布/删除视频后递增，旧版本缓存不再命中），不存在时为 0
+	// 过期时间在请求开始时读取一次（热更新不影响进行中的请求），为 0 时不缓存
 	var cacheKey string
-	if viewerAccountID != 0 && f.cache != nil {
-		before := int64(0)
-		if !latestBefore.IsZero() {
-			before = latestBefore.Unix()
-		}
-		cacheKey = fmt.Sprintf("feed:listByFollowing:limit=%d:accountID=%d:before=%d", limit, viewerAccountID, before)
-
+	ttl := f.followingTTL()
+	stale := f.staleWindow()
+	if viewerAccountID != 0 && f.cache != nil && ttl > 0 {
 		// 设置缓存查询超时：50 毫秒
 		cacheCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
 		defer cancel()
 
+		version := "0"
+		if b, err := f.cache.GetBytes(cacheCtx, invalidation.FollowingVersionKey(viewerAccountID)); err == nil {
+			version = string(b)
+		}
+		cacheKey = fmt.Sprintf("feed:listByFollowing:limit=%d:accountID=%d:before=%s:id=%d:v=%s", limit, viewerAccountID, timeCursorKey(cursor), cursor.ID, version)
+
 		// 1. 尝试从 Redis 缓存读取
 		b, err := f.cache.GetBytes(cacheCtx, cacheKey)
 		if err == nil {
 			// 缓存命中：反序列化并返回
 			var cached ListByFollowingResponse
-			if err := json.Unmarshal(b, &cached); err == nil {
+			if fresh, err := decodeFeedPage(b, &cached); err == nil {
+				if fresh {
+					metrics.ObserveCache(metrics.CacheFeedFollowing, metrics.CacheHit)
+				} else {
+					// 已软过期：返回旧数据，同时在后台刷新（stale-while-revalidate）
+					metrics.ObserveCache(metrics.CacheFeedFollowing, metrics.CacheStaleHit)
+					f.revalidate(ctx, cacheKey, ttl, stale, func(ctx context.Context) (any, error) {
+						return doListByFollowingFromDB(ctx)
+					})
+				}
 				return cached, nil
 			}
+			metrics.ObserveCache(metrics.CacheFeedFollowing, metrics.CacheError)
 		} else if rediscache.IsMiss(err) { // 缓存未命中
-			// 分布式锁键：lock:feed:listByFollowing:limit=10:accountID=123:before=0
+			metrics.ObserveCache(metrics.CacheFeedFollowing, metrics.CacheMiss)
+			// 分布式锁键：lock:feed:listByFollowing:limit=10:accountID=123:before=0:id=0:v=3
 			lockKey := "lock:" + cacheKey
 
 			// 2. 尝试获取分布式锁（防止缓存击穿）
-			token, locked, _ := f.cache.Lock(cacheCtx, lockKey, 500*time.Millisecond)
+			token, locked, _ := f.cache.Lock(cacheCtx, lockKey, f.cache.LockPolicy().TTL)
 			if locked {
 				// 获取锁成功：再次检查缓存（双重检查）
 				defer func() { _ = f.cache.Unlock(context.Background(), lockKey, token) }()
@@ -360,53 +467,56 @@ func (f *FeedService) ListByFollowing(ctx context.Context, limit int, latestBefo
 				if b, err := f.cache.GetBytes(cacheCtx, cacheKey); err == nil {
 					// 缓存已存在（其他 goroutine 已写入）
 					var cached ListByFollowingResponse
-					if err := json.Unmarshal(b, &cached); err == nil {
+					if _, err := decodeFeedPage(b, &cached); err == nil {
 						return cached, nil
 					}
 				} else {
 					// 缓存仍然未命中：查询数据库
-					resp, err := doListByFollowingFromDB()
+					resp, err := doListByFollowingFromDB(ctx)
 					if err != nil {
 						return ListByFollowingResponse{}, err
 					}
-					// 写入缓存
-					if b, err := json.Marshal(resp); err == nil {
-						_ = f.cache.SetBytes(cacheCtx, cacheKey, b, f.cacheTTL)
-					}
+					// 写入缓存（查询数据库后 cacheCtx 可能已超时，使用新的超时上下文，保证等待中的请求能读到回填）
+					f.storeFeedPage(ctx, cacheKey, resp, ttl, stale)
 					return resp, nil
 				}
 			} else {
 				// 获取锁失败：其他 goroutine 正在查询数据库
-				// 短暂等待后重试（最多 5 次，每次 20 毫秒）
-				for i := 0; i < 5; i++ {
-					time.Sleep(20 * time.Millisecond)
-					if b, err := f.cache.GetBytes(cacheCtx, cacheKey); err == nil {
-						var cached ListByFollowingResponse
-						if err := json.Unmarshal(b, &cached); err == nil {
-							return cached, nil
-						}
+				// 按击穿保护策略等待回填（默认最多 5 次，每次约 20 毫秒并带随机抖动；每次读取使用独立的超时）
+				var cached ListByFollowingResponse
+				if f.cache.LockWait(ctx, func() bool {
+					getCtx, getCancel := context.WithTimeout(ctx, 50*time.Millisecond)
+					defer getCancel()
+					b, err := f.cache.GetBytes(getCtx, cacheKey)
+					if err != nil {
+						return false
 					}
+					_, err = decodeFeedPage(b, &cached)
+					return err == nil
+				}) {
+					metrics.ObserveCache(metrics.CacheFeedFollowing, metrics.CacheLockWaitHit)
+					return cached, nil
 				}
 				// 等待超时：直接查询数据库
+				metrics.ObserveCache(metrics.CacheFeedFollowing, metrics.CacheLockWaitTimeout)
 			}
+		} else {
+			// Redis 出错：直接查询数据库
+			metrics.ObserveCache(metrics.CacheFeedFollowing, metrics.CacheError)
 		}
 	}
 
 	// ========== 数据库查询逻辑 ==========
 
 	// 缓存中没有查询到结果，从数据库中查询
-	resp, err := doListByFollowingFromDB()
+	resp, err := doListByFollowingFromDB(ctx)
 	if err != nil {
 		return ListByFollowingResponse{}, err
 	}
 
-	// 异步写入缓存（不阻塞响应）
+	// 写入缓存（软过期时间为 ttl，实际过期时间再加 stale 窗口）
 	if cacheKey != "" {
-		if b, err := json.Marshal(resp); err == nil {
-			cacheCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
-			defer cancel()
-			_ = f.cache.SetBytes(cacheCtx, cacheKey, b, f.cacheTTL)
-		}
+		f.storeFeedPage(ctx, cacheKey, resp, ttl, stale)
 	}
 
 	return resp, nil
@@ -448,6 +558,8 @@ func (f *FeedService) ListByFollowing(ctx context.Context, limit int, latestBefo
 //      a. 降级到数据库查询（复合游标分页）
 //      b. 使用热度 + 时间 + ID 三重游标
 //
+// 响应的 algo 区分两条路径：popularity_zset（Redis 热榜）/ popularity_db_fallback（数据库降级）
+//
 // 参数：
 //   ctx - 上下文
 //   limit - 返回的视频数量
@@ -457,11 +569,12 @@ func (f *FeedService) ListByFollowing(ctx context.Context, limit int, latestBefo
 //   latestPopularity - DB Fallback 用游标：热度
 //   latestBefore - DB Fallback 用游标：时间
 //   latestIDBefore - DB Fallback 用游标：ID
+//   engagement - 互动量下限（Redis 热榜路径在取出一页后过滤，DB Fallback 路径作为 WHERE 条件）
 //
 // 返回：
 //   ListByPopularityResponse - 响应对象
 //   error - 错误信息
-func (f *FeedService) ListByPopularity(ctx context.Context, limit int, reqAsOf int64, offset int, viewerAccountID uint, latestPopularity int64, latestBefore time.Time, latestIDBefore uint) (ListByPopularityResponse, error) {
+func (f *FeedService) ListByPopularity(ctx context.Context, limit int, reqAsOf int64, offset int, viewerAccountID uint, latestPopularity int64, latestBefore time.Time, latestIDBefore uint, engagement EngagementFilter) (ListByPopularityResponse, error) {
 	// ========== Redis 热榜查询 ==========
 
 	if f.cache != nil {
@@ -471,9 +584,9 @@ func (f *FeedService) ListByPopularity(ctx context.Context, limit int, reqAsOf i
 			asOf = time.Unix(reqAsOf, 0).UTC().Truncate(time.Minute)
 		}
 
-		// 2. 聚合最近 60 分钟的热度数据
-		// 聚合最近 60 个 ZSET 的键名
-		const win = 60
+		// 2. 聚合最近 win 分钟的热度数据（默认 60，可通过配置热更新）
+		// 聚合最近 win 个 ZSET 的键名
+		win := f.popularityWindow()
 		keys := make([]string, 0, win)
 		for i := 0; i < win; i++ {
 			// Key 格式：hot:video:1m:202401011500
@@ -481,21 +594,29 @@ func (f *FeedService) ListByPopularity(ctx context.Context, limit int, reqAsOf i
 		}
 
 		// 3. 生成热榜快照（ZUNIONSTORE）
-		// 快照 Key 格式：hot:video:merge:1m:202401011500
+		// 快照 Key 格式：hot:video:merge:1m:202401011500（窗口不是默认的 60 分钟时追加 :w={窗口}）
 		// 同一个 as_of 内，快照 Key 复用（避免重复聚合）
 		dest := "hot:video:merge:1m:" + asOf.Format("200601021504")
+		if win != config.DefaultPopularityWindow {
+			dest += fmt.Sprintf(":w=%d", win)
+		}
 		opCtx, cancel := context.WithTimeout(ctx, 80*time.Millisecond)
 		defer cancel()
 
 		// 检查快照是否已存在
+		// 一致性保证：
+		//   - 快照过期时间为 2 分钟，每次翻页访问都会续期：相邻两页间隔不超过 2 分钟时，整个翻页过程读取同一份快照，顺序稳定、不重复不遗漏
+		//   - 相邻两页间隔超过 2 分钟时快照已过期，按同一个 as_of 从分钟窗口重新聚合：
+		//     as_of 之前的分钟窗口不再变化，结果只在 as_of 所在分钟的热度上有差异（该分钟创建快照时尚未结束），可能出现少量重复或遗漏
+		//   - 分钟窗口保留 2 小时，as_of 早于 2 小时的翻页会拿到不完整的快照，客户端应从第一页重新开始
 		exists, _ := f.cache.Exists(opCtx, dest)
 		if !exists {
 			// 快照不存在：聚合最近 60 分钟的热度数据（SUM 求和）
 			_ = f.cache.ZUnionStore(opCtx, dest, keys, "SUM")
-			// 设置快照过期时间：2 分钟（给翻页留时间）
-			randomOffset := rand.Intn(30)
-			_ = f.cache.Expire(opCtx, dest, 2*time.Minute+time.Duration(randomOffset)*time.Second)
 		}
+		// 设置（或续期）快照过期时间：2 分钟（给翻页留时间，随机抖动避免同时过期）
+		randomOffset := rand.Intn(30)
+		_ = f.cache.Expire(opCtx, dest, 2*time.Minute+time.Duration(randomOffset)*time.Second)
 
 		// 4. 使用 offset 分页获取视频 ID
 		// ZREVRANGE：按分数降序返回指定范围的成员
@@ -503,16 +624,18 @@ func (f *FeedService) ListByPopularity(ctx context.Context, limit int, reqAsOf i
 		stop := start + int64(limit) - 1
 		members, err := f.cache.ZRevRange(opCtx, dest, start, stop)
 
-		// 处理空结果（offset 过大）
-		if err == nil && len(members) == 0 {
-			if offset > 0 {
-				return ListByPopularityResponse{
-					VideoList:  []FeedVideoItem{},
-					AsOf:       asOf.Unix(),
-					NextOffset: offset,
-					HasMore:    false,
-				}, nil
-			}
+		// 处理空结果：
+		//   - offset > 0：已翻到热榜末尾，返回空页
+		//   - offset == 0：热榜为空（如 Redis 刚重启、最近窗口内没有互动），第一页降级到数据库热度查询，
+		//     响应的 as_of 为 0，客户端后续翻页使用 DB 游标
+		if err == nil && len(members) == 0 && offset > 0 {
+			return ListByPopularityResponse{
+				VideoList:  []FeedVideoItem{},
+				AsOf:       asOf.Unix(),
+				NextOffset: offset,
+				HasMore:    false,
+				Algo:       AlgoPopularityZSet,
+			}, nil
 		}
 
 		// 5. 批量查询视频详细信息
@@ -526,8 +649,8 @@ func (f *FeedService) ListByPopularity(ctx context.Context, limit int, reqAsOf i
 				}
 			}
 
-			// 批量查询视频
-			videos, err := f.repo.GetByIDs(ctx, ids)
+			// 批量查询视频（优先读取详情缓存，只对未命中的ID查询数据库）
+			videos, err := f.getVideosByIDs(ctx, ids)
 			if err == nil {
 				// 6. 保持 Redis 返回的顺序（按热度降序）
 				// 使用 map 快速查找
@@ -537,9 +660,10 @@ func (f *FeedService) ListByPopularity(ctx context.Context, limit int, reqAsOf i
 				}
 
 				// 按 ids 的顺序构建 ordered 列表
+				// 热榜按窗口热度排序，互动量下限只能在取出一页后过滤（本页可能少于 limit 条）
 				ordered := make([]*video.Video, 0, len(ids))
 				for _, id := range ids {
-					if v := byID[id]; v != nil {
+					if v := byID[id]; v != nil && engagement.matches(v) {
 						ordered = append(ordered, v)
 					}
 				}
@@ -551,11 +675,13 @@ func (f *FeedService) ListByPopularity(ctx context.Context, limit int, reqAsOf i
 				}
 
 				// 8. 构建响应对象
+				// 下一页偏移量按热榜中取出的成员数计算（被过滤或已删除的视频也占用热榜位置，否则下一页会重复）
 				resp := ListByPopularityResponse{
 					VideoList:  items,
 					AsOf:       asOf.Unix(),
-					NextOffset: offset + len(items),
-					HasMore:    len(items) == limit,
+					NextOffset: offset + len(members),
+					HasMore:    len(members) == limit,
+					Algo:       AlgoPopularityZSet,
 				}
 
 				// 9. 计算下一页游标（DB Fallback 用）
@@ -574,10 +700,10 @@ func (f *FeedService) ListByPopularity(ctx context.Context, limit int, reqAsOf i
 		}
 	}
 
-	// ========== DB Fallback（Redis 不可用）==========
+	// ========== DB Fallback（Redis 不可用 / 热榜为空）==========
 
-	// Redis 不可用时，降级到数据库查询
-	videos, err := f.repo.ListByPopularity(ctx, limit, latestPopularity, latestBefore, latestIDBefore)
+	// Redis 不可用、热榜第一页为空或查询视频详情失败时，降级到数据库查询
+	videos, err := f.repo.ListByPopularity(ctx, limit, latestPopularity, latestBefore, latestIDBefore, engagement)
 	if err != nil {
 		return ListByPopularityResponse{}, err
 	}
@@ -594,6 +720,7 @@ func (f *FeedService) ListByPopularity(ctx context.Context, limit int, reqAsOf i
 		AsOf:       0,
 		NextOffset: 0,
 		HasMore:    len(items) == limit,
+		Algo:       AlgoPopularityDBFallback,
 	}
 
 	// 计算下一页游标
@@ -610,15 +737,66 @@ func (f *FeedService) ListByPopularity(ctx context.Context, limit int, reqAsOf i
 	return resp, nil
 }
 
+// getVideosByIDs 批量查询视频详情（热榜路径）
+// 先用一次 MGET 读取 video:detail:id={id} 缓存，只对未命中的ID查询数据库；返回顺序不保证，由调用方按 ids 排序
+// 详情缓存包含非公开视频，这里只保留公开视频（与 repo.GetByIDs 的过滤条件一致）；命中负缓存的ID直接跳过
+// Redis 出错时整体降级为数据库查询
+func (f *FeedService) getVideosByIDs(ctx context.Context, ids []uint) ([]*video.Video, error) {
+	if f.cache == nil || len(ids) == 0 {
+		return f.repo.GetByIDs(ctx, ids)
+	}
+
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = video.DetailCacheKey(id)
+	}
+	opCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	vals, err := f.cache.MGetBytes(opCtx, keys)
+	cancel()
+	if err != nil {
+		return f.repo.GetByIDs(ctx, ids)
+	}
+
+	videos := make([]*video.Video, 0, len(ids))
+	misses := make([]uint, 0)
+	for i, b := range vals {
+		if b == nil {
+			misses = append(misses, ids[i])
+			continue
+		}
+		v, err := video.DecodeDetailCache(b)
+		if errors.Is(err, video.ErrVideoNotFound) {
+			continue
+		}
+		if err != nil {
+			// 缓存数据损坏：从数据库查询
+			misses = append(misses, ids[i])
+			continue
+		}
+		if v.IsPublic() {
+			videos = append(videos, v)
+		}
+	}
+
+	if len(misses) > 0 {
+		fetched, err := f.repo.GetByIDs(ctx, misses)
+		if err != nil {
+			return nil, err
+		}
+		videos = append(videos, fetched...)
+	}
+	return videos, nil
+}
+
 // ============================================================================
 // ============ 辅助方法：构建 FeedVideoItem ============
 // ============================================================================
 
-// buildFeedVideos 批量查询点赞状态并构建 FeedVideoItem
+// buildFeedVideos 批量查询点赞状态、关注状态并构建 FeedVideoItem
 //
 // 业务流程：
-//   1. 提取所有视频 ID
-//   2. 批量查询点赞状态（一次性查询，避免 N+1 问题）
+//   1. 提取所有视频 ID 和作者 ID
+//   2. 批量查询点赞状态、是否已关注作者（各一次查询，避免 N+1 问题）
 //   3. 遍历视频列表，构建 FeedVideoItem
 //
 // N+1 问题说明：
@@ -642,10 +820,12 @@ func (f *FeedService) buildFeedVideos(ctx context.Context, videos []*video.Video
 	// 1. 预分配内存（提升性能）
 	feedVideos := make([]FeedVideoItem, 0, len(videos))
 
-	// 2. 提取所有视频 ID
+	// 2. 提取所有视频 ID 和作者 ID
 	videoIDs := make([]uint, len(videos))
+	authorIDs := make([]uint, 0, len(videos))
 	for i, v := range videos {
 		videoIDs[i] = v.ID
+		authorIDs = append(authorIDs, v.AuthorID)
 	}
 
 	// 3. 批量查询点赞状态（避免 N+1 问题）
@@ -655,6 +835,12 @@ func (f *FeedService) buildFeedVideos(ctx context.Context, videos []*video.Video
 		return nil, err
 	}
 
+	// 批量查询是否已关注作者（匿名用户返回空 map）
+	followingMap, err := f.socialRepo.BatchIsFollowed(ctx, viewerAccountID, authorIDs)
+	if err != nil {
+		return nil, err
+	}
+
 	// 4. 遍历视频列表，构建 FeedVideoItem
 	for _, video := range videos {
 		feedVideos = append(feedVideos, FeedVideoItem{
@@ -665,10 +851,37 @@ func (f *FeedService) buildFeedVideos(ctx context.Context, videos []*video.Video
 			PlayURL:     video.PlayURL,
 			CoverURL:    video.CoverURL,
 			CreateTime:  video.CreateTime.Unix(),
+			UpdatedAt:   unixOrZero(video.UpdatedAt),
 			LikesCount:  video.LikesCount,
 			IsLiked:     likedMap[video.ID], // 从批量查询结果中获取点赞状态
+			IsFollowing: followingMap[video.AuthorID], // 从批量查询结果中获取关注状态
 		})
 	}
 
 	return feedVideos, nil
 }
+
+// matches 判断视频是否满足互动量下限
+func (e EngagementFilter) matches(v *video.Video) bool {
+	return v.LikesCount >= e.MinLikes && v.Popularity >= e.MinPopularity
+}
+
+// timeCursorKey 游标时间在缓存键中的表示：秒级游标为秒数，毫秒游标追加 ms 后缀（两种游标的过滤条件不同，不能共用缓存）
+func timeCursorKey(cursor TimeCursor) string {
+	switch {
+	case cursor.Time.IsZero():
+		return "0"
+	case cursor.Exact:
+		return strconv.FormatInt(cursor.Time.UnixMilli(), 10) + "ms"
+	default:
+		return strconv.FormatInt(cursor.Time.Unix(), 10)
+	}
+}
+
+// unixOrZero 转换为 Unix 时间戳（零值返回 0，如迁移前没有 updated_at 的旧数据）
+func unixOrZero(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
@@ -0,0 +1,111 @@
+package feed
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"feedsystem_video_go/internal/config"
+	rediscache "feedsystem_video_go/internal/middleware/redis"
+	"feedsystem_video_go/internal/social"
+	"feedsystem_video_go/internal/video"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+func newTestCache(t *testing.T) (*rediscache.Client, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	port, err := strconv.Atoi(mr.Port())
+	if err != nil {
+		t.Fatalf("miniredis port: %v", err)
+	}
+	cache, err := rediscache.NewFromEnv(&config.RedisConfig{Host: mr.Host(), Port: port})
+	if err != nil {
+		t.Fatalf("redis client: %v", err)
+	}
+	t.Cleanup(func() { _ = cache.Close() })
+	return cache, mr
+}
+
+// 热榜翻页：相邻两页间隔小于快照过期时间时，每次访问都会续期，整个翻页过程读取同一份快照；
+// 间隔超过过期时间后快照按同一个 as_of 从分钟窗口重新聚合，as_of 之前的窗口不变，翻页结果仍然不重复、不遗漏
+func TestListByPopularitySlowPaginationAcrossSnapshotExpiry(t *testing.T) {
+	db := newTestDB(t)
+	cache, mr := newTestCache(t)
+	f := NewFeedService(NewFeedRepository(db), video.NewLikeRepository(db), social.NewSocialRepository(db), cache, nil)
+
+	// 6 个视频，热度分布在 as_of 所在分钟和前一分钟的窗口中
+	asOf := time.Now().UTC().Truncate(time.Minute).Add(-10 * time.Minute)
+	windows := []string{
+		"hot:video:1m:" + asOf.Format("200601021504"),
+		"hot:video:1m:" + asOf.Add(-time.Minute).Format("200601021504"),
+	}
+	scores := map[uint]float64{1: 10, 2: 60, 3: 30, 4: 50, 5: 20, 6: 40}
+	for id, score := range scores {
+		if err := db.Create(&video.Video{ID: id, AuthorID: 1, Title: fmt.Sprintf("v%d", id), Visibility: video.VisibilityPublic}).Error; err != nil {
+			t.Fatalf("insert video %d: %v", id, err)
+		}
+		member := strconv.FormatUint(uint64(id), 10)
+		if _, err := mr.ZAdd(windows[0], score/2, member); err != nil {
+			t.Fatalf("seed window: %v", err)
+		}
+		if _, err := mr.ZAdd(windows[1], score/2, member); err != nil {
+			t.Fatalf("seed window: %v", err)
+		}
+	}
+	want := []uint{2, 4, 6, 3, 5, 1}
+	snapshot := "hot:video:merge:1m:" + asOf.Format("200601021504")
+
+	var got []uint
+	offset := 0
+	page := func() ListByPopularityResponse {
+		t.Helper()
+		resp, err := f.ListByPopularity(context.Background(), 2, asOf.Unix(), offset, 0, 0, time.Time{}, 0, EngagementFilter{})
+		if err != nil {
+			t.Fatalf("ListByPopularity offset=%d: %v", offset, err)
+		}
+		if resp.Algo != AlgoPopularityZSet || resp.AsOf != asOf.Unix() {
+			t.Fatalf("algo=%s as_of=%d, want %s as_of=%d", resp.Algo, resp.AsOf, AlgoPopularityZSet, asOf.Unix())
+		}
+		for _, item := range resp.VideoList {
+			got = append(got, item.ID)
+		}
+		offset = resp.NextOffset
+		return resp
+	}
+
+	// 第 1、2 页：间隔 90 秒（小于快照过期时间），快照续期后仍是同一份
+	page()
+	mr.FastForward(90 * time.Second)
+	// 快照建立后修改分钟窗口：如果快照被重新聚合，顺序会变化
+	if _, err := mr.ZAdd(windows[0], scores[1]/2+1000, "1"); err != nil {
+		t.Fatalf("bump window: %v", err)
+	}
+	page()
+	mr.FastForward(90 * time.Second)
+	if !mr.Exists(snapshot) {
+		t.Fatalf("snapshot %s expired although each page refreshed its TTL", snapshot)
+	}
+	if _, err := mr.ZAdd(windows[0], scores[1]/2, "1"); err != nil {
+		t.Fatalf("restore window: %v", err)
+	}
+
+	// 第 3 页：间隔超过快照过期时间，按同一个 as_of 重新聚合
+	mr.FastForward(3 * time.Minute)
+	if mr.Exists(snapshot) {
+		t.Fatalf("snapshot %s still exists after its TTL", snapshot)
+	}
+	if resp := page(); !resp.HasMore {
+		t.Fatalf("has_more = false on a full page")
+	}
+	if resp := page(); resp.HasMore || len(resp.VideoList) != 0 {
+		t.Fatalf("page past the end = %+v, want empty page without has_more", resp)
+	}
+
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("paged ids = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,91 @@
+package feed
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+)
+
+// feedRefreshTimeout 后台刷新（stale-while-revalidate）查询数据库并回填缓存的超时时间
+const feedRefreshTimeout = 3 * time.Second
+
+// cachedFeedPage 缓存中的 Feed 页（最新视频流、关注流）
+// 软过期时间之前按正常命中返回；软过期之后、Redis 实际过期之前（feed.stale_window）
+// 仍返回旧数据，同时在后台刷新，避免每个缓存周期都有一个请求承担完整的数据库查询耗时
+type cachedFeedPage struct {
+	SoftExpireMs int64           `json:"soft_expire_ms"` // 软过期时间（毫秒时间戳）
+	Page         json.RawMessage `json:"page"`           // 响应对象
+}
+
+// errFeedPageFormat 缓存数据不是 cachedFeedPage 格式（如升级前写入的旧格式），按缓存损坏处理
+var errFeedPageFormat = errors.New("feed cache: unexpected page format")
+
+// staleWindow 当前的 stale 窗口（0 表示软过期即失效，不返回旧数据）
+func (f *FeedService) staleWindow() time.Duration {
+	return f.live.Tunables().FeedStaleWindow
+}
+
+// decodeFeedPage 解析缓存中的 Feed 页
+// 返回：
+//
+//	fresh - 是否仍在软过期时间之前（false 表示应在后台刷新）
+//	error - 数据损坏或格式不符
+func decodeFeedPage(b []byte, out any) (bool, error) {
+	var page cachedFeedPage
+	if err := json.Unmarshal(b, &page); err != nil {
+		return false, err
+	}
+	if page.SoftExpireMs == 0 || len(page.Page) == 0 {
+		return false, errFeedPageFormat
+	}
+	if err := json.Unmarshal(page.Page, out); err != nil {
+		return false, err
+	}
+	return time.Now().UnixMilli() < page.SoftExpireMs, nil
+}
+
+// storeFeedPage 写入 Feed 页缓存：ttl 后软过期，再过 stale 后由 Redis 删除
+// 写入失败只影响缓存命中率，不返回错误
+func (f *FeedService) storeFeedPage(ctx context.Context, cacheKey string, resp any, ttl, stale time.Duration) {
+	raw, err := json.Marshal(resp)
+	if err != nil {
+		return
+	}
+	b, err := json.Marshal(cachedFeedPage{SoftExpireMs: time.Now().Add(ttl).UnixMilli(), Page: raw})
+	if err != nil {
+		return
+	}
+	setCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	_ = f.cache.SetBytes(setCtx, cacheKey, b, ttl+stale)
+}
+
+// revalidate 在后台刷新已软过期的 Feed 页
+// 与未命中时的击穿保护共用同一把分布式锁：只有拿到锁的请求发起刷新，其余请求直接返回旧数据，数据库查询次数不增加
+// 刷新在独立的 goroutine 中执行（不受请求结束影响），完成或失败后释放锁
+func (f *FeedService) revalidate(ctx context.Context, cacheKey string, ttl, stale time.Duration, load func(ctx context.Context) (any, error)) {
+	lockKey := "lock:" + cacheKey
+	lockCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	token, locked, _ := f.cache.Lock(lockCtx, lockKey, f.cache.LockPolicy().TTL)
+	cancel()
+	if !locked {
+		return
+	}
+
+	// 保留追踪ID等上下文值，但不随请求取消
+	bgCtx := context.WithoutCancel(ctx)
+	go func() {
+		defer func() { _ = f.cache.Unlock(context.Background(), lockKey, token) }()
+
+		loadCtx, cancel := context.WithTimeout(bgCtx, feedRefreshTimeout)
+		defer cancel()
+		resp, err := load(loadCtx)
+		if err != nil {
+			log.Printf("feed cache: background refresh failed key=%s: %v", cacheKey, err)
+			return
+		}
+		f.storeFeedPage(bgCtx, cacheKey, resp, ttl, stale)
+	}()
+}
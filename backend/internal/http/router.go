@@ -3,11 +3,21 @@
 package http
 
 import (
+	"context"
 	"feedsystem_video_go/internal/account"
+	"feedsystem_video_go/internal/config"
 	"feedsystem_video_go/internal/feed"
+	"feedsystem_video_go/internal/invalidation"
+	"feedsystem_video_go/internal/metrics"
+	"feedsystem_video_go/internal/middleware/bodylimit"
+	"feedsystem_video_go/internal/middleware/compress"
 	"feedsystem_video_go/internal/middleware/jwt"
 	"feedsystem_video_go/internal/middleware/rabbitmq"
+	"feedsystem_video_go/internal/middleware/recovery"
+	"feedsystem_video_go/internal/middleware/trace"
 	rediscache "feedsystem_video_go/internal/middleware/redis"
+	"feedsystem_video_go/internal/notification"
+	"feedsystem_video_go/internal/report"
 	"feedsystem_video_go/internal/social"
 	"feedsystem_video_go/internal/video"
 	"log"
@@ -16,6 +26,9 @@ import (
 	"gorm.io/gorm"
 )
 
+// multipartOverhead 上传接口在文件上限之外允许的 multipart 额外开销（边界、表单字段等）
+const multipartOverhead int64 = 1 << 20
+
 // SetRouter 设置所有 HTTP 路由，并初始化依赖注入
 //
 // 依赖注入流程（以点赞模块为例）：
@@ -30,18 +43,68 @@ import (
 //   db    - GORM 数据库连接
 //   cache - Redis 缓存客户端（可能为 nil）
 //   rmq   - RabbitMQ 基础连接（可能为 nil）
+//   live  - 运行时配置（启动时的完整配置 + 支持 SIGHUP 热更新的参数）
+//   commentFilter - 评论敏感词过滤器（可能为 nil，表示不过滤）
+//   countsHub - 视频实时计数推送中心（可能为 nil，表示 Redis 不可用，不注册 WebSocket 路由）
 //
 // 返回：
 //   *gin.Engine - Gin 路由引擎
-func SetRouter(db *gorm.DB, cache *rediscache.Client, rmq *rabbitmq.RabbitMQ) *gin.Engine {
-	r := gin.Default()
+func SetRouter(db *gorm.DB, cache *rediscache.Client, rmq *rabbitmq.RabbitMQ, live *config.Live, commentFilter *video.CommentFilter, countsHub *video.CountsHub) *gin.Engine {
+	cfg := live.Config()
+	// 不使用 gin.Default()：它自带的 Recovery 返回空的 500，这里换成返回统一 JSON 错误响应的版本
+	r := gin.New()
+	r.Use(gin.Logger())
+	r.Use(trace.Middleware())    // 为每个请求生成追踪ID（X-Request-ID），随 MQ 消息传递给 Worker
+	r.Use(recovery.Middleware()) // 捕获 panic，记录调用栈和追踪ID，返回 500 统一错误响应
+	// 限制请求体大小：JSON 接口 1MB，上传接口按文件上限放宽（额外 1MB 留给 multipart 边界和其他字段），超过返回 413
+	// multipart 解析时超过 MaxMultipartMemory 的部分写入临时文件，避免大文件全部读入内存
+	r.MaxMultipartMemory = 8 << 20
+	r.Use(bodylimit.Middleware(bodylimit.DefaultJSONLimit, map[string]int64{
+		"/video/uploadVideo": video.MaxVideoUploadSize + multipartOverhead,
+		"/video/uploadCover": video.MaxCoverUploadSize + multipartOverhead,
+	}))
+	// Feed/列表接口的响应压缩（由反向代理负责压缩时在配置中关闭）
+	if cfg.Server.Gzip.Enabled {
+		r.Use(compress.Gzip(cfg.Server.Gzip.MinSize, []string{
+			"/feed/listLatest",
+			"/feed/listLikesCount",
+			"/feed/listByPopularity",
+			"/feed/listByFollowing",
+			"/feed/home",
+			"/video/listByAuthorID",
+			"/video/getDetails",
+			"/comment/listAll",
+			"/comment/list",
+			"/comment/listByAuthor",
+			"/notification/list",
+			"/social/getAllFollowers",
+			"/social/getAllVloggers",
+		}))
+	}
 
-	// 静态文件服务：提供上传的图片和视频访问
+	// 静态文件服务：提供上传的图片和视频访问（目录和路径前缀见配置 storage）
 	// 访问路径：http://localhost:8080/static/xxx.jpg
-	r.Static("/static", "./.run/uploads")
+	// 支持 Range 请求（206 Partial Content），播放器拖动进度条时不需要下载整个视频
+	uploads := video.ServeUploads(cfg.Storage.UploadRoot())
+	r.GET(cfg.Storage.URLPrefix()+"/*filepath", uploads)
+	r.HEAD(cfg.Storage.URLPrefix()+"/*filepath", uploads)
+	// 指标接口：Prometheus 文本格式（缓存命中率等），供 Prometheus 抓取，生产环境应只对内网开放
+	r.GET("/metrics", metrics.Handler())
 	// account
+	// 初始化账户 MQ（用于改名后异步同步视频/评论中的冗余用户名）
+	accountMQ, err := rabbitmq.NewAccountMQ(rmq)
+	if err != nil {
+		log.Printf("AccountMQ init failed (mq disabled): %v", err)
+		accountMQ = nil
+	}
+	// 初始化账户生命周期 MQ（用于注册后异步执行引导操作，如欢迎通知）
+	accountLifecycleMQ, err := rabbitmq.NewAccountLifecycleMQ(rmq)
+	if err != nil {
+		log.Printf("AccountLifecycleMQ init failed (mq disabled): %v", err)
+		accountLifecycleMQ = nil
+	}
 	accountRepository := account.NewAccountRepository(db)
-	accountService := account.NewAccountService(accountRepository, cache)
+	accountService := account.NewAccountService(accountRepository, cache, accountMQ, accountLifecycleMQ, cfg.Auth.DevLogEmailTokens)
 	accountHandler := account.NewAccountHandler(accountService)
 	accountGroup := r.Group("/account")
 	{
@@ -49,13 +112,32 @@ func SetRouter(db *gorm.DB, cache *rediscache.Client, rmq *rabbitmq.RabbitMQ) *g
 		accountGroup.POST("/login", accountHandler.Login)
 		accountGroup.POST("/changePassword", accountHandler.ChangePassword)
 		accountGroup.POST("/findByID", accountHandler.FindByID)
+		accountGroup.POST("/findByIDs", accountHandler.FindByIDs)
 		accountGroup.POST("/findByUsername", accountHandler.FindByUsername)
+		accountGroup.POST("/verifyEmail", accountHandler.VerifyEmail) // token 即凭证，不需要登录
 	}
 	protectedAccountGroup := accountGroup.Group("")
 	protectedAccountGroup.Use(jwt.JWTAuth(accountRepository, cache))
 	{
 		protectedAccountGroup.POST("/logout", accountHandler.Logout)
 		protectedAccountGroup.POST("/rename", accountHandler.Rename)
+		protectedAccountGroup.POST("/me", accountHandler.Me) // 当前登录用户信息（含最近登录时间）
+		protectedAccountGroup.POST("/setEmail", accountHandler.SetEmail)             // 需要携带 /verifyPassword 返回的 reauth_token
+		protectedAccountGroup.POST("/verifyPassword", accountHandler.VerifyPassword) // 敏感操作前的二次验证
+	}
+	// ========== 通知模块 ==========
+	// 通知由 Worker 写入点赞/评论/关注后创建；MQ 不可用时由各服务的 Fallback 创建
+	notificationRepository := notification.NewNotificationRepository(db)
+	notificationService := notification.NewNotificationService(notificationRepository, cache)
+	notificationHandler := notification.NewNotificationHandler(notificationService)
+
+	// 设置通知路由（全部需要登录）
+	notificationGroup := r.Group("/notification")
+	notificationGroup.Use(jwt.JWTAuth(accountRepository, cache))
+	{
+		notificationGroup.POST("/list", notificationHandler.List)               // 分页查询通知
+		notificationGroup.POST("/unreadCount", notificationHandler.UnreadCount) // 查询未读数
+		notificationGroup.POST("/markRead", notificationHandler.MarkRead)       // 标记已读
 	}
 	// ========== 视频模块 ==========
 	// 初始化视频仓储
@@ -67,21 +149,29 @@ func SetRouter(db *gorm.DB, cache *rediscache.Client, rmq *rabbitmq.RabbitMQ) *g
 	//   2. 声明 Queue("video.popularity.events")
 	//   3. 绑定：Routing Key "video.popularity.*" → Queue
 	// 如果 RabbitMQ 不可用，popularityMQ 会被设为 nil
-	popularityMQ, err := rabbitmq.NewPopularityMQ(rmq)
-	if err != nil {
+	var popularityMQ *rabbitmq.PopularityBatcher
+	if mq, err := rabbitmq.NewPopularityMQ(rmq); err != nil {
 		log.Printf("PopularityMQ init failed (mq disabled): %v", err)
-		popularityMQ = nil
+	} else {
+		// 热度变化按窗口合并后用一条批量消息发送（热门视频集中互动时减少消息数），发送失败时直接写入 Redis 热榜
+		// API 进程没有优雅退出流程，发送循环随进程结束；热度消息只影响 Redis 热榜，最多丢失一个窗口的增量
+		popularityMQ = rabbitmq.NewPopularityBatcher(mq, rabbitmq.DefaultPopularityBatchInterval, func(ctx context.Context, changes map[uint]int64) {
+			video.UpdatePopularityCacheBatch(ctx, cache, changes)
+		})
+		go func() { _ = popularityMQ.Run(context.Background()) }()
 	}
 
 	// 初始化视频服务（注入 cache 和 popularityMQ）
-	videoService := video.NewVideoService(videoRepository, cache, popularityMQ)
+	videoService := video.NewVideoService(videoRepository, cache, popularityMQ, cfg.Video, cfg.Storage)
 	videoHandler := video.NewVideoHandler(videoService, accountService)
 
 	// 设置视频路由
 	videoGroup := r.Group("/video")
+	videoGroup.Use(jwt.SoftJWTAuth(accountRepository, cache)) // 可选登录：作者本人可以查看非公开视频
 	{
 		videoGroup.POST("/listByAuthorID", videoHandler.ListByAuthorID)
 		videoGroup.POST("/getDetail", videoHandler.GetDetail)
+		videoGroup.POST("/getDetails", videoHandler.GetDetails) // 批量获取视频详情（最多 50 个）
 	}
 	protectedVideoGroup := videoGroup.Group("")
 	protectedVideoGroup.Use(jwt.JWTAuth(accountRepository, cache))
@@ -89,7 +179,34 @@ func SetRouter(db *gorm.DB, cache *rediscache.Client, rmq *rabbitmq.RabbitMQ) *g
 		protectedVideoGroup.POST("/uploadVideo", videoHandler.UploadVideo)
 		protectedVideoGroup.POST("/uploadCover", videoHandler.UploadCover)
 		protectedVideoGroup.POST("/publish", videoHandler.PublishVideo)
+		protectedVideoGroup.POST("/delete", videoHandler.DeleteVideo) // 删除视频（软删除）
+		protectedVideoGroup.POST("/setVisibility", videoHandler.SetVisibility) // 修改可见性（public/private/draft）
+	}
+	adminVideoGroup := videoGroup.Group("")
+	adminVideoGroup.Use(jwt.JWTAuth(accountRepository, cache), jwt.AdminAuth(accountRepository))
+	{
+		adminVideoGroup.POST("/restore", videoHandler.RestoreVideo) // 恢复已删除视频（管理员）
+	}
+
+	// 视频实时计数（WebSocket，仅 Redis 可用时启用）
+	if countsHub != nil {
+		countsHandler := video.NewCountsHandler(videoService, countsHub)
+		wsGroup := r.Group("/ws")
+		wsGroup.Use(jwt.SoftJWTAuth(accountRepository, cache)) // 可选登录：作者本人可以订阅非公开视频
+		{
+			wsGroup.GET("/video/:id", countsHandler.Subscribe)
+		}
+	}
+
+	// ========== 管理后台（内容审核） ==========
+	// 全部接口需要登录且账户为管理员（accounts.is_admin = true）
+	adminGroup := r.Group("/admin")
+	adminGroup.Use(jwt.JWTAuth(accountRepository, cache), jwt.AdminAuth(accountRepository))
+	{
+		adminGroup.POST("/video/delete", videoHandler.AdminDeleteVideo) // 删除任意视频
 	}
+	purgeHandler := invalidation.NewPurgeHandler(cache)
+	adminGroup.POST("/cache/purge", purgeHandler.PurgeCaches) // 清空应用缓存（保留登录 token 和热榜窗口）
 
 	// ========== 点赞模块 ==========
 	// 初始化点赞 MQ（用于异步处理点赞/取消点赞事件）
@@ -108,7 +225,7 @@ func SetRouter(db *gorm.DB, cache *rediscache.Client, rmq *rabbitmq.RabbitMQ) *g
 
 	// 初始化点赞服务（注入 repo、cache、likeMQ、popularityMQ）
 	// 注意：likeMQ 用于异步处理点赞记录，popularityMQ 用于异步更新热度
-	likeService := video.NewLikeService(likeRepository, videoRepository, cache, likeMQ, popularityMQ)
+	likeService := video.NewLikeService(likeRepository, videoRepository, cache, likeMQ, popularityMQ, notificationService)
 	likeHandler := video.NewLikeHandler(likeService)
 
 	// 设置点赞路由（全部需要登录）
@@ -119,16 +236,18 @@ func SetRouter(db *gorm.DB, cache *rediscache.Client, rmq *rabbitmq.RabbitMQ) *g
 		protectedLikeGroup.POST("/like", likeHandler.Like)                // 点赞
 		protectedLikeGroup.POST("/unlike", likeHandler.Unlike)            // 取消点赞
 		protectedLikeGroup.POST("/isLiked", likeHandler.IsLiked)          // 查询是否点赞
+		protectedLikeGroup.POST("/batchStatus", likeHandler.BatchStatus)  // 批量查询点赞状态
 		protectedLikeGroup.POST("/listMyLikedVideos", likeHandler.ListMyLikedVideos) // 查询点赞列表
 	}
 
 	// ========== 评论模块 ==========
 	// 初始化评论仓储
 	commentRepository := video.NewCommentRepository(db)
+	commentLikeRepository := video.NewCommentLikeRepository(db)
 
 	// 初始化评论 MQ（用于异步处理发布/删除评论事件）
 	// NewCommentMQ 内部会：
-	//   1. 声明 Exchange("comment.events")
+	//   1. 声明 Exchange("comment.events")（发布/删除/点赞评论共用）
 	//   2. 声明 Queue("comment.events")
 	//   3. 绑定：Routing Key "comment.*" → Queue
 	commentMQ, err := rabbitmq.NewCommentMQ(rmq)
@@ -138,21 +257,46 @@ func SetRouter(db *gorm.DB, cache *rediscache.Client, rmq *rabbitmq.RabbitMQ) *g
 	}
 
 	// 初始化评论服务（注入 repo、cache、commentMQ、popularityMQ）
-	commentService := video.NewCommentService(commentRepository, videoRepository, cache, commentMQ, popularityMQ)
+	commentService := video.NewCommentService(commentRepository, commentLikeRepository, videoRepository, cache, commentMQ, popularityMQ, commentFilter, cfg.Comment.MaxLength, cfg.Comment.RateInterval, cfg.Comment.MaxPerVideo, notificationService)
 	commentHandler := video.NewCommentHandler(commentService, accountService)
 
 	// 设置评论路由
 	commentGroup := r.Group("/comment")
+	commentGroup.Use(jwt.SoftJWTAuth(accountRepository, cache)) // 可选登录：登录后返回点赞状态
 	{
 		commentGroup.POST("/listAll", commentHandler.GetAllComments) // 公开接口：查询评论
+		commentGroup.POST("/list", commentHandler.ListComments)      // 公开接口：分页查询评论
+		commentGroup.POST("/listByAuthor", commentHandler.ListAuthorComments) // 公开接口：分页查询用户的评论历史
 	}
 	protectedCommentGroup := commentGroup.Group("")
 	protectedCommentGroup.Use(jwt.JWTAuth(accountRepository, cache))
 	{
 		protectedCommentGroup.POST("/publish", commentHandler.PublishComment) // 发布评论（需要登录）
 		protectedCommentGroup.POST("/delete", commentHandler.DeleteComment)   // 删除评论（需要登录）
+		protectedCommentGroup.POST("/restore", commentHandler.RestoreComment) // 撤销删除评论（仅作者，删除后短时间内）
+		protectedCommentGroup.POST("/like", commentHandler.LikeComment)       // 点赞评论（需要登录）
+		protectedCommentGroup.POST("/unlike", commentHandler.UnlikeComment)   // 取消点赞评论（需要登录）
 	}
 
+	// ========== 举报模块 ==========
+	reportRepository := report.NewReportRepository(db)
+	reportService := report.NewReportService(reportRepository, videoRepository, commentRepository, cache, live)
+	reportHandler := report.NewReportHandler(reportService)
+
+	// 设置举报路由（需要登录）
+	reportGroup := r.Group("/report")
+	reportGroup.Use(jwt.JWTAuth(accountRepository, cache))
+	{
+		reportGroup.POST("", reportHandler.CreateReport) // 举报视频/评论
+	}
+	adminGroup.POST("/reports/list", reportHandler.ListReports) // 查询举报列表（管理员）
+
+	// 视频互动统计（管理员刷量检测、个人主页作者统计，只读）
+	statsService := video.NewStatsService(videoRepository, likeRepository, commentRepository, cache)
+	statsHandler := video.NewStatsHandler(statsService)
+	adminGroup.POST("/video/stats", statsHandler.AdminStats) // 查询视频互动统计（管理员）
+	videoGroup.POST("/authorStats", statsHandler.AuthorStats) // 查询作者公开视频数和点赞总数（公开）
+
 	// ========== 关注模块 ==========
 	// 初始化关注 MQ（用于异步处理关注/取关事件）
 	// NewSocialMQ 内部会：
@@ -167,7 +311,7 @@ func SetRouter(db *gorm.DB, cache *rediscache.Client, rmq *rabbitmq.RabbitMQ) *g
 
 	// 初始化关注仓储和服务
 	socialRepository := social.NewSocialRepository(db)
-	socialService := social.NewSocialService(socialRepository, accountRepository, socialMQ)
+	socialService := social.NewSocialService(socialRepository, accountRepository, videoRepository, cache, socialMQ, notificationService)
 	socialHandler := social.NewSocialHandler(socialService)
 
 	// 设置关注路由（全部需要登录）
@@ -182,7 +326,7 @@ func SetRouter(db *gorm.DB, cache *rediscache.Client, rmq *rabbitmq.RabbitMQ) *g
 	}
 	// feed
 	feedRepository := feed.NewFeedRepository(db)
-	feedService := feed.NewFeedService(feedRepository, likeRepository, cache)
+	feedService := feed.NewFeedService(feedRepository, likeRepository, socialRepository, cache, live)
 	feedHandler := feed.NewFeedHandler(feedService)
 	feedGroup := r.Group("/feed")
 	feedGroup.Use(jwt.SoftJWTAuth(accountRepository, cache))
@@ -190,6 +334,7 @@ func SetRouter(db *gorm.DB, cache *rediscache.Client, rmq *rabbitmq.RabbitMQ) *g
 		feedGroup.POST("/listLatest", feedHandler.ListLatest)
 		feedGroup.POST("/listLikesCount", feedHandler.ListLikesCount)
 		feedGroup.POST("/listByPopularity", feedHandler.ListByPopularity)
+		feedGroup.POST("/home", feedHandler.Home) // 首页：关注流 + 热榜补足（未登录返回最新视频）
 	}
 	protectedFeedGroup := feedGroup.Group("")
 	protectedFeedGroup.Use(jwt.JWTAuth(accountRepository, cache))
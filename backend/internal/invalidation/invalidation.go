@@ -0,0 +1,169 @@
+// Package invalidation 跨实例缓存失效广播
+// 内容变更（发布/删除视频、修改可见性、关注/取关等）后发布失效事件到 Redis 频道，
+// 每个 API 实例订阅该频道，按模式删除匹配的 Redis 缓存，并通知注册的进程内缓存
+package invalidation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	rediscache "feedsystem_video_go/internal/middleware/redis"
+)
+
+// Channel 缓存失效事件频道
+const Channel = "cache:invalidate"
+
+// Feed 流缓存 key 模式（与 feed.FeedService 中的缓存 key 格式对应）
+const (
+	FeedLatestPattern    = "feed:listLatest:*"      // 最新视频流（含按作者过滤）
+	FeedFollowingPattern = "feed:listByFollowing:*" // 所有用户的关注流
+)
+
+// allowedPrefixes 允许按模式删除的 key 前缀，防止错误的事件删除其他数据（如幂等键、热榜窗口）
+var allowedPrefixes = []string{"feed:"}
+
+// delTimeout 单个模式删除的超时时间
+const delTimeout = 2 * time.Second
+
+// 关注流缓存版本号：每个用户一个计数器，作为关注流缓存 key 的一部分（见 feed.FeedService.ListByFollowing）
+// 关注的作者发布/删除视频后递增其粉丝的版本号，旧版本的缓存不再被读取、随 TTL 自然过期；
+// 与按模式删除相比，只影响该作者的粉丝，且不需要 SCAN
+const (
+	followingVersionTTL = 24 * time.Hour // 版本号过期时间（远大于关注流缓存 TTL，过期重置为 0 时旧版本缓存早已过期）
+	bumpTimeout         = 200 * time.Millisecond
+)
+
+// FollowingVersionKey 指定用户的关注流缓存版本号 key
+func FollowingVersionKey(accountID uint) string {
+	return fmt.Sprintf("feed:following:ver:accountID=%d", accountID)
+}
+
+// BumpFollowingFeeds 递增指定用户的关注流缓存版本号，使他们的关注流缓存立即失效
+// Redis 不可用时不做任何操作；失败只记录日志（关注流缓存 TTL 很短，最终会自然过期）
+func BumpFollowingFeeds(ctx context.Context, cache *rediscache.Client, reason string, accountIDs []uint) {
+	if cache == nil || len(accountIDs) == 0 {
+		return
+	}
+	keys := make([]string, 0, len(accountIDs))
+	for _, id := range accountIDs {
+		keys = append(keys, FollowingVersionKey(id))
+	}
+	opCtx, cancel := context.WithTimeout(ctx, bumpTimeout)
+	defer cancel()
+	if err := cache.IncrMany(opCtx, keys, followingVersionTTL); err != nil {
+		log.Printf("invalidation: failed to bump following feeds (%s): %v", reason, err)
+	}
+}
+
+// Event 缓存失效事件
+type Event struct {
+	Patterns []string `json:"patterns"`         // 要删除的 key 模式
+	Reason   string   `json:"reason,omitempty"` // 失效原因（仅用于日志）
+}
+
+// Publish 发布缓存失效事件
+// Redis 不可用时不做任何操作；发布失败只记录日志（Feed 缓存 TTL 很短，最终会自然过期）
+func Publish(ctx context.Context, cache *rediscache.Client, reason string, patterns ...string) {
+	if cache == nil || len(patterns) == 0 {
+		return
+	}
+	b, err := json.Marshal(Event{Patterns: patterns, Reason: reason})
+	if err != nil {
+		return
+	}
+	opCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	if err := cache.Publish(opCtx, Channel, b); err != nil {
+		log.Printf("invalidation: failed to publish %s: %v", reason, err)
+	}
+}
+
+// Listener 缓存失效事件监听器（每个 API 实例一个）
+type Listener struct {
+	cache *rediscache.Client // Redis 缓存客户端（订阅频道、删除缓存）
+
+	mu       sync.RWMutex
+	handlers []func(Event) // 进程内缓存的失效回调
+}
+
+// NewListener 创建缓存失效事件监听器
+// cache 为 nil（Redis 不可用）时返回 nil
+func NewListener(cache *rediscache.Client) *Listener {
+	if cache == nil {
+		return nil
+	}
+	return &Listener{cache: cache}
+}
+
+// OnInvalidate 注册进程内缓存的失效回调（收到事件并删除 Redis 缓存后调用）
+func (l *Listener) OnInvalidate(fn func(Event)) {
+	if l == nil || fn == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.handlers = append(l.handlers, fn)
+}
+
+// Run 订阅失效事件并处理（阻塞，直到 ctx 被取消）
+func (l *Listener) Run(ctx context.Context) error {
+	if l == nil || l.cache == nil {
+		return errors.New("invalidation listener is not initialized")
+	}
+	msgs, cancel := l.cache.Subscribe(ctx, Channel)
+	defer cancel()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case msg, ok := <-msgs:
+			if !ok {
+				return errors.New("invalidation: subscription closed")
+			}
+			var evt Event
+			if err := json.Unmarshal(msg.Payload, &evt); err != nil {
+				// 格式错误的事件直接丢弃
+				continue
+			}
+			l.handle(ctx, evt)
+		}
+	}
+}
+
+// handle 按模式删除 Redis 缓存，并调用进程内缓存的失效回调
+func (l *Listener) handle(ctx context.Context, evt Event) {
+	for _, pattern := range evt.Patterns {
+		if !allowed(pattern) {
+			log.Printf("invalidation: ignore pattern %q (reason=%s)", pattern, evt.Reason)
+			continue
+		}
+		opCtx, cancel := context.WithTimeout(ctx, delTimeout)
+		if _, err := l.cache.DelByPattern(opCtx, pattern); err != nil {
+			log.Printf("invalidation: failed to delete %q (reason=%s): %v", pattern, evt.Reason, err)
+		}
+		cancel()
+	}
+
+	l.mu.RLock()
+	handlers := l.handlers
+	l.mu.RUnlock()
+	for _, fn := range handlers {
+		fn(evt)
+	}
+}
+
+// allowed 判断模式是否在允许删除的前缀范围内
+func allowed(pattern string) bool {
+	for _, prefix := range allowedPrefixes {
+		if strings.HasPrefix(pattern, prefix) {
+			return true
+		}
+	}
+	return false
+}
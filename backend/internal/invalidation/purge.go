@@ -0,0 +1,73 @@
+package invalidation
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"feedsystem_video_go/internal/apierror"
+	rediscache "feedsystem_video_go/internal/middleware/redis"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PurgePatterns 可以安全清空的缓存 key 模式
+// 只包含可以从数据库重建的缓存；登录 token（account:{id}）、热榜窗口（hot:*）、分布式锁（lock:*）、幂等键等不在其中
+var PurgePatterns = []string{
+	"feed:*",            // Feed 流缓存
+	"video:detail:*",    // 视频详情缓存
+	"account:profile:*", // 账户公开资料缓存
+}
+
+// purgeTimeout 单个模式清空的超时时间（比广播失效宽松：人工操作，需要尽量删干净）
+const purgeTimeout = 10 * time.Second
+
+// PurgeResult 单个模式的清空结果
+type PurgeResult struct {
+	Pattern string `json:"pattern"`         // key 模式
+	Deleted int64  `json:"deleted"`         // 删除的 key 数量
+	Error   string `json:"error,omitempty"` // 未删除完时的错误（如超时、达到 SCAN 轮数上限），可以再次调用继续删除
+}
+
+// Purge 清空所有可重建的应用缓存，返回每个模式的删除数量
+// 单个模式失败不影响其他模式
+func Purge(ctx context.Context, cache *rediscache.Client) []PurgeResult {
+	results := make([]PurgeResult, 0, len(PurgePatterns))
+	for _, pattern := range PurgePatterns {
+		opCtx, cancel := context.WithTimeout(ctx, purgeTimeout)
+		n, err := cache.DelByPattern(opCtx, pattern)
+		cancel()
+
+		result := PurgeResult{Pattern: pattern, Deleted: n}
+		if err != nil {
+			result.Error = err.Error()
+			log.Printf("invalidation: purge %q stopped after %d keys: %v", pattern, n, err)
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// PurgeHandler 清空缓存处理器（管理员）
+type PurgeHandler struct {
+	cache *rediscache.Client // Redis 缓存客户端（可能为 nil）
+}
+
+// NewPurgeHandler 创建清空缓存处理器实例
+func NewPurgeHandler(cache *rediscache.Client) *PurgeHandler {
+	return &PurgeHandler{cache: cache}
+}
+
+// PurgeCaches 清空应用缓存接口（管理员）
+// 路由：POST /admin/cache/purge
+// 功能：删除 Feed 流、视频详情、账户资料缓存（用于故障时清除错误的缓存数据），保留登录 token 和热榜窗口
+// 响应：{"results": [{"pattern": "feed:*", "deleted": 12}, ...]}
+func (h *PurgeHandler) PurgeCaches(c *gin.Context) {
+	if h.cache == nil {
+		apierror.Write(c, http.StatusServiceUnavailable, "CACHE_UNAVAILABLE", "redis is not configured")
+		return
+	}
+	results := Purge(c.Request.Context(), h.cache)
+	c.JSON(http.StatusOK, gin.H{"results": results})
+}
@@ -0,0 +1,27 @@
+package metrics
+
+// 缓存名（cache 标签）
+const (
+	CacheFeedLatest    = "feed_latest"    // 最新视频流（FeedService.ListLatest）
+	CacheFeedFollowing = "feed_following" // 关注流（FeedService.ListByFollowing）
+	CacheVideoDetail   = "video_detail"   // 视频详情（VideoService.GetDetail）
+)
+
+// 缓存查询结果（result 标签）
+// 命中率 = (hit + stale_hit) / (hit + stale_hit + miss)；miss 中没拿到锁的请求会再记录一次 lock_wait_hit 或 lock_wait_timeout
+const (
+	CacheHit             = "hit"               // 首次读取命中
+	CacheStaleHit        = "stale_hit"         // 首次读取命中已软过期的数据：返回旧数据并在后台刷新（仅 Feed 流）
+	CacheMiss            = "miss"              // 首次读取未命中
+	CacheLockWaitHit     = "lock_wait_hit"     // 未拿到锁，等待其他请求回填后命中
+	CacheLockWaitTimeout = "lock_wait_timeout" // 未拿到锁，等待超时后降级查询数据库
+	CacheError           = "error"             // Redis 出错或缓存数据损坏，直接查询数据库
+)
+
+// cacheLookups 缓存查询次数，按缓存名和结果分类
+var cacheLookups = NewCounterVec("vloop_cache_lookups_total", "Cache lookups by cache and result.", "cache", "result")
+
+// ObserveCache 记录一次缓存查询结果
+func ObserveCache(cache, result string) {
+	cacheLookups.Inc(cache, result)
+}
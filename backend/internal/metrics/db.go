@@ -0,0 +1,39 @@
+package metrics
+
+import "time"
+
+// SQL 操作类型（op 标签，对应 GORM 的回调类型）
+const (
+	DBOpCreate = "create"
+	DBOpQuery  = "query"
+	DBOpUpdate = "update"
+	DBOpDelete = "delete"
+	DBOpRow    = "row"
+	DBOpRaw    = "raw"
+)
+
+// 数据库查询统计（仅当配置开启 database.enable_metrics 时才有数据）
+// 平均耗时 = rate(vloop_db_query_duration_microseconds_total) / rate(vloop_db_queries_total)
+var (
+	dbQueries       = NewCounterVec("vloop_db_queries_total", "SQL statements executed by op.", "op")
+	dbQueryErrors   = NewCounterVec("vloop_db_query_errors_total", "SQL statements that failed (record not found excluded) by op.", "op")
+	dbSlowQueries   = NewCounterVec("vloop_db_slow_queries_total", "SQL statements slower than database.slow_threshold by op.", "op")
+	dbQueryDuration = NewCounterVec("vloop_db_query_duration_microseconds_total", "Total SQL execution time in microseconds by op.", "op")
+)
+
+// ObserveDBQuery 记录一条 SQL 的执行结果
+// 参数：
+//   - op: 操作类型（DBOpCreate 等）
+//   - elapsed: 执行耗时
+//   - slow: 是否超过慢查询阈值
+//   - failed: 是否出错（记录不存在不算出错）
+func ObserveDBQuery(op string, elapsed time.Duration, slow, failed bool) {
+	dbQueries.Inc(op)
+	dbQueryDuration.Add(elapsed.Microseconds(), op)
+	if slow {
+		dbSlowQueries.Inc(op)
+	}
+	if failed {
+		dbQueryErrors.Inc(op)
+	}
+}
@@ -0,0 +1,115 @@
+// Package metrics 进程内指标，通过 /metrics 接口以 Prometheus 文本格式暴露
+// 计数器使用原子操作实现，不依赖 Prometheus 客户端库；输出格式遵循 Prometheus exposition format（text/plain; version=0.0.4）
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+)
+
+// registry 已注册的指标（按注册顺序输出）
+var registry struct {
+	mu      sync.Mutex
+	metrics []*CounterVec
+}
+
+// CounterVec 带标签的计数器（只增不减）
+type CounterVec struct {
+	name   string   // 指标名
+	help   string   // 指标说明
+	labels []string // 标签名
+
+	mu     sync.RWMutex
+	values map[string]*counterValue // 标签值（以 \xff 连接）-> 计数
+}
+
+// counterValue 一组标签值对应的计数
+type counterValue struct {
+	labelValues []string
+	n           atomic.Int64
+}
+
+// NewCounterVec 创建并注册带标签的计数器
+// 参数：
+//   - name: 指标名（如 vloop_cache_lookups_total）
+//   - help: 指标说明
+//   - labels: 标签名
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	v := &CounterVec{name: name, help: help, labels: labels, values: make(map[string]*counterValue)}
+	registry.mu.Lock()
+	registry.metrics = append(registry.metrics, v)
+	registry.mu.Unlock()
+	return v
+}
+
+// Inc 计数加一（标签值数量必须与标签名一致，否则忽略）
+func (v *CounterVec) Inc(labelValues ...string) {
+	v.Add(1, labelValues...)
+}
+
+// Add 计数增加 n（n 必须为正数，标签值数量必须与标签名一致，否则忽略）
+func (v *CounterVec) Add(n int64, labelValues ...string) {
+	if v == nil || n <= 0 || len(labelValues) != len(v.labels) {
+		return
+	}
+	key := strings.Join(labelValues, "\xff")
+
+	v.mu.RLock()
+	c, ok := v.values[key]
+	v.mu.RUnlock()
+	if !ok {
+		v.mu.Lock()
+		if c, ok = v.values[key]; !ok {
+			c = &counterValue{labelValues: append([]string(nil), labelValues...)}
+			v.values[key] = c
+		}
+		v.mu.Unlock()
+	}
+	c.n.Add(n)
+}
+
+// write 按 Prometheus 文本格式输出（标签值按字典序排列，输出稳定）
+func (v *CounterVec) write(w io.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n", v.name, v.help)
+	fmt.Fprintf(w, "# TYPE %s counter\n", v.name)
+
+	v.mu.RLock()
+	keys := make([]string, 0, len(v.values))
+	for k := range v.values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		c := v.values[k]
+		pairs := make([]string, len(v.labels))
+		for i, label := range v.labels {
+			pairs[i] = fmt.Sprintf("%s=%q", label, c.labelValues[i])
+		}
+		fmt.Fprintf(w, "%s{%s} %d\n", v.name, strings.Join(pairs, ","), c.n.Load())
+	}
+	v.mu.RUnlock()
+}
+
+// Handler 指标接口
+// 路由：GET /metrics
+// 功能：以 Prometheus 文本格式输出所有已注册的指标，供 Prometheus 抓取
+func Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		c.Status(http.StatusOK)
+
+		registry.mu.Lock()
+		metrics := append([]*CounterVec(nil), registry.metrics...)
+		registry.mu.Unlock()
+		for _, m := range metrics {
+			m.write(c.Writer)
+		}
+	}
+}
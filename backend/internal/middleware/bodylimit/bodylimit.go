@@ -0,0 +1,37 @@
+// Package bodylimit 限制请求体大小，防止客户端发送超大请求体耗尽服务器内存
+package bodylimit
+
+import (
+	"net/http"
+
+	"feedsystem_video_go/internal/apierror"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultJSONLimit JSON 接口的默认请求体上限（1MB）
+const DefaultJSONLimit int64 = 1 << 20
+
+// Middleware 按路由限制请求体大小，超过上限返回 413
+// limits 以路由模板（c.FullPath()，如 "/video/uploadVideo"）为键指定单独的上限，未指定的路由使用 defaultLimit
+//
+// 两层保护：
+//   - Content-Length 已声明且超过上限：直接返回 413，不读取请求体
+//   - 未声明或声明不实（如 chunked 编码）：用 http.MaxBytesReader 包装请求体，读取超过上限时返回 *http.MaxBytesError，
+//     处理器中解析失败时由 apierror.BadRequest 转换为 413
+func Middleware(defaultLimit int64, limits map[string]int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit := defaultLimit
+		if l, ok := limits[c.FullPath()]; ok {
+			limit = l
+		}
+		if c.Request.ContentLength > limit {
+			apierror.Abort(c, http.StatusRequestEntityTooLarge, apierror.CodeTooLarge, "request body too large")
+			return
+		}
+		if c.Request.Body != nil {
+			c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+		}
+		c.Next()
+	}
+}
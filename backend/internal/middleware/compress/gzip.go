@@ -0,0 +1,190 @@
+// Package compress 按路由对响应体进行 gzip 压缩，减少 Feed/列表接口的传输量
+package compress
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultMinSize 默认的最小压缩长度（字节），更小的响应体压缩收益不足以抵消 CPU 和 gzip 头部开销
+const DefaultMinSize = 1024
+
+// gzipWriters 复用 gzip.Writer（每个 Writer 内部有几百 KB 的压缩状态）
+var gzipWriters = sync.Pool{
+	New: func() any {
+		w, _ := gzip.NewWriterLevel(nil, gzip.DefaultCompression)
+		return w
+	},
+}
+
+// Gzip 对指定路由的响应体进行 gzip 压缩
+// paths 以路由模板（c.FullPath()，如 "/feed/listLatest"）为键，未列出的路由不处理
+//
+// 只有同时满足以下条件时才压缩：
+//   - 请求头 Accept-Encoding 接受 gzip
+//   - 响应没有设置 Content-Encoding，且 Content-Type 不是已压缩的格式（图片、视频、压缩包等）
+//   - 响应体不小于 minSize 字节（minSize <= 0 时使用 DefaultMinSize）
+//
+// 处理器的输出先写入缓冲区，处理结束后统一决定是否压缩（列表接口的响应体是一次性写出的 JSON）
+func Gzip(minSize int, paths []string) gin.HandlerFunc {
+	if minSize <= 0 {
+		minSize = DefaultMinSize
+	}
+	enabled := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		enabled[p] = true
+	}
+
+	return func(c *gin.Context) {
+		if !enabled[c.FullPath()] {
+			c.Next()
+			return
+		}
+		// 同一路由的响应是否压缩取决于 Accept-Encoding，告知中间缓存按该请求头区分
+		c.Header("Vary", "Accept-Encoding")
+		if !acceptsGzip(c.GetHeader("Accept-Encoding")) {
+			c.Next()
+			return
+		}
+
+		w := &bufferedWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		completed := false
+		defer func() {
+			// 处理器 panic 时丢弃已缓存的内容，恢复原始 Writer，由 recovery 中间件写入错误响应
+			c.Writer = w.ResponseWriter
+			if completed {
+				w.finish(minSize)
+			}
+		}()
+		c.Next()
+		completed = true
+	}
+}
+
+// bufferedWriter 缓存处理器写出的响应体，处理结束后再决定是否压缩
+type bufferedWriter struct {
+	gin.ResponseWriter
+	buf         bytes.Buffer
+	passthrough bool // 处理器调用了 Flush（流式输出），之后的数据直接写出，不再压缩
+}
+
+func (w *bufferedWriter) Write(b []byte) (int, error) {
+	if w.passthrough {
+		return w.ResponseWriter.Write(b)
+	}
+	return w.buf.Write(b)
+}
+
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	if w.passthrough {
+		return w.ResponseWriter.WriteString(s)
+	}
+	return w.buf.WriteString(s)
+}
+
+// Written 缓冲中已有数据也视为已写出（gin 据此判断是否还需要写入默认响应）
+func (w *bufferedWriter) Written() bool {
+	return w.buf.Len() > 0 || w.ResponseWriter.Written()
+}
+
+// Flush 流式输出：写出已缓存的数据并切换为直接写出
+func (w *bufferedWriter) Flush() {
+	if !w.passthrough {
+		w.passthrough = true
+		if w.buf.Len() > 0 {
+			_, _ = w.ResponseWriter.Write(w.buf.Bytes())
+			w.buf.Reset()
+		}
+	}
+	w.ResponseWriter.Flush()
+}
+
+// finish 写出缓存的响应体（满足条件时压缩）
+func (w *bufferedWriter) finish(minSize int) {
+	if w.passthrough || w.buf.Len() == 0 {
+		return
+	}
+	body := w.buf.Bytes()
+	if !shouldCompress(w.Status(), w.Header(), len(body), minSize) {
+		_, _ = w.ResponseWriter.Write(body)
+		return
+	}
+
+	var out bytes.Buffer
+	gz := gzipWriters.Get().(*gzip.Writer)
+	gz.Reset(&out)
+	_, err := gz.Write(body)
+	if err == nil {
+		err = gz.Close()
+	}
+	gzipWriters.Put(gz)
+	if err != nil || out.Len() >= len(body) {
+		// 压缩失败或压缩后反而更大：按原样写出
+		_, _ = w.ResponseWriter.Write(body)
+		return
+	}
+
+	h := w.Header()
+	h.Set("Content-Encoding", "gzip")
+	h.Set("Content-Length", strconv.Itoa(out.Len()))
+	// 强校验的 ETag 对应未压缩的字节，压缩后改为弱校验
+	if etag := h.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		h.Set("ETag", "W/"+etag)
+	}
+	_, _ = w.ResponseWriter.Write(out.Bytes())
+}
+
+// shouldCompress 判断响应是否需要压缩
+func shouldCompress(status int, h http.Header, size, minSize int) bool {
+	if status < http.StatusOK || status == http.StatusNoContent || status == http.StatusNotModified {
+		return false
+	}
+	if size < minSize || h.Get("Content-Encoding") != "" {
+		return false
+	}
+	return !isCompressedType(h.Get("Content-Type"))
+}
+
+// isCompressedType 判断 Content-Type 是否为已压缩的格式
+func isCompressedType(contentType string) bool {
+	ct := strings.ToLower(strings.TrimSpace(strings.SplitN(contentType, ";", 2)[0]))
+	switch {
+	case strings.HasPrefix(ct, "image/") && ct != "image/svg+xml":
+		return true
+	case strings.HasPrefix(ct, "video/"), strings.HasPrefix(ct, "audio/"):
+		return true
+	}
+	switch ct {
+	case "application/gzip", "application/zip", "application/x-gzip", "application/zstd", "application/x-7z-compressed", "application/x-rar-compressed":
+		return true
+	}
+	return false
+}
+
+// acceptsGzip 判断 Accept-Encoding 是否接受 gzip（q=0 表示明确拒绝）
+func acceptsGzip(header string) bool {
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		name := strings.ToLower(strings.TrimSpace(fields[0]))
+		if name != "gzip" && name != "*" {
+			continue
+		}
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if q, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64); err == nil && q == 0 {
+					return false
+				}
+			}
+		}
+		return true
+	}
+	return false
+}
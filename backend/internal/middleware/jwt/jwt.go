@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"feedsystem_video_go/internal/account"
+	"feedsystem_video_go/internal/apierror"
 	"feedsystem_video_go/internal/auth"
 	rediscache "feedsystem_video_go/internal/middleware/redis"
 
@@ -27,13 +28,13 @@ func JWTAuth(accountRepo *account.AccountRepository, cache *rediscache.Client) g
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing authorization header"})
+			apierror.Abort(c, http.StatusUnauthorized, apierror.CodeUnauthorized, "missing authorization header")
 			return
 		}
 
 		parts := strings.SplitN(authHeader, " ", 2)
 		if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid authorization header"})
+			apierror.Abort(c, http.StatusUnauthorized, apierror.CodeUnauthorized, "invalid authorization header")
 			return
 		}
 
@@ -41,7 +42,7 @@ func JWTAuth(accountRepo *account.AccountRepository, cache *rediscache.Client) g
 
 		claims, err := auth.ParseToken(tokenString)
 		if err != nil {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired token"})
+			apierror.Abort(c, http.StatusUnauthorized, apierror.CodeUnauthorized, "invalid or expired token")
 			return
 		}
 		check(c, claims, tokenString, accountRepo, cache)
@@ -58,7 +59,7 @@ func SoftJWTAuth(accountRepo *account.AccountRepository, cache *rediscache.Clien
 
 		parts := strings.SplitN(authHeader, " ", 2)
 		if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid authorization header"})
+			apierror.Abort(c, http.StatusUnauthorized, apierror.CodeUnauthorized, "invalid authorization header")
 			return
 		}
 
@@ -66,7 +67,7 @@ func SoftJWTAuth(accountRepo *account.AccountRepository, cache *rediscache.Clien
 
 		claims, err := auth.ParseToken(tokenString)
 		if err != nil {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired token"})
+			apierror.Abort(c, http.StatusUnauthorized, apierror.CodeUnauthorized, "invalid or expired token")
 			return
 		}
 
@@ -85,7 +86,7 @@ func check(c *gin.Context, claims *auth.Claims, tokenString string, accountRepo
 		b, err := cache.GetBytes(cacheCtx, key)
 		if err == nil {
 			if string(b) != tokenString {
-				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "token has been revoked"})
+				apierror.Abort(c, http.StatusUnauthorized, apierror.CodeUnauthorized, "token has been revoked")
 				return
 			}
 			c.Set("accountID", claims.AccountID)
@@ -98,7 +99,7 @@ func check(c *gin.Context, claims *auth.Claims, tokenString string, accountRepo
 	// Redis 故障/未启用：查 DB 兜底
 	accountInfo, err := accountRepo.FindByID(c.Request.Context(), claims.AccountID)
 	if err != nil || accountInfo.Token == "" || accountInfo.Token != tokenString {
-		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "token has been revoked"})
+		apierror.Abort(c, http.StatusUnauthorized, apierror.CodeUnauthorized, "token has been revoked")
 		return
 	}
 
@@ -118,6 +119,24 @@ func check(c *gin.Context, claims *auth.Claims, tokenString string, accountRepo
 
 }
 
+// AdminAuth 管理员鉴权，必须放在 JWTAuth 之后使用
+// 以数据库中的 is_admin 为准（不信任 token 中的声明），非管理员返回 403
+func AdminAuth(accountRepo *account.AccountRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID, err := GetAccountID(c)
+		if err != nil {
+			apierror.Abort(c, http.StatusUnauthorized, apierror.CodeUnauthorized, "unauthorized")
+			return
+		}
+		accountInfo, err := accountRepo.FindByID(c.Request.Context(), accountID)
+		if err != nil || !accountInfo.IsAdmin {
+			apierror.Abort(c, http.StatusForbidden, apierror.CodeForbidden, "admin only")
+			return
+		}
+		c.Next()
+	}
+}
+
 func GetAccountID(c *gin.Context) (uint, error) {
 	uidValue, exists := c.Get("accountID")
 	if !exists {
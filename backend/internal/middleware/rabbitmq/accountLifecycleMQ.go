@@ -0,0 +1,78 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// AccountLifecycleMQ 账户生命周期消息队列，用于异步执行账户创建后的引导操作
+// 工作流程：
+// 1. 用户注册成功 → Service层发送 account.created 事件到MQ
+// 2. Worker消费MQ消息 → 执行引导操作（发送欢迎通知等）
+// 与 AccountMQ（改名同步）使用不同的交换机和队列，引导操作变慢不会积压改名事件
+type AccountLifecycleMQ struct {
+	*RabbitMQ // 嵌入基础RabbitMQ客户端
+}
+
+// 常量定义：交换机、队列、路由键
+const (
+	accountLifecycleExchange   = "account.lifecycle"   // 交换机名称
+	accountLifecycleQueue      = "account.lifecycle"   // 队列名称
+	accountLifecycleBindingKey = "account.lifecycle.*" // 绑定键（通配符：匹配所有账户生命周期事件）
+
+	accountCreatedRK = "account.lifecycle.created" // 账户创建路由键
+)
+
+// NewAccountLifecycleMQ 创建账户生命周期消息队列实例
+// 会声明Topic交换机、队列和绑定关系
+// 参数：
+//   - base: 基础RabbitMQ客户端
+//
+// 返回：
+//   - *AccountLifecycleMQ: 账户生命周期消息队列实例
+//   - error: 错误信息
+func NewAccountLifecycleMQ(base *RabbitMQ) (*AccountLifecycleMQ, error) {
+	if base == nil {
+		return nil, errors.New("rabbitmq base is nil")
+	}
+	// 声明Topic交换机、队列和绑定关系
+	if err := base.DeclareTopic(accountLifecycleExchange, accountLifecycleQueue, accountLifecycleBindingKey); err != nil {
+		return nil, err
+	}
+	return &AccountLifecycleMQ{RabbitMQ: base}, nil
+}
+
+// Created 发送账户创建事件到MQ（事件结构与改名事件相同，Action 为 created）
+// Worker消费后会：执行新账户的引导操作（目前为发送欢迎通知）
+// 参数：
+//   - ctx: 上下文
+//   - accountID: 新账户ID
+//   - username: 用户名
+//
+// 返回：
+//   - error: 错误信息
+func (a *AccountLifecycleMQ) Created(ctx context.Context, accountID uint, username string) error {
+	if a == nil || a.RabbitMQ == nil {
+		return errors.New("account lifecycle mq is not initialized")
+	}
+	if accountID == 0 {
+		return errors.New("accountID is required")
+	}
+
+	// 生成事件ID
+	id, err := newEventID(16)
+	if err != nil {
+		return err
+	}
+
+	evt := AccountEvent{
+		EventID:    id,
+		Action:     "created",
+		AccountID:  accountID,
+		Username:   username,
+		OccurredAt: time.Now().UTC(),
+	}
+	// 发布事件到MQ
+	return a.PublishEvent(ctx, accountLifecycleExchange, accountCreatedRK, evt.EventID, evt)
+}
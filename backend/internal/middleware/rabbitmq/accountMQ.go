@@ -0,0 +1,100 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// AccountMQ 账户消息队列，用于异步处理账户相关事件
+// 工作流程：
+// 1. 用户改名 → Service层发送改名事件到MQ
+// 2. Worker消费MQ消息 → 同步视频、评论表中冗余存储的用户名
+type AccountMQ struct {
+	*RabbitMQ // 嵌入基础RabbitMQ客户端
+}
+
+// 常量定义：交换机、队列、路由键
+const (
+	accountExchange   = "account.events" // 交换机名称
+	accountQueue      = "account.events" // 队列名称
+	accountBindingKey = "account.*"      // 绑定键（通配符：匹配所有以account.开头的路由键）
+
+	accountRenamedRK = "account.renamed" // 改名路由键
+)
+
+// AccountEvent 账户事件结构体
+type AccountEvent struct {
+	EventID    string    `json:"event_id"`    // 事件唯一ID
+	Action     string    `json:"action"`      // 操作类型：renamed / created
+	AccountID  uint      `json:"account_id"`  // 账户ID
+	Username   string    `json:"username"`    // 新用户名
+	OccurredAt time.Time `json:"occurred_at"` // 事件发生时间
+}
+
+// NewAccountMQ 创建账户消息队列实例
+// 会声明Topic交换机、队列和绑定关系
+// 参数：
+//   - base: 基础RabbitMQ客户端
+//
+// 返回：
+//   - *AccountMQ: 账户消息队列实例
+//   - error: 错误信息
+func NewAccountMQ(base *RabbitMQ) (*AccountMQ, error) {
+	if base == nil {
+		return nil, errors.New("rabbitmq base is nil")
+	}
+	// 声明Topic交换机、队列和绑定关系
+	if err := base.DeclareTopic(accountExchange, accountQueue, accountBindingKey); err != nil {
+		return nil, err
+	}
+	return &AccountMQ{RabbitMQ: base}, nil
+}
+
+// Renamed 发送改名事件到MQ
+// Worker消费后会：将该账户所有视频、评论中的冗余用户名更新为最新用户名
+// 参数：
+//   - ctx: 上下文
+//   - accountID: 账户ID
+//   - username: 新用户名
+//
+// 返回：
+//   - error: 错误信息
+func (a *AccountMQ) Renamed(ctx context.Context, accountID uint, username string) error {
+	return a.publish(ctx, "renamed", accountRenamedRK, AccountEvent{
+		AccountID: accountID,
+		Username:  username,
+	})
+}
+
+// publish 发送账户事件到MQ（内部方法）
+// 参数：
+//   - ctx: 上下文
+//   - action: 操作类型
+//   - routingKey: 路由键
+//   - evt: 账户事件
+//
+// 返回：
+//   - error: 错误信息
+func (a *AccountMQ) publish(ctx context.Context, action, routingKey string, evt AccountEvent) error {
+	if a == nil || a.RabbitMQ == nil {
+		return errors.New("account mq is not initialized")
+	}
+	if evt.AccountID == 0 {
+		return errors.New("accountID is required")
+	}
+
+	// 生成事件ID
+	id, err := newEventID(16)
+	if err != nil {
+		return err
+	}
+
+	// 填充事件字段
+	evt.EventID = id
+	evt.Action = action
+	evt.OccurredAt = time.Now().UTC()
+
+	// 发布事件到MQ
+	return a.PublishEvent(ctx, accountExchange, routingKey, evt.EventID, evt)
+}
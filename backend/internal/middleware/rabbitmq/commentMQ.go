@@ -22,13 +22,16 @@ const (
 
 	commentPublishRK = "comment.publish" // 发布评论路由键
 	commentDeleteRK  = "comment.delete"  // 删除评论路由键
+	commentLikeRK    = "comment.like"    // 点赞评论路由键
+	commentUnlikeRK  = "comment.unlike"  // 取消点赞评论路由键
 )
 
 // CommentEvent 评论事件结构体
 type CommentEvent struct {
 	EventID    string    `json:"event_id"`             // 事件唯一ID
-	Action     string    `json:"action"`              // 操作类型：publish/delete
-	CommentID  uint      `json:"comment_id,omitempty"`  // 评论ID（删除时使用）
+	Action     string    `json:"action"`              // 操作类型：publish/delete/like/unlike
+	CommentID  uint      `json:"comment_id,omitempty"`  // 评论ID（删除、点赞时使用）
+	AccountID  uint      `json:"account_id,omitempty"` // 点赞用户ID（点赞时使用）
 	Username   string    `json:"username,omitempty"`   // 用户名（发布时使用）
 	VideoID    uint      `json:"video_id,omitempty"`   // 视频ID（发布时使用）
 	AuthorID   uint      `json:"author_id,omitempty"`  // 作者ID（发布时使用）
@@ -86,10 +89,40 @@ func (c *CommentMQ) Delete(ctx context.Context, commentID uint) error {
 	})
 }
 
+// Like 发送点赞评论事件到MQ
+// Worker消费后会：1) 插入评论点赞记录 2) 评论点赞数+1
+// 参数：
+//   - ctx: 上下文
+//   - accountID: 点赞用户ID
+//   - commentID: 评论ID
+// 返回：
+//   - error: 错误信息
+func (c *CommentMQ) Like(ctx context.Context, accountID, commentID uint) error {
+	return c.publish(ctx, "like", commentLikeRK, CommentEvent{
+		CommentID: commentID,
+		AccountID: accountID,
+	})
+}
+
+// Unlike 发送取消点赞评论事件到MQ
+// Worker消费后会：1) 删除评论点赞记录 2) 评论点赞数-1
+// 参数：
+//   - ctx: 上下文
+//   - accountID: 点赞用户ID
+//   - commentID: 评论ID
+// 返回：
+//   - error: 错误信息
+func (c *CommentMQ) Unlike(ctx context.Context, accountID, commentID uint) error {
+	return c.publish(ctx, "unlike", commentUnlikeRK, CommentEvent{
+		CommentID: commentID,
+		AccountID: accountID,
+	})
+}
+
 // publish 发送评论事件到MQ（内部方法）
 // 参数：
 //   - ctx: 上下文
-//   - action: 操作类型（publish/delete）
+//   - action: 操作类型（publish/delete/like/unlike）
 //   - routingKey: 路由键
 //   - evt: 评论事件
 // 返回：
@@ -111,6 +144,6 @@ func (c *CommentMQ) publish(ctx context.Context, action, routingKey string, evt
 	evt.OccurredAt = time.Now().UTC()
 
 	// 发布事件到MQ
-	return c.PublishJSON(ctx, commentExchange, routingKey, evt)
+	return c.PublishEvent(ctx, commentExchange, routingKey, evt.EventID, evt)
 }
 
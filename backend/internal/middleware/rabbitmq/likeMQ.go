@@ -108,5 +108,5 @@ func (l *LikeMQ) publish(ctx context.Context, action, routingKey string, userID,
 	}
 
 	// 发布事件到MQ
-	return l.PublishJSON(ctx, likeExchange, routingKey, event)
+	return l.PublishEvent(ctx, likeExchange, routingKey, event.EventID, event)
 }
@@ -20,14 +20,23 @@ const (
 	popularityBindingKey = "video.popularity.*"     // 绑定键（通配符：匹配所有以video.popularity.开头的路由键）
 
 	popularityUpdateRK = "video.popularity.update" // 热度更新路由键
+	popularityBatchRK  = "video.popularity.batch"  // 批量热度更新路由键
 )
 
 // PopularityEvent 热度更新事件结构体
+// 单条更新使用 VideoID/Change；批量更新使用 Changes（此时 VideoID/Change 为空）
 type PopularityEvent struct {
-	EventID    string    `json:"event_id"`   // 事件唯一ID
-	VideoID    uint      `json:"video_id"`   // 视频ID
-	Change     int64     `json:"change"`     // 热度变化量（可为正数或负数）
-	OccurredAt time.Time `json:"occurred_at"` // 事件发生时间
+	EventID    string             `json:"event_id"`          // 事件唯一ID
+	VideoID    uint               `json:"video_id"`          // 视频ID
+	Change     int64              `json:"change"`            // 热度变化量（可为正数或负数）
+	Changes    []PopularityChange `json:"changes,omitempty"` // 批量热度变化（UpdateBatch 使用）
+	OccurredAt time.Time          `json:"occurred_at"`       // 事件发生时间
+}
+
+// PopularityChange 单个视频的热度变化量
+type PopularityChange struct {
+	VideoID uint  `json:"video_id"` // 视频ID
+	Change  int64 `json:"change"`   // 热度变化量（可为正数或负数）
 }
 
 // NewPopularityMQ 创建热度更新消息队列实例
@@ -79,6 +88,48 @@ func (p *PopularityMQ) Update(ctx context.Context, videoID uint, change int64) e
 	}
 
 	// 发布事件到MQ
-	return p.PublishJSON(ctx, popularityExchange, popularityUpdateRK, event)
+	return p.PublishEvent(ctx, popularityExchange, popularityUpdateRK, event.EventID, event)
 }
 
+
+// UpdateBatch 批量发送热度更新事件到MQ（一条消息携带多个视频的热度变化）
+// API 侧由 PopularityBatcher 调用：合并窗口内所有视频的热度变化后发送一条消息，减少消息数量
+// Worker消费后会在一次Redis Pipeline中写入所有变化
+// 参数：
+//   - ctx: 上下文
+//   - changes: 热度变化列表（videoID 为 0 或 change 为 0 的项会被忽略）
+// 返回：
+//   - error: 错误信息
+func (p *PopularityMQ) UpdateBatch(ctx context.Context, changes []PopularityChange) error {
+	if p == nil || p.RabbitMQ == nil {
+		return errors.New("popularity mq is not initialized")
+	}
+
+	// 过滤无效项
+	valid := make([]PopularityChange, 0, len(changes))
+	for _, c := range changes {
+		if c.VideoID == 0 || c.Change == 0 {
+			continue
+		}
+		valid = append(valid, c)
+	}
+	if len(valid) == 0 {
+		return errors.New("changes are required")
+	}
+
+	// 生成事件ID
+	id, err := newEventID(16)
+	if err != nil {
+		return err
+	}
+
+	// 构造批量热度更新事件
+	event := PopularityEvent{
+		EventID:    id,
+		Changes:    valid,
+		OccurredAt: time.Now().UTC(),
+	}
+
+	// 发布事件到MQ
+	return p.PublishEvent(ctx, popularityExchange, popularityBatchRK, event.EventID, event)
+}
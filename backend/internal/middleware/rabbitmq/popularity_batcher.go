@@ -0,0 +1,124 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+)
+
+// 热度批量发送参数
+const (
+	DefaultPopularityBatchInterval = 200 * time.Millisecond // 合并窗口：窗口内的热度变化合并成一条消息
+	maxPopularityBatchSize         = 500                    // 单条消息最多携带的视频数（超出时拆成多条）
+)
+
+// PopularityBatcher 热度变化合并发送器（API 侧的热度消息生产者）
+// 短时间内的热度变化先在内存中按视频合并，每个窗口用 UpdateBatch 发送一条消息：
+// 热门视频集中被点赞/评论时，消息数从每次操作一条降为每个窗口一条
+// 注意：热度消息只用于更新 Redis 热榜（数据库热度由各 Worker 写入），进程退出时最多丢失一个窗口的热榜增量
+type PopularityBatcher struct {
+	mq       *PopularityMQ
+	interval time.Duration
+	fallback func(ctx context.Context, changes map[uint]int64) // 发送失败时的降级处理（如直接写入 Redis 热榜）
+
+	mu      sync.Mutex
+	pending map[uint]int64 // 当前窗口内合并的热度变化
+}
+
+// NewPopularityBatcher 创建热度变化合并发送器（需要调用 Run 启动发送循环）
+// 参数：
+//   - mq: 热度消息队列（为 nil 时返回 nil，调用方按 MQ 不可用处理）
+//   - interval: 合并窗口（<= 0 时使用 DefaultPopularityBatchInterval）
+//   - fallback: 发送失败时的降级处理（可能为 nil）
+func NewPopularityBatcher(mq *PopularityMQ, interval time.Duration, fallback func(ctx context.Context, changes map[uint]int64)) *PopularityBatcher {
+	if mq == nil {
+		return nil
+	}
+	if interval <= 0 {
+		interval = DefaultPopularityBatchInterval
+	}
+	return &PopularityBatcher{mq: mq, interval: interval, fallback: fallback, pending: make(map[uint]int64)}
+}
+
+// Update 记录一次热度变化，在当前窗口结束时合并发送
+// 与 PopularityMQ.Update 参数一致；返回错误时调用方应自行降级（直接更新 Redis 热榜）
+func (b *PopularityBatcher) Update(ctx context.Context, videoID uint, change int64) error {
+	if b == nil || b.mq == nil {
+		return errors.New("popularity batcher is not initialized")
+	}
+	if videoID == 0 || change == 0 {
+		return errors.New("videoID and change are required")
+	}
+	b.mu.Lock()
+	b.pending[videoID] += change
+	b.mu.Unlock()
+	return nil
+}
+
+// Run 按窗口发送合并后的热度变化（阻塞，直到 ctx 被取消；取消时发送剩余的变化）
+func (b *PopularityBatcher) Run(ctx context.Context) error {
+	if b == nil || b.mq == nil {
+		return errors.New("popularity batcher is not initialized")
+	}
+	ticker := time.NewTicker(b.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			b.Flush(context.Background())
+			return ctx.Err()
+		case <-ticker.C:
+			b.Flush(ctx)
+		}
+	}
+}
+
+// Flush 立即发送当前窗口内的热度变化（合并后为 0 的视频不发送）
+// 发送失败的部分交给 fallback 处理
+func (b *PopularityBatcher) Flush(ctx context.Context) {
+	b.mu.Lock()
+	if len(b.pending) == 0 {
+		b.mu.Unlock()
+		return
+	}
+	pending := b.pending
+	b.pending = make(map[uint]int64, len(pending))
+	b.mu.Unlock()
+
+	batch := make([]PopularityChange, 0, min(len(pending), maxPopularityBatchSize))
+	for id, change := range pending {
+		if change == 0 {
+			continue
+		}
+		batch = append(batch, PopularityChange{VideoID: id, Change: change})
+		if len(batch) == maxPopularityBatchSize {
+			b.send(ctx, batch)
+			batch = batch[:0]
+		}
+	}
+	if len(batch) > 0 {
+		b.send(ctx, batch)
+	}
+}
+
+// send 发送一批热度变化，失败时交给 fallback
+func (b *PopularityBatcher) send(ctx context.Context, batch []PopularityChange) {
+	opCtx, cancel := context.WithTimeout(ctx, time.Second)
+	err := b.mq.UpdateBatch(opCtx, batch)
+	cancel()
+	if err == nil {
+		return
+	}
+	log.Printf("popularity batcher: failed to publish %d changes: %v", len(batch), err)
+	if b.fallback == nil {
+		return
+	}
+	changes := make(map[uint]int64, len(batch))
+	for _, c := range batch {
+		changes[c.VideoID] += c.Change
+	}
+	b.fallback(ctx, changes)
+}
@@ -0,0 +1,67 @@
+package rabbitmq
+
+import (
+	"feedsystem_video_go/internal/config"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// QueueArgs 根据配置生成队列声明参数（消息 TTL、长度上限、死信交换机）
+// API（生产者）和 Worker（消费者）都会声明同一个队列，必须使用同一份配置生成参数，
+// 否则 RabbitMQ 会因参数不一致返回 PRECONDITION_FAILED
+// 参数：
+//   - cfg: RabbitMQ配置（可能为 nil）
+//   - queue: 队列名称
+// 返回：
+//   - amqp.Table: 队列参数（无配置时返回 nil）
+func QueueArgs(cfg *config.RabbitMQConfig, queue string) amqp.Table {
+	if cfg == nil {
+		return nil
+	}
+	args := amqp.Table{}
+	if qc, ok := cfg.Queues[queue]; ok {
+		if qc.MessageTTL > 0 {
+			args["x-message-ttl"] = qc.MessageTTL.Milliseconds()
+		}
+		if qc.MaxLength > 0 {
+			args["x-max-length"] = int64(qc.MaxLength)
+		}
+	}
+	// 过期或溢出的消息转入死信交换机，使用队列名作为路由键，落到 <队列名>.dlq
+	if cfg.DeadLetterExchange != "" {
+		args["x-dead-letter-exchange"] = cfg.DeadLetterExchange
+		args["x-dead-letter-routing-key"] = queue
+	}
+	if len(args) == 0 {
+		return nil
+	}
+	return args
+}
+
+// DeclareDeadLetter 声明死信交换机和指定队列对应的死信队列（<队列名>.dlq）
+// 未配置死信交换机时不做任何操作
+// 参数：
+//   - ch: RabbitMQ通道
+//   - cfg: RabbitMQ配置（可能为 nil）
+//   - queue: 业务队列名称
+// 返回：
+//   - error: 错误信息
+func DeclareDeadLetter(ch *amqp.Channel, cfg *config.RabbitMQConfig, queue string) error {
+	if cfg == nil || cfg.DeadLetterExchange == "" {
+		return nil
+	}
+
+	// 1. 声明死信交换机（Direct类型，按队列名路由）
+	if err := ch.ExchangeDeclare(cfg.DeadLetterExchange, "direct", true, false, false, false, nil); err != nil {
+		return err
+	}
+
+	// 2. 声明死信队列（持久化，不设置 TTL，由运维排查后手动处理）
+	dlq := queue + ".dlq"
+	if _, err := ch.QueueDeclare(dlq, true, false, false, false, nil); err != nil {
+		return err
+	}
+
+	// 3. 绑定死信队列
+	return ch.QueueBind(dlq, queue, cfg.DeadLetterExchange, false, nil)
+}
@@ -7,6 +7,8 @@ import (
 	"encoding/json"
 	"errors"
 	"feedsystem_video_go/internal/config"
+	"feedsystem_video_go/internal/middleware/trace"
+	"fmt"
 	"strconv"
 	"time"
 
@@ -21,8 +23,9 @@ import (
 //   - Routing Key（路由键）：用于决定消息路由到哪个队列
 //   - Binding（绑定）：将队列绑定到交换机，并指定路由键
 type RabbitMQ struct {
-	conn *amqp.Connection // RabbitMQ连接
-	ch   *amqp.Channel    // RabbitMQ通道（轻量级连接，用于发送和接收消息）
+	conn *amqp.Connection       // RabbitMQ连接
+	ch   *amqp.Channel          // RabbitMQ通道（轻量级连接，用于发送和接收消息）
+	cfg  *config.RabbitMQConfig // RabbitMQ配置（用于生成队列参数）
 }
 
 // NewRabbitMQ 创建RabbitMQ连接和通道
@@ -50,7 +53,7 @@ func NewRabbitMQ(cfg *config.RabbitMQConfig) (*RabbitMQ, error) {
 		return nil, err
 	}
 
-	return &RabbitMQ{conn: conn, ch: ch}, nil
+	return &RabbitMQ{conn: conn, ch: ch, cfg: cfg}, nil
 }
 
 // Close 关闭RabbitMQ连接和通道
@@ -75,19 +78,43 @@ func (r *RabbitMQ) Close() error {
 // 例如：
 //   - 路由键 "like.like" 可以匹配绑定键 "like.*"
 //   - 路由键 "video.popularity.update" 可以匹配绑定键 "video.popularity.*"
+// 队列参数（TTL、长度上限、死信）来自配置中对应队列的设置，见 QueueArgs
+// 一个队列可以绑定多个绑定键（如聚合队列同时接收 "like.*"、"comment.*"），每个绑定键各执行一次 QueueBind
 // 参数：
 //   - exchange: 交换机名称
 //   - queue: 队列名称
 //   - bindingKey: 绑定键（支持通配符 * 和 #）
+//   - moreKeys: 可选的其他绑定键
 // 返回：
 //   - error: 错误信息
-func (r *RabbitMQ) DeclareTopic(exchange string, queue string, bindingKey string) error {
+func (r *RabbitMQ) DeclareTopic(exchange string, queue string, bindingKey string, moreKeys ...string) error {
+	if r == nil || r.ch == nil {
+		return errors.New("rabbitmq is not initialized")
+	}
+	return r.DeclareTopicWithArgs(exchange, queue, bindingKey, QueueArgs(r.cfg, queue), moreKeys...)
+}
+
+// DeclareTopicWithArgs 声明Topic类型的交换机、队列和绑定关系，并指定队列参数
+// 参数：
+//   - exchange: 交换机名称
+//   - queue: 队列名称
+//   - bindingKey: 绑定键（支持通配符 * 和 #）
+//   - args: 队列参数（如 x-message-ttl、x-max-length、x-dead-letter-exchange，可为 nil）
+//   - moreKeys: 可选的其他绑定键
+// 返回：
+//   - error: 错误信息
+func (r *RabbitMQ) DeclareTopicWithArgs(exchange string, queue string, bindingKey string, args amqp.Table, moreKeys ...string) error {
 	if r == nil || r.ch == nil {
 		return errors.New("rabbitmq is not initialized")
 	}
 	if exchange == "" || queue == "" || bindingKey == "" {
 		return errors.New("exchange/queue/bindingKey is required")
 	}
+	for _, key := range moreKeys {
+		if key == "" {
+			return errors.New("bindingKey must not be empty")
+		}
+	}
 
 	// 1. 声明交换机（Topic类型，持久化）
 	if err := r.ch.ExchangeDeclare(
@@ -102,27 +129,50 @@ func (r *RabbitMQ) DeclareTopic(exchange string, queue string, bindingKey string
 		return err
 	}
 
-	// 2. 声明队列（持久化）
+	// 2. 声明死信交换机和死信队列（配置了死信交换机时）
+	if err := DeclareDeadLetter(r.ch, r.cfg, queue); err != nil {
+		return err
+	}
+
+	// 3. 声明队列（持久化）
 	q, err := r.ch.QueueDeclare(
 		queue,          // 队列名称
 		true,           // durable: 持久化
 		false,          // autoDelete: 不自动删除
 		false,          // exclusive: 不独占
 		false,          // noWait: 不等待服务器确认
-		nil,            // args: 额外参数
+		args,           // args: 队列参数（TTL、长度上限、死信）
 	)
 	if err != nil {
 		return err
 	}
 
-	// 3. 将队列绑定到交换机（通过绑定键）
-	return r.ch.QueueBind(
-		q.Name,         // 队列名称
-		bindingKey,     // 绑定键（支持通配符）
-		exchange,       // 交换机名称
-		false,          // noWait: 不等待服务器确认
-		nil,            // args: 额外参数
-	)
+	// 4. 将队列绑定到交换机（每个绑定键一次 QueueBind，重复绑定是幂等的）
+	return BindQueue(r.ch, q.Name, exchange, append([]string{bindingKey}, moreKeys...)...)
+}
+
+// BindQueue 将队列按多个绑定键绑定到同一个 Topic 交换机
+// Worker 自行声明拓扑时也可以使用（如聚合通知队列同时绑定 "like.*"、"comment.*"、"social.*"）
+// 参数：
+//   - ch: RabbitMQ 通道
+//   - queue: 队列名称
+//   - exchange: 交换机名称
+//   - bindingKeys: 绑定键（支持通配符 * 和 #）
+// 返回：
+//   - error: 错误信息
+func BindQueue(ch *amqp.Channel, queue string, exchange string, bindingKeys ...string) error {
+	for _, key := range bindingKeys {
+		if err := ch.QueueBind(
+			queue,          // 队列名称
+			key,            // 绑定键（支持通配符）
+			exchange,       // 交换机名称
+			false,          // noWait: 不等待服务器确认
+			nil,            // args: 额外参数
+		); err != nil {
+			return fmt.Errorf("bind %s to %s with %q: %w", queue, exchange, key, err)
+		}
+	}
+	return nil
 }
 
 // PublishJSON 发布JSON格式消息到指定的交换机
@@ -134,6 +184,21 @@ func (r *RabbitMQ) DeclareTopic(exchange string, queue string, bindingKey string
 // 返回：
 //   - error: 错误信息
 func (r *RabbitMQ) PublishJSON(ctx context.Context, exchange string, routingKey string, payload any) error {
+	return r.PublishEvent(ctx, exchange, routingKey, "", payload)
+}
+
+// PublishEvent 发布JSON格式的事件消息，并设置消息头用于链路追踪
+//   - MessageId: 事件ID（RabbitMQ 管理界面可直接看到）
+//   - CorrelationId: 上下文中的追踪ID（HTTP 请求的 X-Request-ID），没有时使用事件ID
+// 参数：
+//   - ctx: 上下文（用于超时控制和读取追踪ID）
+//   - exchange: 交换机名称
+//   - routingKey: 路由键（决定消息路由到哪个队列）
+//   - eventID: 事件ID
+//   - payload: 消息内容（任意对象，会被序列化为JSON）
+// 返回：
+//   - error: 错误信息
+func (r *RabbitMQ) PublishEvent(ctx context.Context, exchange string, routingKey string, eventID string, payload any) error {
 	if r == nil || r.ch == nil {
 		return errors.New("rabbitmq is not initialized")
 	}
@@ -147,12 +212,20 @@ func (r *RabbitMQ) PublishJSON(ctx context.Context, exchange string, routingKey
 		return err
 	}
 
+	// 追踪ID：优先使用请求上下文中的ID
+	correlationID := trace.FromContext(ctx)
+	if correlationID == "" {
+		correlationID = eventID
+	}
+
 	// 发布消息到交换机
 	return r.ch.PublishWithContext(ctx, exchange, routingKey, false, false, amqp.Publishing{
-		ContentType:  "application/json", // 内容类型
-		DeliveryMode: amqp.Persistent,    // 持久化模式（RabbitMQ重启后消息不丢失）
-		Timestamp:    time.Now(),         // 消息时间戳
-		Body:         b,                  // 消息体（JSON字节）
+		ContentType:   "application/json", // 内容类型
+		DeliveryMode:  amqp.Persistent,    // 持久化模式（RabbitMQ重启后消息不丢失）
+		Timestamp:     time.Now(),         // 消息时间戳
+		MessageId:     eventID,            // 消息ID（事件ID）
+		CorrelationId: correlationID,      // 追踪ID
+		Body:          b,                  // 消息体（JSON字节）
 	})
 }
 
@@ -108,5 +108,5 @@ func (s *SocialMQ) publish(ctx context.Context, action, routingKey string, follo
 	}
 
 	// 发布事件到MQ
-	return s.PublishJSON(ctx, socialExchange, routingKey, evt)
+	return s.PublishEvent(ctx, socialExchange, routingKey, evt.EventID, evt)
 }
@@ -0,0 +1,66 @@
+// Package recovery 提供返回统一 JSON 错误响应的 panic 恢复中间件
+// gin 默认的 Recovery 只返回空的 500，这里改为返回 {"code": "INTERNAL_ERROR", ...}，并在日志中带上追踪ID和调用栈
+package recovery
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"runtime/debug"
+	"syscall"
+
+	"feedsystem_video_go/internal/apierror"
+	"feedsystem_video_go/internal/middleware/trace"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Middleware 捕获处理器中的 panic
+// 必须注册在 trace.Middleware 之后，才能在日志中记录追踪ID
+//
+// 处理规则：
+//   - http.ErrAbortHandler：标准库约定的"静默中止"信号，继续向上抛出交给 net/http 处理
+//   - 客户端已断开（请求上下文被取消、连接被重置）：无法再写响应，只记录日志并中止
+//   - 其他 panic：记录调用栈，返回 500 统一错误响应（响应已开始写出时只能中止）
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+			if rec == http.ErrAbortHandler {
+				panic(rec)
+			}
+
+			traceID := trace.FromContext(c.Request.Context())
+			if clientGone(c.Request.Context(), rec) {
+				log.Printf("panic after client disconnected: %s %s trace_id=%s: %v", c.Request.Method, c.Request.URL.Path, traceID, rec)
+				c.Abort()
+				return
+			}
+
+			log.Printf("panic recovered: %s %s trace_id=%s: %v\n%s", c.Request.Method, c.Request.URL.Path, traceID, rec, debug.Stack())
+			if c.Writer.Written() {
+				c.Abort()
+				return
+			}
+			apierror.AbortInternal(c)
+		}()
+		c.Next()
+	}
+}
+
+// clientGone 判断 panic 是否由客户端断开引起
+// 请求上下文已取消，或 panic 的错误是 context.Canceled / 连接断开（broken pipe、connection reset）
+func clientGone(ctx context.Context, rec any) bool {
+	if errors.Is(ctx.Err(), context.Canceled) {
+		return true
+	}
+	err, ok := rec.(error)
+	if !ok {
+		return false
+	}
+	return errors.Is(err, context.Canceled) || errors.Is(err, syscall.EPIPE) || errors.Is(err, syscall.ECONNRESET)
+}
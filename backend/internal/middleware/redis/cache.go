@@ -3,6 +3,8 @@ package redis
 import (
 	"context"
 	"time"
+
+	redis "github.com/redis/go-redis/v9"
 )
 
 func (c *Client) GetBytes(ctx context.Context, key string) ([]byte, error) {
@@ -13,6 +15,97 @@ func (c *Client) SetBytes(ctx context.Context, key string, value []byte, ttl tim
 	return c.rdb.Set(ctx, key, value, ttl).Err()
 }
 
+// MGetBytes 一次读取多个键，返回值与 keys 一一对应，不存在的键对应 nil
+func (c *Client) MGetBytes(ctx context.Context, keys []string) ([][]byte, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	vals, err := c.rdb.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+	out := make([][]byte, len(vals))
+	for i, v := range vals {
+		if s, ok := v.(string); ok {
+			out[i] = []byte(s)
+		}
+	}
+	return out, nil
+}
+
+// Del 删除一个键（c 为 nil 时不做任何事，与 zset 操作一致）
 func (c *Client) Del(ctx context.Context, key string) error {
+	if c == nil || c.rdb == nil {
+		return nil
+	}
 	return c.rdb.Del(ctx, key).Err()
 }
+
+// DelIfEqual 键的值等于 value 时删除（原子操作，用于一次性 token 等只能被消费一次的键），返回是否删除
+func (c *Client) DelIfEqual(ctx context.Context, key string, value string) (bool, error) {
+	n, err := unlockScript.Run(ctx, c.rdb, []string{key}, value).Int64()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// SetNX 键不存在时写入（用于幂等键等一次性标记），返回是否写入成功
+func (c *Client) SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	return c.rdb.SetNX(ctx, key, value, ttl).Result()
+}
+
+// DelMany 一次删除多个键（c 为 nil 时不做任何事）
+func (c *Client) DelMany(ctx context.Context, keys []string) error {
+	if c == nil || c.rdb == nil || len(keys) == 0 {
+		return nil
+	}
+	return c.rdb.Del(ctx, keys...).Err()
+}
+
+// IncrWithTTL 自增计数器，首次创建时设置过期时间（用于限流等固定窗口计数）
+func (c *Client) IncrWithTTL(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	n, err := c.rdb.Incr(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+	if n == 1 {
+		_ = c.rdb.Expire(ctx, key, ttl).Err()
+	}
+	return n, nil
+}
+
+// IncrMany 在一次 Pipeline 中对多个计数器自增，并刷新它们的过期时间（用于批量递增缓存版本号）
+func (c *Client) IncrMany(ctx context.Context, keys []string, ttl time.Duration) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	pipe := c.rdb.Pipeline()
+	for _, key := range keys {
+		pipe.Incr(ctx, key)
+		pipe.Expire(ctx, key, ttl)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// HIncrByExpireAt 原子地对哈希的多个字段自增，并设置 key 的绝对过期时间，返回各字段自增后的值（用于按自然日重置的配额计数）
+func (c *Client) HIncrByExpireAt(ctx context.Context, key string, incr map[string]int64, expireAt time.Time) (map[string]int64, error) {
+	if len(incr) == 0 {
+		return map[string]int64{}, nil
+	}
+	pipe := c.rdb.TxPipeline()
+	cmds := make(map[string]*redis.IntCmd, len(incr))
+	for field, n := range incr {
+		cmds[field] = pipe.HIncrBy(ctx, key, field, n)
+	}
+	pipe.ExpireAt(ctx, key, expireAt)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+	out := make(map[string]int64, len(cmds))
+	for field, cmd := range cmds {
+		out[field] = cmd.Val()
+	}
+	return out, nil
+}
@@ -0,0 +1,90 @@
+package redis
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"feedsystem_video_go/internal/config"
+)
+
+// 缓存击穿保护的默认策略
+const (
+	defaultLockTTL          = 2 * time.Second
+	defaultLockWaitAttempts = 5
+	defaultLockWaitInterval = 20 * time.Millisecond
+)
+
+// LockPolicy 读穿缓存的击穿保护策略
+// 缓存未命中时只有拿到分布式锁（TTL）的请求查询数据库并回填，
+// 其余请求每隔约 WaitInterval（带随机抖动）检查一次缓存，最多 WaitAttempts 次，仍未回填则降级查询数据库
+type LockPolicy struct {
+	TTL          time.Duration // 分布式锁过期时间
+	WaitAttempts int           // 没拿到锁时检查缓存的次数
+	WaitInterval time.Duration // 每次检查前的平均等待间隔（实际等待 ±50% 随机抖动）
+}
+
+// newLockPolicy 根据配置生成击穿保护策略（未配置的项使用默认值）
+func newLockPolicy(cfg *config.RedisConfig) LockPolicy {
+	p := LockPolicy{TTL: defaultLockTTL, WaitAttempts: defaultLockWaitAttempts, WaitInterval: defaultLockWaitInterval}
+	if cfg == nil {
+		return p
+	}
+	if cfg.LockTTL > 0 {
+		p.TTL = cfg.LockTTL
+	}
+	if cfg.LockWaitAttempts > 0 {
+		p.WaitAttempts = cfg.LockWaitAttempts
+	}
+	if cfg.LockWaitInterval > 0 {
+		p.WaitInterval = cfg.LockWaitInterval
+	}
+	return p
+}
+
+// LockPolicy 返回客户端配置的击穿保护策略
+func (c *Client) LockPolicy() LockPolicy {
+	if c == nil {
+		return newLockPolicy(nil)
+	}
+	return c.lock
+}
+
+// LockWait 按客户端配置的策略等待持锁请求回填缓存，见 LockWait
+func (c *Client) LockWait(ctx context.Context, check func() bool) bool {
+	p := c.LockPolicy()
+	return LockWait(ctx, p.WaitAttempts, p.WaitInterval, check)
+}
+
+// LockWait 没拿到锁时等待持锁请求回填缓存
+// 每次等待 interval 加随机抖动（[interval/2, interval*3/2)）后调用一次 check，最多 attempts 次；
+// 抖动让同时没拿到锁的大量请求错开重试时间，不会每隔 interval 整齐地再次冲击 Redis
+// check 返回 true 表示缓存已回填（调用方在 check 中保存读到的值）
+// 返回：
+//   - bool: 缓存已回填返回 true；次数用完或 ctx 被取消返回 false（调用方降级查询数据库或返回 ctx 错误）
+func LockWait(ctx context.Context, attempts int, interval time.Duration, check func() bool) bool {
+	timer := time.NewTimer(jitter(interval))
+	defer timer.Stop()
+	for i := 0; i < attempts; i++ {
+		if i > 0 {
+			timer.Reset(jitter(interval))
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case <-timer.C:
+		}
+		if check() {
+			return true
+		}
+	}
+	return false
+}
+
+// jitter 在 interval 基础上加随机抖动，返回 [interval/2, interval*3/2) 内的随机时长
+func jitter(interval time.Duration) time.Duration {
+	if interval <= 1 {
+		return interval
+	}
+	return interval/2 + time.Duration(rand.Int63n(int64(interval)))
+}
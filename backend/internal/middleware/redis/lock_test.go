@@ -0,0 +1,84 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestJitterRange(t *testing.T) {
+	const interval = 20 * time.Millisecond
+	lo, hi := interval/2, interval*3/2
+
+	seen := make(map[time.Duration]struct{})
+	for i := 0; i < 1000; i++ {
+		d := jitter(interval)
+		if d < lo || d >= hi {
+			t.Fatalf("jitter(%v) = %v, want [%v, %v)", interval, d, lo, hi)
+		}
+		seen[d] = struct{}{}
+	}
+	// 抖动的目的是错开重试时间，采样结果不能全部相同
+	if len(seen) < 2 {
+		t.Fatalf("jitter(%v) returned the same value for all samples", interval)
+	}
+}
+
+func TestJitterTinyInterval(t *testing.T) {
+	for _, d := range []time.Duration{0, 1} {
+		if got := jitter(d); got != d {
+			t.Fatalf("jitter(%v) = %v, want %v", d, got, d)
+		}
+	}
+}
+
+func TestLockWaitWaitsBetweenChecks(t *testing.T) {
+	const (
+		attempts = 5
+		interval = 20 * time.Millisecond
+	)
+
+	var gaps []time.Duration
+	last := time.Now()
+	ok := LockWait(context.Background(), attempts, interval, func() bool {
+		now := time.Now()
+		gaps = append(gaps, now.Sub(last))
+		last = now
+		return false
+	})
+	if ok {
+		t.Fatal("LockWait returned true, want false when check never succeeds")
+	}
+	if len(gaps) != attempts {
+		t.Fatalf("check called %d times, want %d", len(gaps), attempts)
+	}
+	// 只校验下限：上限受调度延迟影响，在繁忙的机器上不稳定
+	for i, gap := range gaps {
+		if gap < interval/2 {
+			t.Fatalf("wait #%d = %v, want >= %v", i, gap, interval/2)
+		}
+	}
+}
+
+func TestLockWaitStopsOnSuccess(t *testing.T) {
+	calls := 0
+	ok := LockWait(context.Background(), 5, time.Millisecond, func() bool {
+		calls++
+		return calls == 2
+	})
+	if !ok || calls != 2 {
+		t.Fatalf("LockWait = %v after %d checks, want true after 2", ok, calls)
+	}
+}
+
+func TestLockWaitContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	ok := LockWait(ctx, 5, time.Second, func() bool {
+		t.Fatal("check called after ctx was canceled")
+		return true
+	})
+	if ok {
+		t.Fatal("LockWait returned true, want false when ctx is canceled")
+	}
+}
@@ -0,0 +1,89 @@
+package redis
+
+import (
+	"context"
+	"sync"
+
+	redis "github.com/redis/go-redis/v9"
+)
+
+// subscribeBuffer 订阅消息通道的缓冲数量
+const subscribeBuffer = 64
+
+// Message 订阅收到的消息
+type Message struct {
+	Channel string // 消息所在频道（模式订阅时为实际频道名）
+	Payload []byte // 消息内容
+}
+
+// Publish 向频道发布消息（没有订阅者时消息直接丢弃）
+func (c *Client) Publish(ctx context.Context, channel string, payload []byte) error {
+	if c == nil || c.rdb == nil {
+		return nil
+	}
+	return c.rdb.Publish(ctx, channel, payload).Err()
+}
+
+// Subscribe 订阅频道
+// 返回：
+//   - <-chan Message: 消息通道，订阅结束（调用取消函数或 ctx 被取消）后关闭
+//   - func(): 取消函数，关闭订阅连接（可重复调用）
+//
+// 订阅独占一个 Redis 连接，连接断开时 go-redis 会自动重连并重新订阅
+// Redis 不可用（c 为 nil）时返回已关闭的通道
+func (c *Client) Subscribe(ctx context.Context, channel string) (<-chan Message, func()) {
+	if c == nil || c.rdb == nil {
+		return closedMessages(), func() {}
+	}
+	return pumpMessages(ctx, c.rdb.Subscribe(ctx, channel))
+}
+
+// PSubscribe 按模式订阅频道（如 "video:live:*"），返回值同 Subscribe
+func (c *Client) PSubscribe(ctx context.Context, pattern string) (<-chan Message, func()) {
+	if c == nil || c.rdb == nil {
+		return closedMessages(), func() {}
+	}
+	return pumpMessages(ctx, c.rdb.PSubscribe(ctx, pattern))
+}
+
+// pumpMessages 把 go-redis 的订阅消息转发到 Message 通道，直到取消或 ctx 结束
+func pumpMessages(ctx context.Context, ps *redis.PubSub) (<-chan Message, func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	var once sync.Once
+	stop := func() {
+		once.Do(func() {
+			cancel()
+			_ = ps.Close()
+		})
+	}
+
+	out := make(chan Message, subscribeBuffer)
+	go func() {
+		defer close(out)
+		defer stop()
+		in := ps.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-in:
+				if !ok {
+					return
+				}
+				select {
+				case out <- Message{Channel: msg.Channel, Payload: []byte(msg.Payload)}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return out, stop
+}
+
+// closedMessages 返回已关闭的消息通道（Redis 不可用时使用）
+func closedMessages() <-chan Message {
+	ch := make(chan Message)
+	close(ch)
+	return ch
+}
@@ -12,7 +12,8 @@ import (
 )
 
 type Client struct {
-	rdb *redis.Client
+	rdb  *redis.Client
+	lock LockPolicy // 读穿缓存的击穿保护策略
 }
 
 func NewFromEnv(cfg *config.RedisConfig) (*Client, error) {
@@ -21,7 +22,7 @@ func NewFromEnv(cfg *config.RedisConfig) (*Client, error) {
 		Password: cfg.Password,
 		DB:       cfg.DB,
 	})
-	return &Client{rdb: rdb}, nil
+	return &Client{rdb: rdb, lock: newLockPolicy(cfg)}, nil
 }
 
 func (c *Client) Close() error {
@@ -0,0 +1,88 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	redis "github.com/redis/go-redis/v9"
+)
+
+const (
+	scanBatchSize      = 200             // SCAN 每轮的 COUNT 提示值
+	maxScanRounds      = 500             // DelByPattern 最多 SCAN 轮数（约 10 万个 key），防止长时间占用连接
+	defaultScanTimeout = 5 * time.Second // ctx 没有截止时间时使用的默认超时
+)
+
+var (
+	ErrPatternTooBroad = errors.New("redis: pattern must start with a literal prefix") // 模式没有固定前缀（如 "*"），会扫描并删除整个库
+	ErrScanLimit       = errors.New("redis: scan round limit reached")                 // 达到 SCAN 轮数上限，仍有未扫描的 key
+)
+
+// DelByPattern 删除匹配模式的所有 key，返回删除数量
+// 防止阻塞 Redis：
+//   - 使用 SCAN 分批遍历（不使用会阻塞整个实例的 KEYS）
+//   - 上一批 key 的 UNLINK 与下一轮 SCAN 在同一个 Pipeline 中发送，每轮只需一次往返；UNLINK 在后台线程释放内存
+//   - 模式必须以固定前缀开头；SCAN 轮数有上限；遵守 ctx 的截止时间（没有截止时间时最多执行 5 秒）
+//
+// 达到轮数上限时返回已删除数量和 ErrScanLimit，超时时返回已删除数量和 ctx 的错误
+func (c *Client) DelByPattern(ctx context.Context, pattern string) (int64, error) {
+	if c == nil || c.rdb == nil {
+		return 0, nil
+	}
+	if pattern == "" || strings.IndexAny(pattern, "*?[\\") == 0 {
+		return 0, ErrPatternTooBroad
+	}
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, defaultScanTimeout)
+		defer cancel()
+	}
+
+	var (
+		cursor  uint64
+		deleted int64
+		pending []string // 上一轮 SCAN 返回、尚未删除的 key
+	)
+	for round := 0; round < maxScanRounds; round++ {
+		if err := ctx.Err(); err != nil {
+			return deleted, err
+		}
+
+		pipe := c.rdb.Pipeline()
+		var unlink *redis.IntCmd
+		if len(pending) > 0 {
+			unlink = pipe.Unlink(ctx, pending...)
+		}
+		scan := pipe.Scan(ctx, cursor, pattern, scanBatchSize)
+		if _, err := pipe.Exec(ctx); err != nil {
+			return deleted, err
+		}
+		if unlink != nil {
+			deleted += unlink.Val()
+		}
+
+		keys, next := scan.Val()
+		pending = keys
+		cursor = next
+		if cursor == 0 {
+			// 遍历结束，删除最后一批
+			if len(pending) > 0 {
+				n, err := c.rdb.Unlink(ctx, pending...).Result()
+				if err != nil {
+					return deleted, err
+				}
+				deleted += n
+			}
+			return deleted, nil
+		}
+	}
+	// 达到轮数上限：删除已扫描到的最后一批，剩余的 key 等待 TTL 过期
+	if len(pending) > 0 {
+		if n, err := c.rdb.Unlink(ctx, pending...).Result(); err == nil {
+			deleted += n
+		}
+	}
+	return deleted, ErrScanLimit
+}
@@ -14,6 +14,23 @@ func (c *Client) ZincrBy(ctx context.Context, key string, member string, score f
 	return c.rdb.ZIncrBy(ctx, key, score, member).Err()
 }
 
+// ZIncrByBatch 在一次 Pipeline 中对同一个 ZSET 的多个成员自增，并设置过期时间
+func (c *Client) ZIncrByBatch(ctx context.Context, key string, increments map[string]float64, ttl time.Duration) error {
+	if c == nil || c.rdb == nil || len(increments) == 0 {
+		return nil
+	}
+	_, err := c.rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for member, score := range increments {
+			pipe.ZIncrBy(ctx, key, score, member)
+		}
+		if ttl > 0 {
+			pipe.Expire(ctx, key, ttl)
+		}
+		return nil
+	})
+	return err
+}
+
 func (c *Client) Expire(ctx context.Context, key string, ttl time.Duration) error {
 	if c == nil || c.rdb == nil {
 		return nil
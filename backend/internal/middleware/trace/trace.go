@@ -0,0 +1,56 @@
+package trace
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HeaderName 请求/响应中携带追踪ID的HTTP头
+const HeaderName = "X-Request-ID"
+
+// ctxKey 上下文中存放追踪ID的键（私有类型，避免与其他包冲突）
+type ctxKey struct{}
+
+// WithID 将追踪ID写入上下文
+func WithID(ctx context.Context, id string) context.Context {
+	if id == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, ctxKey{}, id)
+}
+
+// FromContext 从上下文读取追踪ID（不存在时返回空字符串）
+func FromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(ctxKey{}).(string)
+	return id
+}
+
+// Middleware 为每个请求生成（或沿用客户端传入的）追踪ID
+// 追踪ID会写入请求上下文和响应头，Service 层发送 MQ 消息时会把它作为 CorrelationId 带给 Worker
+// 这样一次用户操作在 API 和 Worker 的日志中可以按同一个ID串起来
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(HeaderName)
+		if id == "" || len(id) > 64 {
+			id = newID()
+		}
+		c.Request = c.Request.WithContext(WithID(c.Request.Context(), id))
+		c.Header(HeaderName, id)
+		c.Next()
+	}
+}
+
+// newID 生成随机追踪ID（16字节=32位十六进制字符串）
+func newID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,52 @@
+package notification
+
+import "time"
+
+// 通知类型
+const (
+	TypeLike    = "like"    // 视频被点赞（TargetID 为视频ID）
+	TypeComment = "comment" // 视频被评论（TargetID 为视频ID）
+	TypeFollow  = "follow"  // 被关注（TargetID 为 0）
+	TypeWelcome = "welcome" // 注册欢迎（系统通知，ActorID 和 TargetID 均为 0）
+)
+
+// Notification 通知实体模型，对应数据库中的notifications表
+// 索引 idx_notification_recipient_id (recipient_id, id)：按接收者游标分页
+// 索引 idx_notification_recipient_read (recipient_id, read)：统计未读数
+// 唯一索引 idx_notification_event_id (event_id)：按事件ID去重，重复消费同一事件只产生一条通知（非事件产生的通知为 NULL）
+type Notification struct {
+	ID          uint      `gorm:"primaryKey;index:idx_notification_recipient_id,priority:2" json:"id"`                                                          // 主键ID
+	RecipientID uint      `gorm:"not null;index:idx_notification_recipient_id,priority:1;index:idx_notification_recipient_read,priority:1" json:"recipient_id"` // 接收者ID（内容所有者）
+	Type        string    `gorm:"type:varchar(16);not null" json:"type"`                                                                                        // 通知类型：like/comment/follow/welcome
+	ActorID     uint      `gorm:"not null" json:"actor_id"`                                                                                                     // 触发者ID（系统通知为 0）
+	TargetID    uint      `gorm:"not null;default:0" json:"target_id"`                                                                                          // 目标ID（视频ID，关注通知为 0）
+	Read        bool      `gorm:"not null;default:false;index:idx_notification_recipient_read,priority:2" json:"read"`                                          // 是否已读
+	EventID     *string   `gorm:"type:varchar(64);uniqueIndex:idx_notification_event_id" json:"-"`                                                              // 产生通知的事件ID（消息队列事件去重用）
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`                                                                                             // 创建时间
+}
+
+// ListRequest 分页查询通知请求体
+type ListRequest struct {
+	Limit      int  `json:"limit"`       // 返回的通知数量（1-50）
+	BeforeID   uint `json:"before_id"`   // 游标：上一页最后一条通知的ID（第一页传 0）
+	UnreadOnly bool `json:"unread_only"` // 是否只返回未读通知
+}
+
+// ListResponse 分页查询通知响应体
+type ListResponse struct {
+	Notifications []Notification `json:"notifications"`  // 通知列表（按ID倒序）
+	NextBeforeID  uint           `json:"next_before_id"` // 游标：用于下一页的通知ID
+	HasMore       bool           `json:"has_more"`       // 是否还有更多数据
+}
+
+// MarkReadRequest 标记已读请求体
+// IDs 为空且 All 为 true 时标记全部已读
+type MarkReadRequest struct {
+	IDs []uint `json:"ids"` // 要标记已读的通知ID列表（最多 100 个）
+	All bool   `json:"all"` // 是否标记全部已读
+}
+
+// UnreadCountResponse 未读数响应体
+type UnreadCountResponse struct {
+	Unread int64 `json:"unread"` // 未读通知数量
+}
@@ -0,0 +1,108 @@
+package notification
+
+import (
+	"net/http"
+
+	"feedsystem_video_go/internal/apierror"
+	"feedsystem_video_go/internal/middleware/jwt"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NotificationHandler 通知处理器，负责处理通知相关的HTTP请求
+type NotificationHandler struct {
+	service *NotificationService // 通知服务层
+}
+
+// notificationErrors 通知服务层错误到错误码/HTTP状态码的映射
+var notificationErrors = []apierror.Mapping{
+	{Err: ErrMarkReadEmpty, Status: http.StatusBadRequest, Code: apierror.CodeInvalidArgument},
+	{Err: ErrTooManyMarkRead, Status: http.StatusBadRequest, Code: apierror.CodeInvalidArgument},
+}
+
+// NewNotificationHandler 创建通知处理器实例
+func NewNotificationHandler(service *NotificationService) *NotificationHandler {
+	return &NotificationHandler{service: service}
+}
+
+// List 分页查询通知接口
+// 路由：POST /notification/list
+// 功能：按通知ID游标分页查询当前用户收到的通知
+// 请求体：{"limit": 20, "before_id": 0, "unread_only": false}
+func (h *NotificationHandler) List(c *gin.Context) {
+	// 1. 解析JSON请求体
+	var req ListRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.BadRequest(c, err)
+		return
+	}
+
+	// 2. 从JWT中间件获取当前登录用户ID
+	accountID, err := jwt.GetAccountID(c)
+	if err != nil {
+		apierror.Unauthorized(c)
+		return
+	}
+
+	// 3. 调用Service层分页查询通知
+	resp, err := h.service.List(c.Request.Context(), accountID, req.BeforeID, req.Limit, req.UnreadOnly)
+	if err != nil {
+		apierror.FromError(c, err, notificationErrors...)
+		return
+	}
+
+	// 4. 返回通知列表及分页信息
+	c.JSON(http.StatusOK, resp)
+}
+
+// UnreadCount 查询未读通知数量接口
+// 路由：POST /notification/unreadCount
+// 功能：查询当前用户的未读通知数量（Redis 缓存）
+func (h *NotificationHandler) UnreadCount(c *gin.Context) {
+	// 1. 从JWT中间件获取当前登录用户ID
+	accountID, err := jwt.GetAccountID(c)
+	if err != nil {
+		apierror.Unauthorized(c)
+		return
+	}
+
+	// 2. 调用Service层查询未读数
+	n, err := h.service.UnreadCount(c.Request.Context(), accountID)
+	if err != nil {
+		apierror.FromError(c, err, notificationErrors...)
+		return
+	}
+
+	// 3. 返回未读数
+	c.JSON(http.StatusOK, UnreadCountResponse{Unread: n})
+}
+
+// MarkRead 标记通知已读接口
+// 路由：POST /notification/markRead
+// 功能：将当前用户的指定通知（或全部通知）标记为已读
+// 请求体：{"ids": [通知ID列表]} 或 {"all": true}
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	// 1. 解析JSON请求体
+	var req MarkReadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.BadRequest(c, err)
+		return
+	}
+
+	// 2. 从JWT中间件获取当前登录用户ID
+	accountID, err := jwt.GetAccountID(c)
+	if err != nil {
+		apierror.Unauthorized(c)
+		return
+	}
+
+	// 3. 调用Service层标记已读
+	n, err := h.service.MarkRead(c.Request.Context(), accountID, req.IDs, req.All)
+	if err != nil {
+		apierror.FromError(c, err, notificationErrors...)
+		return
+	}
+
+	// 4. 返回实际标记的数量
+	c.JSON(http.StatusOK, gin.H{"message": "marked read", "updated": n})
+}
@@ -0,0 +1,98 @@
+package notification
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+)
+
+// NotificationRepository 通知仓储层，负责通知数据库操作
+type NotificationRepository struct {
+	db *gorm.DB // GORM数据库实例
+}
+
+// NewNotificationRepository 创建通知仓储实例
+func NewNotificationRepository(db *gorm.DB) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+// Create 创建通知记录
+func (r *NotificationRepository) Create(ctx context.Context, n *Notification) error {
+	return r.db.WithContext(ctx).Create(n).Error
+}
+
+// CreateIgnoreDuplicate 创建通知记录（事件ID重复时忽略）
+// 同一事件已产生过通知时返回 created=false
+func (r *NotificationRepository) CreateIgnoreDuplicate(ctx context.Context, n *Notification) (created bool, err error) {
+	err = r.db.WithContext(ctx).Create(n).Error
+	if err == nil {
+		return true, nil
+	}
+	// 唯一索引冲突（重复消费同一事件）不算错误
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
+		return false, nil
+	}
+	return false, err
+}
+
+// ExistsByType 判断接收者是否已有指定类型的通知
+func (r *NotificationRepository) ExistsByType(ctx context.Context, recipientID uint, typ string) (bool, error) {
+	var n Notification
+	err := r.db.WithContext(ctx).Select("id").
+		Where("recipient_id = ? AND type = ?", recipientID, typ).
+		Limit(1).Find(&n).Error
+	return n.ID != 0, err
+}
+
+// ListByRecipient 按ID倒序分页查询接收者的通知（游标分页）
+// 参数：
+//   - ctx: 上下文
+//   - recipientID: 接收者ID
+//   - beforeID: 游标，只返回ID小于它的通知（0 表示第一页）
+//   - limit: 返回数量
+//   - unreadOnly: 是否只返回未读通知
+func (r *NotificationRepository) ListByRecipient(ctx context.Context, recipientID, beforeID uint, limit int, unreadOnly bool) ([]Notification, error) {
+	var notifications []Notification
+	query := r.db.WithContext(ctx).Where("recipient_id = ?", recipientID)
+	if beforeID > 0 {
+		query = query.Where("id < ?", beforeID)
+	}
+	if unreadOnly {
+		query = query.Where("`read` = ?", false)
+	}
+	err := query.Order("id desc").Limit(limit).Find(&notifications).Error
+	return notifications, err
+}
+
+// CountUnread 统计接收者的未读通知数量
+func (r *NotificationRepository) CountUnread(ctx context.Context, recipientID uint) (int64, error) {
+	var total int64
+	err := r.db.WithContext(ctx).Model(&Notification{}).
+		Where("recipient_id = ? AND `read` = ?", recipientID, false).
+		Count(&total).Error
+	return total, err
+}
+
+// MarkRead 将接收者的指定通知标记为已读（只会更新属于该接收者的通知）
+// 返回实际更新的数量
+func (r *NotificationRepository) MarkRead(ctx context.Context, recipientID uint, ids []uint) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	result := r.db.WithContext(ctx).Model(&Notification{}).
+		Where("recipient_id = ? AND id IN ? AND `read` = ?", recipientID, ids, false).
+		Update("read", true)
+	return result.RowsAffected, result.Error
+}
+
+// MarkAllRead 将接收者的全部未读通知标记为已读
+// 返回实际更新的数量
+func (r *NotificationRepository) MarkAllRead(ctx context.Context, recipientID uint) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&Notification{}).
+		Where("recipient_id = ? AND `read` = ?", recipientID, false).
+		Update("read", true)
+	return result.RowsAffected, result.Error
+}
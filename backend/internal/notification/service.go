@@ -0,0 +1,209 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	rediscache "feedsystem_video_go/internal/middleware/redis"
+)
+
+// unreadCountTTL 未读数缓存过期时间
+const unreadCountTTL = 10 * time.Minute
+
+// maxMarkReadIDs 单次标记已读的最大通知数量
+const maxMarkReadIDs = 100
+
+var (
+	ErrMarkReadEmpty   = errors.New("ids or all is required")   // 未指定要标记的通知
+	ErrTooManyMarkRead = errors.New("too many ids to mark read") // 单次标记数量超过上限
+)
+
+// NotificationService 通知服务层，处理通知业务逻辑
+// - 通知由 Worker（或 MQ 失败时的 Fallback）在点赞/评论/关注写入成功后创建
+// - 未读数使用 Redis 缓存（key: notification:unread:{recipientID}），通知新增或标记已读时删除缓存
+type NotificationService struct {
+	repo  *NotificationRepository // 通知仓储层
+	cache *rediscache.Client      // Redis缓存客户端（可能为 nil）
+}
+
+// NewNotificationService 创建通知服务实例
+func NewNotificationService(repo *NotificationRepository, cache *rediscache.Client) *NotificationService {
+	return &NotificationService{repo: repo, cache: cache}
+}
+
+// Notify 为内容所有者创建一条通知
+// 自己对自己的操作（如给自己的视频点赞）不产生通知
+// s 为 nil 时不做任何事，调用方不需要判断是否启用了通知
+func (s *NotificationService) Notify(ctx context.Context, recipientID uint, typ string, actorID uint, targetID uint) error {
+	if s == nil || s.repo == nil {
+		return nil
+	}
+	if recipientID == 0 || recipientID == actorID {
+		return nil
+	}
+	n := &Notification{
+		RecipientID: recipientID,
+		Type:        typ,
+		ActorID:     actorID,
+		TargetID:    targetID,
+	}
+	if err := s.repo.Create(ctx, n); err != nil {
+		return err
+	}
+	s.invalidateUnread(ctx, recipientID)
+	return nil
+}
+
+// NotifyEvent 为内容所有者创建一条由消息队列事件产生的通知
+// 按 eventID 去重：重复消费同一事件（如处理后 ACK 失败被重新投递）只产生一条通知
+// eventID 为空时（旧版本生产者的消息）退化为 Notify，不做去重
+func (s *NotificationService) NotifyEvent(ctx context.Context, eventID string, recipientID uint, typ string, actorID uint, targetID uint) error {
+	if eventID == "" {
+		return s.Notify(ctx, recipientID, typ, actorID, targetID)
+	}
+	if s == nil || s.repo == nil {
+		return nil
+	}
+	if recipientID == 0 || recipientID == actorID {
+		return nil
+	}
+	n := &Notification{
+		RecipientID: recipientID,
+		Type:        typ,
+		ActorID:     actorID,
+		TargetID:    targetID,
+		EventID:     &eventID,
+	}
+	created, err := s.repo.CreateIgnoreDuplicate(ctx, n)
+	if err != nil {
+		return err
+	}
+	if created {
+		s.invalidateUnread(ctx, recipientID)
+	}
+	return nil
+}
+
+// Welcome 为新注册的账户创建欢迎通知（系统通知）
+// 账户已有欢迎通知时跳过，重复消费 account.created 事件不会产生多条
+// s 为 nil 时不做任何事
+func (s *NotificationService) Welcome(ctx context.Context, accountID uint) error {
+	if s == nil || s.repo == nil || accountID == 0 {
+		return nil
+	}
+	exists, err := s.repo.ExistsByType(ctx, accountID, TypeWelcome)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+	return s.Notify(ctx, accountID, TypeWelcome, 0, 0)
+}
+
+// List 分页查询当前用户的通知（按ID倒序）
+// 参数：
+//   - ctx: 上下文
+//   - recipientID: 当前用户ID
+//   - beforeID: 游标（0 表示第一页）
+//   - limit: 返回数量（1-50，默认 20）
+//   - unreadOnly: 是否只返回未读通知
+func (s *NotificationService) List(ctx context.Context, recipientID, beforeID uint, limit int, unreadOnly bool) (ListResponse, error) {
+	if limit <= 0 || limit > 50 {
+		limit = 20
+	}
+	// 多查一条用于判断是否还有更多数据
+	notifications, err := s.repo.ListByRecipient(ctx, recipientID, beforeID, limit+1, unreadOnly)
+	if err != nil {
+		return ListResponse{}, err
+	}
+	hasMore := len(notifications) > limit
+	if hasMore {
+		notifications = notifications[:limit]
+	}
+	var nextBeforeID uint
+	if len(notifications) > 0 {
+		nextBeforeID = notifications[len(notifications)-1].ID
+	}
+	if notifications == nil {
+		notifications = []Notification{}
+	}
+	return ListResponse{
+		Notifications: notifications,
+		NextBeforeID:  nextBeforeID,
+		HasMore:       hasMore,
+	}, nil
+}
+
+// UnreadCount 查询当前用户的未读通知数量
+// 优先读 Redis 缓存，未命中时查数据库并回写
+func (s *NotificationService) UnreadCount(ctx context.Context, recipientID uint) (int64, error) {
+	key := unreadKey(recipientID)
+	if s.cache != nil {
+		opCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		b, err := s.cache.GetBytes(opCtx, key)
+		cancel()
+		if err == nil {
+			if n, err := strconv.ParseInt(string(b), 10, 64); err == nil {
+				return n, nil
+			}
+		}
+	}
+
+	n, err := s.repo.CountUnread(ctx, recipientID)
+	if err != nil {
+		return 0, err
+	}
+
+	if s.cache != nil {
+		opCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		_ = s.cache.SetBytes(opCtx, key, []byte(strconv.FormatInt(n, 10)), unreadCountTTL)
+		cancel()
+	}
+	return n, nil
+}
+
+// MarkRead 将当前用户的通知标记为已读
+// all 为 true 时标记全部已读，否则只标记 ids 中属于当前用户的通知
+// 返回实际标记的数量
+func (s *NotificationService) MarkRead(ctx context.Context, recipientID uint, ids []uint, all bool) (int64, error) {
+	var (
+		n   int64
+		err error
+	)
+	switch {
+	case all:
+		n, err = s.repo.MarkAllRead(ctx, recipientID)
+	case len(ids) == 0:
+		return 0, ErrMarkReadEmpty
+	case len(ids) > maxMarkReadIDs:
+		return 0, ErrTooManyMarkRead
+	default:
+		n, err = s.repo.MarkRead(ctx, recipientID, ids)
+	}
+	if err != nil {
+		return 0, err
+	}
+	if n > 0 {
+		s.invalidateUnread(ctx, recipientID)
+	}
+	return n, nil
+}
+
+// invalidateUnread 删除未读数缓存（失败时等待缓存过期）
+func (s *NotificationService) invalidateUnread(ctx context.Context, recipientID uint) {
+	if s.cache == nil {
+		return
+	}
+	opCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	_ = s.cache.Del(opCtx, unreadKey(recipientID))
+	cancel()
+}
+
+// unreadKey 未读数缓存 key
+func unreadKey(recipientID uint) string {
+	return fmt.Sprintf("notification:unread:%d", recipientID)
+}
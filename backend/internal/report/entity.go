@@ -0,0 +1,53 @@
+package report
+
+import "time"
+
+// 举报目标类型
+const (
+	TargetVideo   = "video"   // 视频
+	TargetComment = "comment" // 评论
+)
+
+// 举报状态
+const (
+	StatusOpen     = "open"     // 待处理
+	StatusResolved = "resolved" // 已处理
+)
+
+// Report 举报实体模型，对应数据库中的reports表
+// 同一用户对同一目标只能有一条待处理的举报：Service 层先查询，并发提交由唯一索引 idx_report_open_target 兜底
+type Report struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`                                                                       // 主键ID
+	ReporterID uint      `gorm:"not null;uniqueIndex:idx_report_open_target,priority:1" json:"reporter_id"`                  // 举报人ID
+	TargetType string    `gorm:"type:varchar(16);not null;uniqueIndex:idx_report_open_target,priority:2" json:"target_type"` // 目标类型：video/comment
+	TargetID   uint      `gorm:"not null;uniqueIndex:idx_report_open_target,priority:3" json:"target_id"`                    // 目标ID
+	Reason     string    `gorm:"type:varchar(512);not null" json:"reason"`                                                   // 举报原因
+	Status     string    `gorm:"type:varchar(16);not null;default:open;index" json:"status"`                                 // 处理状态：open/resolved
+	CreatedAt  time.Time `gorm:"autoCreateTime" json:"created_at"`                                                           // 举报时间
+
+	// 待处理标记：待处理时为 true，处理后必须置为 NULL（唯一索引不约束 NULL，同一目标可以有多条已处理的举报）
+	OpenMarker *bool `gorm:"uniqueIndex:idx_report_open_target,priority:4" json:"-"`
+}
+
+// CreateReportRequest 举报请求体
+type CreateReportRequest struct {
+	TargetType string `json:"target_type"` // 目标类型：video/comment
+	TargetID   uint   `json:"target_id"`   // 目标ID
+	Reason     string `json:"reason"`      // 举报原因
+}
+
+// ListReportsRequest 查询举报列表请求体（管理员）
+type ListReportsRequest struct {
+	Page       int    `json:"page"`        // 页码（从 1 开始）
+	PageSize   int    `json:"page_size"`   // 每页数量（1-100）
+	Status     string `json:"status"`      // 状态过滤（可选，默认 open）
+	TargetType string `json:"target_type"` // 目标类型过滤（可选）
+}
+
+// ListReportsResponse 查询举报列表响应体
+type ListReportsResponse struct {
+	Reports []Report `json:"reports"`  // 举报列表
+	Total   int64    `json:"total"`    // 符合条件的总数
+	Page    int      `json:"page"`     // 当前页码
+	HasMore bool     `json:"has_more"` // 是否还有更多数据
+}
@@ -0,0 +1,88 @@
+package report
+
+import (
+	"feedsystem_video_go/internal/apierror"
+	"feedsystem_video_go/internal/middleware/jwt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReportHandler 举报处理器，负责处理举报相关的HTTP请求
+type ReportHandler struct {
+	service *ReportService // 举报服务层
+}
+
+// reportErrors 举报服务层错误到错误码/HTTP状态码的映射
+var reportErrors = []apierror.Mapping{
+	{Err: ErrInvalidTargetType, Status: http.StatusBadRequest, Code: "INVALID_TARGET_TYPE"},
+	{Err: ErrTargetRequired, Status: http.StatusBadRequest, Code: apierror.CodeInvalidArgument},
+	{Err: ErrReasonRequired, Status: http.StatusBadRequest, Code: "REASON_REQUIRED"},
+	{Err: ErrReasonTooLong, Status: http.StatusBadRequest, Code: "REASON_TOO_LONG"},
+	{Err: ErrTargetNotFound, Status: http.StatusNotFound, Code: "TARGET_NOT_FOUND"},
+	{Err: ErrAlreadyReported, Status: http.StatusConflict, Code: "ALREADY_REPORTED"},
+	{Err: ErrTooManyReports, Status: http.StatusTooManyRequests, Code: apierror.CodeRateLimited},
+}
+
+// NewReportHandler 创建举报处理器实例
+func NewReportHandler(service *ReportService) *ReportHandler {
+	return &ReportHandler{service: service}
+}
+
+// CreateReport 举报接口
+// 路由：POST /report
+// 功能：用户举报违规视频或评论
+// 请求体：{"target_type": "video", "target_id": 1, "reason": "举报原因"}
+func (h *ReportHandler) CreateReport(c *gin.Context) {
+	// 1. 解析JSON请求体
+	var req CreateReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.BadRequest(c, err)
+		return
+	}
+
+	// 2. 从JWT中间件获取当前登录用户ID（举报人）
+	reporterID, err := jwt.GetAccountID(c)
+	if err != nil {
+		apierror.Unauthorized(c)
+		return
+	}
+
+	// 3. 调用Service层提交举报（含去重和限流）
+	report := &Report{
+		ReporterID: reporterID,
+		TargetType: req.TargetType,
+		TargetID:   req.TargetID,
+		Reason:     req.Reason,
+	}
+	if err := h.service.Create(c.Request.Context(), report); err != nil {
+		apierror.FromError(c, err, reportErrors...)
+		return
+	}
+
+	// 4. 返回举报记录
+	c.JSON(http.StatusOK, report)
+}
+
+// ListReports 查询举报列表接口（管理员）
+// 路由：POST /admin/reports/list
+// 功能：分页查询举报，供审核人员处理
+// 请求体：{"page": 1, "page_size": 20, "status": "open", "target_type": "video"}
+func (h *ReportHandler) ListReports(c *gin.Context) {
+	// 1. 解析JSON请求体
+	var req ListReportsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.BadRequest(c, err)
+		return
+	}
+
+	// 2. 调用Service层查询举报列表
+	resp, err := h.service.List(c.Request.Context(), req)
+	if err != nil {
+		apierror.FromError(c, err)
+		return
+	}
+
+	// 3. 返回举报列表
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,100 @@
+package report
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ReportRepository 举报仓储层，负责举报相关数据库操作
+type ReportRepository struct {
+	db *gorm.DB // GORM数据库实例
+}
+
+// NewReportRepository 创建举报仓储实例
+func NewReportRepository(db *gorm.DB) *ReportRepository {
+	return &ReportRepository{db: db}
+}
+
+// Create 添加举报记录
+// 参数：
+//   - ctx: 上下文
+//   - report: 举报对象
+func (r *ReportRepository) Create(ctx context.Context, report *Report) error {
+	return r.db.WithContext(ctx).Create(report).Error
+}
+
+// HasOpenReport 查询用户是否已对目标提交过待处理的举报
+// 参数：
+//   - ctx: 上下文
+//   - reporterID: 举报人ID
+//   - targetType: 目标类型
+//   - targetID: 目标ID
+//
+// 返回：
+//   - bool: 是否存在待处理举报
+//   - error: 错误信息
+func (r *ReportRepository) HasOpenReport(ctx context.Context, reporterID uint, targetType string, targetID uint) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&Report{}).
+		Where("reporter_id = ? AND target_type = ? AND target_id = ? AND status = ?", reporterID, targetType, targetID, StatusOpen).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// CountSince 统计用户在指定时间之后提交的举报数（Redis 不可用时的限流兜底）
+// 参数：
+//   - ctx: 上下文
+//   - reporterID: 举报人ID
+//   - since: 起始时间
+//
+// 返回：
+//   - int64: 举报数
+//   - error: 错误信息
+func (r *ReportRepository) CountSince(ctx context.Context, reporterID uint, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&Report{}).
+		Where("reporter_id = ? AND created_at >= ?", reporterID, since).
+		Count(&count).Error
+	return count, err
+}
+
+// List 分页查询举报列表（按举报时间倒序）
+// 参数：
+//   - ctx: 上下文
+//   - status: 状态过滤（空字符串表示不过滤）
+//   - targetType: 目标类型过滤（空字符串表示不过滤）
+//   - offset: 偏移量
+//   - limit: 每页数量
+//
+// 返回：
+//   - []Report: 举报列表
+//   - int64: 符合条件的总数
+//   - error: 错误信息
+func (r *ReportRepository) List(ctx context.Context, status, targetType string, offset, limit int) ([]Report, int64, error) {
+	query := r.db.WithContext(ctx).Model(&Report{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if targetType != "" {
+		query = query.Where("target_type = ?", targetType)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var reports []Report
+	if err := query.Order("created_at desc, id desc").
+		Offset(offset).
+		Limit(limit).
+		Find(&reports).Error; err != nil {
+		return nil, 0, err
+	}
+	return reports, total, nil
+}
@@ -0,0 +1,183 @@
+package report
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"feedsystem_video_go/internal/config"
+	rediscache "feedsystem_video_go/internal/middleware/redis"
+	"feedsystem_video_go/internal/video"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// 举报限流：每个用户每小时最多提交 report.rate_limit 条举报（默认 10，支持 SIGHUP 热更新）
+const (
+	reportRateWindow = time.Hour
+	maxReasonLength  = 512
+)
+
+var (
+	ErrInvalidTargetType = errors.New("target_type must be video or comment") // 目标类型不合法
+	ErrTargetRequired    = errors.New("target_id is required")                // 缺少目标ID
+	ErrReasonRequired    = errors.New("reason is required")                   // 缺少举报原因
+	ErrReasonTooLong     = errors.New("reason is too long")                   // 举报原因过长
+	ErrTargetNotFound    = errors.New("report target not found")              // 目标不存在
+	ErrAlreadyReported   = errors.New("already reported")                     // 已有待处理的举报
+	ErrTooManyReports    = errors.New("too many reports, try again later")    // 触发限流
+)
+
+// ReportService 举报服务层，处理举报业务逻辑
+type ReportService struct {
+	repo        *ReportRepository        // 举报仓储层
+	videoRepo   *video.VideoRepository   // 视频仓储层，校验视频是否存在
+	commentRepo *video.CommentRepository // 评论仓储层，校验评论是否存在
+	cache       *rediscache.Client       // Redis缓存客户端，用于限流计数
+	live        *config.Live             // 运行时配置（限流阈值）
+}
+
+// NewReportService 创建举报服务实例
+func NewReportService(repo *ReportRepository, videoRepo *video.VideoRepository, commentRepo *video.CommentRepository, cache *rediscache.Client, live *config.Live) *ReportService {
+	return &ReportService{repo: repo, videoRepo: videoRepo, commentRepo: commentRepo, cache: cache, live: live}
+}
+
+// Create 提交举报
+// 业务流程：
+// 1. 校验参数（目标类型、目标ID、举报原因）
+// 2. 校验举报目标是否存在
+// 3. 去重：同一用户对同一目标只能有一条待处理举报
+// 4. 限流：每个用户每小时最多提交 report.rate_limit 条举报（默认 10）
+// 5. 写入数据库
+// 参数：
+//   - ctx: 上下文
+//   - report: 举报对象
+func (s *ReportService) Create(ctx context.Context, report *Report) error {
+	// 1. 校验参数
+	if report == nil {
+		return errors.New("report is nil")
+	}
+	report.TargetType = strings.ToLower(strings.TrimSpace(report.TargetType))
+	report.Reason = strings.TrimSpace(report.Reason)
+	if report.TargetType != TargetVideo && report.TargetType != TargetComment {
+		return ErrInvalidTargetType
+	}
+	if report.TargetID == 0 {
+		return ErrTargetRequired
+	}
+	if report.Reason == "" {
+		return ErrReasonRequired
+	}
+	if len([]rune(report.Reason)) > maxReasonLength {
+		return ErrReasonTooLong
+	}
+
+	// 2. 校验举报目标是否存在
+	exists, err := s.targetExists(ctx, report.TargetType, report.TargetID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrTargetNotFound
+	}
+
+	// 3. 去重
+	reported, err := s.repo.HasOpenReport(ctx, report.ReporterID, report.TargetType, report.TargetID)
+	if err != nil {
+		return err
+	}
+	if reported {
+		return ErrAlreadyReported
+	}
+
+	// 4. 限流
+	if err := s.checkRateLimit(ctx, report.ReporterID); err != nil {
+		return err
+	}
+
+	// 5. 写入数据库（并发提交时第 3 步可能都没有查到，由唯一索引拒绝重复的待处理举报）
+	open := true
+	report.Status = StatusOpen
+	report.OpenMarker = &open
+	if err := s.repo.Create(ctx, report); err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
+			return ErrAlreadyReported
+		}
+		return err
+	}
+	return nil
+}
+
+// List 分页查询举报列表（管理员）
+// 参数：
+//   - ctx: 上下文
+//   - req: 查询条件（页码、每页数量、状态、目标类型）
+//
+// 返回：
+//   - ListReportsResponse: 举报列表及分页信息
+//   - error: 错误信息
+func (s *ReportService) List(ctx context.Context, req ListReportsRequest) (ListReportsResponse, error) {
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 || req.PageSize > 100 {
+		req.PageSize = 20
+	}
+	if req.Status == "" {
+		req.Status = StatusOpen
+	}
+	reports, total, err := s.repo.List(ctx, req.Status, req.TargetType, (req.Page-1)*req.PageSize, req.PageSize)
+	if err != nil {
+		return ListReportsResponse{}, err
+	}
+	return ListReportsResponse{
+		Reports: reports,
+		Total:   total,
+		Page:    req.Page,
+		HasMore: int64(req.Page*req.PageSize) < total,
+	}, nil
+}
+
+// targetExists 校验举报目标是否存在
+func (s *ReportService) targetExists(ctx context.Context, targetType string, targetID uint) (bool, error) {
+	switch targetType {
+	case TargetVideo:
+		return s.videoRepo.IsExist(ctx, targetID)
+	case TargetComment:
+		return s.commentRepo.IsExist(ctx, targetID)
+	default:
+		return false, ErrInvalidTargetType
+	}
+}
+
+// checkRateLimit 举报限流
+// 优先使用 Redis 固定窗口计数；Redis 不可用时按数据库最近一小时的举报数兜底
+func (s *ReportService) checkRateLimit(ctx context.Context, reporterID uint) error {
+	limit := int64(s.live.Tunables().ReportRateLimit)
+	if s.cache != nil {
+		opCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+		key := fmt.Sprintf("report:rate:account=%d", reporterID)
+		n, err := s.cache.IncrWithTTL(opCtx, key, reportRateWindow)
+		if err == nil {
+			if n > limit {
+				return ErrTooManyReports
+			}
+			return nil
+		}
+		log.Printf("report rate limit: redis unavailable, fallback to db: %v", err)
+	}
+
+	count, err := s.repo.CountSince(ctx, reporterID, time.Now().Add(-reportRateWindow))
+	if err != nil {
+		return err
+	}
+	if count >= limit {
+		return ErrTooManyReports
+	}
+	return nil
+}
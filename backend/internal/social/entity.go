@@ -10,14 +10,21 @@ type Social struct {
 	VloggerID  uint `gorm:"not null;index:idx_social_vlogger;uniqueIndex:idx_social_follower_vlogger"`  // 被关注者（博主）ID（带索引，联合唯一索引）
 }
 
+// FollowAccount 关注/粉丝列表中的账户项
+// IsFollowing 表示当前查看者是否已关注该账户（用于展示“回关”按钮）
+type FollowAccount struct {
+	*account.Account
+	IsFollowing bool `json:"is_following"` // 当前用户是否已关注该账户
+}
+
 // FollowRequest 关注请求体
 type FollowRequest struct {
-	VloggerID uint `json:"vlogger_id"` // 博主ID
+	VloggerID uint `json:"vlogger_id" binding:"required,gt=0"` // 博主ID
 }
 
 // UnfollowRequest 取消关注请求体
 type UnfollowRequest struct {
-	VloggerID uint `json:"vlogger_id"` // 博主ID
+	VloggerID uint `json:"vlogger_id" binding:"required,gt=0"` // 博主ID
 }
 
 // GetAllFollowersRequest 查询粉丝列表请求体
@@ -27,7 +34,7 @@ type GetAllFollowersRequest struct {
 
 // GetAllFollowersResponse 查询粉丝列表响应体
 type GetAllFollowersResponse struct {
-	Followers []*account.Account `json:"followers"` // 粉丝列表
+	Followers []*FollowAccount `json:"followers"` // 粉丝列表
 }
 
 // GetAllVloggersRequest 查询关注列表请求体
@@ -37,5 +44,5 @@ type GetAllVloggersRequest struct {
 
 // GetAllVloggersResponse 查询关注列表响应体
 type GetAllVloggersResponse struct {
-	Vloggers []*account.Account `json:"vloggers"` // 关注的博主列表
+	Vloggers []*FollowAccount `json:"vloggers"` // 关注的博主列表
 }
@@ -1,6 +1,8 @@
 package social
 
 import (
+	"feedsystem_video_go/internal/account"
+	"feedsystem_video_go/internal/apierror"
 	"feedsystem_video_go/internal/middleware/jwt"
 	"net/http"
 
@@ -12,6 +14,14 @@ type SocialHandler struct {
 	service *SocialService // 关注服务层
 }
 
+// socialErrors 关注服务层错误到错误码/HTTP状态码的映射
+var socialErrors = []apierror.Mapping{
+	account.NotFoundMapping,
+	{Err: ErrFollowSelf, Status: http.StatusBadRequest, Code: "CANNOT_FOLLOW_SELF"},
+	{Err: ErrAlreadyFollowed, Status: http.StatusConflict, Code: "ALREADY_FOLLOWED"},
+	{Err: ErrNotFollowed, Status: http.StatusConflict, Code: "NOT_FOLLOWED"},
+}
+
 // NewSocialHandler 创建关注处理器实例
 func NewSocialHandler(service *SocialService) *SocialHandler {
 	return &SocialHandler{service: service}
@@ -25,36 +35,30 @@ func (h *SocialHandler) Follow(c *gin.Context) {
 	// 1. 解析JSON请求体
 	var req FollowRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	// 2. 校验博主ID
-	if req.VloggerID <= 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "vlogger_id is required"})
+		apierror.BadRequest(c, err)
 		return
 	}
 
-	// 3. 从JWT中间件获取当前登录用户ID（关注者ID）
+	// 2. 从JWT中间件获取当前登录用户ID（关注者ID）
 	FollowerID, err := jwt.GetAccountID(c)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		apierror.Unauthorized(c)
 		return
 	}
 
-	// 4. 构造关注对象
+	// 3. 构造关注对象
 	social := &Social{
 		FollowerID: FollowerID, // 关注者ID
 		VloggerID:  req.VloggerID, // 被关注者（博主）ID
 	}
 
-	// 5. 调用Service层处理关注（含MQ异步处理）
+	// 4. 调用Service层处理关注（含MQ异步处理）
 	if err := h.service.Follow(c.Request.Context(), social); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.FromError(c, err, socialErrors...)
 		return
 	}
 
-	// 6. 返回成功消息
+	// 5. 返回成功消息
 	c.JSON(http.StatusOK, gin.H{"message": "followed"})
 }
 
@@ -66,36 +70,30 @@ func (h *SocialHandler) Unfollow(c *gin.Context) {
 	// 1. 解析JSON请求体
 	var req UnfollowRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	// 2. 校验博主ID
-	if req.VloggerID <= 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "vlogger_id is required"})
+		apierror.BadRequest(c, err)
 		return
 	}
 
-	// 3. 从JWT中间件获取当前登录用户ID（关注者ID）
+	// 2. 从JWT中间件获取当前登录用户ID（关注者ID）
 	FollowerID, err := jwt.GetAccountID(c)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		apierror.Unauthorized(c)
 		return
 	}
 
-	// 4. 构造关注对象
+	// 3. 构造关注对象
 	social := &Social{
 		FollowerID: FollowerID, // 关注者ID
 		VloggerID:  req.VloggerID, // 被关注者（博主）ID
 	}
 
-	// 5. 调用Service层处理取消关注（含MQ异步处理）
+	// 4. 调用Service层处理取消关注（含MQ异步处理）
 	if err := h.service.Unfollow(c.Request.Context(), social); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.FromError(c, err, socialErrors...)
 		return
 	}
 
-	// 6. 返回成功消息
+	// 5. 返回成功消息
 	c.JSON(http.StatusOK, gin.H{"message": "unfollowed"})
 }
 
@@ -107,29 +105,31 @@ func (h *SocialHandler) GetAllFollowers(c *gin.Context) {
 	// 1. 解析JSON请求体
 	var req GetAllFollowersRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.BadRequest(c, err)
 		return
 	}
 
-	// 2. 获取博主ID（如果请求体未指定，则使用当前登录用户ID）
+	// 2. 从JWT中间件获取当前登录用户ID（查看者）
+	viewerID, err := jwt.GetAccountID(c)
+	if err != nil {
+		apierror.Unauthorized(c)
+		return
+	}
+
+	// 3. 获取博主ID（如果请求体未指定，则使用当前登录用户ID）
 	vloggerID := req.VloggerID
 	if vloggerID == 0 {
-		accountID, err := jwt.GetAccountID(c)
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
-			return
-		}
-		vloggerID = accountID
+		vloggerID = viewerID
 	}
 
-	// 3. 调用Service层查询粉丝列表
-	followers, err := h.service.GetAllFollowers(c.Request.Context(), vloggerID)
+	// 4. 调用Service层查询粉丝列表（含查看者是否已关注每个粉丝）
+	followers, err := h.service.GetAllFollowers(c.Request.Context(), vloggerID, viewerID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.FromError(c, err, socialErrors...)
 		return
 	}
 
-	// 4. 返回粉丝列表
+	// 5. 返回粉丝列表
 	c.JSON(http.StatusOK, GetAllFollowersResponse{Followers: followers})
 }
 
@@ -141,28 +141,30 @@ func (h *SocialHandler) GetAllVloggers(c *gin.Context) {
 	// 1. 解析JSON请求体
 	var req GetAllVloggersRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.BadRequest(c, err)
+		return
+	}
+
+	// 2. 从JWT中间件获取当前登录用户ID（查看者）
+	viewerID, err := jwt.GetAccountID(c)
+	if err != nil {
+		apierror.Unauthorized(c)
 		return
 	}
 
-	// 2. 获取关注者ID（如果请求体未指定，则使用当前登录用户ID）
+	// 3. 获取关注者ID（如果请求体未指定，则使用当前登录用户ID）
 	followerID := req.FollowerID
 	if followerID == 0 {
-		accountID, err := jwt.GetAccountID(c)
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
-			return
-		}
-		followerID = accountID
+		followerID = viewerID
 	}
 
-	// 3. 调用Service层查询关注列表
-	vloggers, err := h.service.GetAllVloggers(c.Request.Context(), followerID)
+	// 4. 调用Service层查询关注列表（含查看者是否已关注每个博主）
+	vloggers, err := h.service.GetAllVloggers(c.Request.Context(), followerID, viewerID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.FromError(c, err, socialErrors...)
 		return
 	}
 
-	// 4. 返回关注列表
+	// 5. 返回关注列表
 	c.JSON(http.StatusOK, GetAllVloggersResponse{Vloggers: vloggers})
 }
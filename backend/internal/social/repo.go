@@ -2,8 +2,11 @@ package social
 
 import (
 	"context"
+	"errors"
 	"feedsystem_video_go/internal/account"
+	"log"
 
+	"github.com/go-sql-driver/mysql"
 	"gorm.io/gorm"
 )
 
@@ -35,6 +38,123 @@ func (r *SocialRepository) Unfollow(ctx context.Context, social *Social) error {
 		Delete(&Social{}).Error
 }
 
+// FollowWithCounts 添加关注记录并维护双方的粉丝数/关注数（同一事务）
+// 重复关注（唯一索引冲突）返回 created=false，不修改计数
+// 参数：
+//   - ctx: 上下文
+//   - social: 关注对象
+// 返回：
+//   - bool: 是否创建了新记录
+//   - error: 错误信息
+func (r *SocialRepository) FollowWithCounts(ctx context.Context, social *Social) (created bool, err error) {
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(social).Error; err != nil {
+			return err
+		}
+		// 博主粉丝数+1
+		if err := tx.Model(&account.Account{}).Where("id = ?", social.VloggerID).
+			UpdateColumn("followers_count", gorm.Expr("followers_count + 1")).Error; err != nil {
+			return err
+		}
+		// 关注者关注数+1
+		return tx.Model(&account.Account{}).Where("id = ?", social.FollowerID).
+			UpdateColumn("following_count", gorm.Expr("following_count + 1")).Error
+	})
+	if err == nil {
+		return true, nil
+	}
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
+		return false, nil
+	}
+	return false, err
+}
+
+// UnfollowWithCounts 删除关注记录并维护双方的粉丝数/关注数（同一事务）
+// 关注记录不存在时返回 deleted=false，不修改计数
+// 参数：
+//   - ctx: 上下文
+//   - social: 关注对象
+// 返回：
+//   - bool: 是否删除了记录
+//   - error: 错误信息
+func (r *SocialRepository) UnfollowWithCounts(ctx context.Context, social *Social) (deleted bool, err error) {
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		del := tx.Where("follower_id = ? AND vlogger_id = ?", social.FollowerID, social.VloggerID).Delete(&Social{})
+		if del.Error != nil {
+			return del.Error
+		}
+		if del.RowsAffected == 0 {
+			return nil
+		}
+		deleted = true
+		// 博主粉丝数-1（确保不小于0）
+		if err := tx.Model(&account.Account{}).Where("id = ?", social.VloggerID).
+			UpdateColumn("followers_count", gorm.Expr("GREATEST(followers_count - 1, 0)")).Error; err != nil {
+			return err
+		}
+		// 关注者关注数-1（确保不小于0）
+		return tx.Model(&account.Account{}).Where("id = ?", social.FollowerID).
+			UpdateColumn("following_count", gorm.Expr("GREATEST(following_count - 1, 0)")).Error
+	})
+	if err != nil {
+		return false, err
+	}
+	return deleted, nil
+}
+
+// followCountsDryRunSamples dry run 时最多记录的差异账户数
+const followCountsDryRunSamples = 20
+
+// ReconcileFollowCounts 根据关注关系表重新计算所有账户的粉丝数/关注数
+// 用于上线冗余计数字段后的一次性回填，或计数漂移后的修复
+// dryRun 为 true 时只统计计数不一致的账户数并记录前 20 条差异，不写入数据库
+// 返回：
+//   - int64: 计数发生变化（dry run 时为需要修正）的账户数
+//   - error: 错误信息
+func (r *SocialRepository) ReconcileFollowCounts(ctx context.Context, dryRun bool) (int64, error) {
+	if dryRun {
+		return r.previewFollowCounts(ctx)
+	}
+	res := r.db.WithContext(ctx).Exec(`
+UPDATE accounts a
+LEFT JOIN (SELECT vlogger_id, COUNT(*) AS cnt FROM socials GROUP BY vlogger_id) f ON f.vlogger_id = a.id
+LEFT JOIN (SELECT follower_id, COUNT(*) AS cnt FROM socials GROUP BY follower_id) g ON g.follower_id = a.id
+SET a.followers_count = COALESCE(f.cnt, 0),
+    a.following_count = COALESCE(g.cnt, 0)`)
+	return res.RowsAffected, res.Error
+}
+
+// previewFollowCounts 统计粉丝数/关注数与关注关系表不一致的账户（ReconcileFollowCounts 的 dry run）
+func (r *SocialRepository) previewFollowCounts(ctx context.Context) (int64, error) {
+	var diffs []struct {
+		ID             uint
+		FollowersCount int64
+		FollowingCount int64
+		Followers      int64
+		Following      int64
+	}
+	if err := r.db.WithContext(ctx).Raw(`
+SELECT a.id, a.followers_count, a.following_count,
+       COALESCE(f.cnt, 0) AS followers, COALESCE(g.cnt, 0) AS following
+FROM accounts a
+LEFT JOIN (SELECT vlogger_id, COUNT(*) AS cnt FROM socials GROUP BY vlogger_id) f ON f.vlogger_id = a.id
+LEFT JOIN (SELECT follower_id, COUNT(*) AS cnt FROM socials GROUP BY follower_id) g ON g.follower_id = a.id
+WHERE a.followers_count <> COALESCE(f.cnt, 0) OR a.following_count <> COALESCE(g.cnt, 0)`).
+		Scan(&diffs).Error; err != nil {
+		return 0, err
+	}
+	for i, d := range diffs {
+		if i == followCountsDryRunSamples {
+			log.Printf("reconcile follow counts (dry run): ... and %d more accounts", len(diffs)-i)
+			break
+		}
+		log.Printf("reconcile follow counts (dry run): account %d followers %d -> %d, following %d -> %d",
+			d.ID, d.FollowersCount, d.Followers, d.FollowingCount, d.Following)
+	}
+	return int64(len(diffs)), nil
+}
+
 // GetAllFollowers 查询指定博主的所有粉丝
 // 使用两次查询：
 // 1. 查询关注关系表，获取粉丝ID列表
@@ -133,3 +253,32 @@ func (r *SocialRepository) IsFollowed(ctx context.Context, social *Social) (bool
 	}
 	return count > 0, nil
 }
+
+// BatchIsFollowed 批量查询查看者是否已关注目标账户（用于粉丝/关注列表的“回关”标记）
+// 参数：
+//   - ctx: 上下文
+//   - viewerID: 查看者（关注者）ID
+//   - targetIDs: 目标账户ID列表
+// 返回：
+//   - map[uint]bool: targetID -> 是否已关注
+//   - error: 错误信息
+func (r *SocialRepository) BatchIsFollowed(ctx context.Context, viewerID uint, targetIDs []uint) (map[uint]bool, error) {
+	followMap := make(map[uint]bool)
+	if len(targetIDs) == 0 {
+		return followMap, nil
+	}
+	if viewerID == 0 {
+		return followMap, nil
+	}
+	var relations []Social
+	err := r.db.WithContext(ctx).Model(&Social{}).
+		Where("follower_id = ? AND vlogger_id IN ?", viewerID, targetIDs).
+		Find(&relations).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, rel := range relations {
+		followMap[rel.VloggerID] = true
+	}
+	return followMap, nil
+}
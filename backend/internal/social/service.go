@@ -4,7 +4,12 @@ import (
 	"context"
 	"errors"
 	"feedsystem_video_go/internal/account"
+	"feedsystem_video_go/internal/invalidation"
 	"feedsystem_video_go/internal/middleware/rabbitmq"
+	rediscache "feedsystem_video_go/internal/middleware/redis"
+	"feedsystem_video_go/internal/notification"
+	"feedsystem_video_go/internal/video"
+	"log"
 )
 
 // SocialService 关注服务层，处理关注业务逻辑
@@ -13,12 +18,21 @@ import (
 type SocialService struct {
 	repo        *SocialRepository          // 关注仓储层，负责数据库操作
 	accountrepo *account.AccountRepository // 账户仓储层，校验账户是否存在
+	videoRepo   *video.VideoRepository     // 视频仓储层，Fallback 时更新博主最新视频热度
+	cache       *rediscache.Client         // Redis缓存客户端（可能为 nil）
 	socialMQ    *rabbitmq.SocialMQ         // 关注消息队列，异步处理关注事件
+	notifier    *notification.NotificationService // 通知服务层，Fallback 时通知被关注者（可能为 nil）
 }
 
+var (
+	ErrFollowSelf      = errors.New("can not follow self") // 不能关注自己
+	ErrAlreadyFollowed = errors.New("already followed")    // 已关注
+	ErrNotFollowed     = errors.New("not followed")        // 未关注
+)
+
 // NewSocialService 创建关注服务实例
-func NewSocialService(repo *SocialRepository, accountrepo *account.AccountRepository, socialMQ *rabbitmq.SocialMQ) *SocialService {
-	return &SocialService{repo: repo, accountrepo: accountrepo, socialMQ: socialMQ}
+func NewSocialService(repo *SocialRepository, accountrepo *account.AccountRepository, videoRepo *video.VideoRepository, cache *rediscache.Client, socialMQ *rabbitmq.SocialMQ, notifier *notification.NotificationService) *SocialService {
+	return &SocialService{repo: repo, accountrepo: accountrepo, videoRepo: videoRepo, cache: cache, socialMQ: socialMQ, notifier: notifier}
 }
 
 // Follow 关注博主
@@ -27,7 +41,7 @@ func NewSocialService(repo *SocialRepository, accountrepo *account.AccountReposi
 // 2. 防止自己关注自己
 // 3. 校验是否已关注（防止重复关注）
 // 4. 发送关注事件到MQ（Worker异步处理：插入关注记录、更新粉丝数、更新视频热度）
-// 5. MQ失败时Fallback：直接写入数据库
+// 5. MQ失败时Fallback：直接写入数据库，并更新博主最新视频热度
 // 参数：
 //   - ctx: 上下文
 //   - social: 关注对象
@@ -46,7 +60,7 @@ func (s *SocialService) Follow(ctx context.Context, social *Social) error {
 
 	// 3. 防止自己关注自己
 	if social.FollowerID == social.VloggerID {
-		return errors.New("can not follow self")
+		return ErrFollowSelf
 	}
 
 	// 4. 校验是否已关注（防止重复关注）
@@ -55,16 +69,39 @@ func (s *SocialService) Follow(ctx context.Context, social *Social) error {
 		return err
 	}
 	if isFollowed {
-		return errors.New("already followed")
+		return ErrAlreadyFollowed
 	}
 
-	// 5. 发送关注事件到MQ（Worker异步处理）
+	// 5. 发送关注事件到MQ（Worker异步处理），发送成功直接返回
 	if s.socialMQ != nil {
-		s.socialMQ.Follow(ctx, social.FollowerID, social.VloggerID)
+		if err := s.socialMQ.Follow(ctx, social.FollowerID, social.VloggerID); err == nil {
+			return nil
+		}
+	}
+
+	// 6. Fallback: MQ发送失败时，直接写入数据库（关注记录 + 粉丝数/关注数，同一事务）
+	created, err := s.repo.FollowWithCounts(ctx, social)
+	if err != nil {
+		return err
+	}
+	if !created {
+		return ErrAlreadyFollowed
+	}
+	// 粉丝数/关注数已变化，删除双方的公开资料缓存
+	account.InvalidateProfileCache(ctx, s.cache, social.FollowerID, social.VloggerID)
+	// 关注列表已变化，递增关注者的关注流版本号，使其关注流缓存失效
+	invalidation.BumpFollowingFeeds(ctx, s.cache, "following changed", []uint{social.FollowerID})
+
+	// 7. Fallback: 博主最新视频热度+10（与 Worker 行为一致，失败只记录日志）
+	if err := video.BumpLatestVideoPopularity(ctx, s.videoRepo, s.cache, social.VloggerID, video.FollowPopularityWeight); err != nil {
+		log.Printf("social: failed to update latest video popularity for vlogger %d: %v", social.VloggerID, err)
 	}
 
-	// 6. Fallback: MQ发送失败时，直接写入数据库
-	return s.repo.Follow(ctx, social)
+	// 8. Fallback: 通知被关注者（与 Worker 行为一致，失败只记录日志）
+	if err := s.notifier.Notify(ctx, social.VloggerID, notification.TypeFollow, social.FollowerID, 0); err != nil {
+		log.Printf("social: failed to notify vlogger %d: %v", social.VloggerID, err)
+	}
+	return nil
 }
 
 // Unfollow 取消关注
@@ -72,7 +109,7 @@ func (s *SocialService) Follow(ctx context.Context, social *Social) error {
 // 1. 校验关注者和博主是否存在
 // 2. 校验是否已关注（防止取消未关注的用户）
 // 3. 发送取关事件到MQ（Worker异步处理：删除关注记录、更新粉丝数、更新视频热度）
-// 4. MQ失败时Fallback：直接删除数据库记录
+// 4. MQ失败时Fallback：直接删除数据库记录，并更新博主最新视频热度
 // 参数：
 //   - ctx: 上下文
 //   - social: 关注对象
@@ -95,48 +132,96 @@ func (s *SocialService) Unfollow(ctx context.Context, social *Social) error {
 		return err
 	}
 	if !isFollowed {
-		return errors.New("not followed")
+		return ErrNotFollowed
 	}
 
-	// 4. 发送取关事件到MQ（Worker异步处理）
+	// 4. 发送取关事件到MQ（Worker异步处理），发送成功直接返回
 	if s.socialMQ != nil {
-		s.socialMQ.UnFollow(ctx, social.FollowerID, social.VloggerID)
+		if err := s.socialMQ.UnFollow(ctx, social.FollowerID, social.VloggerID); err == nil {
+			return nil
+		}
 	}
 
-	// 5. Fallback: MQ发送失败时，直接删除数据库记录
-	return s.repo.Unfollow(ctx, social)
+	// 5. Fallback: MQ发送失败时，直接删除数据库记录（关注记录 + 粉丝数/关注数，同一事务）
+	deleted, err := s.repo.UnfollowWithCounts(ctx, social)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrNotFollowed
+	}
+	// 粉丝数/关注数已变化，删除双方的公开资料缓存
+	account.InvalidateProfileCache(ctx, s.cache, social.FollowerID, social.VloggerID)
+	// 关注列表已变化，递增关注者的关注流版本号，使其关注流缓存失效
+	invalidation.BumpFollowingFeeds(ctx, s.cache, "following changed", []uint{social.FollowerID})
+
+	// 6. Fallback: 博主最新视频热度-10（与 Worker 行为一致，失败只记录日志）
+	if err := video.BumpLatestVideoPopularity(ctx, s.videoRepo, s.cache, social.VloggerID, -video.FollowPopularityWeight); err != nil {
+		log.Printf("social: failed to update latest video popularity for vlogger %d: %v", social.VloggerID, err)
+	}
+	return nil
 }
 
 // GetAllFollowers 查询指定博主的粉丝列表
+// 每个粉丝附带查看者是否已关注该粉丝（is_following）
 // 参数：
 //   - ctx: 上下文
 //   - VloggerID: 博主ID
+//   - viewerID: 查看者ID
 // 返回：
-//   - []*account.Account: 粉丝列表
+//   - []*FollowAccount: 粉丝列表
 //   - error: 错误信息
-func (s *SocialService) GetAllFollowers(ctx context.Context, VloggerID uint) ([]*account.Account, error) {
+func (s *SocialService) GetAllFollowers(ctx context.Context, VloggerID uint, viewerID uint) ([]*FollowAccount, error) {
 	// 校验博主是否存在
 	_, err := s.accountrepo.FindByID(ctx, VloggerID)
 	if err != nil {
 		return nil, err
 	}
-	return s.repo.GetAllFollowers(ctx, VloggerID)
+	followers, err := s.repo.GetAllFollowers(ctx, VloggerID)
+	if err != nil {
+		return nil, err
+	}
+	return s.buildFollowAccounts(ctx, followers, viewerID)
 }
 
 // GetAllVloggers 查询指定用户关注的博主列表
+// 每个博主附带查看者是否已关注该博主（is_following）
 // 参数：
 //   - ctx: 上下文
 //   - FollowerID: 关注者ID
+//   - viewerID: 查看者ID
 // 返回：
-//   - []*account.Account: 关注的博主列表
+//   - []*FollowAccount: 关注的博主列表
 //   - error: 错误信息
-func (s *SocialService) GetAllVloggers(ctx context.Context, FollowerID uint) ([]*account.Account, error) {
+func (s *SocialService) GetAllVloggers(ctx context.Context, FollowerID uint, viewerID uint) ([]*FollowAccount, error) {
 	// 校验关注者是否存在
 	_, err := s.accountrepo.FindByID(ctx, FollowerID)
 	if err != nil {
 		return nil, err
 	}
-	return s.repo.GetAllVloggers(ctx, FollowerID)
+	vloggers, err := s.repo.GetAllVloggers(ctx, FollowerID)
+	if err != nil {
+		return nil, err
+	}
+	return s.buildFollowAccounts(ctx, vloggers, viewerID)
+}
+
+// buildFollowAccounts 组装关注/粉丝列表项（一次批量查询查看者的关注状态）
+func (s *SocialService) buildFollowAccounts(ctx context.Context, accounts []*account.Account, viewerID uint) ([]*FollowAccount, error) {
+	ids := make([]uint, 0, len(accounts))
+	for _, a := range accounts {
+		ids = append(ids, a.ID)
+	}
+	followMap, err := s.repo.BatchIsFollowed(ctx, viewerID, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*FollowAccount, 0, len(accounts))
+	for _, a := range accounts {
+		items = append(items, &FollowAccount{Account: a, IsFollowing: followMap[a.ID]})
+	}
+	return items, nil
 }
 
 // IsFollowed 查询是否已关注
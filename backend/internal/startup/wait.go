@@ -0,0 +1,48 @@
+// Package startup 进程启动时等待依赖服务（MySQL、RabbitMQ）就绪
+// 编排启动（docker compose、Kubernetes）时依赖服务可能比应用晚几秒可用，
+// 直接失败退出会导致容器反复重启；这里在超时时间内按指数退避重试
+package startup
+
+import (
+	"fmt"
+	"log"
+	"time"
+)
+
+// 重试间隔：从 initialBackoff 开始每次翻倍，最大 maxBackoff
+const (
+	initialBackoff = 500 * time.Millisecond
+	maxBackoff     = 5 * time.Second
+)
+
+// Wait 调用 connect 直到成功或超过 timeout
+// timeout <= 0 时只尝试一次（与不等待的行为一致）；超时后返回最后一次的错误
+// 参数：
+//   - name: 依赖名称（仅用于日志）
+//   - timeout: 最长等待时间
+//   - connect: 建立连接的函数
+func Wait[T any](name string, timeout time.Duration, connect func() (T, error)) (T, error) {
+	v, err := connect()
+	if err == nil || timeout <= 0 {
+		return v, err
+	}
+
+	deadline := time.Now().Add(timeout)
+	backoff := initialBackoff
+	for attempt := 2; ; attempt++ {
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			var zero T
+			return zero, fmt.Errorf("%s not ready after %s: %w", name, timeout, err)
+		}
+		wait := min(backoff, remaining)
+		log.Printf("startup: %s not ready (%v), retrying in %s", name, err, wait)
+		time.Sleep(wait)
+		backoff = min(backoff*2, maxBackoff)
+
+		if v, err = connect(); err == nil {
+			log.Printf("startup: %s ready after %d attempts", name, attempt)
+			return v, nil
+		}
+	}
+}
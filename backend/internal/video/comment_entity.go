@@ -1,6 +1,10 @@
 package video
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 // Comment 评论实体模型，对应数据库中的comments表
 type Comment struct {
@@ -9,21 +13,72 @@ type Comment struct {
 	VideoID   uint      `gorm:"index" json:"video_id"`              // 视频ID（带索引，用于查询）
 	AuthorID  uint      `gorm:"index" json:"author_id"`             // 评论者ID（带索引，用于查询）
 	Content   string    `gorm:"type:text" json:"content"`           // 评论内容（TEXT类型，支持长文本）
+	LikesCount int64    `gorm:"not null;default:0" json:"likes_count"` // 点赞数
 	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`   // 创建时间（自动生成）
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`            // 软删除时间（GORM 查询自动排除已删除评论）
 }
 
 // PublishCommentRequest 发布评论请求体
 type PublishCommentRequest struct {
-	VideoID uint   `json:"video_id"` // 视频ID
+	VideoID uint   `json:"video_id" binding:"required,gt=0"` // 视频ID
 	Content string `json:"content"`  // 评论内容
 }
 
 // DeleteCommentRequest 删除评论请求体
 type DeleteCommentRequest struct {
-	CommentID uint `json:"comment_id"` // 评论ID
+	CommentID uint `json:"comment_id" binding:"required,gt=0"` // 评论ID
+}
+
+// RestoreCommentRequest 恢复评论请求体
+type RestoreCommentRequest struct {
+	CommentID uint `json:"comment_id" binding:"required,gt=0"` // 评论ID
 }
 
 // GetAllCommentsRequest 查询评论列表请求体
 type GetAllCommentsRequest struct {
-	VideoID uint `json:"video_id"` // 视频ID
+	VideoID uint `json:"video_id" binding:"required,gt=0"` // 视频ID
+}
+
+// ListCommentsRequest 分页查询评论列表请求体
+type ListCommentsRequest struct {
+	VideoID      uint `json:"video_id" binding:"required,gt=0"` // 视频ID
+	Limit        int  `json:"limit"`                            // 返回的评论数量（1-50）
+	BeforeID     uint `json:"before_id"`                        // 游标：上一页最后一条评论的ID（第一页传 0）
+	IncludeTotal bool `json:"include_total"`                    // 是否返回评论总数（需要额外的 COUNT 查询，默认不返回）
+}
+
+// CommentItem 评论列表项（附带当前用户的点赞状态）
+type CommentItem struct {
+	Comment
+	IsLiked bool `json:"is_liked"` // 当前用户是否已点赞
+}
+
+// ListCommentsResponse 分页查询评论列表响应体
+type ListCommentsResponse struct {
+	Comments     []CommentItem `json:"comments"`       // 评论列表
+	NextBeforeID uint          `json:"next_before_id"` // 游标：用于下一页的评论ID
+	HasMore      bool          `json:"has_more"`       // 是否还有更多数据
+	Total        *int64        `json:"total,omitempty"` // 评论总数（仅 include_total 为 true 时返回）
+}
+
+// ListAuthorCommentsRequest 查询用户评论历史请求体
+type ListAuthorCommentsRequest struct {
+	AuthorID uint `json:"author_id" binding:"required,gt=0"` // 评论者ID
+	Limit    int  `json:"limit"`                             // 返回的评论数量（1-50）
+	BeforeID uint `json:"before_id"`                         // 游标：上一页最后一条评论的ID（第一页传 0）
+}
+
+// AuthorCommentItem 用户评论历史列表项（附带所属视频的标题）
+type AuthorCommentItem struct {
+	Comment
+	VideoTitle      string `json:"video_title"` // 所属视频标题
+	VideoVisibility string `json:"-"`           // 所属视频可见性（用于过滤非公开视频下的评论）
+	VideoAuthorID   uint   `json:"-"`           // 所属视频作者ID
+}
+
+// ListAuthorCommentsResponse 查询用户评论历史响应体
+type ListAuthorCommentsResponse struct {
+	Comments     []AuthorCommentItem `json:"comments"`       // 评论列表
+	NextBeforeID uint                `json:"next_before_id"` // 游标：用于下一页的评论ID
+	HasMore      bool                `json:"has_more"`       // 是否还有更多数据
 }
@@ -0,0 +1,162 @@
+package video
+
+import (
+	"bufio"
+	"errors"
+	"feedsystem_video_go/internal/config"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// 敏感词处理方式
+const (
+	FilterModeReject = "reject" // 包含敏感词时拒绝发布
+	FilterModeMask   = "mask"   // 将敏感词替换为等长的 *
+)
+
+// ErrCommentBlocked 评论包含敏感词（reject 模式）
+var ErrCommentBlocked = errors.New("comment contains blocked words")
+
+// CommentFilter 评论敏感词过滤器
+// 匹配不区分大小写；mask 模式按字符（rune）逐个替换为 *，保证替换后长度不变
+// nil 过滤器表示不过滤
+type CommentFilter struct {
+	mode  string
+	words [][]rune // 已转为小写的敏感词
+}
+
+// NewCommentFilter 根据配置创建评论过滤器
+// 敏感词来自配置中的 blocked_words 以及 blocked_words_file（每行一个，空行和 # 开头的行忽略）
+// 参数：
+//   - cfg: 评论配置
+//
+// 返回：
+//   - *CommentFilter: 过滤器（未开启或词表为空时返回 nil）
+//   - error: 错误信息
+func NewCommentFilter(cfg config.CommentConfig) (*CommentFilter, error) {
+	mode := strings.ToLower(strings.TrimSpace(cfg.FilterMode))
+	switch mode {
+	case "":
+		return nil, nil
+	case FilterModeReject, FilterModeMask:
+	default:
+		return nil, fmt.Errorf("unknown comment filter mode %q", cfg.FilterMode)
+	}
+
+	words := append([]string{}, cfg.BlockedWords...)
+	if cfg.BlockedWordsFile != "" {
+		fileWords, err := loadWordList(cfg.BlockedWordsFile)
+		if err != nil {
+			return nil, err
+		}
+		words = append(words, fileWords...)
+	}
+
+	f := &CommentFilter{mode: mode}
+	seen := make(map[string]struct{}, len(words))
+	for _, w := range words {
+		w = strings.TrimSpace(w)
+		if w == "" {
+			continue
+		}
+		lower := toLowerRunes(w)
+		key := string(lower)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		f.words = append(f.words, lower)
+	}
+	if len(f.words) == 0 {
+		return nil, nil
+	}
+	return f, nil
+}
+
+// Apply 对评论内容执行过滤
+// reject 模式下命中敏感词返回 ErrCommentBlocked；mask 模式下返回打码后的内容
+func (f *CommentFilter) Apply(content string) (string, error) {
+	if f == nil || len(f.words) == 0 {
+		return content, nil
+	}
+
+	src := []rune(content)
+	lower := toLowerRunes(content)
+	var out []rune
+	for i := 0; i < len(lower); {
+		n := f.matchAt(lower, i)
+		if n == 0 {
+			i++
+			continue
+		}
+		if f.mode == FilterModeReject {
+			return "", ErrCommentBlocked
+		}
+		if out == nil {
+			out = append([]rune{}, src...)
+		}
+		for j := i; j < i+n; j++ {
+			out[j] = '*'
+		}
+		i += n
+	}
+	if out == nil {
+		return content, nil
+	}
+	return string(out), nil
+}
+
+// matchAt 返回从位置 i 开始命中的最长敏感词长度，未命中返回 0
+func (f *CommentFilter) matchAt(text []rune, i int) int {
+	best := 0
+	for _, w := range f.words {
+		if len(w) <= best || i+len(w) > len(text) {
+			continue
+		}
+		matched := true
+		for j, r := range w {
+			if text[i+j] != r {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			best = len(w)
+		}
+	}
+	return best
+}
+
+// toLowerRunes 按 rune 转小写（逐字符映射，保证与原文下标一一对应）
+func toLowerRunes(s string) []rune {
+	rs := []rune(s)
+	for i, r := range rs {
+		rs[i] = unicode.ToLower(r)
+	}
+	return rs
+}
+
+// loadWordList 从文件读取敏感词（每行一个，空行和 # 开头的行忽略）
+func loadWordList(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var words []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		words = append(words, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return words, nil
+}
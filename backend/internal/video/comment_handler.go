@@ -2,7 +2,9 @@ package video
 
 import (
 	"feedsystem_video_go/internal/account"
+	"feedsystem_video_go/internal/apierror"
 	"feedsystem_video_go/internal/middleware/jwt"
+	"net/http"
 
 	"github.com/gin-gonic/gin"
 )
@@ -26,37 +28,31 @@ func (h *CommentHandler) PublishComment(c *gin.Context) {
 	// 1. 解析JSON请求体
 	var req PublishCommentRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(400, gin.H{"error": err.Error()})
+		apierror.BadRequest(c, err)
 		return
 	}
 
 	// 2. 校验评论内容
 	if req.Content == "" {
-		c.JSON(400, gin.H{"error": "content is required"})
+		apierror.Write(c, http.StatusBadRequest, "CONTENT_REQUIRED", "content is required")
 		return
 	}
 
-	// 3. 校验视频ID
-	if req.VideoID <= 0 {
-		c.JSON(400, gin.H{"error": "video_id is required"})
-		return
-	}
-
-	// 4. 从JWT中间件获取当前登录用户ID
+	// 3. 从JWT中间件获取当前登录用户ID
 	authorId, err := jwt.GetAccountID(c)
 	if err != nil {
-		c.JSON(400, gin.H{"error": err.Error()})
+		apierror.Unauthorized(c)
 		return
 	}
 
-	// 5. 查询用户信息（获取用户名）
+	// 4. 查询用户信息（获取用户名）
 	user, err := h.accountService.FindByID(c.Request.Context(), authorId)
 	if err != nil {
-		c.JSON(400, gin.H{"error": err.Error()})
+		apierror.FromError(c, err, account.NotFoundMapping)
 		return
 	}
 
-	// 6. 构造评论对象
+	// 5. 构造评论对象
 	comment := &Comment{
 		Username: user.Username, // 评论者用户名（冗余存储，便于查询）
 		VideoID:  req.VideoID,  // 视频ID
@@ -64,13 +60,14 @@ func (h *CommentHandler) PublishComment(c *gin.Context) {
 		Content:  req.Content,  // 评论内容
 	}
 
-	// 7. 调用Service层发布评论（含MQ异步处理）
+	// 6. 调用Service层发布评论（含MQ异步处理）
+	// 内容校验失败（含敏感词 reject 模式）返回 400，视频不存在返回 404
 	if err := h.service.Publish(c.Request.Context(), comment); err != nil {
-		c.JSON(400, gin.H{"error": err.Error()})
+		apierror.FromError(c, err, commentErrors...)
 		return
 	}
 
-	// 8. 返回成功消息
+	// 7. 返回成功消息
 	c.JSON(200, gin.H{"message": "comment published successfully"})
 }
 
@@ -82,31 +79,54 @@ func (h *CommentHandler) DeleteComment(c *gin.Context) {
 	// 1. 解析JSON请求体
 	var req DeleteCommentRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(400, gin.H{"error": err.Error()})
+		apierror.BadRequest(c, err)
 		return
 	}
 
 	// 2. 从JWT中间件获取当前登录用户ID
 	accountID, err := jwt.GetAccountID(c)
 	if err != nil {
-		c.JSON(400, gin.H{"error": err.Error()})
+		apierror.Unauthorized(c)
 		return
 	}
 
-	// 3. 校验评论ID
-	if req.CommentID <= 0 {
-		c.JSON(400, gin.H{"error": "comment_id is required"})
+	// 3. 调用Service层删除评论（会验证是否为评论作者）
+	if err := h.service.Delete(c.Request.Context(), req.CommentID, accountID); err != nil {
+		apierror.FromError(c, err, commentErrors...)
 		return
 	}
 
-	// 4. 调用Service层删除评论（会验证是否为评论作者）
-	if err := h.service.Delete(c.Request.Context(), req.CommentID, accountID); err != nil {
-		c.JSON(400, gin.H{"error": err.Error()})
+	// 4. 返回成功消息
+	c.JSON(200, gin.H{"message": "comment deleted successfully"})
+}
+
+// RestoreComment 撤销删除评论接口
+// 路由：POST /comment/restore
+// 功能：评论作者在删除后的短时间窗口内恢复评论
+// 请求体：{"comment_id": 评论ID}
+func (h *CommentHandler) RestoreComment(c *gin.Context) {
+	// 1. 解析JSON请求体
+	var req RestoreCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.BadRequest(c, err)
 		return
 	}
 
-	// 5. 返回成功消息
-	c.JSON(200, gin.H{"message": "comment deleted successfully"})
+	// 2. 从JWT中间件获取当前登录用户ID
+	accountID, err := jwt.GetAccountID(c)
+	if err != nil {
+		apierror.Unauthorized(c)
+		return
+	}
+
+	// 3. 调用Service层恢复评论（会验证作者和撤销窗口）
+	if err := h.service.Restore(c.Request.Context(), req.CommentID, accountID); err != nil {
+		apierror.FromError(c, err, commentErrors...)
+		return
+	}
+
+	// 4. 返回成功消息
+	c.JSON(200, gin.H{"message": "comment restored"})
 }
 
 // GetAllComments 查询视频的所有评论接口
@@ -117,23 +137,159 @@ func (h *CommentHandler) GetAllComments(c *gin.Context) {
 	// 1. 解析JSON请求体
 	var req GetAllCommentsRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(400, gin.H{"error": err.Error()})
+		apierror.BadRequest(c, err)
 		return
 	}
 
-	// 2. 校验视频ID
-	if req.VideoID == 0 {
-		c.JSON(400, gin.H{"error": "video_id is required"})
-		return
+	// 2. 获取当前用户ID（可选登录，未登录时为 0）
+	viewerAccountID, err := jwt.GetAccountID(c)
+	if err != nil {
+		viewerAccountID = 0
 	}
 
-	// 3. 调用Service层查询评论列表
-	comments, err := h.service.GetAll(c.Request.Context(), req.VideoID)
+	// 3. 调用Service层查询评论列表（含点赞状态）
+	comments, err := h.service.GetAll(c.Request.Context(), req.VideoID, viewerAccountID)
 	if err != nil {
-		c.JSON(400, gin.H{"error": err.Error()})
+		apierror.FromError(c, err, commentErrors...)
 		return
 	}
 
 	// 4. 返回评论列表
 	c.JSON(200, comments)
 }
+
+// ListComments 分页查询视频评论接口
+// 路由：POST /comment/list
+// 功能：按评论ID游标分页查询指定视频的评论，附带点赞数和当前用户的点赞状态
+// 请求体：{"video_id": 视频ID, "limit": 10, "before_id": 0, "include_total": false}
+func (h *CommentHandler) ListComments(c *gin.Context) {
+	// 1. 解析JSON请求体
+	var req ListCommentsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.BadRequest(c, err)
+		return
+	}
+
+	// 2. 获取当前用户ID（可选登录，未登录时为 0）
+	viewerAccountID, err := jwt.GetAccountID(c)
+	if err != nil {
+		viewerAccountID = 0
+	}
+
+	// 3. 调用Service层分页查询评论
+	resp, err := h.service.List(c.Request.Context(), req.VideoID, req.BeforeID, req.Limit, req.IncludeTotal, viewerAccountID)
+	if err != nil {
+		apierror.FromError(c, err, commentErrors...)
+		return
+	}
+
+	// 4. 返回评论列表及分页信息
+	c.JSON(200, resp)
+}
+
+// ListAuthorComments 查询用户评论历史接口
+// 路由：POST /comment/listByAuthor
+// 功能：按评论ID游标分页查询指定用户发布的评论，附带所属视频标题（非公开视频下的评论只有评论者本人和视频作者可见）
+// 请求体：{"author_id": 用户ID, "limit": 10, "before_id": 0}
+func (h *CommentHandler) ListAuthorComments(c *gin.Context) {
+	// 1. 解析JSON请求体
+	var req ListAuthorCommentsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.BadRequest(c, err)
+		return
+	}
+
+	// 2. 获取当前用户ID（可选登录，未登录时为 0）
+	viewerAccountID, err := jwt.GetAccountID(c)
+	if err != nil {
+		viewerAccountID = 0
+	}
+
+	// 3. 调用Service层分页查询评论
+	resp, err := h.service.ListByAuthor(c.Request.Context(), req.AuthorID, req.BeforeID, req.Limit, viewerAccountID)
+	if err != nil {
+		apierror.FromError(c, err, commentErrors...)
+		return
+	}
+
+	// 4. 返回评论列表及分页信息
+	c.JSON(200, resp)
+}
+
+// LikeComment 点赞评论接口
+// 路由：POST /comment/like
+// 功能：用户点赞指定评论（支持MQ异步处理）
+// 请求体：{"comment_id": 评论ID}
+func (h *CommentHandler) LikeComment(c *gin.Context) {
+	// 1. 解析JSON请求体
+	var req CommentLikeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.BadRequest(c, err)
+		return
+	}
+
+	// 2. 从JWT中间件获取当前登录用户ID
+	accountID, err := jwt.GetAccountID(c)
+	if err != nil {
+		apierror.Unauthorized(c)
+		return
+	}
+
+	// 3. 调用Service层点赞评论
+	if err := h.service.Like(c.Request.Context(), req.CommentID, accountID); err != nil {
+		apierror.FromError(c, err, commentErrors...)
+		return
+	}
+
+	// 4. 返回成功消息
+	c.JSON(200, gin.H{"message": "like success"})
+}
+
+// UnlikeComment 取消点赞评论接口
+// 路由：POST /comment/unlike
+// 功能：用户取消点赞指定评论（支持MQ异步处理）
+// 请求体：{"comment_id": 评论ID}
+func (h *CommentHandler) UnlikeComment(c *gin.Context) {
+	// 1. 解析JSON请求体
+	var req CommentLikeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.BadRequest(c, err)
+		return
+	}
+
+	// 2. 从JWT中间件获取当前登录用户ID
+	accountID, err := jwt.GetAccountID(c)
+	if err != nil {
+		apierror.Unauthorized(c)
+		return
+	}
+
+	// 3. 调用Service层取消点赞评论
+	if err := h.service.Unlike(c.Request.Context(), req.CommentID, accountID); err != nil {
+		apierror.FromError(c, err, commentErrors...)
+		return
+	}
+
+	// 4. 返回成功消息
+	c.JSON(200, gin.H{"message": "unlike success"})
+}
+
+// commentErrors 评论服务层错误到错误码/HTTP状态码的映射
+// 视频/评论不存在返回 404，无权限返回 403，重复点赞/取消、评论数达到上限返回 409，参数或内容校验失败返回 400，其余返回 500
+var commentErrors = []apierror.Mapping{
+	{Err: ErrVideoNotFound, Status: http.StatusNotFound, Code: "VIDEO_NOT_FOUND"},
+	{Err: ErrVideoForbidden, Status: http.StatusForbidden, Code: "VIDEO_FORBIDDEN"},
+	{Err: ErrCommentNotFound, Status: http.StatusNotFound, Code: "COMMENT_NOT_FOUND"},
+	{Err: ErrDeletedCommentNotFound, Status: http.StatusNotFound, Code: "DELETED_COMMENT_NOT_FOUND"},
+	{Err: ErrCommentRestoreExpired, Status: http.StatusBadRequest, Code: "RESTORE_WINDOW_EXPIRED"},
+	{Err: ErrCommentPermissionDenied, Status: http.StatusForbidden, Code: "COMMENT_PERMISSION_DENIED"},
+	{Err: ErrCommentAlreadyLiked, Status: http.StatusConflict, Code: "COMMENT_ALREADY_LIKED"},
+	{Err: ErrCommentNotLiked, Status: http.StatusConflict, Code: "COMMENT_NOT_LIKED"},
+	{Err: ErrCommentInvalid, Status: http.StatusBadRequest, Code: apierror.CodeInvalidArgument},
+	{Err: ErrCommentLikeInvalid, Status: http.StatusBadRequest, Code: apierror.CodeInvalidArgument},
+	{Err: ErrCommentContentRequired, Status: http.StatusBadRequest, Code: "CONTENT_REQUIRED"},
+	{Err: ErrCommentTooLong, Status: http.StatusBadRequest, Code: "COMMENT_TOO_LONG"},
+	{Err: ErrCommentBlocked, Status: http.StatusBadRequest, Code: "COMMENT_BLOCKED"},
+	{Err: ErrTooManyComments, Status: http.StatusTooManyRequests, Code: apierror.CodeRateLimited},
+	{Err: ErrCommentLimitReached, Status: http.StatusConflict, Code: "COMMENT_LIMIT_REACHED"},
+}
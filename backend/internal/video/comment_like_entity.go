@@ -0,0 +1,17 @@
+package video
+
+import "time"
+
+// CommentLike 评论点赞实体模型，对应数据库中的comment_likes表
+// 使用联合唯一索引 (comment_id, account_id) 防止重复点赞
+type CommentLike struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`                                                    // 主键ID
+	CommentID uint      `gorm:"uniqueIndex:idx_comment_like_comment_account;not null" json:"comment_id"` // 评论ID（联合唯一索引）
+	AccountID uint      `gorm:"uniqueIndex:idx_comment_like_comment_account;not null" json:"account_id"` // 用户ID（联合唯一索引）
+	CreatedAt time.Time `json:"created_at"`                                                              // 点赞时间
+}
+
+// CommentLikeRequest 评论点赞请求体
+type CommentLikeRequest struct {
+	CommentID uint `json:"comment_id" binding:"required,gt=0"` // 评论ID
+}
@@ -0,0 +1,106 @@
+package video
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// CommentLikeRepository 评论点赞仓储层，负责评论点赞相关数据库操作
+type CommentLikeRepository struct {
+	db *gorm.DB // GORM数据库实例
+}
+
+// NewCommentLikeRepository 创建评论点赞仓储实例
+func NewCommentLikeRepository(db *gorm.DB) *CommentLikeRepository {
+	return &CommentLikeRepository{db: db}
+}
+
+// LikeIgnoreDuplicate 添加评论点赞记录（忽略重复错误）
+// 如果已点赞则返回created=false，否则返回created=true
+// 参数：
+//   - ctx: 上下文
+//   - like: 评论点赞对象
+// 返回：
+//   - bool: 是否创建了新记录
+//   - error: 错误信息
+func (r *CommentLikeRepository) LikeIgnoreDuplicate(ctx context.Context, like *CommentLike) (created bool, err error) {
+	if like == nil || like.CommentID == 0 || like.AccountID == 0 {
+		return false, nil
+	}
+	err = r.db.WithContext(ctx).Create(like).Error
+	if err == nil {
+		return true, nil
+	}
+	// 唯一索引冲突（重复点赞）不算错误
+	if isDupKey(err) {
+		return false, nil
+	}
+	return false, err
+}
+
+// DeleteByCommentAndAccount 根据评论ID和用户ID删除点赞记录
+// 参数：
+//   - ctx: 上下文
+//   - commentID: 评论ID
+//   - accountID: 用户ID
+// 返回：
+//   - bool: 是否删除成功
+//   - error: 错误信息
+func (r *CommentLikeRepository) DeleteByCommentAndAccount(ctx context.Context, commentID, accountID uint) (deleted bool, err error) {
+	if commentID == 0 || accountID == 0 {
+		return false, nil
+	}
+	res := r.db.WithContext(ctx).
+		Where("comment_id = ? AND account_id = ?", commentID, accountID).
+		Delete(&CommentLike{})
+	return res.RowsAffected > 0, res.Error
+}
+
+// IsLiked 查询是否已点赞评论
+// 参数：
+//   - ctx: 上下文
+//   - commentID: 评论ID
+//   - accountID: 用户ID
+// 返回：
+//   - bool: 是否已点赞
+//   - error: 错误信息
+func (r *CommentLikeRepository) IsLiked(ctx context.Context, commentID, accountID uint) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&CommentLike{}).
+		Where("comment_id = ? AND account_id = ?", commentID, accountID).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// BatchGetLiked 批量查询是否已点赞评论（用于评论列表场景）
+// 参数：
+//   - ctx: 上下文
+//   - commentIDs: 评论ID列表
+//   - accountID: 用户ID
+// 返回：
+//   - map[uint]bool: commentID -> 是否已点赞
+//   - error: 错误信息
+func (r *CommentLikeRepository) BatchGetLiked(ctx context.Context, commentIDs []uint, accountID uint) (map[uint]bool, error) {
+	likeMap := make(map[uint]bool)
+	if len(commentIDs) == 0 {
+		return likeMap, nil
+	}
+	if accountID == 0 {
+		return likeMap, nil
+	}
+	var likes []CommentLike
+	err := r.db.WithContext(ctx).Model(&CommentLike{}).
+		Where("comment_id IN ? AND account_id = ?", commentIDs, accountID).
+		Find(&likes).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, like := range likes {
+		likeMap[like.CommentID] = true
+	}
+	return likeMap, nil
+}
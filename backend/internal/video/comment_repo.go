@@ -2,6 +2,7 @@ package video
 
 import (
 	"context"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -24,12 +25,56 @@ func (r *CommentRepository) CreateComment(ctx context.Context, comment *Comment)
 	return r.db.WithContext(ctx).Create(comment).Error
 }
 
-// DeleteComment 删除评论记录
+// DeleteComment 删除评论记录（软删除）
+// Comment 含 DeletedAt 字段，GORM 只会设置 deleted_at，之后的普通查询自动排除该评论
 // 参数：
 //   - ctx: 上下文
 //   - comment: 评论对象
-func (r *CommentRepository) DeleteComment(ctx context.Context, comment *Comment) error {
-	return r.db.WithContext(ctx).Delete(comment).Error
+// 返回：
+//   - bool: 是否删除了记录（评论已被删除时为 false，调用方据此避免重复扣减热度）
+//   - error: 错误信息
+func (r *CommentRepository) DeleteComment(ctx context.Context, comment *Comment) (bool, error) {
+	res := r.db.WithContext(ctx).Delete(comment)
+	return res.RowsAffected > 0, res.Error
+}
+
+// GetDeletedByID 查询已被软删除的评论
+// 参数：
+//   - ctx: 上下文
+//   - id: 评论ID
+// 返回：
+//   - *Comment: 评论对象（未删除或不存在时返回 gorm.ErrRecordNotFound）
+//   - error: 错误信息
+func (r *CommentRepository) GetDeletedByID(ctx context.Context, id uint) (*Comment, error) {
+	var comment Comment
+	if err := r.db.WithContext(ctx).Unscoped().
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		First(&comment).Error; err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
+
+// RestoreWithPopularity 恢复已被软删除的评论，并加回视频热度（同一事务）
+// 评论已被恢复（或不存在）时返回 gorm.ErrRecordNotFound
+// 参数：
+//   - ctx: 上下文
+//   - comment: 已删除的评论
+//   - popularity: 加回的热度
+func (r *CommentRepository) RestoreWithPopularity(ctx context.Context, comment *Comment, popularity int64) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Unscoped().Model(&Comment{}).
+			Where("id = ? AND deleted_at IS NOT NULL", comment.ID).
+			Update("deleted_at", nil)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return tx.Model(&Video{}).Where("id = ?", comment.VideoID).
+			UpdateColumns(map[string]any{"popularity": gorm.Expr("popularity + ?", popularity), "updated_at": time.Now()}).Error
+	})
 }
 
 // GetAllComments 查询指定视频的所有评论
@@ -46,7 +91,131 @@ func (r *CommentRepository) GetAllComments(ctx context.Context, videoID uint) ([
 	return comments, err
 }
 
-// IsExist 检查评论是否存在
+// ListByVideo 分页查询指定视频的评论
+// 按评论ID倒序排列（ID自增，等价于按创建时间倒序），使用ID游标分页
+// 参数：
+//   - ctx: 上下文
+//   - videoID: 视频ID
+//   - beforeID: 游标，只返回ID小于该值的评论（0 表示第一页）
+//   - limit: 返回的评论数量
+// 返回：
+//   - []Comment: 评论列表
+//   - error: 错误信息
+func (r *CommentRepository) ListByVideo(ctx context.Context, videoID, beforeID uint, limit int) ([]Comment, error) {
+	var comments []Comment
+	query := r.db.WithContext(ctx).Where("video_id = ?", videoID)
+	if beforeID > 0 {
+		query = query.Where("id < ?", beforeID)
+	}
+	err := query.Order("id desc").Limit(limit).Find(&comments).Error
+	return comments, err
+}
+
+// ListByAuthor 分页查询指定用户的评论（附带所属视频的标题）
+// 按评论ID倒序排列，使用ID游标分页；已删除的评论、已删除视频下的评论不返回
+// 参数：
+//   - ctx: 上下文
+//   - authorID: 评论者ID
+//   - beforeID: 游标，只返回ID小于该值的评论（0 表示第一页）
+//   - limit: 返回的评论数量
+// 返回：
+//   - []AuthorCommentItem: 评论列表
+//   - error: 错误信息
+func (r *CommentRepository) ListByAuthor(ctx context.Context, authorID, beforeID uint, limit int) ([]AuthorCommentItem, error) {
+	var items []AuthorCommentItem
+	query := r.db.WithContext(ctx).Model(&Comment{}).
+		Select("comments.*, videos.title AS video_title, videos.visibility AS video_visibility, videos.author_id AS video_author_id").
+		Joins("JOIN videos ON videos.id = comments.video_id AND videos.deleted_at IS NULL").
+		Where("comments.author_id = ?", authorID)
+	if beforeID > 0 {
+		query = query.Where("comments.id < ?", beforeID)
+	}
+	err := query.Order("comments.id desc").Limit(limit).Scan(&items).Error
+	return items, err
+}
+
+// CountByVideo 统计指定视频的评论总数
+// 参数：
+//   - ctx: 上下文
+//   - videoID: 视频ID
+// 返回：
+//   - int64: 评论总数
+//   - error: 错误信息
+func (r *CommentRepository) CountByVideo(ctx context.Context, videoID uint) (int64, error) {
+	var total int64
+	err := r.db.WithContext(ctx).Model(&Comment{}).Where("video_id = ?", videoID).Count(&total).Error
+	return total, err
+}
+
+// HasAtLeast 判断视频的评论数是否已达到 n 条（不含已删除的评论）
+// 只扫描前 n 条评论（OFFSET n-1 LIMIT 1），评论很多的视频也不需要 COUNT 全部记录
+// 参数：
+//   - ctx: 上下文
+//   - videoID: 视频ID
+//   - n: 评论数阈值（<= 0 时直接返回 true）
+// 返回：
+//   - bool: 评论数是否 >= n
+//   - error: 错误信息
+func (r *CommentRepository) HasAtLeast(ctx context.Context, videoID uint, n int) (bool, error) {
+	return r.HasAtLeastBefore(ctx, videoID, n, time.Time{})
+}
+
+// HasAtLeastBefore 判断视频在指定时间之前创建的评论数是否已达到 n 条（不含已删除的评论）
+// 通知 Worker 与评论 Worker 并行消费同一事件，用事件发生时间排除本条及之后写入的评论，
+// 避免评论 Worker 先写入本条评论后把它自己算进上限
+// 参数：
+//   - ctx: 上下文
+//   - videoID: 视频ID
+//   - n: 评论数阈值（<= 0 时直接返回 true）
+//   - before: 创建时间上限（零值表示不限制）
+// 返回：
+//   - bool: 评论数是否 >= n
+//   - error: 错误信息
+func (r *CommentRepository) HasAtLeastBefore(ctx context.Context, videoID uint, n int, before time.Time) (bool, error) {
+	if n <= 0 {
+		return true, nil
+	}
+	query := r.db.WithContext(ctx).Model(&Comment{}).Where("video_id = ?", videoID)
+	if !before.IsZero() {
+		query = query.Where("created_at < ?", before)
+	}
+	var ids []uint
+	err := query.
+		Order("id").
+		Offset(n-1).
+		Limit(1).
+		Pluck("id", &ids).Error
+	return len(ids) > 0, err
+}
+
+// CountDistinctAuthors 统计评论指定视频的不同用户数（用于刷量检测）
+// 参数：
+//   - ctx: 上下文
+//   - videoID: 视频ID
+// 返回：
+//   - int64: 不同评论用户数
+//   - error: 错误信息
+func (r *CommentRepository) CountDistinctAuthors(ctx context.Context, videoID uint) (int64, error) {
+	var total int64
+	err := r.db.WithContext(ctx).Model(&Comment{}).
+		Where("video_id = ?", videoID).
+		Distinct("author_id").
+		Count(&total).Error
+	return total, err
+}
+
+// ChangeLikesCount 更新评论点赞数（增量，确保不小于0）
+// 参数：
+//   - ctx: 上下文
+//   - id: 评论ID
+//   - delta: 变化量（+1 或 -1）
+func (r *CommentRepository) ChangeLikesCount(ctx context.Context, id uint, delta int64) error {
+	return r.db.WithContext(ctx).Model(&Comment{}).Where("id = ?", id).
+		UpdateColumn("likes_count", gorm.Expr("GREATEST(likes_count + ?, 0)", delta)).Error
+}
+
+// IsExist 检查评论是否存在（已软删除的评论视为不存在）
+// 只查询 SELECT 1 ... LIMIT 1，不读取整行
 // 参数：
 //   - ctx: 上下文
 //   - id: 评论ID
@@ -54,14 +223,16 @@ func (r *CommentRepository) GetAllComments(ctx context.Context, videoID uint) ([
 //   - bool: 是否存在
 //   - error: 错误信息
 func (r *CommentRepository) IsExist(ctx context.Context, id uint) (bool, error) {
-	var comment Comment
-	if err := r.db.WithContext(ctx).First(&comment, id).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return false, nil
-		}
+	var found []int
+	if err := r.db.WithContext(ctx).
+		Model(&Comment{}).
+		Select("1").
+		Where("id = ?", id).
+		Limit(1).
+		Find(&found).Error; err != nil {
 		return false, err
 	}
-	return true, nil
+	return len(found) > 0, nil
 }
 
 // GetByID 根据ID查询评论详情
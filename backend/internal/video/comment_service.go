@@ -5,21 +5,97 @@ import (
 	"errors"
 	"feedsystem_video_go/internal/middleware/rabbitmq"
 	rediscache "feedsystem_video_go/internal/middleware/redis"
+	"feedsystem_video_go/internal/notification"
+	"fmt"
+	"log"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"gorm.io/gorm"
 )
 
+// defaultMaxCommentLength 评论默认最大字符数（按 rune 计算）
+const defaultMaxCommentLength = 500
+
+// defaultCommentRateInterval 同一用户两次发布评论的默认最小间隔
+const defaultCommentRateInterval = 3 * time.Second
+
+// CommentRestoreWindow 评论删除后作者可以撤销删除的时间窗口
+const CommentRestoreWindow = 10 * time.Minute
+
+var (
+	ErrCommentTooLong          = errors.New("content is too long")                    // 评论内容过长
+	ErrCommentInvalid          = errors.New("video_id and author_id are required")    // 缺少视频ID或作者ID
+	ErrCommentContentRequired  = errors.New("content is required")                    // 评论内容为空
+	ErrCommentNotFound         = errors.New("comment not found")                      // 评论不存在
+	ErrCommentPermissionDenied = errors.New("permission denied")                      // 不是评论作者
+	ErrCommentAlreadyLiked     = errors.New("user has liked this comment")            // 已点赞该评论
+	ErrCommentNotLiked         = errors.New("user has not liked this comment")        // 未点赞该评论
+	ErrCommentLikeInvalid      = errors.New("comment_id and account_id are required") // 缺少评论ID或用户ID
+	ErrTooManyComments         = errors.New("commenting too frequently")              // 发布评论过于频繁
+	ErrDeletedCommentNotFound  = errors.New("deleted comment not found")              // 已删除的评论不存在
+	ErrCommentRestoreExpired   = errors.New("comment is past the restore window")     // 超过撤销删除的时间窗口
+	ErrCommentLimitReached     = errors.New("video has reached the comment limit")    // 视频评论数已达上限
+)
+
 type CommentService struct {
 	repo            *CommentRepository
+	likeRepo        *CommentLikeRepository // 评论点赞仓储层
 	VideoRepository *VideoRepository
 	cache           *rediscache.Client
 	commentMQ       *rabbitmq.CommentMQ
-	popularityMQ    *rabbitmq.PopularityMQ
+	popularityMQ    *rabbitmq.PopularityBatcher
+	filter          *CommentFilter // 敏感词过滤器（nil 表示不过滤）
+	maxLength       int            // 评论最大字符数
+	rateInterval    time.Duration  // 同一用户两次发布评论的最小间隔
+	maxPerVideo     int            // 每个视频最多的评论数（0 表示不限制）
+	notifier        *notification.NotificationService // 通知服务层，Fallback 时通知视频作者（可能为 nil）
 }
 
-func NewCommentService(repo *CommentRepository, videoRepo *VideoRepository, cache *rediscache.Client, commentMQ *rabbitmq.CommentMQ, popularityMQ *rabbitmq.PopularityMQ) *CommentService {
-	return &CommentService{repo: repo, VideoRepository: videoRepo, cache: cache, commentMQ: commentMQ, popularityMQ: popularityMQ}
+func NewCommentService(repo *CommentRepository, likeRepo *CommentLikeRepository, videoRepo *VideoRepository, cache *rediscache.Client, commentMQ *rabbitmq.CommentMQ, popularityMQ *rabbitmq.PopularityBatcher, filter *CommentFilter, maxLength int, rateInterval time.Duration, maxPerVideo int, notifier *notification.NotificationService) *CommentService {
+	if maxLength <= 0 {
+		maxLength = defaultMaxCommentLength
+	}
+	if rateInterval <= 0 {
+		rateInterval = defaultCommentRateInterval
+	}
+	return &CommentService{repo: repo, likeRepo: likeRepo, VideoRepository: videoRepo, cache: cache, commentMQ: commentMQ, popularityMQ: popularityMQ, filter: filter, maxLength: maxLength, rateInterval: rateInterval, maxPerVideo: maxPerVideo, notifier: notifier}
+}
+
+// checkRateLimit 评论限流：同一用户 rateInterval 内只能发布一条评论
+// 使用 SETNX + TTL（comment:rate:{accountID}），key 已存在说明距上一条评论不足 rateInterval
+// Redis 不可用时不限流（只记录日志），不影响正常发布
+func (s *CommentService) checkRateLimit(ctx context.Context, accountID uint) error {
+	if s.cache == nil {
+		return nil
+	}
+	opCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	ok, err := s.cache.SetNX(opCtx, fmt.Sprintf("comment:rate:%d", accountID), []byte("1"), s.rateInterval)
+	if err != nil {
+		log.Printf("comment rate limit: redis unavailable, skip: %v", err)
+		return nil
+	}
+	if !ok {
+		return ErrTooManyComments
+	}
+	return nil
+}
+
+// checkCommentLimit 视频评论数达到上限时返回 ErrCommentLimitReached（maxPerVideo 为 0 时不限制）
+func (s *CommentService) checkCommentLimit(ctx context.Context, videoID uint) error {
+	if s.maxPerVideo <= 0 {
+		return nil
+	}
+	full, err := s.repo.HasAtLeast(ctx, videoID, s.maxPerVideo)
+	if err != nil {
+		return err
+	}
+	if full {
+		return ErrCommentLimitReached
+	}
+	return nil
 }
 
 func (s *CommentService) Publish(ctx context.Context, comment *Comment) error {
@@ -29,18 +105,35 @@ func (s *CommentService) Publish(ctx context.Context, comment *Comment) error {
 	comment.Username = strings.TrimSpace(comment.Username)
 	comment.Content = strings.TrimSpace(comment.Content)
 	if comment.VideoID == 0 || comment.AuthorID == 0 {
-		return errors.New("video_id and author_id are required")
+		return ErrCommentInvalid
 	}
 	if comment.Content == "" {
-		return errors.New("content is required")
+		return ErrCommentContentRequired
 	}
-
-	exists, err := s.VideoRepository.IsExist(ctx, comment.VideoID)
+	// 按字符（rune）而非字节计算长度，避免中文被误判
+	if n := utf8.RuneCountInString(comment.Content); n > s.maxLength {
+		return fmt.Errorf("%w: %d characters, max %d", ErrCommentTooLong, n, s.maxLength)
+	}
+	// 敏感词过滤：reject 模式返回 ErrCommentBlocked，mask 模式替换为等长的 *
+	content, err := s.filter.Apply(comment.Content)
 	if err != nil {
 		return err
 	}
-	if !exists {
-		return errors.New("video not found")
+	comment.Content = content
+
+	// 非公开视频只有作者本人可以评论
+	if err := checkVideoAccess(ctx, s.VideoRepository, comment.VideoID, comment.AuthorID); err != nil {
+		return err
+	}
+
+	// 评论数上限：达到 comment.max_per_video 后拒绝新评论（Worker 写入前会再检查一次）
+	if err := s.checkCommentLimit(ctx, comment.VideoID); err != nil {
+		return err
+	}
+
+	// 限流放在所有校验之后、投递消息之前：被拒绝的评论不会进入消息队列，校验失败的请求也不占用限流窗口
+	if err := s.checkRateLimit(ctx, comment.AuthorID); err != nil {
+		return err
 	}
 
 	mysqlEnqueued := false
@@ -51,7 +144,7 @@ func (s *CommentService) Publish(ctx context.Context, comment *Comment) error {
 		}
 	}
 	if s.popularityMQ != nil {
-		if err := s.popularityMQ.Update(ctx, comment.VideoID, 1); err == nil {
+		if err := s.popularityMQ.Update(ctx, comment.VideoID, CommentPopularityWeight); err == nil {
 			redisEnqueued = true
 		}
 	}
@@ -65,7 +158,7 @@ func (s *CommentService) Publish(ctx context.Context, comment *Comment) error {
 			// 再次校验视频是否存在（事务内）
 			if err := tx.Select("id").First(&Video{}, comment.VideoID).Error; err != nil {
 				if errors.Is(err, gorm.ErrRecordNotFound) {
-					return errors.New("video not found")
+					return ErrVideoNotFound
 				}
 				return err
 			}
@@ -73,17 +166,23 @@ func (s *CommentService) Publish(ctx context.Context, comment *Comment) error {
 			if err := tx.Create(comment).Error; err != nil {
 				return err
 			}
-			// 更新视频热度（评论+1）
+			// 更新视频热度（评论+CommentPopularityWeight）
 			return tx.Model(&Video{}).Where("id = ?", comment.VideoID).
-				UpdateColumn("popularity", gorm.Expr("popularity + 1")).Error
+				UpdateColumns(map[string]any{"popularity": gorm.Expr("popularity + ?", CommentPopularityWeight), "updated_at": time.Now()}).Error
 		}); err != nil {
 			return err
 		}
+		// 推送最新评论数给正在观看该视频的客户端
+		PublishCommentsCount(ctx, s.cache, s.repo, comment.VideoID)
+		// 评论已写入：通知视频作者（与 Worker 行为一致，失败只记录日志）
+		if err := NotifyVideoAuthor(ctx, s.VideoRepository, s.notifier, notification.TypeComment, comment.AuthorID, comment.VideoID); err != nil {
+			log.Printf("comment: failed to notify author of video %d: %v", comment.VideoID, err)
+		}
 	}
 
 	// Fallback: direct Redis update when popularity MQ publish fails.
 	if !redisEnqueued {
-		UpdatePopularityCache(ctx, s.cache, comment.VideoID, 1)
+		UpdatePopularityCache(ctx, s.cache, comment.VideoID, CommentPopularityWeight)
 	}
 	return nil
 }
@@ -92,8 +191,9 @@ func (s *CommentService) Publish(ctx context.Context, comment *Comment) error {
 // 业务流程：
 // 1. 查询评论是否存在
 // 2. 校验操作者是否为评论作者（防止删除他人评论）
-// 3. 优先使用MQ异步处理：发送删除评论消息到队列
-// 4. MQ失败时Fallback：直接删除数据库记录
+// 3. 优先使用MQ异步处理：发送删除评论消息，由 Worker 以评论是否真正被删除为准扣减热度（数据库 + Redis）
+// 4. MQ失败时Fallback：在事务中删除评论记录并扣减视频热度（不小于0）
+// 5. Fallback 确实删除了评论时，更新Redis热度缓存（优先走热度MQ）；重复删除不会重复扣减热度
 // 参数：
 //   - ctx: 上下文
 //   - commentID: 评论ID
@@ -102,48 +202,371 @@ func (s *CommentService) Delete(ctx context.Context, commentID uint, accountID u
 	// 1. 查询评论是否存在
 	comment, err := s.repo.GetByID(ctx, commentID)
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrCommentNotFound
+		}
 		return err
 	}
 	if comment == nil {
-		return errors.New("comment not found")
+		return ErrCommentNotFound
 	}
 
 	// 2. 校验操作者是否为评论作者
 	if comment.AuthorID != accountID {
-		return errors.New("permission denied")
+		return ErrCommentPermissionDenied
 	}
 
-	// 3. 尝试使用MQ异步处理
+	// 3. 优先使用MQ异步处理：Worker 以评论是否真正被删除为准扣减热度（数据库 + Redis）
 	if s.commentMQ != nil {
 		if err := s.commentMQ.Delete(ctx, commentID); err == nil {
 			return nil
 		}
 	}
 
-	// 4. Fallback: MQ发送失败时，直接删除数据库记录
-	return s.repo.DeleteComment(ctx, comment)
+	// 4. Fallback: MQ发送失败时，直接软删除评论并扣减视频热度
+	// 以删除的行数为准：没有删除任何记录说明评论已被删除（如重复点击），不修改热度
+	deleted := false
+	if err := s.repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		del := tx.Delete(&Comment{}, comment.ID)
+		if del.Error != nil {
+			return del.Error
+		}
+		if del.RowsAffected == 0 {
+			return nil
+		}
+		deleted = true
+		// 撤销评论带来的热度（GREATEST 保证不小于0）
+		return tx.Model(&Video{}).Where("id = ?", comment.VideoID).
+			UpdateColumns(map[string]any{"popularity": gorm.Expr("GREATEST(popularity - ?, 0)", CommentPopularityWeight), "updated_at": time.Now()}).Error
+	}); err != nil {
+		return err
+	}
+	if !deleted {
+		return nil
+	}
+
+	// 5. 评论已删除：更新Redis热度（优先走热度MQ，失败时直接更新缓存），推送最新评论数
+	if s.popularityMQ == nil || s.popularityMQ.Update(ctx, comment.VideoID, -CommentPopularityWeight) != nil {
+		UpdatePopularityCache(ctx, s.cache, comment.VideoID, -CommentPopularityWeight)
+	}
+	PublishCommentsCount(ctx, s.cache, s.repo, comment.VideoID)
+	return nil
+}
+
+// Restore 撤销删除评论（仅评论作者，删除后 CommentRestoreWindow 内有效）
+// 业务流程：
+// 1. 查询已被软删除的评论（删除消息尚未被 Worker 处理时评论仍未删除，返回 ErrDeletedCommentNotFound）
+// 2. 校验操作者是否为评论作者、是否仍在撤销窗口内
+// 3. 校验视频评论数是否已达上限（达到上限返回 ErrCommentLimitReached）
+// 4. 在事务中清空 deleted_at 并加回视频热度
+// 5. 更新Redis热度缓存（优先使用热度MQ），推送最新评论数
+// 参数：
+//   - ctx: 上下文
+//   - commentID: 评论ID
+//   - accountID: 操作者的账户ID
+func (s *CommentService) Restore(ctx context.Context, commentID uint, accountID uint) error {
+	// 1. 查询已被软删除的评论
+	comment, err := s.repo.GetDeletedByID(ctx, commentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrDeletedCommentNotFound
+		}
+		return err
+	}
+
+	// 2. 校验作者和撤销窗口
+	if comment.AuthorID != accountID {
+		return ErrCommentPermissionDenied
+	}
+	if !comment.DeletedAt.Valid || time.Since(comment.DeletedAt.Time) > CommentRestoreWindow {
+		return ErrCommentRestoreExpired
+	}
+
+	// 3. 评论数上限：恢复的评论重新计入评论数，不能借删除再恢复绕过上限
+	if err := s.checkCommentLimit(ctx, comment.VideoID); err != nil {
+		return err
+	}
+
+	// 4. 恢复评论并加回视频热度
+	if err := s.repo.RestoreWithPopularity(ctx, comment, CommentPopularityWeight); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// 并发恢复：另一个请求已经恢复了该评论
+			return ErrDeletedCommentNotFound
+		}
+		return err
+	}
+
+	// 5. 更新Redis热度缓存，推送最新评论数
+	if s.popularityMQ == nil || s.popularityMQ.Update(ctx, comment.VideoID, CommentPopularityWeight) != nil {
+		UpdatePopularityCache(ctx, s.cache, comment.VideoID, CommentPopularityWeight)
+	}
+	PublishCommentsCount(ctx, s.cache, s.repo, comment.VideoID)
+	return nil
 }
 
 // GetAll 查询视频的所有评论
 // 业务流程：
-// 1. 校验视频是否存在
+// 1. 校验视频是否存在（非公开视频只有作者本人可以查看，其他人返回 ErrVideoForbidden）
 // 2. 查询指定视频的所有评论（按创建时间倒序）
+// 3. 批量查询当前用户的点赞状态
 // 参数：
 //   - ctx: 上下文
 //   - videoID: 视频ID
+//   - viewerAccountID: 当前用户ID（未登录为 0）
 // 返回：
-//   - []Comment: 评论列表
+//   - []CommentItem: 评论列表
 //   - error: 错误信息
-func (s *CommentService) GetAll(ctx context.Context, videoID uint) ([]Comment, error) {
-	// 1. 校验视频是否存在
-	exists, err := s.VideoRepository.IsExist(ctx, videoID)
+func (s *CommentService) GetAll(ctx context.Context, videoID uint, viewerAccountID uint) ([]CommentItem, error) {
+	// 1. 校验视频是否存在（非公开视频只有作者本人可以查看评论）
+	if err := checkVideoAccess(ctx, s.VideoRepository, videoID, viewerAccountID); err != nil {
+		return nil, err
+	}
+
+	// 2. 查询指定视频的所有评论
+	comments, err := s.repo.GetAllComments(ctx, videoID)
 	if err != nil {
 		return nil, err
 	}
+
+	// 3. 批量查询点赞状态
+	return s.buildCommentItems(ctx, comments, viewerAccountID)
+}
+
+// List 分页查询视频的评论
+// 业务流程：
+// 1. 校验参数（limit 默认 10，最大 50）
+// 2. 校验视频是否存在（非公开视频只有作者本人可以查看，其他人返回 ErrVideoForbidden）
+// 3. 按ID游标查询一页评论（多查一条用于判断 has_more）
+// 4. 批量查询当前用户的点赞状态
+// 5. includeTotal 为 true 时额外统计评论总数
+// 参数：
+//   - ctx: 上下文
+//   - videoID: 视频ID
+//   - beforeID: 游标（上一页最后一条评论的ID，第一页传 0）
+//   - limit: 每页数量
+//   - includeTotal: 是否返回评论总数
+//   - viewerAccountID: 当前用户ID（未登录为 0）
+// 返回：
+//   - ListCommentsResponse: 评论列表及分页信息
+//   - error: 错误信息
+func (s *CommentService) List(ctx context.Context, videoID, beforeID uint, limit int, includeTotal bool, viewerAccountID uint) (ListCommentsResponse, error) {
+	// 1. 校验参数
+	if limit <= 0 || limit > 50 {
+		limit = 10
+	}
+
+	// 2. 校验视频是否存在（非公开视频只有作者本人可以查看评论）
+	if err := checkVideoAccess(ctx, s.VideoRepository, videoID, viewerAccountID); err != nil {
+		return ListCommentsResponse{}, err
+	}
+
+	// 3. 查询一页评论
+	comments, err := s.repo.ListByVideo(ctx, videoID, beforeID, limit+1)
+	if err != nil {
+		return ListCommentsResponse{}, err
+	}
+	hasMore := len(comments) > limit
+	if hasMore {
+		comments = comments[:limit]
+	}
+
+	// 4. 批量查询点赞状态
+	items, err := s.buildCommentItems(ctx, comments, viewerAccountID)
+	if err != nil {
+		return ListCommentsResponse{}, err
+	}
+	resp := ListCommentsResponse{Comments: items, HasMore: hasMore}
+	if len(comments) > 0 {
+		resp.NextBeforeID = comments[len(comments)-1].ID
+	}
+
+	// 5. 按需统计评论总数（额外一次 COUNT 查询，普通滚动加载不需要）
+	if includeTotal {
+		total, err := s.repo.CountByVideo(ctx, videoID)
+		if err != nil {
+			return ListCommentsResponse{}, err
+		}
+		resp.Total = &total
+	}
+	return resp, nil
+}
+
+// ListByAuthor 分页查询用户的评论历史（用户自查、审核）
+// 业务流程：
+// 1. 校验参数（limit 默认 10，最大 50）
+// 2. 按ID游标查询一页评论（多查一条用于判断 has_more）
+// 3. 过滤非公开视频下的评论：只有评论者本人、视频作者可以看到
+// 参数：
+//   - ctx: 上下文
+//   - authorID: 评论者ID
+//   - beforeID: 游标（上一页最后一条评论的ID，第一页传 0）
+//   - limit: 每页数量
+//   - viewerAccountID: 当前用户ID（未登录为 0）
+// 返回：
+//   - ListAuthorCommentsResponse: 评论列表及分页信息
+//   - error: 错误信息
+func (s *CommentService) ListByAuthor(ctx context.Context, authorID, beforeID uint, limit int, viewerAccountID uint) (ListAuthorCommentsResponse, error) {
+	// 1. 校验参数
+	if limit <= 0 || limit > 50 {
+		limit = 10
+	}
+
+	// 2. 查询一页评论
+	rows, err := s.repo.ListByAuthor(ctx, authorID, beforeID, limit+1)
+	if err != nil {
+		return ListAuthorCommentsResponse{}, err
+	}
+	hasMore := len(rows) > limit
+	if hasMore {
+		rows = rows[:limit]
+	}
+
+	// 3. 过滤非公开视频下的评论（游标仍按过滤前的最后一条计算，过滤后本页可能少于 limit 条）
+	items := make([]AuthorCommentItem, 0, len(rows))
+	for _, item := range rows {
+		if isPublic(item.VideoVisibility) || viewerAccountID == authorID || viewerAccountID == item.VideoAuthorID {
+			items = append(items, item)
+		}
+	}
+	resp := ListAuthorCommentsResponse{Comments: items, HasMore: hasMore}
+	if len(rows) > 0 {
+		resp.NextBeforeID = rows[len(rows)-1].ID
+	}
+	return resp, nil
+}
+
+// buildCommentItems 组装评论列表项（一次批量查询当前用户的点赞状态）
+func (s *CommentService) buildCommentItems(ctx context.Context, comments []Comment, viewerAccountID uint) ([]CommentItem, error) {
+	likedMap := make(map[uint]bool)
+	if viewerAccountID != 0 && s.likeRepo != nil && len(comments) > 0 {
+		ids := make([]uint, 0, len(comments))
+		for _, c := range comments {
+			ids = append(ids, c.ID)
+		}
+		m, err := s.likeRepo.BatchGetLiked(ctx, ids, viewerAccountID)
+		if err != nil {
+			return nil, err
+		}
+		likedMap = m
+	}
+
+	items := make([]CommentItem, 0, len(comments))
+	for _, c := range comments {
+		items = append(items, CommentItem{Comment: c, IsLiked: likedMap[c.ID]})
+	}
+	return items, nil
+}
+
+// Like 点赞评论
+// 业务流程：
+// 1. 校验评论是否存在
+// 2. 校验是否已点赞（防止重复点赞）
+// 3. 优先使用MQ异步处理：发送点赞评论消息到队列
+// 4. MQ失败时Fallback：直接写入数据库事务
+// 参数：
+//   - ctx: 上下文
+//   - commentID: 评论ID
+//   - accountID: 点赞用户ID
+func (s *CommentService) Like(ctx context.Context, commentID, accountID uint) error {
+	if commentID == 0 || accountID == 0 {
+		return ErrCommentLikeInvalid
+	}
+
+	// 1. 校验评论是否存在
+	exists, err := s.repo.IsExist(ctx, commentID)
+	if err != nil {
+		return err
+	}
 	if !exists {
-		return nil, errors.New("video not found")
+		return ErrCommentNotFound
 	}
 
-	// 2. 查询指定视频的所有评论
-	return s.repo.GetAllComments(ctx, videoID)
+	// 2. 校验是否已点赞
+	isLiked, err := s.likeRepo.IsLiked(ctx, commentID, accountID)
+	if err != nil {
+		return err
+	}
+	if isLiked {
+		return ErrCommentAlreadyLiked
+	}
+
+	// 3. 尝试使用MQ异步处理
+	if s.commentMQ != nil {
+		if err := s.commentMQ.Like(ctx, accountID, commentID); err == nil {
+			return nil
+		}
+	}
+
+	// 4. Fallback: MQ发送失败时，直接写入数据库事务
+	return s.repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 4.1 插入评论点赞记录
+		like := &CommentLike{CommentID: commentID, AccountID: accountID, CreatedAt: time.Now()}
+		if err := tx.Create(like).Error; err != nil {
+			if isDupKey(err) {
+				return ErrCommentAlreadyLiked
+			}
+			return err
+		}
+
+		// 4.2 更新评论点赞数（增量+1）
+		return tx.Model(&Comment{}).Where("id = ?", commentID).
+			UpdateColumn("likes_count", gorm.Expr("likes_count + 1")).Error
+	})
+}
+
+// Unlike 取消点赞评论
+// 业务流程：
+// 1. 校验评论是否存在
+// 2. 校验是否已点赞（防止取消未点赞的评论）
+// 3. 优先使用MQ异步处理：发送取消点赞评论消息到队列
+// 4. MQ失败时Fallback：直接写入数据库事务
+// 参数：
+//   - ctx: 上下文
+//   - commentID: 评论ID
+//   - accountID: 点赞用户ID
+func (s *CommentService) Unlike(ctx context.Context, commentID, accountID uint) error {
+	if commentID == 0 || accountID == 0 {
+		return ErrCommentLikeInvalid
+	}
+
+	// 1. 校验评论是否存在
+	exists, err := s.repo.IsExist(ctx, commentID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrCommentNotFound
+	}
+
+	// 2. 校验是否已点赞
+	isLiked, err := s.likeRepo.IsLiked(ctx, commentID, accountID)
+	if err != nil {
+		return err
+	}
+	if !isLiked {
+		return ErrCommentNotLiked
+	}
+
+	// 3. 尝试使用MQ异步处理
+	if s.commentMQ != nil {
+		if err := s.commentMQ.Unlike(ctx, accountID, commentID); err == nil {
+			return nil
+		}
+	}
+
+	// 4. Fallback: MQ发送失败时，直接写入数据库事务
+	return s.repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 4.1 删除评论点赞记录
+		del := tx.Where("comment_id = ? AND account_id = ?", commentID, accountID).Delete(&CommentLike{})
+		if del.Error != nil {
+			return del.Error
+		}
+		if del.RowsAffected == 0 {
+			return ErrCommentNotLiked
+		}
+
+		// 4.2 更新评论点赞数（增量-1，确保不小于0）
+		return tx.Model(&Comment{}).Where("id = ?", commentID).
+			UpdateColumn("likes_count", gorm.Expr("GREATEST(likes_count - 1, 0)")).Error
+	})
 }
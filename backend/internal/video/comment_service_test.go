@@ -0,0 +1,85 @@
+package video
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// expectVideoAccess 设置 GetAccess 查询视频作者和可见性的期望
+func expectVideoAccess(mock sqlmock.Sqlmock, videoID, authorID uint, visibility string) {
+	mock.ExpectQuery("SELECT `author_id`,`visibility` FROM `videos` WHERE id = \\?").
+		WithArgs(videoID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"author_id", "visibility"}).AddRow(authorID, visibility))
+}
+
+func newTestCommentService(t *testing.T) (*CommentService, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock := newMockDB(t)
+	s := NewCommentService(NewCommentRepository(db), NewCommentLikeRepository(db), NewVideoRepository(db), nil, nil, nil, nil, 0, 0, 0, nil)
+	return s, mock
+}
+
+// 非公开视频的评论只有作者本人可以查看：其他用户返回 ErrVideoForbidden，且不查询评论
+func TestCommentListPrivateVideoForbiddenForNonOwner(t *testing.T) {
+	const (
+		videoID  uint = 42
+		authorID uint = 7
+	)
+	for _, visibility := range []string{VisibilityPrivate, VisibilityDraft} {
+		for _, viewer := range []uint{0, 8} {
+			s, mock := newTestCommentService(t)
+			expectVideoAccess(mock, videoID, authorID, visibility)
+			if _, err := s.List(context.Background(), videoID, 0, 10, false, viewer); !errors.Is(err, ErrVideoForbidden) {
+				t.Fatalf("List(%s video, viewer=%d) err = %v, want ErrVideoForbidden", visibility, viewer, err)
+			}
+
+			expectVideoAccess(mock, videoID, authorID, visibility)
+			if _, err := s.GetAll(context.Background(), videoID, viewer); !errors.Is(err, ErrVideoForbidden) {
+				t.Fatalf("GetAll(%s video, viewer=%d) err = %v, want ErrVideoForbidden", visibility, viewer, err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+}
+
+func TestCommentListPrivateVideoAllowedForOwner(t *testing.T) {
+	const (
+		videoID  uint = 42
+		authorID uint = 7
+	)
+	s, mock := newTestCommentService(t)
+	expectVideoAccess(mock, videoID, authorID, VisibilityPrivate)
+	mock.ExpectQuery("SELECT \\* FROM `comments` WHERE video_id = \\?").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "video_id", "author_id", "content"}).AddRow(3, videoID, 9, "hi"))
+	mock.ExpectQuery("SELECT \\* FROM `comment_likes`").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	resp, err := s.List(context.Background(), videoID, 0, 10, false, authorID)
+	if err != nil {
+		t.Fatalf("List as owner: %v", err)
+	}
+	if len(resp.Comments) != 1 || resp.Comments[0].ID != 3 {
+		t.Fatalf("List as owner = %+v, want comment 3", resp.Comments)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCommentPublishPrivateVideoForbiddenForNonOwner(t *testing.T) {
+	s, mock := newTestCommentService(t)
+	expectVideoAccess(mock, 42, 7, VisibilityPrivate)
+
+	err := s.Publish(context.Background(), &Comment{VideoID: 42, AuthorID: 8, Username: "bob", Content: "hello"})
+	if !errors.Is(err, ErrVideoForbidden) {
+		t.Fatalf("Publish err = %v, want ErrVideoForbidden", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
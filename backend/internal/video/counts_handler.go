@@ -0,0 +1,119 @@
+package video
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"feedsystem_video_go/internal/apierror"
+	"feedsystem_video_go/internal/middleware/jwt"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/websocket"
+)
+
+// countsWriteTimeout 向 WebSocket 客户端写入单条消息的超时时间（超时视为客户端已断开）
+const countsWriteTimeout = 10 * time.Second
+
+// countsMaxClientFrame 客户端发来的单帧最大字节数（客户端不需要发送数据，只允许关闭帧等小帧）
+const countsMaxClientFrame = 512
+
+// CountsHandler 视频实时计数处理器，通过 WebSocket 推送点赞数/评论数/热度变化
+type CountsHandler struct {
+	service *VideoService // 视频服务层（校验视频是否存在及可见性）
+	hub     *CountsHub    // 实时计数推送中心
+}
+
+// NewCountsHandler 创建视频实时计数处理器实例
+func NewCountsHandler(service *VideoService, hub *CountsHub) *CountsHandler {
+	return &CountsHandler{service: service, hub: hub}
+}
+
+// Subscribe 订阅视频实时计数接口
+// 路由：GET /ws/video/:id（WebSocket）
+// 功能：连接建立后先推送一次当前点赞数，之后每次点赞/评论/热度变化推送一条 LiveCountsEvent（JSON 文本帧）
+// 非公开视频只有作者本人可以订阅（未登录时 viewerID = 0）
+func (h *CountsHandler) Subscribe(c *gin.Context) {
+	// 1. 解析视频ID
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		apierror.Write(c, http.StatusBadRequest, apierror.CodeInvalidArgument, "invalid video id")
+		return
+	}
+
+	// 2. 校验视频是否存在及可见性（在升级 WebSocket 之前，错误可以按普通 JSON 响应返回）
+	viewerID, err := jwt.GetAccountID(c)
+	if err != nil {
+		viewerID = 0
+	}
+	video, err := h.service.GetDetail(c.Request.Context(), uint(id), viewerID)
+	if err != nil {
+		apierror.FromError(c, err, videoErrors...)
+		return
+	}
+
+	// 3. 订阅视频计数事件，连接结束后取消订阅
+	sub, err := h.hub.subscribe(video.ID)
+	if err != nil {
+		apierror.FromError(c, err)
+		return
+	}
+	defer h.hub.unsubscribe(sub)
+
+	// 4. 升级为 WebSocket 连接（ServeHTTP 阻塞到 Handler 返回）
+	// 推送的计数是公开数据且接口不依赖 Cookie 鉴权，因此不校验 Origin
+	server := websocket.Server{
+		Handshake: func(*websocket.Config, *http.Request) error { return nil },
+		Handler:   func(ws *websocket.Conn) { h.serve(ws, video, sub) },
+	}
+	server.ServeHTTP(c.Writer, c.Request)
+}
+
+// serve 向一个 WebSocket 连接推送计数事件，直到客户端断开或推送中心停止
+func (h *CountsHandler) serve(ws *websocket.Conn, video *Video, sub *countsSubscriber) {
+	defer ws.Close()
+	ws.MaxPayloadBytes = countsMaxClientFrame
+
+	// 1. 推送当前点赞数快照
+	likes := video.LikesCount
+	snapshot, _ := json.Marshal(LiveCountsEvent{VideoID: video.ID, LikesCount: &likes})
+	if err := h.send(ws, snapshot); err != nil {
+		return
+	}
+
+	// 2. 读协程：客户端不需要发送数据，读取只用于及时感知断开（关闭帧、连接错误、超大帧）
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		var discard []byte
+		for {
+			if err := websocket.Message.Receive(ws, &discard); err != nil {
+				return
+			}
+		}
+	}()
+
+	// 3. 推送计数事件
+	for {
+		select {
+		case <-done:
+			return
+		case msg, ok := <-sub.ch:
+			if !ok {
+				return
+			}
+			if err := h.send(ws, msg); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// send 发送一条 JSON 文本帧
+func (h *CountsHandler) send(ws *websocket.Conn, msg []byte) error {
+	if err := ws.SetWriteDeadline(time.Now().Add(countsWriteTimeout)); err != nil {
+		return err
+	}
+	return websocket.Message.Send(ws, string(msg))
+}
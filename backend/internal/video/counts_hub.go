@@ -0,0 +1,199 @@
+package video
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	rediscache "feedsystem_video_go/internal/middleware/redis"
+)
+
+// liveCountsChannelPrefix 实时计数频道前缀，每个视频一个频道：video:live:{id}
+const liveCountsChannelPrefix = "video:live:"
+
+// countsSubscriberBuffer 每个 WebSocket 连接的待发送消息缓冲数量
+const countsSubscriberBuffer = 16
+
+// ErrCountsHubClosed 实时计数推送已停止
+var ErrCountsHubClosed = errors.New("counts hub is closed")
+
+// LiveCountsEvent 视频实时计数变化事件（Worker 或 Fallback 发布到 Redis，CountsHub 推送给 WebSocket 客户端）
+// 点赞数/评论数为变化后的最新值（只包含本次变化的字段），热度为增量
+type LiveCountsEvent struct {
+	VideoID         uint   `json:"video_id"`                   // 视频ID
+	LikesCount      *int64 `json:"likes_count,omitempty"`      // 最新点赞数
+	CommentsCount   *int64 `json:"comments_count,omitempty"`   // 最新评论数
+	PopularityDelta int64  `json:"popularity_delta,omitempty"` // 热度变化量
+}
+
+// liveCountsChannel 视频实时计数频道名
+func liveCountsChannel(videoID uint) string {
+	return liveCountsChannelPrefix + strconv.FormatUint(uint64(videoID), 10)
+}
+
+// PublishLiveCounts 发布视频计数变化事件（Redis 不可用时不做任何操作，失败只丢弃事件）
+func PublishLiveCounts(ctx context.Context, cache *rediscache.Client, evt LiveCountsEvent) {
+	if cache == nil || evt.VideoID == 0 {
+		return
+	}
+	b, err := json.Marshal(evt)
+	if err != nil {
+		return
+	}
+	opCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	_ = cache.Publish(opCtx, liveCountsChannel(evt.VideoID), b)
+}
+
+// PublishLikesCount 查询视频最新点赞数并发布（点赞/取消点赞写入成功后调用）
+func PublishLikesCount(ctx context.Context, cache *rediscache.Client, repo *VideoRepository, videoID uint) {
+	if cache == nil || repo == nil {
+		return
+	}
+	v, err := repo.GetByID(ctx, videoID)
+	if err != nil {
+		return
+	}
+	n := v.LikesCount
+	PublishLiveCounts(ctx, cache, LiveCountsEvent{VideoID: videoID, LikesCount: &n})
+}
+
+// PublishCommentsCount 查询视频最新评论数并发布（发布/删除评论写入成功后调用）
+func PublishCommentsCount(ctx context.Context, cache *rediscache.Client, repo *CommentRepository, videoID uint) {
+	if cache == nil || repo == nil {
+		return
+	}
+	n, err := repo.CountByVideo(ctx, videoID)
+	if err != nil {
+		return
+	}
+	PublishLiveCounts(ctx, cache, LiveCountsEvent{VideoID: videoID, CommentsCount: &n})
+}
+
+// countsSubscriber 一个 WebSocket 连接对某个视频的订阅
+type countsSubscriber struct {
+	videoID uint
+	ch      chan []byte // 待发送的事件（JSON），CountsHub 停止时关闭
+}
+
+// CountsHub 视频实时计数推送中心
+// 职责：按模式订阅 Redis 中的所有视频频道（video:live:*），把收到的事件分发给本进程中订阅该视频的所有连接
+// 多个 API 实例各自订阅，Worker 发布一次即可推送到所有实例上的连接；没有连接订阅的视频事件直接丢弃
+type CountsHub struct {
+	cache *rediscache.Client // Redis 缓存客户端（订阅频道）
+
+	mu     sync.Mutex
+	subs   map[uint]map[*countsSubscriber]struct{} // 视频ID -> 订阅该视频的连接
+	closed bool
+}
+
+// NewCountsHub 创建实时计数推送中心
+// cache 为 nil（Redis 不可用）时返回 nil，调用方据此不注册 WebSocket 路由
+func NewCountsHub(cache *rediscache.Client) *CountsHub {
+	if cache == nil {
+		return nil
+	}
+	return &CountsHub{
+		cache: cache,
+		subs:  make(map[uint]map[*countsSubscriber]struct{}),
+	}
+}
+
+// Run 接收 Redis 订阅消息并分发（阻塞，直到 ctx 被取消）
+// 退出时关闭所有连接的事件通道（对应的 WebSocket 连接随之关闭）
+func (h *CountsHub) Run(ctx context.Context) error {
+	if h == nil || h.cache == nil {
+		return errors.New("counts hub is not initialized")
+	}
+	defer h.close()
+
+	msgs, cancel := h.cache.PSubscribe(ctx, liveCountsChannelPrefix+"*")
+	defer cancel()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case msg, ok := <-msgs:
+			if !ok {
+				return errors.New("counts hub: subscription closed")
+			}
+			h.dispatch(msg)
+		}
+	}
+}
+
+// dispatch 把事件分发给订阅该视频的所有连接
+// 连接的缓冲已满（客户端读取过慢）时丢弃本条事件：点赞数/评论数是最新值，下一条事件会覆盖
+func (h *CountsHub) dispatch(msg rediscache.Message) {
+	id, err := strconv.ParseUint(strings.TrimPrefix(msg.Channel, liveCountsChannelPrefix), 10, 64)
+	if err != nil {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for sub := range h.subs[uint(id)] {
+		select {
+		case sub.ch <- msg.Payload:
+		default:
+		}
+	}
+}
+
+// subscribe 为一个连接订阅视频计数事件
+func (h *CountsHub) subscribe(videoID uint) (*countsSubscriber, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return nil, ErrCountsHubClosed
+	}
+
+	set := h.subs[videoID]
+	if set == nil {
+		set = make(map[*countsSubscriber]struct{})
+		h.subs[videoID] = set
+	}
+	sub := &countsSubscriber{videoID: videoID, ch: make(chan []byte, countsSubscriberBuffer)}
+	set[sub] = struct{}{}
+	return sub, nil
+}
+
+// unsubscribe 取消一个连接的订阅
+func (h *CountsHub) unsubscribe(sub *countsSubscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		// Run 退出时已关闭所有事件通道
+		return
+	}
+
+	set := h.subs[sub.videoID]
+	if _, ok := set[sub]; !ok {
+		return
+	}
+	delete(set, sub)
+	close(sub.ch)
+	if len(set) == 0 {
+		delete(h.subs, sub.videoID)
+	}
+}
+
+// close 停止推送：关闭所有连接的事件通道
+func (h *CountsHub) close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return
+	}
+	h.closed = true
+	for _, set := range h.subs {
+		for sub := range set {
+			close(sub.ch)
+		}
+	}
+	h.subs = nil
+}
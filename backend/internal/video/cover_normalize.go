@@ -0,0 +1,164 @@
+package video
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"log"
+	"strings"
+
+	"feedsystem_video_go/internal/config"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // 注册 WebP 解码器（只用于读取上传的 WebP 封面）
+)
+
+// 封面规范化默认值
+const (
+	defaultCoverFormat       = "jpeg"
+	defaultCoverQuality      = 80
+	defaultCoverMaxDimension = 1280
+)
+
+// maxCoverPixels 允许解码的封面最大像素数（约 4000 万像素），防止小文件解码出超大图片耗尽内存
+const maxCoverPixels = 40_000_000
+
+// errCoverTooLarge 封面像素数超过上限，不做转换
+var errCoverTooLarge = errors.New("cover image has too many pixels")
+
+// coverNormalizer 封面规范化：把上传的封面统一转码为目标格式，并把长边缩小到上限以内
+// 只支持标准库可以编码的格式（jpeg/png）；Go 没有纯 Go 的 WebP/AVIF 编码器，配置为这两种格式时退回 jpeg
+type coverNormalizer struct {
+	enabled bool   // 是否启用（video.normalize_covers）
+	format  string // 目标格式：jpeg / png
+	quality int    // JPEG 质量（1-100）
+	maxDim  int    // 长边最大像素
+}
+
+func newCoverNormalizer(cfg config.VideoConfig) coverNormalizer {
+	n := coverNormalizer{
+		enabled: cfg.NormalizeCovers,
+		format:  strings.ToLower(strings.TrimSpace(cfg.CoverFormat)),
+		quality: cfg.CoverQuality,
+		maxDim:  cfg.CoverMaxDimension,
+	}
+	if n.format == "jpg" {
+		n.format = "jpeg"
+	}
+	switch n.format {
+	case "jpeg", "png":
+	case "":
+		n.format = defaultCoverFormat
+	default:
+		if n.enabled {
+			log.Printf("video: cover_format %q is not supported (jpeg/png only), using %s", n.format, defaultCoverFormat)
+		}
+		n.format = defaultCoverFormat
+	}
+	if n.quality <= 0 || n.quality > 100 {
+		n.quality = defaultCoverQuality
+	}
+	if n.maxDim <= 0 {
+		n.maxDim = defaultCoverMaxDimension
+	}
+	return n
+}
+
+// ext 目标格式对应的文件扩展名
+func (n coverNormalizer) ext() string {
+	if n.format == "png" {
+		return ".png"
+	}
+	return ".jpg"
+}
+
+// normalize 转码封面
+// 返回：
+//   - []byte: 转码后的图片
+//   - string: 转码后的扩展名（如 .jpg）
+//   - bool: 是否需要使用转码结果；原图已经是目标格式且尺寸在上限以内时返回 false（保留原图，避免重复有损压缩）
+//   - error: 解码或编码失败（调用方保留原图）
+func (n coverNormalizer) normalize(data []byte) ([]byte, string, bool, error) {
+	// 1. 先只读取尺寸，拒绝像素数过大的图片
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", false, err
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || int64(cfg.Width)*int64(cfg.Height) > maxCoverPixels {
+		return nil, "", false, errCoverTooLarge
+	}
+	if format == n.format && cfg.Width <= n.maxDim && cfg.Height <= n.maxDim {
+		return nil, "", false, nil
+	}
+
+	// 2. 解码并按比例缩小（长边不超过 maxDim）
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", false, err
+	}
+	img := n.resize(src)
+
+	// 3. 编码为目标格式（JPEG 不支持透明通道，先铺白色背景）
+	var buf bytes.Buffer
+	switch n.format {
+	case "png":
+		err = png.Encode(&buf, img)
+	default:
+		err = jpeg.Encode(&buf, flatten(img), &jpeg.Options{Quality: n.quality})
+	}
+	if err != nil {
+		return nil, "", false, fmt.Errorf("encode cover: %w", err)
+	}
+	return buf.Bytes(), n.ext(), true, nil
+}
+
+// resize 按比例缩小图片，使长边不超过 maxDim（不放大）
+func (n coverNormalizer) resize(src image.Image) image.Image {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= n.maxDim && h <= n.maxDim {
+		return src
+	}
+	if w >= h {
+		h = h * n.maxDim / w
+		w = n.maxDim
+	} else {
+		w = w * n.maxDim / h
+		h = n.maxDim
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, max(w, 1), max(h, 1)))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, b, draw.Over, nil)
+	return dst
+}
+
+// flatten 把图片绘制到白色背景上（去掉透明通道）
+func flatten(src image.Image) image.Image {
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), src, b.Min, draw.Over)
+	return dst
+}
+
+// NormalizeCover 按配置转码上传的封面（video.normalize_covers 关闭时不做任何处理）
+// 参数：
+//   - data: 原始图片
+// 返回：
+//   - []byte: 转码后的图片
+//   - string: 转码后的扩展名
+//   - bool: 是否使用转码结果（false 时保存原图）
+func (s *VideoService) NormalizeCover(data []byte) ([]byte, string, bool) {
+	if !s.covers.enabled {
+		return nil, "", false
+	}
+	out, ext, ok, err := s.covers.normalize(data)
+	if err != nil {
+		log.Printf("video: keep original cover, normalize failed: %v", err)
+		return nil, "", false
+	}
+	return out, ext, ok
+}
@@ -0,0 +1,64 @@
+package video
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// detailCacheControl 视频详情响应的缓存策略：只允许客户端短时间缓存（响应可能因登录用户不同而不同），过期后携带 If-None-Match 重新验证
+const detailCacheControl = "private, max-age=5"
+
+// detailETag 根据视频ID和更新时间生成弱 ETag，variant 非空时追加到末尾（响应附带额外内容，如作者资料）
+// 使用弱校验（W/）：详情可能来自短 TTL 的 Redis 缓存，与数据库中的计数略有差异
+func detailETag(v *Video, variant string) string {
+	if variant != "" {
+		return fmt.Sprintf(`W/"%d-%d-%s"`, v.ID, v.UpdatedAt.UnixNano(), variant)
+	}
+	return fmt.Sprintf(`W/"%d-%d"`, v.ID, v.UpdatedAt.UnixNano())
+}
+
+// writeDetailValidators 写入 ETag / Last-Modified / Cache-Control 响应头，并判断客户端缓存是否仍然有效
+// 优先按 If-None-Match 判断；没有 If-None-Match 时才使用 If-Modified-Since（RFC 9110 13.2.2）
+// variant 非空时响应还依赖视频以外的数据，updated_at 不能代表整个响应，不写 Last-Modified
+// 返回：
+//   - bool: 客户端缓存有效（调用方应返回 304）
+func writeDetailValidators(c *gin.Context, v *Video, variant string) bool {
+	etag := detailETag(v, variant)
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", detailCacheControl)
+	if variant != "" {
+		return etagMatch(c.GetHeader("If-None-Match"), etag)
+	}
+	if !v.UpdatedAt.IsZero() {
+		c.Header("Last-Modified", v.UpdatedAt.UTC().Format(http.TimeFormat))
+	}
+
+	if inm := c.GetHeader("If-None-Match"); inm != "" {
+		return etagMatch(inm, etag)
+	}
+	if ims := c.GetHeader("If-Modified-Since"); ims != "" && !v.UpdatedAt.IsZero() {
+		t, err := http.ParseTime(ims)
+		// Last-Modified 精度为秒，比较前截断
+		return err == nil && !v.UpdatedAt.Truncate(time.Second).After(t)
+	}
+	return false
+}
+
+// etagMatch 弱比较 If-None-Match 中的 ETag 列表（忽略 W/ 前缀，"*" 匹配任意值；header 为空时不匹配）
+func etagMatch(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+	want := strings.TrimPrefix(etag, "W/")
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == want {
+			return true
+		}
+	}
+	return false
+}
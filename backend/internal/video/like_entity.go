@@ -13,5 +13,15 @@ type Like struct {
 
 // LikeRequest 点赞请求体
 type LikeRequest struct {
-	VideoID uint `json:"video_id"` // 视频ID
+	VideoID uint `json:"video_id" binding:"required,gt=0"` // 视频ID
+}
+
+// BatchLikeStatusRequest 批量查询点赞状态请求体
+type BatchLikeStatusRequest struct {
+	VideoIDs []uint `json:"video_ids"` // 视频ID列表（最多 100 个）
+}
+
+// BatchLikeStatusResponse 批量查询点赞状态响应体
+type BatchLikeStatusResponse struct {
+	IsLiked map[uint]bool `json:"is_liked"` // videoID -> 是否已点赞（请求中的每个ID都会返回）
 }
@@ -1,7 +1,9 @@
 package video
 
 import (
+	"feedsystem_video_go/internal/apierror"
 	"feedsystem_video_go/internal/middleware/jwt"
+	"net/http"
 
 	"github.com/gin-gonic/gin"
 )
@@ -11,6 +13,17 @@ type LikeHandler struct {
 	service *LikeService // 点赞服务层
 }
 
+// likeErrors 点赞服务层错误到错误码/HTTP状态码的映射
+var likeErrors = []apierror.Mapping{
+	{Err: ErrLikeInvalid, Status: http.StatusBadRequest, Code: apierror.CodeInvalidArgument},
+	{Err: ErrLikeIDsRequired, Status: http.StatusBadRequest, Code: apierror.CodeInvalidArgument},
+	{Err: ErrTooManyVideoIDs, Status: http.StatusBadRequest, Code: "TOO_MANY_IDS"},
+	{Err: ErrVideoNotFound, Status: http.StatusNotFound, Code: "VIDEO_NOT_FOUND"},
+	{Err: ErrVideoForbidden, Status: http.StatusForbidden, Code: "VIDEO_FORBIDDEN"},
+	{Err: ErrAlreadyLiked, Status: http.StatusConflict, Code: "ALREADY_LIKED"},
+	{Err: ErrNotLiked, Status: http.StatusConflict, Code: "NOT_LIKED"},
+}
+
 // NewLikeHandler 创建点赞处理器实例
 func NewLikeHandler(service *LikeService) *LikeHandler {
 	return &LikeHandler{service: service}
@@ -24,36 +37,30 @@ func (lh *LikeHandler) Like(c *gin.Context) {
 	// 1. 解析JSON请求体
 	var req LikeRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(400, gin.H{"error": err.Error()})
+		apierror.BadRequest(c, err)
 		return
 	}
 
-	// 2. 校验视频ID
-	if req.VideoID <= 0 {
-		c.JSON(400, gin.H{"error": "video_id is required"})
-		return
-	}
-
-	// 3. 从JWT中间件获取当前登录用户ID
+	// 2. 从JWT中间件获取当前登录用户ID
 	accountID, err := jwt.GetAccountID(c)
 	if err != nil {
-		c.JSON(400, gin.H{"error": err.Error()})
+		apierror.Unauthorized(c)
 		return
 	}
 
-	// 4. 构造点赞对象
+	// 3. 构造点赞对象
 	like := &Like{
 		VideoID:   req.VideoID, // 视频ID
 		AccountID: accountID,  // 用户ID
 	}
 
-	// 5. 调用Service层处理点赞（含MQ异步更新点赞数）
+	// 4. 调用Service层处理点赞（含MQ异步更新点赞数）
 	if err := lh.service.Like(c.Request.Context(), like); err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		apierror.FromError(c, err, likeErrors...)
 		return
 	}
 
-	// 6. 返回成功消息
+	// 5. 返回成功消息
 	c.JSON(200, gin.H{"message": "like success"})
 }
 
@@ -65,36 +72,30 @@ func (lh *LikeHandler) Unlike(c *gin.Context) {
 	// 1. 解析JSON请求体
 	var req LikeRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(400, gin.H{"error": err.Error()})
+		apierror.BadRequest(c, err)
 		return
 	}
 
-	// 2. 校验视频ID
-	if req.VideoID <= 0 {
-		c.JSON(400, gin.H{"error": "video_id is required"})
-		return
-	}
-
-	// 3. 从JWT中间件获取当前登录用户ID
+	// 2. 从JWT中间件获取当前登录用户ID
 	accountID, err := jwt.GetAccountID(c)
 	if err != nil {
-		c.JSON(400, gin.H{"error": err.Error()})
+		apierror.Unauthorized(c)
 		return
 	}
 
-	// 4. 构造点赞对象
+	// 3. 构造点赞对象
 	like := &Like{
 		VideoID:   req.VideoID, // 视频ID
 		AccountID: accountID,  // 用户ID
 	}
 
-	// 5. 调用Service层处理取消点赞（含MQ异步更新点赞数）
+	// 4. 调用Service层处理取消点赞（含MQ异步更新点赞数）
 	if err := lh.service.Unlike(c.Request.Context(), like); err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		apierror.FromError(c, err, likeErrors...)
 		return
 	}
 
-	// 6. 返回成功消息
+	// 5. 返回成功消息
 	c.JSON(200, gin.H{"message": "unlike success"})
 }
 
@@ -107,32 +108,57 @@ func (lh *LikeHandler) IsLiked(c *gin.Context) {
 	// 1. 解析JSON请求体
 	var req LikeRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(400, gin.H{"error": err.Error()})
+		apierror.BadRequest(c, err)
 		return
 	}
 
-	// 2. 校验视频ID
-	if req.VideoID <= 0 {
-		c.JSON(400, gin.H{"error": "video_id is required"})
+	// 2. 从JWT中间件获取当前登录用户ID
+	accountID, err := jwt.GetAccountID(c)
+	if err != nil {
+		apierror.Unauthorized(c)
 		return
 	}
 
-	// 3. 从JWT中间件获取当前登录用户ID
+	// 3. 调用Service层查询是否已点赞
+	isLiked, err := lh.service.IsLiked(c.Request.Context(), req.VideoID, accountID)
+	if err != nil {
+		apierror.FromError(c, err, likeErrors...)
+		return
+	}
+
+	// 4. 返回点赞状态
+	c.JSON(200, gin.H{"is_liked": isLiked})
+}
+
+// BatchStatus 批量查询点赞状态接口
+// 路由：POST /like/batchStatus
+// 功能：查询当前用户对一组视频的点赞状态
+// 请求体：{"video_ids": [1, 2, 3]}（最多 100 个）
+// 返回：{"is_liked": {"1": true, "2": false, "3": false}}
+func (lh *LikeHandler) BatchStatus(c *gin.Context) {
+	// 1. 解析JSON请求体
+	var req BatchLikeStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.BadRequest(c, err)
+		return
+	}
+
+	// 2. 从JWT中间件获取当前登录用户ID
 	accountID, err := jwt.GetAccountID(c)
 	if err != nil {
-		c.JSON(400, gin.H{"error": err.Error()})
+		apierror.Unauthorized(c)
 		return
 	}
 
-	// 4. 调用Service层查询是否已点赞
-	isLiked, err := lh.service.IsLiked(c.Request.Context(), req.VideoID, accountID)
+	// 3. 调用Service层批量查询点赞状态
+	likedMap, err := lh.service.BatchIsLiked(c.Request.Context(), req.VideoIDs, accountID)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		apierror.FromError(c, err, likeErrors...)
 		return
 	}
 
-	// 5. 返回点赞状态
-	c.JSON(200, gin.H{"is_liked": isLiked})
+	// 4. 返回点赞状态
+	c.JSON(200, BatchLikeStatusResponse{IsLiked: likedMap})
 }
 
 // ListMyLikedVideos 查询我点赞的视频列表接口
@@ -142,14 +168,14 @@ func (lh *LikeHandler) ListMyLikedVideos(c *gin.Context) {
 	// 1. 从JWT中间件获取当前登录用户ID
 	accountID, err := jwt.GetAccountID(c)
 	if err != nil {
-		c.JSON(400, gin.H{"error": err.Error()})
+		apierror.Unauthorized(c)
 		return
 	}
 
 	// 2. 调用Service层查询点赞的视频列表
 	videos, err := lh.service.ListLikedVideos(c.Request.Context(), accountID)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		apierror.FromError(c, err, likeErrors...)
 		return
 	}
 
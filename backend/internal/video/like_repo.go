@@ -97,6 +97,22 @@ func (r *LikeRepository) IsLiked(ctx context.Context, videoID, accountID uint) (
 	return count > 0, nil
 }
 
+// CountDistinctLikers 统计点赞指定视频的不同用户数（用于刷量检测）
+// 参数：
+//   - ctx: 上下文
+//   - videoID: 视频ID
+// 返回：
+//   - int64: 不同点赞用户数
+//   - error: 错误信息
+func (r *LikeRepository) CountDistinctLikers(ctx context.Context, videoID uint) (int64, error) {
+	var total int64
+	err := r.db.WithContext(ctx).Model(&Like{}).
+		Where("video_id = ?", videoID).
+		Distinct("account_id").
+		Count(&total).Error
+	return total, err
+}
+
 // BatchGetLiked 批量查询是否已点赞（用于Feed流场景）
 // 参数：
 //   - ctx: 上下文
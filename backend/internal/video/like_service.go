@@ -5,6 +5,9 @@ import (
 	"errors"
 	"feedsystem_video_go/internal/middleware/rabbitmq"
 	rediscache "feedsystem_video_go/internal/middleware/redis"
+	"feedsystem_video_go/internal/notification"
+	"fmt"
+	"log"
 	"time"
 
 	"github.com/go-sql-driver/mysql"
@@ -19,12 +22,24 @@ type LikeService struct {
 	VideoRepo    *VideoRepository             // 视频仓储层，校验视频是否存在
 	cache        *rediscache.Client            // Redis缓存客户端
 	likeMQ       *rabbitmq.LikeMQ             // 点赞消息队列，异步处理点赞记录和点赞数
-	popularityMQ *rabbitmq.PopularityMQ       // 热度消息队列，异步更新视频热度
+	popularityMQ *rabbitmq.PopularityBatcher  // 热度消息合并发送器，异步更新视频热度
+	notifier     *notification.NotificationService // 通知服务层，Fallback 时通知视频作者（可能为 nil）
 }
 
+// maxBatchLikeStatus 批量查询点赞状态的单次最大视频数量
+const maxBatchLikeStatus = 100
+
+var (
+	ErrLikeInvalid     = errors.New("like is nil")                                          // 点赞对象为空
+	ErrLikeIDsRequired = errors.New("video_id and account_id are required")                 // 视频ID和用户ID不能为空
+	ErrAlreadyLiked    = errors.New("user has liked this video")                            // 已点赞
+	ErrNotLiked        = errors.New("user has not liked this video")                        // 未点赞
+	ErrTooManyVideoIDs = fmt.Errorf("at most %d video_ids per request", maxBatchLikeStatus) // 批量查询的视频ID数量超过上限
+)
+
 // NewLikeService 创建点赞服务实例
-func NewLikeService(repo *LikeRepository, videoRepo *VideoRepository, cache *rediscache.Client, likeMQ *rabbitmq.LikeMQ, popularityMQ *rabbitmq.PopularityMQ) *LikeService {
-	return &LikeService{repo: repo, VideoRepo: videoRepo, cache: cache, likeMQ: likeMQ, popularityMQ: popularityMQ}
+func NewLikeService(repo *LikeRepository, videoRepo *VideoRepository, cache *rediscache.Client, likeMQ *rabbitmq.LikeMQ, popularityMQ *rabbitmq.PopularityBatcher, notifier *notification.NotificationService) *LikeService {
+	return &LikeService{repo: repo, VideoRepo: videoRepo, cache: cache, likeMQ: likeMQ, popularityMQ: popularityMQ, notifier: notifier}
 }
 
 // isDupKey 判断错误是否为MySQL唯一索引冲突（错误码1062）
@@ -36,32 +51,28 @@ func isDupKey(err error) bool {
 // Like 点赞视频
 // 业务流程：
 // 1. 校验参数（视频ID和用户ID）
-// 2. 校验视频是否存在
-// 3. 校验是否已点赞（防止重复点赞）
-// 4. 优先使用MQ异步处理：发送点赞消息到队列
-// 5. MQ失败时Fallback：直接写入数据库事务
-// 6. MQ失败时Fallback：直接更新Redis热度缓存
+// 2. 校验视频是否存在（非公开视频只有作者本人可以操作，其他人返回 ErrVideoForbidden）
+// 3. 校验是否已点赞（快速失败；并发请求以数据库唯一索引为准）
+// 4. 优先使用MQ异步处理：发送点赞消息到队列（Worker 负责点赞数和热度）
+// 5. MQ失败时Fallback：直接写入数据库事务，插入冲突时不修改计数
+// 6. Fallback 成功插入后更新Redis热度
 // 参数：
 //   - ctx: 上下文
 //   - like: 点赞对象
 func (s *LikeService) Like(ctx context.Context, like *Like) error {
 	// 1. 校验参数
 	if like == nil {
-		return errors.New("like is nil")
+		return ErrLikeInvalid
 	}
 	if like.VideoID == 0 || like.AccountID == 0 {
-		return errors.New("video_id and account_id are required")
+		return ErrLikeIDsRequired
 	}
 
-	// 2. 校验视频是否存在
+	// 2. 校验视频是否存在（非公开视频只有作者本人可以点赞/取消点赞）
 	if s.VideoRepo != nil {
-		ok, err := s.VideoRepo.IsExist(ctx, like.VideoID)
-		if err != nil {
+		if err := checkVideoAccess(ctx, s.VideoRepo, like.VideoID, like.AccountID); err != nil {
 			return err
 		}
-		if !ok {
-			return errors.New("video not found")
-		}
 	}
 
 	// 3. 校验是否已点赞（防止重复点赞）
@@ -70,106 +81,90 @@ func (s *LikeService) Like(ctx context.Context, like *Like) error {
 		return err
 	}
 	if isLiked {
-		return errors.New("user has liked this video")
+		return ErrAlreadyLiked
 	}
 
 	// 4. 设置点赞时间
 	like.CreatedAt = time.Now()
 
-	// 5. 尝试使用MQ异步处理
-	mysqlEnqueued := false // 是否成功发送点赞MQ消息
-	redisEnqueued := false // 是否成功发送热度MQ消息
-
-	// 5.1 发送点赞消息到MQ（Worker异步处理点赞记录和点赞数）
+	// 5. 优先使用MQ异步处理：Worker 以点赞记录是否真正插入为准更新点赞数和热度（数据库 + Redis）
+	// 这里不单独发送热度消息，否则快速点赞/取消时热度会与点赞记录不一致
 	if s.likeMQ != nil {
 		if err := s.likeMQ.Like(ctx, like.AccountID, like.VideoID); err == nil {
-			mysqlEnqueued = true
+			return nil
 		}
 	}
 
-	// 5.2 发送热度更新消息到MQ（Worker异步更新视频热度）
-	if s.popularityMQ != nil {
-		if err := s.popularityMQ.Update(ctx, like.VideoID, 1); err == nil {
-			redisEnqueued = true
-		}
-	}
-
-	// 5.3 如果两个MQ都成功发送，直接返回（Worker会异步处理）
-	if mysqlEnqueued && redisEnqueued {
-		return nil
-	}
-
 	// 6. Fallback: 点赞MQ发送失败时，直接写入数据库事务
-	if !mysqlEnqueued {
-		err := s.repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-			// 6.1 再次校验视频是否存在（事务内）
-			if err := tx.Select("id").First(&Video{}, like.VideoID).Error; err != nil {
-				if errors.Is(err, gorm.ErrRecordNotFound) {
-					return errors.New("video not found")
-				}
-				return err
-			}
-
-			// 6.2 插入点赞记录
-			if err := tx.Create(like).Error; err != nil {
-				if isDupKey(err) {
-					return errors.New("user has liked this video")
-				}
-				return err
+	// 以唯一索引为准：插入冲突说明已点赞（可能是并发请求），不修改任何计数
+	err = s.repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 6.1 再次校验视频是否存在（事务内）
+		if err := tx.Select("id").First(&Video{}, like.VideoID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrVideoNotFound
 			}
+			return err
+		}
 
-			// 6.3 更新视频点赞数（增量+1）
-			if err := tx.Model(&Video{}).Where("id = ?", like.VideoID).
-				UpdateColumn("likes_count", gorm.Expr("likes_count + 1")).Error; err != nil {
-				return err
+		// 6.2 插入点赞记录
+		if err := tx.Create(like).Error; err != nil {
+			if isDupKey(err) {
+				return ErrAlreadyLiked
 			}
+			return err
+		}
 
-			// 6.4 更新视频热度（增量+1）
-			return tx.Model(&Video{}).Where("id = ?", like.VideoID).
-				UpdateColumn("popularity", gorm.Expr("popularity + 1")).Error
-		})
-		if err != nil {
+		// 6.3 更新视频点赞数（增量+1）
+		if err := tx.Model(&Video{}).Where("id = ?", like.VideoID).
+			UpdateColumns(map[string]any{"likes_count": gorm.Expr("likes_count + 1"), "updated_at": time.Now()}).Error; err != nil {
 			return err
 		}
-	}
 
-	// 7. Fallback: 热度MQ发送失败时，直接更新Redis热度缓存
-	if !redisEnqueued {
-		UpdatePopularityCache(ctx, s.cache, like.VideoID, 1)
+		// 6.4 更新视频热度（增量+1）
+		return tx.Model(&Video{}).Where("id = ?", like.VideoID).
+			UpdateColumns(map[string]any{"popularity": gorm.Expr("popularity + 1"), "updated_at": time.Now()}).Error
+	})
+	if err != nil {
+		return err
 	}
 
+	// 7. 点赞记录已插入：更新 Redis 热度（优先走热度MQ，失败时直接更新缓存）
+	s.bumpPopularity(ctx, like.VideoID, 1)
+	// 推送最新点赞数给正在观看该视频的客户端
+	PublishLikesCount(ctx, s.cache, s.VideoRepo, like.VideoID)
+
+	// 8. 通知视频作者（与 Worker 行为一致，失败只记录日志）
+	if err := NotifyVideoAuthor(ctx, s.VideoRepo, s.notifier, notification.TypeLike, like.AccountID, like.VideoID); err != nil {
+		log.Printf("like: failed to notify author of video %d: %v", like.VideoID, err)
+	}
 	return nil
 }
 
 // Unlike 取消点赞
 // 业务流程：
 // 1. 校验参数（视频ID和用户ID）
-// 2. 校验视频是否存在
-// 3. 校验是否已点赞（防止取消未点赞的视频）
-// 4. 优先使用MQ异步处理：发送取消点赞消息到队列
-// 5. MQ失败时Fallback：直接写入数据库事务
-// 6. MQ失败时Fallback：直接更新Redis热度缓存
+// 2. 校验视频是否存在（非公开视频只有作者本人可以操作，其他人返回 ErrVideoForbidden）
+// 3. 校验是否已点赞（快速失败；并发请求以删除的行数为准）
+// 4. 优先使用MQ异步处理：发送取消点赞消息到队列（Worker 负责点赞数和热度）
+// 5. MQ失败时Fallback：直接写入数据库事务，没有删除记录时不修改计数
+// 6. Fallback 成功删除后更新Redis热度
 // 参数：
 //   - ctx: 上下文
 //   - like: 点赞对象
 func (s *LikeService) Unlike(ctx context.Context, like *Like) error {
 	// 1. 校验参数
 	if like == nil {
-		return errors.New("like is nil")
+		return ErrLikeInvalid
 	}
 	if like.VideoID == 0 || like.AccountID == 0 {
-		return errors.New("video_id and account_id are required")
+		return ErrLikeIDsRequired
 	}
 
-	// 2. 校验视频是否存在
+	// 2. 校验视频是否存在（非公开视频只有作者本人可以点赞/取消点赞）
 	if s.VideoRepo != nil {
-		ok, err := s.VideoRepo.IsExist(ctx, like.VideoID)
-		if err != nil {
+		if err := checkVideoAccess(ctx, s.VideoRepo, like.VideoID, like.AccountID); err != nil {
 			return err
 		}
-		if !ok {
-			return errors.New("video not found")
-		}
 	}
 
 	// 3. 校验是否已点赞（防止取消未点赞的视频）
@@ -178,67 +173,59 @@ func (s *LikeService) Unlike(ctx context.Context, like *Like) error {
 		return err
 	}
 	if !isLiked {
-		return errors.New("user has not liked this video")
+		return ErrNotLiked
 	}
 
-	// 4. 尝试使用MQ异步处理
-	mysqlEnqueued := false // 是否成功发送取消点赞MQ消息
-	redisEnqueued := false // 是否成功发送热度更新MQ消息
-
-	// 4.1 发送取消点赞消息到MQ（Worker异步处理）
+	// 4. 优先使用MQ异步处理：Worker 以点赞记录是否真正删除为准更新点赞数和热度（数据库 + Redis）
 	if s.likeMQ != nil {
 		if err := s.likeMQ.Unlike(ctx, like.AccountID, like.VideoID); err == nil {
-			mysqlEnqueued = true
+			return nil
 		}
 	}
 
-	// 4.2 发送热度更新消息到MQ（热度-1）
-	if s.popularityMQ != nil {
-		if err := s.popularityMQ.Update(ctx, like.VideoID, -1); err == nil {
-			redisEnqueued = true
-		}
-	}
-
-	// 4.3 如果两个MQ都成功发送，直接返回
-	if mysqlEnqueued && redisEnqueued {
-		return nil
-	}
-
 	// 5. Fallback: 点赞MQ发送失败时，直接写入数据库事务
-	if !mysqlEnqueued {
-		err := s.repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-			// 5.1 删除点赞记录
-			del := tx.Where("video_id = ? AND account_id = ?", like.VideoID, like.AccountID).Delete(&Like{})
-			if del.Error != nil {
-				return del.Error
-			}
-			if del.RowsAffected == 0 {
-				return errors.New("user has not liked this video")
-			}
-
-			// 5.2 更新视频点赞数（增量-1，确保不小于0）
-			if err := tx.Model(&Video{}).Where("id = ?", like.VideoID).
-				UpdateColumn("likes_count", gorm.Expr("GREATEST(likes_count - 1, 0)")).Error; err != nil {
-				return err
-			}
+	// 以删除的行数为准：没有删除任何记录说明未点赞（可能是并发请求），不修改任何计数
+	err = s.repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 5.1 删除点赞记录
+		del := tx.Where("video_id = ? AND account_id = ?", like.VideoID, like.AccountID).Delete(&Like{})
+		if del.Error != nil {
+			return del.Error
+		}
+		if del.RowsAffected == 0 {
+			return ErrNotLiked
+		}
 
-			// 5.3 更新视频热度（增量-1，确保不小于0）
-			return tx.Model(&Video{}).Where("id = ?", like.VideoID).
-				UpdateColumn("popularity", gorm.Expr("GREATEST(popularity - 1, 0)")).Error
-		})
-		if err != nil {
+		// 5.2 更新视频点赞数（增量-1，确保不小于0）
+		if err := tx.Model(&Video{}).Where("id = ?", like.VideoID).
+			UpdateColumns(map[string]any{"likes_count": gorm.Expr("GREATEST(likes_count - 1, 0)"), "updated_at": time.Now()}).Error; err != nil {
 			return err
 		}
-	}
 
-	// 6. Fallback: 热度MQ发送失败时，直接更新Redis热度缓存
-	if !redisEnqueued {
-		UpdatePopularityCache(ctx, s.cache, like.VideoID, -1)
+		// 5.3 更新视频热度（增量-1，确保不小于0）
+		return tx.Model(&Video{}).Where("id = ?", like.VideoID).
+			UpdateColumns(map[string]any{"popularity": gorm.Expr("GREATEST(popularity - 1, 0)"), "updated_at": time.Now()}).Error
+	})
+	if err != nil {
+		return err
 	}
 
+	// 6. 点赞记录已删除：更新 Redis 热度（优先走热度MQ，失败时直接更新缓存）
+	s.bumpPopularity(ctx, like.VideoID, -1)
+	// 推送最新点赞数给正在观看该视频的客户端
+	PublishLikesCount(ctx, s.cache, s.VideoRepo, like.VideoID)
 	return nil
 }
 
+// bumpPopularity 更新 Redis 热度：优先发送热度MQ消息，失败时直接更新热度缓存
+func (s *LikeService) bumpPopularity(ctx context.Context, videoID uint, change int64) {
+	if s.popularityMQ != nil {
+		if err := s.popularityMQ.Update(ctx, videoID, change); err == nil {
+			return
+		}
+	}
+	UpdatePopularityCache(ctx, s.cache, videoID, change)
+}
+
 // IsLiked 查询是否已点赞
 // 参数：
 //   - ctx: 上下文
@@ -251,6 +238,41 @@ func (s *LikeService) IsLiked(ctx context.Context, videoID, accountID uint) (boo
 	return s.repo.IsLiked(ctx, videoID, accountID)
 }
 
+// BatchIsLiked 批量查询是否已点赞（用于不经过 Feed 的视频列表，如用户自己的作品）
+// 参数：
+//   - ctx: 上下文
+//   - videoIDs: 视频ID列表（最多 100 个，0 会被忽略）
+//   - accountID: 用户ID
+// 返回：
+//   - map[uint]bool: videoID -> 是否已点赞（请求中的每个ID都有结果）
+//   - error: 错误信息
+func (s *LikeService) BatchIsLiked(ctx context.Context, videoIDs []uint, accountID uint) (map[uint]bool, error) {
+	ids := make([]uint, 0, len(videoIDs))
+	result := make(map[uint]bool, len(videoIDs))
+	for _, id := range videoIDs {
+		if id == 0 {
+			continue
+		}
+		if _, ok := result[id]; ok {
+			continue
+		}
+		result[id] = false
+		ids = append(ids, id)
+	}
+	if len(ids) > maxBatchLikeStatus {
+		return nil, ErrTooManyVideoIDs
+	}
+
+	likedMap, err := s.repo.BatchGetLiked(ctx, ids, accountID)
+	if err != nil {
+		return nil, err
+	}
+	for id, liked := range likedMap {
+		result[id] = liked
+	}
+	return result, nil
+}
+
 // ListLikedVideos 查询用户点赞的视频列表
 // 参数：
 //   - ctx: 上下文
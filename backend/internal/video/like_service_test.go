@@ -0,0 +1,48 @@
+package video
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// 非公开视频只有作者本人可以点赞/取消点赞：其他用户返回 ErrVideoForbidden，且不写入点赞记录、不发送消息
+func TestLikePrivateVideoForbiddenForNonOwner(t *testing.T) {
+	const (
+		videoID  uint = 42
+		authorID uint = 7
+		viewerID uint = 8
+	)
+	db, mock := newMockDB(t)
+	s := NewLikeService(NewLikeRepository(db), NewVideoRepository(db), nil, nil, nil, nil)
+
+	for _, visibility := range []string{VisibilityPrivate, VisibilityDraft} {
+		expectVideoAccess(mock, videoID, authorID, visibility)
+		if err := s.Like(context.Background(), &Like{VideoID: videoID, AccountID: viewerID}); !errors.Is(err, ErrVideoForbidden) {
+			t.Fatalf("Like(%s video) err = %v, want ErrVideoForbidden", visibility, err)
+		}
+		expectVideoAccess(mock, videoID, authorID, visibility)
+		if err := s.Unlike(context.Background(), &Like{VideoID: videoID, AccountID: viewerID}); !errors.Is(err, ErrVideoForbidden) {
+			t.Fatalf("Unlike(%s video) err = %v, want ErrVideoForbidden", visibility, err)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLikeMissingVideoNotFound(t *testing.T) {
+	db, mock := newMockDB(t)
+	s := NewLikeService(NewLikeRepository(db), NewVideoRepository(db), nil, nil, nil, nil)
+
+	mock.ExpectQuery("SELECT `author_id`,`visibility` FROM `videos`").
+		WillReturnRows(sqlmock.NewRows([]string{"author_id", "visibility"}))
+	if err := s.Like(context.Background(), &Like{VideoID: 42, AccountID: 8}); !errors.Is(err, ErrVideoNotFound) {
+		t.Fatalf("Like err = %v, want ErrVideoNotFound", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
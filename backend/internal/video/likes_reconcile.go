@@ -0,0 +1,106 @@
+package video
+
+import (
+	"context"
+	"log"
+)
+
+// defaultReconcileBatch 点赞数对账每批处理的视频数量
+const defaultReconcileBatch = 500
+
+// LikesReconcileResult 点赞数对账结果
+type LikesReconcileResult struct {
+	Scanned int64 // 检查的视频数
+	Updated int64 // 点赞数被修正的视频数（dry run 时为需要修正的视频数）
+}
+
+// ReconcileLikesCounts 根据点赞表重新计算视频点赞数（videos.likes_count）
+// 点赞经 MQ 异步写入，消息进入死信队列或事务中途崩溃都可能导致计数漂移
+// 按视频ID分批处理：每批一次 GROUP BY 统计点赞数，只更新不一致的视频，并记录每条差异
+// 包含已软删除的视频（恢复后计数仍然正确）
+// dryRun 为 true 时只记录差异、统计需要修正的数量，不写入数据库
+// 参数：
+//   - ctx: 上下文
+//   - fromID: 起始视频ID（包含，0 表示从头开始）
+//   - toID: 结束视频ID（包含，0 表示不限制）
+//   - batchSize: 每批视频数量（<= 0 时使用默认值 500）
+//   - dryRun: 是否只预览（不修改数据）
+// 返回：
+//   - LikesReconcileResult: 检查/修正的视频数（出错时为已完成批次的统计）
+//   - error: 错误信息
+func (vr *VideoRepository) ReconcileLikesCounts(ctx context.Context, fromID, toID uint, batchSize int, dryRun bool) (LikesReconcileResult, error) {
+	if batchSize <= 0 {
+		batchSize = defaultReconcileBatch
+	}
+
+	var result LikesReconcileResult
+	cursor := fromID
+	for {
+		// 1. 按ID顺序取一批视频
+		var videos []struct {
+			ID         uint
+			LikesCount int64
+		}
+		query := vr.db.WithContext(ctx).Unscoped().
+			Model(&Video{}).
+			Select("id, likes_count").
+			Where("id >= ?", cursor)
+		if toID > 0 {
+			query = query.Where("id <= ?", toID)
+		}
+		if err := query.Order("id asc").Limit(batchSize).Find(&videos).Error; err != nil {
+			return result, err
+		}
+		if len(videos) == 0 {
+			return result, nil
+		}
+
+		// 2. 统计这批视频的实际点赞数
+		ids := make([]uint, 0, len(videos))
+		for _, v := range videos {
+			ids = append(ids, v.ID)
+		}
+		var counts []struct {
+			VideoID uint
+			Cnt     int64
+		}
+		if err := vr.db.WithContext(ctx).
+			Model(&Like{}).
+			Select("video_id, COUNT(*) AS cnt").
+			Where("video_id IN ?", ids).
+			Group("video_id").
+			Find(&counts).Error; err != nil {
+			return result, err
+		}
+		actual := make(map[uint]int64, len(counts))
+		for _, c := range counts {
+			actual[c.VideoID] = c.Cnt
+		}
+
+		// 3. 修正不一致的计数
+		for _, v := range videos {
+			result.Scanned++
+			if v.LikesCount == actual[v.ID] {
+				continue
+			}
+			if dryRun {
+				log.Printf("reconcile likes (dry run): video %d likes_count %d -> %d", v.ID, v.LikesCount, actual[v.ID])
+				result.Updated++
+				continue
+			}
+			log.Printf("reconcile likes: video %d likes_count %d -> %d", v.ID, v.LikesCount, actual[v.ID])
+			if err := vr.db.WithContext(ctx).Unscoped().
+				Model(&Video{}).
+				Where("id = ?", v.ID).
+				UpdateColumn("likes_count", actual[v.ID]).Error; err != nil {
+				return result, err
+			}
+			result.Updated++
+		}
+
+		if len(videos) < batchSize {
+			return result, nil
+		}
+		cursor = videos[len(videos)-1].ID + 1
+	}
+}
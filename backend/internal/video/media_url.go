@@ -0,0 +1,110 @@
+package video
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"feedsystem_video_go/internal/config"
+)
+
+var (
+	ErrInvalidPlayURL  = errors.New("play url must point to a video uploaded via /video/uploadVideo")   // 播放地址不是本站上传的视频
+	ErrInvalidCoverURL = errors.New("cover url must point to an image uploaded via /video/uploadCover") // 封面地址不是本站上传的图片
+	ErrMediaNotOwned   = errors.New("play url or cover url was uploaded by another account")            // 播放地址/封面地址是其他用户上传的文件
+)
+
+// mediaURLPolicy 发布视频时播放地址/封面地址的校验规则
+// 只接受上传接口生成的地址（{scheme}://{媒体域名}{访问路径前缀}/{videos|covers}/{作者ID}/...），
+// 或以配置的外部前缀开头的地址（如 CDN），防止发布指向任意外部地址的视频
+type mediaURLPolicy struct {
+	hosts    map[string]bool // 上传文件的访问域名（host[:port]）；为空时使用发布请求的 Host
+	prefixes []string        // 额外允许的外部地址前缀
+	static   string          // 上传文件的访问路径前缀（如 /static）
+}
+
+func newMediaURLPolicy(cfg config.VideoConfig, staticPrefix string) mediaURLPolicy {
+	p := mediaURLPolicy{hosts: make(map[string]bool, len(cfg.MediaHosts)), static: staticPrefix}
+	for _, h := range cfg.MediaHosts {
+		if h = strings.ToLower(strings.TrimSpace(h)); h != "" {
+			p.hosts[h] = true
+		}
+	}
+	for _, prefix := range cfg.AllowedMediaPrefixes {
+		if prefix = strings.TrimSpace(prefix); prefix != "" {
+			p.prefixes = append(p.prefixes, prefix)
+		}
+	}
+	return p
+}
+
+// check 校验播放地址和封面地址
+// 参数：
+//   - v: 视频对象（使用 AuthorID、PlayURL、CoverURL）
+//   - requestHost: 发布请求的 Host（未配置 media_hosts 时，上传接口返回的地址使用该 Host）
+//
+// 返回：
+//   - ErrInvalidPlayURL / ErrInvalidCoverURL: 不是上传接口生成的地址
+//   - ErrMediaNotOwned: 是其他用户上传的文件（上传目录中的用户ID与作者不一致）
+func (p mediaURLPolicy) check(v *Video, requestHost string) error {
+	if err := p.checkOne(v.PlayURL, "videos", v.AuthorID, requestHost); err != nil {
+		if errors.Is(err, ErrMediaNotOwned) {
+			return err
+		}
+		return ErrInvalidPlayURL
+	}
+	if err := p.checkOne(v.CoverURL, "covers", v.AuthorID, requestHost); err != nil {
+		if errors.Is(err, ErrMediaNotOwned) {
+			return err
+		}
+		return ErrInvalidCoverURL
+	}
+	return nil
+}
+
+// errMediaURL 地址不是上传接口生成的地址（由 check 转换为对应的播放地址/封面地址错误）
+var errMediaURL = errors.New("media url is not an upload url")
+
+// checkOne 校验单个地址：命中外部地址前缀，或为本站上传接口生成的 {访问路径前缀}/{kind}/{上传者ID}/... 且上传者为作者本人
+func (p mediaURLPolicy) checkOne(raw, kind string, authorID uint, requestHost string) error {
+	for _, prefix := range p.prefixes {
+		if strings.HasPrefix(raw, prefix) {
+			return nil
+		}
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.User != nil || u.RawQuery != "" || u.Fragment != "" {
+		return errMediaURL
+	}
+	host := strings.ToLower(u.Host)
+	if len(p.hosts) > 0 {
+		if !p.hosts[host] {
+			return errMediaURL
+		}
+	} else if host == "" || host != strings.ToLower(requestHost) {
+		return errMediaURL
+	}
+
+	// 路径必须是上传接口生成的 {访问路径前缀}/{kind}/{上传者ID}/{日期}/{文件名}，且不包含 ../ 等需要规范化的片段
+	rest, ok := strings.CutPrefix(u.Path, fmt.Sprintf("%s/%s/", p.static, kind))
+	if !ok || path.Clean(u.Path) != u.Path {
+		return errMediaURL
+	}
+	owner, file, ok := strings.Cut(rest, "/")
+	if !ok || file == "" {
+		return errMediaURL
+	}
+	ownerID, err := strconv.ParseUint(owner, 10, 64)
+	if err != nil {
+		return errMediaURL
+	}
+	// 上传接口按上传者ID分目录保存文件，目录与作者不一致说明是其他用户上传的文件
+	if uint(ownerID) != authorID {
+		return ErrMediaNotOwned
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package video
+
+import (
+	"context"
+
+	"feedsystem_video_go/internal/notification"
+)
+
+// NotifyVideoAuthor 通知视频作者其视频收到了互动（点赞/评论）
+// 互动记录已经写入，这里失败只返回错误由调用方记录日志，不应导致重试（重试会重复写入互动或因记录已存在被跳过）
+// 参数：
+//   - ctx: 上下文
+//   - repo: 视频仓储层（查询视频作者）
+//   - notifier: 通知服务层（可能为 nil，表示不发送通知）
+//   - typ: 通知类型（notification.TypeLike / notification.TypeComment）
+//   - actorID: 互动用户ID
+//   - videoID: 视频ID
+func NotifyVideoAuthor(ctx context.Context, repo *VideoRepository, notifier *notification.NotificationService, typ string, actorID, videoID uint) error {
+	if repo == nil || notifier == nil || videoID == 0 {
+		return nil
+	}
+	v, err := repo.GetByID(ctx, videoID)
+	if err != nil {
+		return err
+	}
+	return notifier.Notify(ctx, v.AuthorID, typ, actorID, videoID)
+}
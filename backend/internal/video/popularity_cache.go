@@ -2,20 +2,26 @@ package video
 
 import (
 	"context"
-	"fmt"
 	"strconv"
 	"time"
 
 	rediscache "feedsystem_video_go/internal/middleware/redis"
 )
 
+// 热度权重：各类互动对视频热度的贡献（撤销操作时使用相反数）
+const (
+	CommentPopularityWeight int64 = 5  // 发布评论，评论所在视频热度+5
+	FollowPopularityWeight  int64 = 10 // 关注博主，博主最新视频热度+10
+)
+
 // 更新视频流行度缓存
+// cache 为 nil（Redis 未启用或连接失败）时不做任何事，热度只写入数据库
 func UpdatePopularityCache(ctx context.Context, cache *rediscache.Client, id uint, change int64) {
 	if cache == nil || id == 0 || change == 0 {
 		return
 	}
 
-	_ = cache.Del(context.Background(), fmt.Sprintf("video:detail:id=%d", id))
+	_ = cache.Del(context.Background(), DetailCacheKey(id))
 
 	now := time.Now().UTC().Truncate(time.Minute)
 	windowKey := "hot:video:1m:" + now.Format("200601021504")
@@ -26,4 +32,72 @@ func UpdatePopularityCache(ctx context.Context, cache *rediscache.Client, id uin
 
 	_ = cache.ZincrBy(opCtx, windowKey, member, float64(change))
 	_ = cache.Expire(opCtx, windowKey, 2*time.Hour)
+
+	// 推送热度变化给正在观看该视频的客户端
+	PublishLiveCounts(ctx, cache, LiveCountsEvent{VideoID: id, PopularityDelta: change})
+}
+
+// 批量更新视频流行度缓存（一次删除详情缓存 + 一次 Pipeline 写入时间窗 ZSET）
+// cache 为 nil 时不做任何事
+func UpdatePopularityCacheBatch(ctx context.Context, cache *rediscache.Client, changes map[uint]int64) {
+	if cache == nil || len(changes) == 0 {
+		return
+	}
+
+	detailKeys := make([]string, 0, len(changes))
+	increments := make(map[string]float64, len(changes))
+	for id, change := range changes {
+		if id == 0 || change == 0 {
+			continue
+		}
+		detailKeys = append(detailKeys, DetailCacheKey(id))
+		increments[strconv.FormatUint(uint64(id), 10)] = float64(change)
+	}
+	if len(increments) == 0 {
+		return
+	}
+
+	_ = cache.DelMany(context.Background(), detailKeys)
+
+	now := time.Now().UTC().Truncate(time.Minute)
+	windowKey := "hot:video:1m:" + now.Format("200601021504")
+
+	opCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+
+	_ = cache.ZIncrByBatch(opCtx, windowKey, increments, 2*time.Hour)
+
+	// 推送热度变化给正在观看这些视频的客户端
+	for id, change := range changes {
+		if id == 0 || change == 0 {
+			continue
+		}
+		PublishLiveCounts(ctx, cache, LiveCountsEvent{VideoID: id, PopularityDelta: change})
+	}
+}
+
+// BumpLatestVideoPopularity 调整作者最新视频的热度（数据库 + Redis 热榜）
+// 用于关注/取关博主：博主最新视频热度 ±FollowPopularityWeight；作者没有视频时不做任何操作
+// 参数：
+//   - ctx: 上下文
+//   - repo: 视频仓储层
+//   - cache: Redis缓存客户端（可能为 nil）
+//   - authorID: 作者ID
+//   - change: 热度变化量
+func BumpLatestVideoPopularity(ctx context.Context, repo *VideoRepository, cache *rediscache.Client, authorID uint, change int64) error {
+	if repo == nil || authorID == 0 || change == 0 {
+		return nil
+	}
+	latest, err := repo.GetLatestByAuthorID(ctx, authorID)
+	if err != nil {
+		return err
+	}
+	if latest == nil {
+		return nil
+	}
+	if err := repo.ChangePopularity(ctx, latest.ID, change); err != nil {
+		return err
+	}
+	UpdatePopularityCache(ctx, cache, latest.ID, change)
+	return nil
 }
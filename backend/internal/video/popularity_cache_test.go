@@ -0,0 +1,18 @@
+package video
+
+import (
+	"context"
+	"testing"
+
+	rediscache "feedsystem_video_go/internal/middleware/redis"
+)
+
+// Redis 未启用时 cache 为 nil，热度更新应直接跳过（MQ 降级路径会在这种情况下调用）
+func TestUpdatePopularityCacheNilClient(t *testing.T) {
+	var cache *rediscache.Client
+
+	UpdatePopularityCache(context.Background(), cache, 1, 5)
+	UpdatePopularityCache(context.Background(), cache, 1, -5)
+	UpdatePopularityCacheBatch(context.Background(), cache, map[uint]int64{1: 5, 2: -3})
+	UpdatePopularityCacheBatch(context.Background(), cache, nil)
+}
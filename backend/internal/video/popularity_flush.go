@@ -0,0 +1,101 @@
+package video
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+)
+
+// defaultPopularityFlushInterval 热度变化量默认合并写入间隔
+const defaultPopularityFlushInterval = 200 * time.Millisecond
+
+// popularityFlushTimeout 单次合并写入数据库的超时时间（包括停止时的最后一次写入）
+const popularityFlushTimeout = 5 * time.Second
+
+// PopularityFlusher 热度数据库写入合并器
+// 职责：Worker 处理点赞/评论事件时只在内存中累加每个视频的热度变化量，
+// 每隔一个写入间隔把累计值用一条批量 UPDATE 写入数据库，热点视频的大量单行 UPDATE 合并为一次，减少行锁竞争
+// Redis 热榜仍按事件实时更新（UpdatePopularityCache），不受合并延迟影响
+//
+// 注意：变化量只保存在内存中，消息在写入数据库之前就已确认；进程异常退出时最多丢失一个写入间隔内的热度变化
+// （正常停止时会写入剩余的变化量）。热度只用于排序，可以接受这个误差
+type PopularityFlusher struct {
+	repo     *VideoRepository // 视频仓储层
+	interval time.Duration    // 写入间隔
+
+	mu      sync.Mutex
+	pending map[uint]int64 // 视频ID -> 尚未写入数据库的热度变化量
+}
+
+// NewPopularityFlusher 创建热度写入合并器
+// 参数：
+//   - repo: 视频仓储层
+//   - interval: 写入间隔（<= 0 时使用默认值 200ms）
+func NewPopularityFlusher(repo *VideoRepository, interval time.Duration) *PopularityFlusher {
+	if interval <= 0 {
+		interval = defaultPopularityFlushInterval
+	}
+	return &PopularityFlusher{
+		repo:     repo,
+		interval: interval,
+		pending:  make(map[uint]int64),
+	}
+}
+
+// Add 累加视频的热度变化量（在下一次写入时生效）
+func (f *PopularityFlusher) Add(videoID uint, change int64) {
+	if videoID == 0 || change == 0 {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.pending[videoID] += change
+}
+
+// Run 定期把累计的热度变化量写入数据库（阻塞，直到 ctx 被取消）
+// ctx 被取消后会再写入一次剩余的变化量，然后返回
+func (f *PopularityFlusher) Run(ctx context.Context) error {
+	if f == nil || f.repo == nil {
+		return errors.New("popularity flusher is not initialized")
+	}
+	ticker := time.NewTicker(f.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.Background(), popularityFlushTimeout)
+			f.flush(flushCtx)
+			cancel()
+			return ctx.Err()
+		case <-ticker.C:
+			flushCtx, cancel := context.WithTimeout(ctx, popularityFlushTimeout)
+			f.flush(flushCtx)
+			cancel()
+		}
+	}
+}
+
+// flush 取出累计的变化量并批量写入数据库
+// 写入失败时把变化量放回，下一次写入时重试
+func (f *PopularityFlusher) flush(ctx context.Context) {
+	f.mu.Lock()
+	if len(f.pending) == 0 {
+		f.mu.Unlock()
+		return
+	}
+	changes := f.pending
+	f.pending = make(map[uint]int64, len(changes))
+	f.mu.Unlock()
+
+	if err := f.repo.ChangePopularityBatch(ctx, changes); err != nil {
+		log.Printf("popularity flusher: failed to flush %d videos, will retry: %v", len(changes), err)
+		f.mu.Lock()
+		for id, change := range changes {
+			f.pending[id] += change
+		}
+		f.mu.Unlock()
+	}
+}
@@ -0,0 +1,65 @@
+package video
+
+import (
+	"errors"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ServeUploads 返回上传文件（视频、封面）的访问处理器，挂载到 {storage.static_prefix}/*filepath
+// 显式支持 Range 请求：播放器拖动进度条时只请求需要的片段（206 Partial Content + Content-Range），
+// 不需要下载整个视频文件；范围不合法时返回 416，带 If-Range 时文件已变化则返回完整内容
+// 内容由 http.ServeContent 按 io.ReadSeeker 输出，更换存储方式时只需提供可 Seek 的读取器即可保留 Range 支持
+// 参数：
+//   - root: 上传文件根目录（storage.upload_dir，默认 .run/uploads）
+func ServeUploads(root string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// path.Clean 以 "/" 为根清理路径，"../" 不能跳出上传目录
+		name := path.Clean("/" + c.Param("filepath"))
+		f, err := os.Open(filepath.Join(root, filepath.FromSlash(name)))
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) || errors.Is(err, fs.ErrPermission) {
+				c.Status(http.StatusNotFound)
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		defer f.Close()
+
+		info, err := f.Stat()
+		if err != nil {
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		// 不提供目录列表
+		if info.IsDir() {
+			c.Status(http.StatusNotFound)
+			return
+		}
+
+		// 告知客户端支持按字节范围请求（部分播放器据此决定是否启用拖动）
+		c.Header("Accept-Ranges", "bytes")
+		http.ServeContent(c.Writer, c.Request, info.Name(), info.ModTime(), f)
+	}
+}
+
+// CheckUploadDir 校验上传目录可写（启动时调用，目录不存在时创建）
+// 写入并删除一个临时文件，避免上线后第一次上传才发现卷没有挂载或权限不对
+func CheckUploadDir(root string) error {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(root, ".write-check-*")
+	if err != nil {
+		return err
+	}
+	name := f.Name()
+	_ = f.Close()
+	return os.Remove(name)
+}
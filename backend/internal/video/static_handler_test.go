@@ -0,0 +1,72 @@
+package video
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newUploadsRouter(t *testing.T, content []byte) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "video.mp4"), content, 0o644); err != nil {
+		t.Fatalf("write upload: %v", err)
+	}
+	r := gin.New()
+	r.GET("/static/*filepath", ServeUploads(root))
+	return r
+}
+
+func TestServeUploadsRange(t *testing.T) {
+	content := []byte("0123456789abcdefghijklmnopqrstuvwxyz")
+	r := newUploadsRouter(t, content)
+
+	req := httptest.NewRequest(http.MethodGet, "/static/video.mp4", nil)
+	req.Header.Set("Range", "bytes=0-9")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusPartialContent {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusPartialContent)
+	}
+	if got, want := w.Header().Get("Content-Range"), "bytes 0-9/36"; got != want {
+		t.Fatalf("Content-Range = %q, want %q", got, want)
+	}
+	if got := w.Header().Get("Accept-Ranges"); got != "bytes" {
+		t.Fatalf("Accept-Ranges = %q, want %q", got, "bytes")
+	}
+	if got := w.Body.String(); got != "0123456789" {
+		t.Fatalf("body = %q, want %q", got, "0123456789")
+	}
+}
+
+func TestServeUploadsRangeNotSatisfiable(t *testing.T) {
+	r := newUploadsRouter(t, []byte("0123456789"))
+
+	req := httptest.NewRequest(http.MethodGet, "/static/video.mp4", nil)
+	req.Header.Set("Range", "bytes=100-199")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusRequestedRangeNotSatisfiable {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusRequestedRangeNotSatisfiable)
+	}
+}
+
+func TestServeUploadsNoTraversal(t *testing.T) {
+	r := newUploadsRouter(t, []byte("0123456789"))
+
+	req := httptest.NewRequest(http.MethodGet, "/static/../../etc/passwd", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,63 @@
+package video
+
+import (
+	"net/http"
+
+	"feedsystem_video_go/internal/apierror"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StatsHandler 视频互动统计处理器（管理员刷量检测、个人主页作者统计）
+type StatsHandler struct {
+	service *StatsService // 视频互动统计服务层
+}
+
+// NewStatsHandler 创建视频互动统计处理器实例
+func NewStatsHandler(service *StatsService) *StatsHandler {
+	return &StatsHandler{service: service}
+}
+
+// AdminStats 查询视频互动统计接口（管理员）
+// 路由：POST /admin/video/stats
+// 功能：返回点赞数、不同点赞用户数及比例、评论数、不同评论用户数、热度，用于发现刷量视频
+// 请求体：{"id": 视频ID}
+func (h *StatsHandler) AdminStats(c *gin.Context) {
+	// 1. 解析JSON请求体
+	var req VideoStatsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.BadRequest(c, err)
+		return
+	}
+	// 2. 调用Service层查询统计
+	stats, err := h.service.Get(c.Request.Context(), req.ID)
+	if err != nil {
+		apierror.FromError(c, err, videoErrors...)
+		return
+	}
+
+	// 3. 返回统计结果
+	c.JSON(http.StatusOK, stats)
+}
+
+// AuthorStats 查询作者统计接口（公开接口，不需要登录）
+// 路由：POST /video/authorStats
+// 功能：返回作者的公开视频数和点赞总数，用于个人主页展示（结果缓存 60 秒）
+// 请求体：{"author_id": 作者ID}
+func (h *StatsHandler) AuthorStats(c *gin.Context) {
+	// 1. 解析JSON请求体
+	var req AuthorStatsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.BadRequest(c, err)
+		return
+	}
+	// 2. 调用Service层查询统计
+	stats, err := h.service.AuthorStats(c.Request.Context(), req.AuthorID)
+	if err != nil {
+		apierror.FromError(c, err, videoErrors...)
+		return
+	}
+
+	// 3. 返回统计结果
+	c.JSON(http.StatusOK, stats)
+}
@@ -0,0 +1,133 @@
+package video
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	rediscache "feedsystem_video_go/internal/middleware/redis"
+
+	"gorm.io/gorm"
+)
+
+// authorStatsTTL 作者统计缓存有效期
+// 统计只用于个人主页展示，允许短时间内不是最新值，点赞/发布时不主动失效
+const authorStatsTTL = 60 * time.Second
+
+// StatsService 视频互动统计服务层（只读，供管理员刷量检测和个人主页统计使用）
+type StatsService struct {
+	videos   *VideoRepository   // 视频仓储层（点赞数、热度）
+	likes    *LikeRepository    // 点赞仓储层（不同点赞用户数）
+	comments *CommentRepository // 评论仓储层（评论数、不同评论用户数）
+	cache    *rediscache.Client // Redis 缓存客户端（作者统计，可能为 nil）
+}
+
+// NewStatsService 创建视频互动统计服务实例
+func NewStatsService(videos *VideoRepository, likes *LikeRepository, comments *CommentRepository, cache *rediscache.Client) *StatsService {
+	return &StatsService{videos: videos, likes: likes, comments: comments, cache: cache}
+}
+
+// authorStatsKey 作者统计缓存键
+func authorStatsKey(authorID uint) string {
+	return fmt.Sprintf("video:authorStats:%d", authorID)
+}
+
+// AuthorStats 查询作者的公开视频数和点赞总数
+// 优先读取 Redis 缓存（video:authorStats:{id}，60 秒），未命中时聚合查询数据库并回填
+// 作者不存在或没有公开视频时返回全 0，不区分两种情况（与作者视频列表接口一致）
+// 参数：
+//   - ctx: 上下文
+//   - authorID: 作者ID
+// 返回：
+//   - *AuthorStats: 作者统计
+//   - error: 错误信息
+func (s *StatsService) AuthorStats(ctx context.Context, authorID uint) (*AuthorStats, error) {
+	key := authorStatsKey(authorID)
+
+	// 1. 优先读取缓存
+	if s.cache != nil {
+		opCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		b, err := s.cache.GetBytes(opCtx, key)
+		cancel()
+		if err == nil {
+			var cached AuthorStats
+			if err := json.Unmarshal(b, &cached); err == nil {
+				return &cached, nil
+			}
+		}
+	}
+
+	// 2. 缓存未命中，聚合查询数据库（均走 idx_video_author_time 索引）
+	videos, err := s.videos.CountByAuthor(ctx, authorID)
+	if err != nil {
+		return nil, err
+	}
+	likes, err := s.videos.SumLikesByAuthor(ctx, authorID)
+	if err != nil {
+		return nil, err
+	}
+	stats := &AuthorStats{AuthorID: authorID, TotalVideos: videos, TotalLikes: likes}
+
+	// 3. 回填缓存（失败只记录日志）
+	if s.cache != nil {
+		if b, err := json.Marshal(stats); err == nil {
+			opCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+			if err := s.cache.SetBytes(opCtx, key, b, authorStatsTTL); err != nil {
+				log.Printf("failed to set author stats cache: %v", err)
+			}
+			cancel()
+		}
+	}
+	return stats, nil
+}
+
+// Get 查询视频互动统计
+// 直接查询数据库（不走缓存），保证管理员看到的是最新数据
+// 参数：
+//   - ctx: 上下文
+//   - id: 视频ID
+// 返回：
+//   - *VideoStats: 互动统计
+//   - error: 错误信息（视频不存在返回 ErrVideoNotFound）
+func (s *StatsService) Get(ctx context.Context, id uint) (*VideoStats, error) {
+	// 1. 查询视频（点赞数、热度）
+	video, err := s.videos.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrVideoNotFound
+		}
+		return nil, err
+	}
+
+	// 2. 统计不同点赞用户数
+	likers, err := s.likes.CountDistinctLikers(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. 统计评论数和不同评论用户数
+	comments, err := s.comments.CountByVideo(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	commenters, err := s.comments.CountDistinctAuthors(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &VideoStats{
+		VideoID:            video.ID,
+		LikesCount:         video.LikesCount,
+		DistinctLikers:     likers,
+		CommentsCount:      comments,
+		DistinctCommenters: commenters,
+		Popularity:         video.Popularity,
+	}
+	if video.LikesCount > 0 {
+		stats.DistinctLikerRatio = float64(likers) / float64(video.LikesCount)
+	}
+	return stats, nil
+}
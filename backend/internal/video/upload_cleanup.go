@@ -0,0 +1,248 @@
+package video
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"log"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"feedsystem_video_go/internal/config"
+)
+
+// 未发布上传文件清理的默认值
+const (
+	defaultOrphanUploadMaxAge   = 24 * time.Hour // 上传后超过该时间仍未被视频引用的文件视为孤儿文件
+	defaultOrphanUploadInterval = time.Hour      // 扫描间隔
+	orphanCleanupWorkers        = 4              // 并发扫描的用户目录数
+)
+
+// uploadKinds 上传目录下按类型划分的子目录（与 UploadVideo/UploadCover 的保存路径对应）
+var uploadKinds = []string{"videos", "covers"}
+
+// OrphanUploadCleaner 未发布上传文件清理任务
+// 职责：定期扫描上传目录（{root}/{videos|covers}/{用户ID}/{日期}/{文件名}），
+// 删除上传时间超过 maxAge 且没有被任何视频（包括保留期内的已删除视频）引用的文件
+// 注意：文件保存在 API 进程的上传目录中，因此该任务运行在 API 进程里
+type OrphanUploadCleaner struct {
+	repo         *VideoRepository // 视频仓储层（查询文件是否被引用）
+	uploadRoot   string           // 上传文件根目录
+	staticPrefix string           // 上传文件的访问路径前缀（如 /static）
+	maxAge       time.Duration    // 文件最短保留时间（给上传后再发布留出时间）
+	interval     time.Duration    // 扫描间隔
+	workers      int              // 并发扫描的用户目录数
+}
+
+// NewOrphanUploadCleaner 创建未发布上传文件清理任务
+// video.orphan_upload_interval 为负数时返回 nil（不启用）
+// 参数：
+//   - repo: 视频仓储层
+//   - storage: 上传文件存储配置（上传目录、访问路径前缀）
+//   - cfg: 视频配置（orphan_upload_max_age、orphan_upload_interval）
+func NewOrphanUploadCleaner(repo *VideoRepository, storage config.StorageConfig, cfg config.VideoConfig) *OrphanUploadCleaner {
+	if cfg.OrphanUploadInterval < 0 {
+		return nil
+	}
+	c := &OrphanUploadCleaner{
+		repo:         repo,
+		uploadRoot:   storage.UploadRoot(),
+		staticPrefix: storage.URLPrefix(),
+		maxAge:       cfg.OrphanUploadMaxAge,
+		interval:     cfg.OrphanUploadInterval,
+		workers:      orphanCleanupWorkers,
+	}
+	if c.maxAge <= 0 {
+		c.maxAge = defaultOrphanUploadMaxAge
+	}
+	if c.interval == 0 {
+		c.interval = defaultOrphanUploadInterval
+	}
+	return c
+}
+
+// Run 启动清理任务（阻塞，直到 ctx 被取消）
+func (c *OrphanUploadCleaner) Run(ctx context.Context) error {
+	if c == nil || c.repo == nil {
+		return errors.New("orphan upload cleaner is not initialized")
+	}
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		if n, err := c.CleanOnce(ctx, false); err != nil {
+			log.Printf("orphan upload cleaner: clean failed: %v", err)
+		} else if n > 0 {
+			log.Printf("orphan upload cleaner: removed %d files", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// CleanOnce 执行一轮清理
+// 按用户目录分发给固定数量的协程：每个用户先一次性查询其视频引用的文件，再扫描该用户的上传目录
+// dryRun 为 true 时只记录将要删除的文件，不删除文件和空目录
+// 返回：
+//   - int: 删除（dry run 时为将要删除）的文件数量
+//   - error: 错误信息（ctx 取消时返回 ctx 的错误）
+func (c *OrphanUploadCleaner) CleanOnce(ctx context.Context, dryRun bool) (int, error) {
+	accounts, err := c.listAccounts()
+	if err != nil {
+		return 0, err
+	}
+	cutoff := time.Now().Add(-c.maxAge)
+
+	var removed atomic.Int64
+	jobs := make(chan uint)
+	var wg sync.WaitGroup
+	for i := 0; i < c.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for accountID := range jobs {
+				n, err := c.cleanAccount(ctx, accountID, cutoff, dryRun)
+				removed.Add(int64(n))
+				if err != nil && ctx.Err() == nil {
+					log.Printf("orphan upload cleaner: account %d: %v", accountID, err)
+				}
+			}
+		}()
+	}
+
+dispatch:
+	for _, id := range accounts {
+		select {
+		case jobs <- id:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	return int(removed.Load()), ctx.Err()
+}
+
+// listAccounts 列出上传目录下所有的用户ID（videos 与 covers 目录合并去重）
+func (c *OrphanUploadCleaner) listAccounts() ([]uint, error) {
+	seen := make(map[uint]bool)
+	var ids []uint
+	for _, kind := range uploadKinds {
+		entries, err := os.ReadDir(filepath.Join(c.uploadRoot, kind))
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		for _, e := range entries {
+			id, err := strconv.ParseUint(e.Name(), 10, 64)
+			if !e.IsDir() || err != nil || seen[uint(id)] {
+				continue
+			}
+			seen[uint(id)] = true
+			ids = append(ids, uint(id))
+		}
+	}
+	return ids, nil
+}
+
+// cleanAccount 清理一个用户的上传目录（dryRun 时只记录）
+func (c *OrphanUploadCleaner) cleanAccount(ctx context.Context, accountID uint, cutoff time.Time, dryRun bool) (int, error) {
+	// 1. 查询该用户的视频（含已删除）引用的文件路径
+	urls, err := c.repo.ListMediaURLsByAuthor(ctx, accountID)
+	if err != nil {
+		return 0, err
+	}
+	referenced := make(map[string]bool, len(urls))
+	for _, raw := range urls {
+		if u, err := url.Parse(raw); err == nil {
+			referenced[u.Path] = true
+		}
+	}
+
+	// 2. 扫描该用户的上传目录，删除过期且未被引用的文件
+	removed := 0
+	for _, kind := range uploadKinds {
+		dir := filepath.Join(c.uploadRoot, kind, strconv.FormatUint(uint64(accountID), 10))
+		err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if errors.Is(err, fs.ErrNotExist) {
+					return nil
+				}
+				return err
+			}
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if d.IsDir() {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil || !info.Mode().IsRegular() || !info.ModTime().Before(cutoff) {
+				return nil
+			}
+			rel, err := filepath.Rel(c.uploadRoot, p)
+			if err != nil {
+				return nil
+			}
+			urlPath := path.Join(c.staticPrefix, filepath.ToSlash(rel))
+			if referenced[urlPath] {
+				return nil
+			}
+			// 2.1 不在该用户的视频中：再按路径全表确认一次（早期版本允许发布其他用户上传的文件）
+			used, err := c.repo.IsMediaReferenced(ctx, urlPath)
+			if err != nil || used {
+				return err
+			}
+			if dryRun {
+				log.Printf("orphan upload cleaner (dry run): would remove %s", p)
+				removed++
+				return nil
+			}
+			if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+				log.Printf("orphan upload cleaner: failed to remove %s: %v", p, err)
+				return nil
+			}
+			removed++
+			return nil
+		})
+		if err != nil {
+			return removed, err
+		}
+		if !dryRun {
+			c.removeEmptyDirs(dir, cutoff)
+		}
+	}
+	return removed, nil
+}
+
+// removeEmptyDirs 删除用户目录下已清空的日期目录
+// 只删除修改时间早于 cutoff 的目录，避免与正在向当天目录写入文件的上传请求冲突
+func (c *OrphanUploadCleaner) removeEmptyDirs(dir string, cutoff time.Time) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil || !info.ModTime().Before(cutoff) {
+			continue
+		}
+		// 目录非空时 os.Remove 会失败，直接忽略
+		_ = os.Remove(filepath.Join(dir, e.Name()))
+	}
+}
@@ -0,0 +1,119 @@
+package video
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"feedsystem_video_go/internal/config"
+)
+
+// 每日上传配额默认值（配置为 0 时使用）
+const (
+	defaultDailyUploadCount = 20
+	defaultDailyUploadMB    = 2048
+)
+
+// 配额计数哈希中的字段
+const (
+	uploadQuotaCountField = "count"
+	uploadQuotaBytesField = "bytes"
+)
+
+// ErrUploadQuotaExceeded 超过每日上传配额
+var ErrUploadQuotaExceeded = errors.New("daily upload quota exceeded")
+
+// UploadQuotaError 超过每日上传配额的详细信息（errors.Is 可匹配 ErrUploadQuotaExceeded）
+type UploadQuotaError struct {
+	ResetAt time.Time // 配额重置时间（次日零点）
+}
+
+func (e *UploadQuotaError) Error() string {
+	return fmt.Sprintf("%s, resets at %s", ErrUploadQuotaExceeded, e.ResetAt.Format(time.RFC3339))
+}
+
+func (e *UploadQuotaError) Unwrap() error {
+	return ErrUploadQuotaExceeded
+}
+
+// uploadQuota 每日上传配额（小于 0 表示不限制）
+type uploadQuota struct {
+	maxCount int64 // 每天最多上传视频数
+	maxBytes int64 // 每天最多上传字节数
+}
+
+func newUploadQuota(cfg config.VideoConfig) uploadQuota {
+	q := uploadQuota{maxCount: int64(cfg.DailyUploadCount), maxBytes: int64(cfg.DailyUploadMB) << 20}
+	if cfg.DailyUploadCount == 0 {
+		q.maxCount = defaultDailyUploadCount
+	}
+	if cfg.DailyUploadMB == 0 {
+		q.maxBytes = defaultDailyUploadMB << 20
+	}
+	return q
+}
+
+// uploadQuotaKey 每日上传配额计数 key：upload:quota:{id}:{yyyymmdd}
+func uploadQuotaKey(accountID uint, day time.Time) string {
+	return fmt.Sprintf("upload:quota:%d:%s", accountID, day.Format("20060102"))
+}
+
+// nextMidnight 下一个零点（本地时区），即当天配额的重置时间
+func nextMidnight(now time.Time) time.Time {
+	y, m, d := now.Date()
+	return time.Date(y, m, d+1, 0, 0, 0, 0, now.Location())
+}
+
+// ReserveUpload 占用一次上传配额（保存文件之前调用）
+// 业务流程：
+// 1. 在 Redis 哈希 upload:quota:{id}:{date} 中原子地累加上传次数和字节数，key 在次日零点过期
+// 2. 累加后超过配额时回退本次累加，返回 *UploadQuotaError（包含重置时间）
+// 3. 返回的 release 用于文件保存失败时归还配额
+// Redis 不可用时不做限制（上传大小仍受单文件上限约束）
+// 参数：
+//   - ctx: 上下文
+//   - accountID: 上传者账户ID
+//   - size: 文件字节数
+//
+// 返回：
+//   - release: 归还本次占用的配额
+//   - error: 超过配额时为 *UploadQuotaError
+func (s *VideoService) ReserveUpload(ctx context.Context, accountID uint, size int64) (release func(), err error) {
+	release = func() {}
+	if s.cache == nil || (s.uploadQuota.maxCount < 0 && s.uploadQuota.maxBytes < 0) {
+		return release, nil
+	}
+
+	now := time.Now()
+	key := uploadQuotaKey(accountID, now)
+	resetAt := nextMidnight(now)
+	incr := map[string]int64{uploadQuotaCountField: 1, uploadQuotaBytesField: size}
+	undo := map[string]int64{uploadQuotaCountField: -1, uploadQuotaBytesField: -size}
+
+	// 1. 累加本次上传
+	opCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	used, err := s.cache.HIncrByExpireAt(opCtx, key, incr, resetAt)
+	cancel()
+	if err != nil {
+		log.Printf("upload quota: redis unavailable, skip check for account %d: %v", accountID, err)
+		return release, nil
+	}
+
+	release = func() {
+		opCtx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		if _, err := s.cache.HIncrByExpireAt(opCtx, key, undo, resetAt); err != nil {
+			log.Printf("upload quota: failed to release quota for account %d: %v", accountID, err)
+		}
+	}
+
+	// 2. 超过配额时回退
+	if (s.uploadQuota.maxCount >= 0 && used[uploadQuotaCountField] > s.uploadQuota.maxCount) ||
+		(s.uploadQuota.maxBytes >= 0 && used[uploadQuotaBytesField] > s.uploadQuota.maxBytes) {
+		release()
+		return func() {}, &UploadQuotaError{ResetAt: resetAt}
+	}
+	return release, nil
+}
@@ -1,19 +1,62 @@
 package video
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 // Video 视频实体模型，对应数据库中的videos表
+// 复合索引（支撑 Feed 流排序 + 游标分页，避免全表 filesort）：
+//   - idx_video_popularity_time_id (popularity, create_time, id)：热度榜 DB Fallback
+//   - idx_video_likes_id (likes_count, id)：点赞排行
+//   - idx_video_create_time (create_time)：最新视频
+//   - idx_video_author_time (author_id, create_time)：关注流 / 作者视频列表
+//   - idx_video_visibility_publish_at (visibility, publish_at)：定时发布任务扫描到期视频
+//   - idx_video_updated_at (updated_at)：按更新时间增量同步（最新视频流的 updated_since 过滤）
 type Video struct {
-	ID          uint      `gorm:"primaryKey" json:"id"`                     // 主键ID
-	AuthorID    uint      `gorm:"index;not null" json:"author_id"`          // 作者ID（带索引）
+	ID          uint      `gorm:"primaryKey;index:idx_video_popularity_time_id,priority:3;index:idx_video_likes_id,priority:2" json:"id"` // 主键ID
+	AuthorID    uint      `gorm:"index:idx_video_author_time,priority:1;not null" json:"author_id"` // 作者ID（带索引）
 	Username    string    `gorm:"type:varchar(255);not null" json:"username"` // 作者用户名（冗余存储，便于查询）
 	Title       string    `gorm:"type:varchar(255);not null" json:"title"`  // 视频标题
 	Description string    `gorm:"type:varchar(255);" json:"description,omitempty"` // 视频描述（可选）
 	PlayURL     string    `gorm:"type:varchar(255);not null" json:"play_url"` // 播放地址
 	CoverURL    string    `gorm:"type:varchar(255);not null" json:"cover_url"` // 封面地址
-	CreateTime  time.Time `gorm:"autoCreateTime" json:"create_time"`        // 创建时间（自动生成）
-	LikesCount  int64     `gorm:"column:likes_count;not null;default:0" json:"likes_count"` // 点赞数
-	Popularity  int64     `gorm:"column:popularity;not null;default:0" json:"popularity"` // 热度值
+	CreateTime  time.Time `gorm:"autoCreateTime;index:idx_video_create_time;index:idx_video_author_time,priority:2;index:idx_video_popularity_time_id,priority:2" json:"create_time"` // 创建时间（自动生成）
+	LikesCount  int64     `gorm:"column:likes_count;not null;default:0;index:idx_video_likes_id,priority:1" json:"likes_count"` // 点赞数
+	Popularity  int64     `gorm:"column:popularity;not null;default:0;index:idx_video_popularity_time_id,priority:1" json:"popularity"` // 热度值
+	Visibility  string    `gorm:"type:varchar(16);not null;default:'public';index:idx_video_visibility_publish_at,priority:1" json:"visibility"` // 可见性：public/private/draft/scheduled（见 Visibility* 常量）
+	PublishAt   *time.Time `gorm:"index:idx_video_visibility_publish_at,priority:2" json:"publish_at,omitempty"` // 定时发布时间（仅 scheduled 视频有值，到期后由定时任务改为 public）
+	UpdatedAt   time.Time `gorm:"autoUpdateTime;index:idx_video_updated_at" json:"updated_at"` // 更新时间（元数据、可见性、点赞数、热度变化时更新），用于详情接口的 ETag、客户端增量同步
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"` // 软删除时间（GORM 查询自动排除已删除记录）
+}
+
+// 视频可见性
+// 只有 public 的视频会出现在 Feed 流和热榜中；private/draft/scheduled 只有作者本人可以查看
+const (
+	VisibilityPublic    = "public"    // 公开（默认）
+	VisibilityPrivate   = "private"   // 私密：仅作者可见
+	VisibilityDraft     = "draft"     // 草稿：仅作者可见，稍后再公开
+	VisibilityScheduled = "scheduled" // 定时发布：到达 publish_at 后自动公开（只能通过发布时传 publish_at 进入该状态）
+)
+
+// ValidVisibility 判断客户端可以直接设置的可见性取值是否合法（不含 scheduled）
+func ValidVisibility(v string) bool {
+	switch v {
+	case VisibilityPublic, VisibilityPrivate, VisibilityDraft:
+		return true
+	}
+	return false
+}
+
+// isPublic 判断视频是否公开（可见性为空的旧数据视为公开）
+func isPublic(v string) bool {
+	return v == "" || v == VisibilityPublic
+}
+
+// IsPublic 判断视频是否公开（出现在 Feed 流和热榜中）
+func (v *Video) IsPublic() bool {
+	return isPublic(v.Visibility)
 }
 
 // PublishVideoRequest 发布视频请求体
@@ -22,25 +65,98 @@ type PublishVideoRequest struct {
 	Description string `json:"description"` // 视频描述
 	PlayURL     string `json:"play_url"`    // 播放地址
 	CoverURL    string `json:"cover_url"`   // 封面地址
+	Visibility  string `json:"visibility"`  // 可见性（可选）：public/private/draft，默认 public
+	PublishAt   *time.Time `json:"publish_at"` // 定时发布时间（可选，RFC3339）：必须晚于当前时间，视频在此之前保持隐藏
+
+	IdempotencyKey string `json:"idempotency_key"` // 幂等键（可选）：客户端重试时携带相同的值，不会重复创建视频
 }
 
 // DeleteVideoRequest 删除视频请求体
 type DeleteVideoRequest struct {
-	ID uint `json:"id"` // 视频ID
+	ID uint `json:"id" binding:"required,gt=0"` // 视频ID
+}
+
+// VideoStatsRequest 查询视频互动统计请求体（管理员）
+type VideoStatsRequest struct {
+	ID uint `json:"id" binding:"required,gt=0"` // 视频ID
+}
+
+// VideoStats 视频互动统计（管理员刷量检测）
+// 不同点赞用户数远小于点赞数（比例明显低于 1）说明计数与点赞记录不一致，可能被刷量；
+// 评论同理，少数账户贡献了大部分评论时 DistinctCommenters / CommentsCount 会很低
+type VideoStats struct {
+	VideoID            uint    `json:"video_id"`             // 视频ID
+	LikesCount         int64   `json:"likes_count"`          // 点赞数（videos.likes_count 计数）
+	DistinctLikers     int64   `json:"distinct_likers"`      // 不同点赞用户数（likes 表）
+	DistinctLikerRatio float64 `json:"distinct_liker_ratio"` // 不同点赞用户数 / 点赞数（点赞数为 0 时为 0）
+	CommentsCount      int64   `json:"comments_count"`       // 评论数
+	DistinctCommenters int64   `json:"distinct_commenters"`  // 不同评论用户数
+	Popularity         int64   `json:"popularity"`           // 热度值
+}
+
+// AuthorStatsRequest 查询作者统计请求体
+type AuthorStatsRequest struct {
+	AuthorID uint `json:"author_id" binding:"required,gt=0"` // 作者ID
+}
+
+// AuthorStats 作者统计（个人主页展示，只统计公开视频）
+type AuthorStats struct {
+	AuthorID    uint  `json:"author_id"`    // 作者ID
+	TotalVideos int64 `json:"total_videos"` // 公开视频数
+	TotalLikes  int64 `json:"total_likes"`  // 公开视频收到的点赞总数
+}
+
+// SetVisibilityRequest 修改视频可见性请求体
+type SetVisibilityRequest struct {
+	ID         uint   `json:"id" binding:"required,gt=0"` // 视频ID
+	Visibility string `json:"visibility"`                 // 新的可见性：public/private/draft
+}
+
+// RestoreVideoRequest 恢复已删除视频请求体（管理员）
+type RestoreVideoRequest struct {
+	ID uint `json:"id" binding:"required,gt=0"` // 视频ID
 }
 
 // ListByAuthorIDRequest 查询作者视频列表请求体
 type ListByAuthorIDRequest struct {
-	AuthorID uint `json:"author_id"` // 作者ID
+	AuthorID uint   `json:"author_id" binding:"required,gt=0"` // 作者ID
+	Order    string `json:"order"`                             // 可选：排序方向 asc（最早的在前）/desc（最新的在前），默认 desc
 }
 
+// 作者视频列表排序方向
+const (
+	OrderDesc = "desc" // 按创建时间倒序（默认）
+	OrderAsc  = "asc"  // 按创建时间正序
+)
+
 // GetDetailRequest 获取视频详情请求体
 type GetDetailRequest struct {
-	ID uint `json:"id"` // 视频ID
+	ID            uint `json:"id" binding:"required,gt=0"` // 视频ID
+	IncludeAuthor bool `json:"include_author"`             // 可选：同时返回作者公开资料（author 字段），默认不返回
+}
+
+// DetailAuthor 视频详情中的作者公开资料（include_author 时返回）
+// 字段与 feed.FeedAuthor 一致，并附带粉丝数/关注数（feed 包依赖 video 包，这里不能直接引用 FeedAuthor）
+type DetailAuthor struct {
+	ID             uint   `json:"id"`              // 作者ID
+	Username       string `json:"username"`        // 作者用户名
+	FollowersCount int64  `json:"followers_count"` // 粉丝数
+	FollowingCount int64  `json:"following_count"` // 关注数
+}
+
+// VideoDetailWithAuthor 带作者资料的视频详情（视频字段平铺，与默认响应兼容，额外增加 author 字段）
+type VideoDetailWithAuthor struct {
+	*Video
+	Author *DetailAuthor `json:"author"` // 作者公开资料（查询失败时为 null）
+}
+
+// GetDetailsRequest 批量获取视频详情请求体
+type GetDetailsRequest struct {
+	IDs []uint `json:"ids"` // 视频ID列表（最多 50 个）
 }
 
 // UpdateLikesCountRequest 更新点赞数请求体
 type UpdateLikesCountRequest struct {
-	ID         uint  `json:"id"`          // 视频ID
-	LikesCount int64 `json:"likes_count"` // 新的点赞数
+	ID         uint  `json:"id" binding:"required,gt=0"`  // 视频ID
+	LikesCount int64 `json:"likes_count" binding:"gte=0"` // 新的点赞数
 }
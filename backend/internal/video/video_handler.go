@@ -3,20 +3,31 @@ package video
 import (
 	"crypto/rand"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
+	"mime/multipart"
 	"net/http"
 	"os"
 	"path"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
 	"feedsystem_video_go/internal/account"
+	"feedsystem_video_go/internal/apierror"
 	"feedsystem_video_go/internal/middleware/jwt"
 
 	"github.com/gin-gonic/gin"
 )
 
+// 上传文件大小上限，router 中按同样的值（加上 multipart 开销）限制上传接口的请求体
+const (
+	MaxVideoUploadSize int64 = 200 << 20 // 视频文件上限 200MB
+	MaxCoverUploadSize int64 = 10 << 20  // 封面图片上限 10MB
+)
+
 // VideoHandler 视频处理器，负责处理视频相关的HTTP请求
 type VideoHandler struct {
 	service        *VideoService        // 视频服务层，处理视频业务逻辑
@@ -31,26 +42,26 @@ func NewVideoHandler(service *VideoService, accountService *account.AccountServi
 // PublishVideo 发布视频接口
 // 路由：POST /video/publish
 // 功能：使用已上传的视频和封面URL创建视频记录
-// 请求体：{"title": "标题", "description": "描述", "play_url": "视频URL", "cover_url": "封面URL"}
+// 请求体：{"title": "标题", "description": "描述", "play_url": "视频URL", "cover_url": "封面URL", "visibility": "可选", "publish_at": "可选，RFC3339", "idempotency_key": "可选"}
 func (vh *VideoHandler) PublishVideo(c *gin.Context) {
 	// 1. 解析JSON请求体
 	var req PublishVideoRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(400, gin.H{"error": err.Error()})
+		apierror.BadRequest(c, err)
 		return
 	}
 
 	// 2. 从JWT中间件中获取当前登录用户的ID
 	authorId, err := jwt.GetAccountID(c)
 	if err != nil {
-		c.JSON(400, gin.H{"error": err.Error()})
+		apierror.Unauthorized(c)
 		return
 	}
 
 	// 3. 查询用户信息（获取用户名）
 	user, err := vh.accountService.FindByID(c.Request.Context(), authorId)
 	if err != nil {
-		c.JSON(400, gin.H{"error": err.Error()})
+		apierror.FromError(c, err, account.NotFoundMapping)
 		return
 	}
 
@@ -62,16 +73,18 @@ func (vh *VideoHandler) PublishVideo(c *gin.Context) {
 		Description: req.Description,       // 视频描述
 		PlayURL:     req.PlayURL,           // 播放地址
 		CoverURL:    req.CoverURL,          // 封面地址
+		Visibility:  req.Visibility,        // 可见性（为空时默认 public）
+		PublishAt:   req.PublishAt,         // 定时发布时间（可选）
 		CreateTime:  time.Now(),           // 创建时间
 	}
 
-	// 5. 调用Service层发布视频
-	if err := vh.service.Publish(c.Request.Context(), video); err != nil {
-		c.JSON(400, gin.H{"error": err.Error()})
+	// 5. 调用Service层发布视频（携带幂等键时，重复请求返回首次创建的视频）
+	if err := vh.service.Publish(c.Request.Context(), video, req.IdempotencyKey, c.Request.Host); err != nil {
+		apierror.FromError(c, err, videoErrors...)
 		return
 	}
 
-	// 6. 返回创建的视频信息
+	// 6. 返回创建的视频信息（重复请求时为首次创建的视频）
 	c.JSON(200, video)
 }
 
@@ -79,59 +92,91 @@ func (vh *VideoHandler) PublishVideo(c *gin.Context) {
 // 路由：POST /video/upload
 // 功能：接收MP4视频文件，保存到本地并返回访问URL
 // 请求格式：multipart/form-data，字段名：file
+// 每日上传次数/大小超过配额时返回 429 UPLOAD_QUOTA_EXCEEDED，Retry-After 和 X-Upload-Quota-Reset 响应头给出重置时间
 func (vh *VideoHandler) UploadVideo(c *gin.Context) {
 	// 1. 从JWT中间件获取当前登录用户ID
 	authorId, err := jwt.GetAccountID(c)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Unauthorized(c)
 		return
 	}
 
 	// 2. 获取上传的文件
+	// 请求体超过路由上限（bodylimit 中间件）时解析 multipart 会失败，返回413
 	f, err := c.FormFile("file")
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "missing file"})
+		if apierror.IsTooLarge(err) {
+			apierror.TooLarge(c)
+			return
+		}
+		apierror.Write(c, http.StatusBadRequest, apierror.CodeInvalidArgument, "missing file")
 		return
 	}
 
-	// 3. 验证文件大小（限制200MB）
-	const maxSize = 200 << 20 // 200 * 1024 * 1024
-	if f.Size <= 0 || f.Size > maxSize {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid file size"})
+	// 3. 验证文件大小（限制200MB，超过返回413）
+	if f.Size <= 0 {
+		apierror.Write(c, http.StatusBadRequest, apierror.CodeInvalidArgument, "invalid file size")
+		return
+	}
+	if f.Size > MaxVideoUploadSize {
+		apierror.TooLarge(c)
 		return
 	}
 
 	// 4. 验证文件格式（仅允许.mp4）
 	ext := strings.ToLower(filepath.Ext(f.Filename))
 	if ext != ".mp4" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "only .mp4 is allowed"})
+		apierror.Write(c, http.StatusBadRequest, apierror.CodeInvalidArgument, "only .mp4 is allowed")
+		return
+	}
+
+	// 5. 检查每日上传配额（管理员不受限制），超过返回429并附带重置时间
+	isAdmin, err := vh.accountService.IsAdmin(c.Request.Context(), authorId)
+	if err != nil {
+		apierror.FromError(c, err, account.NotFoundMapping)
 		return
 	}
+	release := func() {} // 文件保存失败时归还配额
+	if !isAdmin {
+		release, err = vh.service.ReserveUpload(c.Request.Context(), authorId, f.Size)
+		if err != nil {
+			var quotaErr *UploadQuotaError
+			if errors.As(err, &quotaErr) {
+				retryAfter := int64(time.Until(quotaErr.ResetAt).Seconds()) + 1
+				c.Header("Retry-After", strconv.FormatInt(retryAfter, 10))
+				c.Header("X-Upload-Quota-Reset", quotaErr.ResetAt.Format(time.RFC3339))
+			}
+			apierror.FromError(c, err, videoErrors...)
+			return
+		}
+	}
 
-	// 5. 构造保存路径：.run/uploads/videos/{用户ID}/{日期}/
+	// 6. 构造保存路径：{上传目录}/videos/{用户ID}/{日期}/
 	date := time.Now().Format("20060102")
 	relDir := filepath.Join("videos", fmt.Sprintf("%d", authorId), date)
-	root := filepath.Join(".run", "uploads")
+	root := vh.service.uploadRoot
 	absDir := filepath.Join(root, relDir)
 	if err := os.MkdirAll(absDir, 0o755); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		release()
+		apierror.FromError(c, err, videoErrors...)
 		return
 	}
 
-	// 6. 生成随机文件名（16位十六进制字符串 + 扩展名）
+	// 7. 生成随机文件名（16位十六进制字符串 + 扩展名）
 	filename := randHex(16) + ext
 	absPath := filepath.Join(absDir, filename)
 
-	// 7. 保存文件到磁盘
+	// 8. 保存文件到磁盘
 	if err := c.SaveUploadedFile(f, absPath); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		release()
+		apierror.FromError(c, err, videoErrors...)
 		return
 	}
 
-	// 8. 构造访问URL：/static/videos/{用户ID}/{日期}/{文件名}
-	urlPath := path.Join("/static", "videos", fmt.Sprintf("%d", authorId), date, filename)
+	// 9. 构造访问URL：{访问路径前缀}/videos/{用户ID}/{日期}/{文件名}
+	urlPath := path.Join(vh.service.staticPrefix, "videos", fmt.Sprintf("%d", authorId), date, filename)
 
-	// 9. 返回完整URL
+	// 10. 返回完整URL
 	c.JSON(http.StatusOK, gin.H{
 		"url":      buildAbsoluteURL(c, urlPath), // 完整URL（含协议和域名）
 		"play_url": buildAbsoluteURL(c, urlPath), // 播放URL（同url）
@@ -146,21 +191,29 @@ func (vh *VideoHandler) UploadCover(c *gin.Context) {
 	// 1. 从JWT中间件获取当前登录用户ID
 	authorId, err := jwt.GetAccountID(c)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Unauthorized(c)
 		return
 	}
 
 	// 2. 获取上传的文件
+	// 请求体超过路由上限（bodylimit 中间件）时解析 multipart 会失败，返回413
 	f, err := c.FormFile("file")
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "missing file"})
+		if apierror.IsTooLarge(err) {
+			apierror.TooLarge(c)
+			return
+		}
+		apierror.Write(c, http.StatusBadRequest, apierror.CodeInvalidArgument, "missing file")
 		return
 	}
 
-	// 3. 验证文件大小（限制10MB）
-	const maxSize = 10 << 20 // 10 * 1024 * 1024
-	if f.Size <= 0 || f.Size > maxSize {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid file size"})
+	// 3. 验证文件大小（限制10MB，超过返回413）
+	if f.Size <= 0 {
+		apierror.Write(c, http.StatusBadRequest, apierror.CodeInvalidArgument, "invalid file size")
+		return
+	}
+	if f.Size > MaxCoverUploadSize {
+		apierror.TooLarge(c)
 		return
 	}
 
@@ -170,40 +223,65 @@ func (vh *VideoHandler) UploadCover(c *gin.Context) {
 	case ".jpg", ".jpeg", ".png", ".webp":
 		// 允许的格式
 	default:
-		c.JSON(http.StatusBadRequest, gin.H{"error": "only .jpg/.jpeg/.png/.webp is allowed"})
+		apierror.Write(c, http.StatusBadRequest, apierror.CodeInvalidArgument, "only .jpg/.jpeg/.png/.webp is allowed")
 		return
 	}
 
-	// 5. 构造保存路径：.run/uploads/covers/{用户ID}/{日期}/
+	// 5. 封面规范化（video.normalize_covers 开启时）：转码为统一格式并限制尺寸，失败时保存原图
+	var normalized []byte
+	if vh.service.covers.enabled {
+		if data, err := readUploadedFile(f); err == nil {
+			if out, newExt, ok := vh.service.NormalizeCover(data); ok {
+				normalized, ext = out, newExt
+			}
+		}
+	}
+
+	// 6. 构造保存路径：{上传目录}/covers/{用户ID}/{日期}/
 	date := time.Now().Format("20060102")
 	relDir := filepath.Join("covers", fmt.Sprintf("%d", authorId), date)
-	root := filepath.Join(".run", "uploads")
+	root := vh.service.uploadRoot
 	absDir := filepath.Join(root, relDir)
 	if err := os.MkdirAll(absDir, 0o755); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.FromError(c, err, videoErrors...)
 		return
 	}
 
-	// 6. 生成随机文件名
+	// 7. 生成随机文件名
 	filename := randHex(16) + ext
 	absPath := filepath.Join(absDir, filename)
 
-	// 7. 保存文件到磁盘
-	if err := c.SaveUploadedFile(f, absPath); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	// 8. 保存文件到磁盘（有转码结果时保存转码后的图片）
+	if normalized != nil {
+		err = os.WriteFile(absPath, normalized, 0o644)
+	} else {
+		err = c.SaveUploadedFile(f, absPath)
+	}
+	if err != nil {
+		apierror.FromError(c, err, videoErrors...)
 		return
 	}
 
-	// 8. 构造访问URL：/static/covers/{用户ID}/{日期}/{文件名}
-	urlPath := path.Join("/static", "covers", fmt.Sprintf("%d", authorId), date, filename)
+	// 9. 构造访问URL：{访问路径前缀}/covers/{用户ID}/{日期}/{文件名}
+	urlPath := path.Join(vh.service.staticPrefix, "covers", fmt.Sprintf("%d", authorId), date, filename)
 
-	// 9. 返回完整URL
+	// 10. 返回完整URL
 	c.JSON(http.StatusOK, gin.H{
 		"url":       buildAbsoluteURL(c, urlPath),  // 完整URL
 		"cover_url": buildAbsoluteURL(c, urlPath), // 封面URL（同url）
 	})
 }
 
+// readUploadedFile 读取上传文件的全部内容（调用方已校验文件大小上限）
+func readUploadedFile(f *multipart.FileHeader) ([]byte, error) {
+	file, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return io.ReadAll(file)
+}
+
 // randHex 生成n字节的随机十六进制字符串
 // 参数：n - 字节长度，例如n=16将生成32位十六进制字符串
 // 返回：随机十六进制字符串
@@ -245,20 +323,76 @@ func (vh *VideoHandler) DeleteVideo(c *gin.Context) {
 	// 1. 解析JSON请求体
 	var req DeleteVideoRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(400, gin.H{"error": err.Error()})
+		apierror.BadRequest(c, err)
 		return
 	}
 
 	// 2. 从JWT中间件获取当前登录用户ID
 	authorId, err := jwt.GetAccountID(c)
 	if err != nil {
-		c.JSON(400, gin.H{"error": err.Error()})
+		apierror.Unauthorized(c)
 		return
 	}
 
 	// 3. 调用Service层删除视频（会验证是否为作者本人）
 	if err := vh.service.Delete(c.Request.Context(), req.ID, authorId); err != nil {
-		c.JSON(400, gin.H{"error": err.Error()})
+		apierror.FromError(c, err, videoErrors...)
+		return
+	}
+
+	// 4. 返回成功消息
+	c.JSON(200, gin.H{"message": "video deleted"})
+}
+
+// SetVisibility 修改视频可见性接口
+// 路由：POST /video/setVisibility
+// 功能：作者修改自己视频的可见性（如将草稿公开、将视频设为私密）
+// 请求体：{"id": 视频ID, "visibility": "public|private|draft"}
+func (vh *VideoHandler) SetVisibility(c *gin.Context) {
+	// 1. 解析JSON请求体
+	var req SetVisibilityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.BadRequest(c, err)
+		return
+	}
+	// 2. 从JWT中间件获取当前登录用户ID
+	authorId, err := jwt.GetAccountID(c)
+	if err != nil {
+		apierror.Unauthorized(c)
+		return
+	}
+
+	// 3. 调用Service层修改可见性（会验证是否为作者本人）
+	if err := vh.service.SetVisibility(c.Request.Context(), req.ID, authorId, req.Visibility); err != nil {
+		apierror.FromError(c, err, videoErrors...)
+		return
+	}
+
+	// 4. 返回成功消息
+	c.JSON(200, gin.H{"message": "visibility updated"})
+}
+
+// AdminDeleteVideo 管理员删除视频接口（内容审核）
+// 路由：POST /admin/video/delete
+// 功能：删除任意用户发布的违规视频（不校验作者）
+// 请求体：{"id": 视频ID}
+func (vh *VideoHandler) AdminDeleteVideo(c *gin.Context) {
+	// 1. 解析JSON请求体
+	var req DeleteVideoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.BadRequest(c, err)
+		return
+	}
+	// 2. 从JWT中间件获取当前管理员ID（用于记录操作者）
+	operatorID, err := jwt.GetAccountID(c)
+	if err != nil {
+		apierror.Unauthorized(c)
+		return
+	}
+
+	// 3. 调用Service层删除视频（跳过作者校验）
+	if err := vh.service.AdminDelete(c.Request.Context(), req.ID, operatorID); err != nil {
+		apierror.FromError(c, err, videoErrors...)
 		return
 	}
 
@@ -266,22 +400,48 @@ func (vh *VideoHandler) DeleteVideo(c *gin.Context) {
 	c.JSON(200, gin.H{"message": "video deleted"})
 }
 
+// RestoreVideo 恢复已删除视频接口（管理员）
+// 路由：POST /video/restore
+// 功能：在保留期内恢复被软删除的视频
+// 请求体：{"id": 视频ID}
+func (vh *VideoHandler) RestoreVideo(c *gin.Context) {
+	// 1. 解析JSON请求体
+	var req RestoreVideoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.BadRequest(c, err)
+		return
+	}
+	// 2. 调用Service层恢复视频（会校验保留期）
+	if err := vh.service.Restore(c.Request.Context(), req.ID); err != nil {
+		apierror.FromError(c, err, videoErrors...)
+		return
+	}
+
+	// 3. 返回成功消息
+	c.JSON(200, gin.H{"message": "video restored"})
+}
+
 // ListByAuthorID 查询作者的视频列表接口
 // 路由：POST /video/list-by-author
 // 功能：根据作者ID查询该作者发布的所有视频
-// 请求体：{"author_id": 作者ID}
+// 请求体：{"author_id": 作者ID, "order": 可选，asc（最早的在前）/desc（最新的在前，默认）}
 func (vh *VideoHandler) ListByAuthorID(c *gin.Context) {
 	// 1. 解析JSON请求体
 	var req ListByAuthorIDRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(400, gin.H{"error": err.Error()})
+		apierror.BadRequest(c, err)
 		return
 	}
 
 	// 2. 调用Service层查询视频列表
-	videos, err := vh.service.ListByAuthorID(c.Request.Context(), req.AuthorID)
+	// 作者本人查看时包含非公开视频（接口可匿名访问，未登录时 viewerID = 0）
+	viewerID, err := jwt.GetAccountID(c)
+	if err != nil {
+		viewerID = 0
+	}
+	videos, err := vh.service.ListByAuthorID(c.Request.Context(), req.AuthorID, viewerID, req.Order)
 	if err != nil {
-		c.JSON(400, gin.H{"error": err.Error()})
+		apierror.FromError(c, err, videoErrors...)
 		return
 	}
 
@@ -292,26 +452,88 @@ func (vh *VideoHandler) ListByAuthorID(c *gin.Context) {
 // GetDetail 获取视频详情接口
 // 路由：POST /video/detail
 // 功能：根据视频ID获取视频完整信息（含缓存逻辑）
-// 请求体：{"id": 视频ID}
+// 请求体：{"id": 视频ID, "include_author": 可选，true 时额外返回 author（作者公开资料）}
+// 响应头：ETag（弱校验，由视频ID和更新时间生成）、Last-Modified、Cache-Control；
+// 请求携带的 If-None-Match 与当前 ETag 匹配时返回 304（无响应体）
 func (vh *VideoHandler) GetDetail(c *gin.Context) {
 	// 1. 解析JSON请求体
 	var req GetDetailRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(400, gin.H{"error": err.Error()})
+		apierror.BadRequest(c, err)
 		return
 	}
 
 	// 2. 调用Service层获取视频详情（含缓存逻辑）
-	video, err := vh.service.GetDetail(c.Request.Context(), req.ID)
+	// 非公开视频只有作者本人可以查看（接口可匿名访问，未登录时 viewerID = 0）
+	viewerID, err := jwt.GetAccountID(c)
 	if err != nil {
-		c.JSON(400, gin.H{"error": err.Error()})
+		viewerID = 0
+	}
+	video, err := vh.service.GetDetail(c.Request.Context(), req.ID, viewerID)
+	if err != nil {
+		apierror.FromError(c, err, videoErrors...)
+		return
+	}
+
+	// 3. 可选：查询作者公开资料（走账户资料缓存），失败时 author 为 null，不影响视频详情
+	if req.IncludeAuthor {
+		resp := VideoDetailWithAuthor{Video: video}
+		variant := "author"
+		if user, err := vh.accountService.FindByID(c.Request.Context(), video.AuthorID); err == nil {
+			resp.Author = &DetailAuthor{
+				ID:             user.ID,
+				Username:       user.Username,
+				FollowersCount: user.FollowersCount,
+				FollowingCount: user.FollowingCount,
+			}
+			variant = fmt.Sprintf("author.%d.%d", user.FollowersCount, user.FollowingCount)
+		}
+		if writeDetailValidators(c, video, variant) {
+			c.Status(http.StatusNotModified)
+			return
+		}
+		c.JSON(200, resp)
 		return
 	}
 
-	// 3. 返回视频详情
+	// 4. 客户端缓存仍然有效（ETag 匹配）时返回 304，不重复返回视频详情
+	if writeDetailValidators(c, video, "") {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	// 5. 返回视频详情
 	c.JSON(200, video)
 }
 
+// GetDetails 批量获取视频详情接口
+// 路由：POST /video/getDetails
+// 功能：一次获取多个视频的详情（分享页、播放列表），按请求顺序返回
+// 请求体：{"ids": [视频ID, ...]}（最多 50 个）
+// 不存在、已删除或当前用户无权查看的视频直接省略，不会导致整个请求失败
+func (vh *VideoHandler) GetDetails(c *gin.Context) {
+	// 1. 解析JSON请求体
+	var req GetDetailsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.BadRequest(c, err)
+		return
+	}
+
+	// 2. 调用Service层批量获取视频详情（接口可匿名访问，未登录时 viewerID = 0）
+	viewerID, err := jwt.GetAccountID(c)
+	if err != nil {
+		viewerID = 0
+	}
+	videos, err := vh.service.GetDetails(c.Request.Context(), req.IDs, viewerID)
+	if err != nil {
+		apierror.FromError(c, err, videoErrors...)
+		return
+	}
+
+	// 3. 返回视频详情列表
+	c.JSON(200, gin.H{"videos": videos})
+}
+
 // UpdateLikesCount 更新视频点赞数接口
 // 路由：POST /video/update-likes
 // 功能：更新视频的点赞数（供Worker异步调用，一般不直接暴露给前端）
@@ -320,16 +542,42 @@ func (vh *VideoHandler) UpdateLikesCount(c *gin.Context) {
 	// 1. 解析JSON请求体
 	var req UpdateLikesCountRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(400, gin.H{"error": err.Error()})
+		apierror.BadRequest(c, err)
 		return
 	}
 
 	// 2. 调用Service层更新点赞数
 	if err := vh.service.UpdateLikesCount(c.Request.Context(), req.ID, req.LikesCount); err != nil {
-		c.JSON(400, gin.H{"error": err.Error()})
+		apierror.FromError(c, err, videoErrors...)
 		return
 	}
 
 	// 3. 返回成功消息
 	c.JSON(200, gin.H{"message": "likes count updated"})
 }
+
+// videoErrors 视频服务层错误到错误码/HTTP状态码的映射
+// 资源不存在返回 404，无权限返回 403，业务校验失败返回 400，其余（数据库、缓存等基础设施错误）返回 500
+var videoErrors = []apierror.Mapping{
+	{Err: ErrVideoInvalid, Status: http.StatusBadRequest, Code: apierror.CodeInvalidArgument},
+	{Err: ErrTitleRequired, Status: http.StatusBadRequest, Code: "TITLE_REQUIRED"},
+	{Err: ErrPlayURLRequired, Status: http.StatusBadRequest, Code: "PLAY_URL_REQUIRED"},
+	{Err: ErrCoverURLRequired, Status: http.StatusBadRequest, Code: "COVER_URL_REQUIRED"},
+	{Err: ErrInvalidPlayURL, Status: http.StatusBadRequest, Code: "INVALID_MEDIA_URL"},
+	{Err: ErrInvalidCoverURL, Status: http.StatusBadRequest, Code: "INVALID_MEDIA_URL"},
+	{Err: ErrMediaNotOwned, Status: http.StatusForbidden, Code: "MEDIA_NOT_OWNED"},
+	{Err: ErrTitleTooLong, Status: http.StatusBadRequest, Code: "TITLE_TOO_LONG"},
+	{Err: ErrDescriptionTooLong, Status: http.StatusBadRequest, Code: "DESCRIPTION_TOO_LONG"},
+	{Err: ErrIdempotencyKeyTooLong, Status: http.StatusBadRequest, Code: "IDEMPOTENCY_KEY_TOO_LONG"},
+	{Err: ErrPublishInProgress, Status: http.StatusConflict, Code: "PUBLISH_IN_PROGRESS"},
+	{Err: ErrInvalidVisibility, Status: http.StatusBadRequest, Code: "INVALID_VISIBILITY"},
+	{Err: ErrPublishAtInPast, Status: http.StatusBadRequest, Code: "PUBLISH_AT_IN_PAST"},
+	{Err: ErrVideoForbidden, Status: http.StatusForbidden, Code: "VIDEO_FORBIDDEN"},
+	{Err: ErrVideoNotFound, Status: http.StatusNotFound, Code: "VIDEO_NOT_FOUND"},
+	{Err: ErrDeletedVideoNotFound, Status: http.StatusNotFound, Code: "DELETED_VIDEO_NOT_FOUND"},
+	{Err: ErrNotVideoAuthor, Status: http.StatusForbidden, Code: "NOT_VIDEO_AUTHOR"},
+	{Err: ErrRestoreWindowExpired, Status: http.StatusBadRequest, Code: "RESTORE_WINDOW_EXPIRED"},
+	{Err: ErrUploadQuotaExceeded, Status: http.StatusTooManyRequests, Code: "UPLOAD_QUOTA_EXCEEDED"},
+	{Err: ErrTooManyDetailIDs, Status: http.StatusBadRequest, Code: "TOO_MANY_IDS"},
+	{Err: ErrInvalidOrder, Status: http.StatusBadRequest, Code: "INVALID_ORDER"},
+}
@@ -0,0 +1,136 @@
+package video
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"feedsystem_video_go/internal/config"
+)
+
+// VideoPurger 已删除视频清理任务
+// 职责：定期扫描超过保留期的软删除视频，删除磁盘上的视频/封面文件并物理删除记录
+// 注意：文件保存在 API 进程的上传目录中，因此该任务运行在 API 进程里
+type VideoPurger struct {
+	repo         *VideoRepository // 视频仓储层
+	uploadRoot   string           // 上传文件根目录
+	staticPrefix string           // 上传文件的访问路径前缀（如 /static）
+	retention    time.Duration    // 保留期
+	interval     time.Duration    // 扫描间隔
+	batchSize    int              // 每批处理数量
+}
+
+// NewVideoPurger 创建清理任务实例
+// 参数：
+//   - repo: 视频仓储层
+//   - storage: 上传文件存储配置（上传目录、访问路径前缀）
+//   - interval: 扫描间隔
+func NewVideoPurger(repo *VideoRepository, storage config.StorageConfig, interval time.Duration) *VideoPurger {
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	return &VideoPurger{
+		repo:         repo,
+		uploadRoot:   storage.UploadRoot(),
+		staticPrefix: storage.URLPrefix(),
+		retention:    VideoRetention,
+		interval:     interval,
+		batchSize:    100,
+	}
+}
+
+// Run 启动清理任务（阻塞，直到 ctx 被取消）
+func (p *VideoPurger) Run(ctx context.Context) error {
+	if p == nil || p.repo == nil {
+		return errors.New("video purger is not initialized")
+	}
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		if n, err := p.PurgeOnce(ctx); err != nil {
+			log.Printf("video purger: purge failed: %v", err)
+		} else if n > 0 {
+			log.Printf("video purger: purged %d videos", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// PurgeOnce 执行一轮清理
+// 按 ID 游标遍历：文件删除失败的视频保持软删除、留到下一轮重试，本轮不会反复取到同一批
+// 返回：
+//   - int: 物理删除的视频数量
+//   - error: 错误信息
+func (p *VideoPurger) PurgeOnce(ctx context.Context) (int, error) {
+	cutoff := time.Now().Add(-p.retention)
+	purged := 0
+	var lastID uint
+	for {
+		videos, err := p.repo.ListDeletedBefore(ctx, cutoff, lastID, p.batchSize)
+		if err != nil {
+			return purged, err
+		}
+		for _, v := range videos {
+			lastID = v.ID
+			// 先删文件再删记录：文件删除失败时记录仍在，下一轮可以重试
+			if err := p.removeFile(v.PlayURL); err != nil {
+				log.Printf("video purger: failed to remove play file of video %d: %v", v.ID, err)
+				continue
+			}
+			if err := p.removeFile(v.CoverURL); err != nil {
+				log.Printf("video purger: failed to remove cover file of video %d: %v", v.ID, err)
+				continue
+			}
+			if err := p.repo.PurgeVideo(ctx, v.ID); err != nil {
+				return purged, err
+			}
+			purged++
+		}
+		if len(videos) < p.batchSize {
+			return purged, nil
+		}
+	}
+}
+
+// removeFile 删除 URL 对应的本地上传文件
+// 非本站上传的文件（无法映射到上传目录）直接忽略
+func (p *VideoPurger) removeFile(rawURL string) error {
+	local, ok := localUploadPath(p.uploadRoot, p.staticPrefix, rawURL)
+	if !ok {
+		return nil
+	}
+	if err := os.Remove(local); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// localUploadPath 将 {访问路径前缀}/... 形式的 URL 映射为上传目录下的本地路径
+// 例如：http://host/static/videos/1/20240101/a.mp4 → {root}/videos/1/20240101/a.mp4
+func localUploadPath(root, staticPrefix, rawURL string) (string, bool) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return "", false
+	}
+	rel, ok := strings.CutPrefix(u.Path, staticPrefix+"/")
+	if !ok || rel == "" {
+		return "", false
+	}
+	// 防止 ../ 逃逸出上传目录
+	clean := filepath.Clean(filepath.FromSlash(rel))
+	if clean == "." || strings.HasPrefix(clean, "..") || filepath.IsAbs(clean) {
+		return "", false
+	}
+	return filepath.Join(root, clean), true
+}
@@ -3,10 +3,16 @@ package video
 import (
 	"context"
 	"errors"
+	"sort"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
 )
 
+// popularityBatchSize ChangePopularityBatch 每条 UPDATE 最多包含的视频数
+const popularityBatchSize = 500
+
 // VideoRepository 视频仓储层，负责视频数据库操作
 type VideoRepository struct {
 	db *gorm.DB // GORM数据库实例
@@ -28,7 +34,8 @@ func (vr *VideoRepository) CreateVideo(ctx context.Context, video *Video) error
 	return nil
 }
 
-// DeleteVideo 删除视频记录
+// DeleteVideo 删除视频记录（软删除）
+// Video 含 DeletedAt 字段，GORM 只会设置 deleted_at，之后的普通查询自动排除该记录
 // 参数：
 //   - ctx: 上下文
 //   - id: 视频ID
@@ -39,19 +46,129 @@ func (vr *VideoRepository) DeleteVideo(ctx context.Context, id uint) error {
 	return nil
 }
 
+// GetDeletedByID 查询已被软删除的视频
+// 参数：
+//   - ctx: 上下文
+//   - id: 视频ID
+// 返回：
+//   - *Video: 视频对象（未删除或不存在时返回 gorm.ErrRecordNotFound）
+//   - error: 错误信息
+func (vr *VideoRepository) GetDeletedByID(ctx context.Context, id uint) (*Video, error) {
+	var video Video
+	if err := vr.db.WithContext(ctx).Unscoped().
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		First(&video).Error; err != nil {
+		return nil, err
+	}
+	return &video, nil
+}
+
+// Restore 恢复已被软删除的视频（清空 deleted_at）
+// 参数：
+//   - ctx: 上下文
+//   - id: 视频ID
+func (vr *VideoRepository) Restore(ctx context.Context, id uint) error {
+	result := vr.db.WithContext(ctx).Unscoped().Model(&Video{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ListDeletedBefore 查询在指定时间之前被软删除的视频（供清理任务使用）
+// 按 ID 升序游标分页：本批处理失败、仍保持软删除的视频不会在同一轮中被重复返回
+// 参数：
+//   - ctx: 上下文
+//   - before: 删除时间上限
+//   - afterID: 游标，只返回 ID 大于该值的视频（第一批传 0）
+//   - limit: 每批数量
+// 返回：
+//   - []Video: 视频列表
+//   - error: 错误信息
+func (vr *VideoRepository) ListDeletedBefore(ctx context.Context, before time.Time, afterID uint, limit int) ([]Video, error) {
+	var videos []Video
+	if err := vr.db.WithContext(ctx).Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ? AND id > ?", before, afterID).
+		Order("id asc").
+		Limit(limit).
+		Find(&videos).Error; err != nil {
+		return nil, err
+	}
+	return videos, nil
+}
+
+// ListMediaURLsByAuthor 查询作者所有视频（包括保留期内的已删除视频）的播放地址和封面地址（供上传文件清理任务使用）
+// 参数：
+//   - ctx: 上下文
+//   - authorID: 作者ID
+// 返回：
+//   - []string: 播放地址和封面地址
+//   - error: 错误信息
+func (vr *VideoRepository) ListMediaURLsByAuthor(ctx context.Context, authorID uint) ([]string, error) {
+	var videos []Video
+	if err := vr.db.WithContext(ctx).Unscoped().
+		Select("play_url", "cover_url").
+		Where("author_id = ?", authorID).
+		Find(&videos).Error; err != nil {
+		return nil, err
+	}
+	urls := make([]string, 0, 2*len(videos))
+	for _, v := range videos {
+		urls = append(urls, v.PlayURL, v.CoverURL)
+	}
+	return urls, nil
+}
+
+// IsMediaReferenced 判断是否有视频（包括保留期内的已删除视频）引用了该路径的文件
+// 按地址后缀匹配（地址中的域名可能不同），无法使用索引，只用于确认少量候选文件
+// 路径中的 _ 等通配符不转义：只会多匹配，结果偏向"已引用"（不删除），是安全的一侧
+// 参数：
+//   - ctx: 上下文
+//   - urlPath: 文件的访问路径（如 /static/videos/1/20240101/a.mp4）
+func (vr *VideoRepository) IsMediaReferenced(ctx context.Context, urlPath string) (bool, error) {
+	var count int64
+	pattern := "%" + urlPath
+	if err := vr.db.WithContext(ctx).Unscoped().Model(&Video{}).
+		Where("play_url LIKE ? OR cover_url LIKE ?", pattern, pattern).
+		Limit(1).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// PurgeVideo 物理删除视频记录（仅清理任务使用）
+// 参数：
+//   - ctx: 上下文
+//   - id: 视频ID
+func (vr *VideoRepository) PurgeVideo(ctx context.Context, id uint) error {
+	return vr.db.WithContext(ctx).Unscoped().Delete(&Video{}, id).Error
+}
+
 // ListByAuthorID 查询指定作者的视频列表
-// 返回按创建时间倒序排列的视频列表
+// 返回按创建时间排序的视频列表（创建时间相同时按ID排序，保证顺序稳定）
 // 参数：
 //   - ctx: 上下文
 //   - authorID: 作者ID
+//   - includeHidden: 是否包含非公开（private/draft）视频，仅作者本人查看时为 true
+//   - ascending: true 时按创建时间正序（最早的在前），否则倒序
 // 返回：
 //   - []Video: 视频列表
 //   - error: 错误信息
-func (vr *VideoRepository) ListByAuthorID(ctx context.Context, authorID int64) ([]Video, error) {
+func (vr *VideoRepository) ListByAuthorID(ctx context.Context, authorID int64, includeHidden bool, ascending bool) ([]Video, error) {
 	var videos []Video
-	if err := vr.db.WithContext(ctx).
-		Where("author_id = ?", authorID).
-		Order("create_time desc").
+	query := vr.db.WithContext(ctx).
+		Where("author_id = ?", authorID)
+	if !includeHidden {
+		query = query.Where("visibility = ?", VisibilityPublic)
+	}
+	if err := query.
+		Order(authorListOrder(ascending)).
 		Offset(0).
 		Find(&videos).Error; err != nil {
 		return nil, err
@@ -59,6 +176,67 @@ func (vr *VideoRepository) ListByAuthorID(ctx context.Context, authorID int64) (
 	return videos, nil
 }
 
+// CountByAuthor 统计作者的公开视频数
+// 参数：
+//   - ctx: 上下文
+//   - authorID: 作者ID
+// 返回：
+//   - int64: 公开视频数
+//   - error: 错误信息
+func (vr *VideoRepository) CountByAuthor(ctx context.Context, authorID uint) (int64, error) {
+	var n int64
+	err := vr.db.WithContext(ctx).Model(&Video{}).
+		Where("author_id = ? AND visibility = ?", authorID, VisibilityPublic).
+		Count(&n).Error
+	return n, err
+}
+
+// SumLikesByAuthor 统计作者所有公开视频收到的点赞数之和
+// SQL 等价查询：SELECT COALESCE(SUM(likes_count), 0) FROM videos WHERE author_id = ? AND visibility = 'public'
+// 参数：
+//   - ctx: 上下文
+//   - authorID: 作者ID
+// 返回：
+//   - int64: 点赞总数（没有视频时为 0）
+//   - error: 错误信息
+func (vr *VideoRepository) SumLikesByAuthor(ctx context.Context, authorID uint) (int64, error) {
+	var total int64
+	err := vr.db.WithContext(ctx).Model(&Video{}).
+		Select("COALESCE(SUM(likes_count), 0)").
+		Where("author_id = ? AND visibility = ?", authorID, VisibilityPublic).
+		Scan(&total).Error
+	return total, err
+}
+
+// authorListOrder 作者视频列表的排序子句
+func authorListOrder(ascending bool) string {
+	if ascending {
+		return "create_time asc, id asc"
+	}
+	return "create_time desc, id desc"
+}
+
+// ListFollowerIDs 查询作者的粉丝ID（最多 limit 个，用于定向失效粉丝的关注流缓存）
+// 直接查询关注关系表（social 包依赖 video 包，这里不能引用 social.Social）
+// 参数：
+//   - ctx: 上下文
+//   - authorID: 作者ID
+//   - limit: 最多返回的数量
+// 返回：
+//   - []uint: 粉丝ID列表
+//   - error: 错误信息
+func (vr *VideoRepository) ListFollowerIDs(ctx context.Context, authorID uint, limit int) ([]uint, error) {
+	var ids []uint
+	if err := vr.db.WithContext(ctx).
+		Table("socials").
+		Where("vlogger_id = ?", authorID).
+		Limit(limit).
+		Pluck("follower_id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
 // GetByID 根据ID查询视频详情
 // 参数：
 //   - ctx: 上下文
@@ -74,6 +252,79 @@ func (vr *VideoRepository) GetByID(ctx context.Context, id uint) (*Video, error)
 	return &video, nil
 }
 
+// GetByIDs 根据视频ID列表批量查询视频（不过滤可见性，返回顺序不保证）
+// 参数：
+//   - ctx: 上下文
+//   - ids: 视频ID列表
+// 返回：
+//   - []*Video: 视频列表（不存在的ID不返回）
+//   - error: 错误信息
+func (vr *VideoRepository) GetByIDs(ctx context.Context, ids []uint) ([]*Video, error) {
+	var videos []*Video
+	if len(ids) == 0 {
+		return videos, nil
+	}
+	if err := vr.db.WithContext(ctx).Where("id IN ?", ids).Find(&videos).Error; err != nil {
+		return nil, err
+	}
+	return videos, nil
+}
+
+// SetVisibility 修改视频可见性（同时清空定时发布时间）
+// 参数：
+//   - ctx: 上下文
+//   - id: 视频ID
+//   - visibility: 新的可见性
+func (vr *VideoRepository) SetVisibility(ctx context.Context, id uint, visibility string) error {
+	return vr.db.WithContext(ctx).Model(&Video{}).
+		Where("id = ?", id).
+		Updates(map[string]any{"visibility": visibility, "publish_at": nil}).Error
+}
+
+// ListDueScheduled 查询发布时间已到的定时发布视频ID（供定时发布任务使用）
+// 索引：idx_video_visibility_publish_at (visibility, publish_at)，只扫描到期的 scheduled 视频
+// 参数：
+//   - ctx: 上下文
+//   - now: 当前时间
+//   - limit: 每批数量
+// 返回：
+//   - []uint: 视频ID列表（按发布时间升序）
+//   - error: 错误信息
+func (vr *VideoRepository) ListDueScheduled(ctx context.Context, now time.Time, limit int) ([]uint, error) {
+	var ids []uint
+	if err := vr.db.WithContext(ctx).Model(&Video{}).
+		Where("visibility = ? AND publish_at <= ?", VisibilityScheduled, now).
+		Order("publish_at asc").
+		Limit(limit).
+		Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// PublishScheduled 将到期的定时发布视频改为公开
+// create_time 更新为计划发布时间，使视频按发布时间出现在最新视频流中
+// 条件更新（仍为 scheduled 且已到期）保证多个实例同时执行时不会重复处理，也不会覆盖作者在此期间的手动修改
+// 参数：
+//   - ctx: 上下文
+//   - id: 视频ID
+//   - now: 当前时间
+// 返回：
+//   - bool: 是否实际更新
+//   - error: 错误信息
+func (vr *VideoRepository) PublishScheduled(ctx context.Context, id uint, now time.Time) (bool, error) {
+	result := vr.db.WithContext(ctx).Model(&Video{}).
+		Where("id = ? AND visibility = ? AND publish_at <= ?", id, VisibilityScheduled, now).
+		Updates(map[string]any{
+			"visibility":  VisibilityPublic,
+			"create_time": gorm.Expr("publish_at"),
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
 // UpdateLikesCount 更新视频点赞数（直接设置为指定值）
 // 参数：
 //   - ctx: 上下文
@@ -88,7 +339,8 @@ func (vr *VideoRepository) UpdateLikesCount(ctx context.Context, id uint, likesC
 	return nil
 }
 
-// IsExist 检查视频是否存在
+// IsExist 检查视频是否存在（已软删除的视频视为不存在）
+// 只查询 SELECT 1 ... LIMIT 1，不读取整行
 // 参数：
 //   - ctx: 上下文
 //   - id: 视频ID
@@ -96,14 +348,72 @@ func (vr *VideoRepository) UpdateLikesCount(ctx context.Context, id uint, likesC
 //   - bool: 是否存在
 //   - error: 错误信息
 func (vr *VideoRepository) IsExist(ctx context.Context, id uint) (bool, error) {
-	var video Video
-	if err := vr.db.WithContext(ctx).First(&video, id).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return false, nil
-		}
+	var found []int
+	if err := vr.db.WithContext(ctx).
+		Model(&Video{}).
+		Select("1").
+		Where("id = ?", id).
+		Limit(1).
+		Find(&found).Error; err != nil {
 		return false, err
 	}
-	return true, nil
+	return len(found) > 0, nil
+}
+
+// VideoAccess 视频的作者和可见性（校验当前用户能否访问视频时使用）
+type VideoAccess struct {
+	AuthorID   uint   // 作者ID
+	Visibility string // 可见性（见 Visibility* 常量）
+}
+
+// GetAccess 查询视频的作者和可见性（已软删除的视频视为不存在）
+// 只查询 author_id、visibility 两列，不读取整行
+// 参数：
+//   - ctx: 上下文
+//   - id: 视频ID
+// 返回：
+//   - *VideoAccess: 作者和可见性（视频不存在时为 nil）
+//   - error: 错误信息
+func (vr *VideoRepository) GetAccess(ctx context.Context, id uint) (*VideoAccess, error) {
+	var found []VideoAccess
+	if err := vr.db.WithContext(ctx).
+		Model(&Video{}).
+		Select("author_id", "visibility").
+		Where("id = ?", id).
+		Limit(1).
+		Find(&found).Error; err != nil {
+		return nil, err
+	}
+	if len(found) == 0 {
+		return nil, nil
+	}
+	return &found[0], nil
+}
+
+// BatchExists 批量检查视频是否存在（已软删除的视频视为不存在）
+// 一次 IN 查询代替逐条 IsExist，用于 Worker 批量处理消息
+// 参数：
+//   - ctx: 上下文
+//   - ids: 视频ID列表（可以重复）
+// 返回：
+//   - map[uint]bool: 存在的视频ID（不存在的ID不在 map 中，查询结果为 false）
+//   - error: 错误信息
+func (vr *VideoRepository) BatchExists(ctx context.Context, ids []uint) (map[uint]bool, error) {
+	exists := make(map[uint]bool, len(ids))
+	if len(ids) == 0 {
+		return exists, nil
+	}
+	var found []uint
+	if err := vr.db.WithContext(ctx).
+		Model(&Video{}).
+		Where("id IN ?", ids).
+		Pluck("id", &found).Error; err != nil {
+		return nil, err
+	}
+	for _, id := range found {
+		exists[id] = true
+	}
+	return exists, nil
 }
 
 // UpdatePopularity 更新视频热度（增量更新）
@@ -123,6 +433,7 @@ func (vr *VideoRepository) UpdatePopularity(ctx context.Context, id uint, change
 
 // ChangeLikesCount 增量更新点赞数（确保不小于0）
 // 使用SQL表达式：likes_count = GREATEST(likes_count + change, 0)
+// UpdateColumn 不会自动维护 updated_at，这里显式更新
 // 参数：
 //   - ctx: 上下文
 //   - id: 视频ID
@@ -130,7 +441,10 @@ func (vr *VideoRepository) UpdatePopularity(ctx context.Context, id uint, change
 func (vr *VideoRepository) ChangeLikesCount(ctx context.Context, id uint, change int64) error {
 	if err := vr.db.WithContext(ctx).Model(&Video{}).
 		Where("id = ?", id).
-		UpdateColumn("likes_count", gorm.Expr("GREATEST(likes_count + ?, 0)", change)).Error; err != nil {
+		UpdateColumns(map[string]any{
+			"likes_count": gorm.Expr("GREATEST(likes_count + ?, 0)", change),
+			"updated_at":  time.Now(),
+		}).Error; err != nil {
 		return err
 	}
 	return nil
@@ -138,6 +452,7 @@ func (vr *VideoRepository) ChangeLikesCount(ctx context.Context, id uint, change
 
 // ChangePopularity 增量更新热度（确保不小于0）
 // 使用SQL表达式：popularity = GREATEST(popularity + change, 0)
+// UpdateColumn 不会自动维护 updated_at，这里显式更新
 // 参数：
 //   - ctx: 上下文
 //   - id: 视频ID
@@ -145,14 +460,68 @@ func (vr *VideoRepository) ChangeLikesCount(ctx context.Context, id uint, change
 func (vr *VideoRepository) ChangePopularity(ctx context.Context, id uint, change int64) error {
 	if err := vr.db.WithContext(ctx).Model(&Video{}).
 		Where("id = ?", id).
-		UpdateColumn("popularity", gorm.Expr("GREATEST(popularity + ?, 0)", change)).Error; err != nil {
+		UpdateColumns(map[string]any{
+			"popularity": gorm.Expr("GREATEST(popularity + ?, 0)", change),
+			"updated_at": time.Now(),
+		}).Error; err != nil {
 		return err
 	}
 	return nil
 }
 
-// GetLatestByAuthorID 查询指定作者的最新视频
-// 返回按创建时间倒序的第一条视频
+// ChangePopularityBatch 批量增量更新热度（确保不小于0）
+// 每批最多 popularityBatchSize 个视频，合并为一条 UPDATE：
+// popularity = GREATEST(popularity + CASE id WHEN ? THEN ? ... END, 0) WHERE id IN (...)
+// 视频ID按升序排列，多个 Worker 同时更新同一批热点视频时按相同顺序加锁，避免死锁
+// 参数：
+//   - ctx: 上下文
+//   - changes: 视频ID -> 热度变化量（变化量为 0 的视频会被跳过）
+func (vr *VideoRepository) ChangePopularityBatch(ctx context.Context, changes map[uint]int64) error {
+	ids := make([]uint, 0, len(changes))
+	for id, change := range changes {
+		if id != 0 && change != 0 {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	if len(ids) == 0 {
+		return nil
+	}
+
+	// 多批在同一事务中执行：中途失败时全部回滚，调用方可以安全地整体重试
+	now := time.Now()
+	return vr.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for start := 0; start < len(ids); start += popularityBatchSize {
+			end := start + popularityBatchSize
+			if end > len(ids) {
+				end = len(ids)
+			}
+			batch := ids[start:end]
+
+			var expr strings.Builder
+			args := make([]interface{}, 0, len(batch)*2)
+			expr.WriteString("GREATEST(popularity + CASE id")
+			for _, id := range batch {
+				expr.WriteString(" WHEN ? THEN ?")
+				args = append(args, id, changes[id])
+			}
+			expr.WriteString(" ELSE 0 END, 0)")
+
+			if err := tx.Model(&Video{}).
+				Where("id IN ?", batch).
+				UpdateColumns(map[string]any{
+					"popularity": gorm.Expr(expr.String(), args...),
+					"updated_at": now,
+				}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetLatestByAuthorID 查询指定作者的最新公开视频
+// 返回按创建时间倒序的第一条公开视频（非公开视频不进入热榜，不需要加热度）
 // 参数：
 //   - ctx: 上下文
 //   - authorID: 作者ID
@@ -162,7 +531,7 @@ func (vr *VideoRepository) ChangePopularity(ctx context.Context, id uint, change
 func (vr *VideoRepository) GetLatestByAuthorID(ctx context.Context,authorID uint)(*Video,error){
 	var video Video
 	err:=vr.db.WithContext(ctx).
-			 Where("author_id = ?",authorID).
+			 Where("author_id = ? AND visibility = ?",authorID,VisibilityPublic).
 			 Order("create_time desc").
 			 First(&video).Error
 	if err!=nil{
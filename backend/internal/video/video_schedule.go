@@ -0,0 +1,100 @@
+package video
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	rediscache "feedsystem_video_go/internal/middleware/redis"
+)
+
+// VideoScheduler 定时发布任务
+// 职责：定期扫描 publish_at 已到期的 scheduled 视频，改为 public，清除详情缓存并广播 Feed 缓存失效
+type VideoScheduler struct {
+	repo      *VideoRepository   // 视频仓储层
+	cache     *rediscache.Client // Redis 缓存客户端（可能为 nil）
+	interval  time.Duration      // 扫描间隔
+	batchSize int                // 每批处理数量
+}
+
+// NewVideoScheduler 创建定时发布任务实例
+// 参数：
+//   - repo: 视频仓储层
+//   - cache: Redis 缓存客户端（可能为 nil）
+//   - interval: 扫描间隔（决定定时发布的最大延迟）
+func NewVideoScheduler(repo *VideoRepository, cache *rediscache.Client, interval time.Duration) *VideoScheduler {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	return &VideoScheduler{
+		repo:      repo,
+		cache:     cache,
+		interval:  interval,
+		batchSize: 100,
+	}
+}
+
+// Run 启动定时发布任务（阻塞，直到 ctx 被取消）
+func (s *VideoScheduler) Run(ctx context.Context) error {
+	if s == nil || s.repo == nil {
+		return errors.New("video scheduler is not initialized")
+	}
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if n, err := s.PublishDueOnce(ctx); err != nil {
+			log.Printf("video scheduler: publish failed: %v", err)
+		} else if n > 0 {
+			log.Printf("video scheduler: published %d scheduled videos", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// PublishDueOnce 执行一轮定时发布
+// 按批查询到期视频，逐条条件更新，直到没有到期视频为止
+// 返回：
+//   - int: 实际公开的视频数量
+//   - error: 错误信息
+func (s *VideoScheduler) PublishDueOnce(ctx context.Context) (int, error) {
+	now := time.Now()
+	published := 0
+	// 新公开的视频进入 Feed 流，本轮结束后（含中途出错）广播一次 Feed 缓存失效
+	defer func() {
+		if published > 0 {
+			invalidateFeeds(ctx, s.cache, "scheduled videos published")
+		}
+	}()
+	for {
+		ids, err := s.repo.ListDueScheduled(ctx, now, s.batchSize)
+		if err != nil {
+			return published, err
+		}
+		for _, id := range ids {
+			ok, err := s.repo.PublishScheduled(ctx, id, now)
+			if err != nil {
+				return published, err
+			}
+			if !ok {
+				// 已被其他实例处理或作者手动修改了可见性
+				continue
+			}
+			published++
+			if s.cache != nil {
+				opCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+				_ = s.cache.Del(opCtx, DetailCacheKey(id))
+				cancel()
+			}
+		}
+		if len(ids) < s.batchSize {
+			return published, nil
+		}
+	}
+}
@@ -5,13 +5,84 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log"
 	"math/rand"
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
+	"feedsystem_video_go/internal/config"
 	"feedsystem_video_go/internal/middleware/rabbitmq"
+	"feedsystem_video_go/internal/invalidation"
+	"feedsystem_video_go/internal/metrics"
 	rediscache "feedsystem_video_go/internal/middleware/redis"
+
+	"gorm.io/gorm"
+)
+
+// VideoRetention 已删除视频的保留期
+// 保留期内管理员可以恢复视频；超过保留期后由清理任务物理删除记录和文件
+const VideoRetention = 7 * 24 * time.Hour
+
+// 发布幂等：同一账户在 publishIdempotencyTTL 内使用相同幂等键重复发布，返回首次创建的视频
+const (
+	publishIdempotencyTTL    = 10 * time.Minute
+	maxIdempotencyKeyLength  = 128
+	publishIdempotencyMarker = "pending" // 首次请求尚未完成时的占位值
+)
+
+// maxDetailBatch 批量获取视频详情的单次最大视频数量
+const maxDetailBatch = 50
+
+// 视频详情负缓存：不存在的ID缓存一个短TTL的占位值，避免热点无效ID反复打到数据库
+const (
+	detailNotFoundTTL    = 30 * time.Second
+	detailNotFoundMarker = "-" // 不是合法 JSON，不会与正常缓存的视频详情混淆
+)
+
+// DetailCacheKey 视频详情缓存键：video:detail:id={视频ID}
+// 缓存中包含非公开视频，读取方需要自行校验可见性
+func DetailCacheKey(id uint) string {
+	return fmt.Sprintf("video:detail:id=%d", id)
+}
+
+// DecodeDetailCache 解析视频详情缓存值，命中负缓存时返回 ErrVideoNotFound
+func DecodeDetailCache(b []byte) (*Video, error) {
+	if string(b) == detailNotFoundMarker {
+		return nil, ErrVideoNotFound
+	}
+	var cached Video
+	if err := json.Unmarshal(b, &cached); err != nil {
+		return nil, err
+	}
+	return &cached, nil
+}
+
+// 标题/描述长度默认上限（按 rune 计算，数据库列为 varchar(255)）
+const (
+	defaultMaxTitleLength       = 100
+	defaultMaxDescriptionLength = 255
+)
+
+var (
+	ErrVideoInvalid          = errors.New("video is nil")                                // 视频对象为空
+	ErrTitleRequired         = errors.New("title is required")                           // 标题不能为空
+	ErrPlayURLRequired       = errors.New("play url is required")                        // 播放地址不能为空
+	ErrCoverURLRequired      = errors.New("cover url is required")                       // 封面地址不能为空
+	ErrVideoNotFound         = errors.New("video not found")                             // 视频不存在
+	ErrNotVideoAuthor        = errors.New("unauthorized")                                // 不是视频作者
+	ErrDeletedVideoNotFound  = errors.New("deleted video not found")                     // 已删除的视频不存在
+	ErrRestoreWindowExpired  = errors.New("video is past the restore window")            // 超过恢复保留期
+	ErrTitleTooLong          = errors.New("title is too long")                           // 标题过长
+	ErrDescriptionTooLong    = errors.New("description is too long")                     // 描述过长
+	ErrIdempotencyKeyTooLong = errors.New("idempotency_key is too long")                 // 幂等键过长
+	ErrPublishInProgress     = errors.New("publish with this key is in progress")        // 相同幂等键的请求正在处理
+	ErrInvalidVisibility     = errors.New("visibility must be public, private or draft") // 可见性取值不合法
+	ErrPublishAtInPast       = errors.New("publish_at must be in the future")            // 定时发布时间不晚于当前时间
+	ErrVideoForbidden        = errors.New("video is not visible")                        // 非公开视频，只有作者可以查看
+	ErrTooManyDetailIDs      = fmt.Errorf("at most %d ids per request", maxDetailBatch)  // 批量获取详情的视频ID数量超过上限
+	ErrInvalidOrder          = errors.New("order must be asc or desc")                   // 排序方向取值不合法
 )
 
 // VideoService 视频服务层，处理视频业务逻辑
@@ -20,58 +91,192 @@ type VideoService struct {
 	repo         *VideoRepository              // 视频仓储层，负责数据库操作
 	cache        *rediscache.Client            // Redis缓存客户端
 	cacheTTL     time.Duration                 // 缓存过期时间（5分钟）
-	popularityMQ *rabbitmq.PopularityMQ         // 热度消息队列，用于异步更新热度
+	popularityMQ *rabbitmq.PopularityBatcher    // 热度消息合并发送器，用于异步更新热度
+	maxTitleLen  int                            // 标题最大字符数
+	maxDescLen   int                            // 描述最大字符数
+	uploadQuota  uploadQuota                    // 每日上传配额
+	covers       coverNormalizer                // 封面规范化
+	mediaURLs    mediaURLPolicy                 // 播放地址/封面地址校验
+	uploadRoot   string                         // 上传文件根目录（storage.upload_dir）
+	staticPrefix string                         // 上传文件的访问路径前缀（storage.static_prefix）
 }
 
 // NewVideoService 创建视频服务实例
-func NewVideoService(repo *VideoRepository, cache *rediscache.Client, popularityMQ *rabbitmq.PopularityMQ) *VideoService {
+func NewVideoService(repo *VideoRepository, cache *rediscache.Client, popularityMQ *rabbitmq.PopularityBatcher, cfg config.VideoConfig, storage config.StorageConfig) *VideoService {
 	randomOffset := rand.Intn(120)  // 0-120 秒随机偏移
-	return &VideoService{
+	vs := &VideoService{
 		repo:        repo,
 		cache:       cache,
 		cacheTTL:    5*time.Minute + time.Duration(randomOffset)*time.Second,  // 5-7 分钟随机
 		popularityMQ: popularityMQ,
-}
+		maxTitleLen:  cfg.MaxTitleLength,
+		maxDescLen:   cfg.MaxDescriptionLength,
+		uploadRoot:   storage.UploadRoot(),
+		staticPrefix: storage.URLPrefix(),
+	}
+	if vs.maxTitleLen <= 0 {
+		vs.maxTitleLen = defaultMaxTitleLength
+	}
+	if vs.maxDescLen <= 0 {
+		vs.maxDescLen = defaultMaxDescriptionLength
+	}
+	vs.uploadQuota = newUploadQuota(cfg)
+	vs.covers = newCoverNormalizer(cfg)
+	vs.mediaURLs = newMediaURLPolicy(cfg, vs.staticPrefix)
+	return vs
 }
 
 // Publish 发布视频
 // 业务流程：
 // 1. 校验视频对象不为空
 // 2. 去除标题、播放URL、封面URL的首尾空格
-// 3. 校验必填字段（标题、播放URL、封面URL）
-// 4. 调用Repository层将视频存入数据库
+// 3. 校验必填字段（标题、播放URL、封面URL）、播放URL/封面URL是否为上传接口生成的地址、标题/描述长度和可见性（为空时默认 public，携带 publish_at 时为 scheduled）
+// 4. 幂等检查：携带幂等键时，重复请求直接返回首次创建的视频
+// 5. 调用Repository层将视频存入数据库
+// 6. 清除该ID可能残留的负缓存
 // 参数：
 //   - ctx: 上下文
 //   - video: 视频对象（包含作者ID、用户名、标题、描述、播放URL、封面URL）
-func (vs *VideoService) Publish(ctx context.Context, video *Video) error {
+//   - idempotencyKey: 幂等键（可选，为空时不做幂等处理）
+//   - requestHost: 发布请求的 Host（未配置 video.media_hosts 时用于校验播放URL/封面URL的域名）
+func (vs *VideoService) Publish(ctx context.Context, video *Video, idempotencyKey string, requestHost string) error {
 	// 1. 校验视频对象不为空
 	if video == nil {
-		return errors.New("video is nil")
+		return ErrVideoInvalid
 	}
 
 	// 2. 去除首尾空格
 	video.Title = strings.TrimSpace(video.Title)
 	video.PlayURL = strings.TrimSpace(video.PlayURL)
 	video.CoverURL = strings.TrimSpace(video.CoverURL)
+	idempotencyKey = strings.TrimSpace(idempotencyKey)
+	if video.Visibility == "" {
+		video.Visibility = VisibilityPublic
+		if video.PublishAt != nil {
+			video.Visibility = VisibilityScheduled
+		}
+	}
 
 	// 3. 校验必填字段
 	if video.Title == "" {
-		return errors.New("title is required")
+		return ErrTitleRequired
 	}
 	if video.PlayURL == "" {
-		return errors.New("play url is required")
+		return ErrPlayURLRequired
 	}
 	if video.CoverURL == "" {
-		return errors.New("cover url is required")
+		return ErrCoverURLRequired
+	}
+	if err := vs.mediaURLs.check(video, requestHost); err != nil {
+		return err
+	}
+	// 按字符（rune）而非字节计算长度，避免中文被误判
+	if n := utf8.RuneCountInString(video.Title); n > vs.maxTitleLen {
+		return fmt.Errorf("%w: %d characters, max %d", ErrTitleTooLong, n, vs.maxTitleLen)
+	}
+	if n := utf8.RuneCountInString(video.Description); n > vs.maxDescLen {
+		return fmt.Errorf("%w: %d characters, max %d", ErrDescriptionTooLong, n, vs.maxDescLen)
+	}
+	if len(idempotencyKey) > maxIdempotencyKeyLength {
+		return ErrIdempotencyKeyTooLong
+	}
+	if video.PublishAt != nil {
+		// 定时发布：只能与 scheduled 搭配，且发布时间必须晚于当前时间
+		if video.Visibility != VisibilityScheduled {
+			return ErrInvalidVisibility
+		}
+		if !video.PublishAt.After(time.Now()) {
+			return ErrPublishAtInPast
+		}
+	} else if !ValidVisibility(video.Visibility) {
+		return ErrInvalidVisibility
+	}
+
+	// 4. 幂等检查（Redis 不可用时跳过，退化为普通发布）
+	idemKey := ""
+	if idempotencyKey != "" && vs.cache != nil {
+		key := fmt.Sprintf("video:publish:idem:account=%d:key=%s", video.AuthorID, idempotencyKey)
+		existing, claimed, err := vs.claimPublishKey(ctx, key)
+		if errors.Is(err, ErrPublishInProgress) {
+			return err
+		}
+		if err != nil {
+			log.Printf("video publish: idempotency check skipped: %v", err)
+		} else if existing != nil {
+			*video = *existing
+			return nil
+		} else if claimed {
+			idemKey = key
+		}
 	}
 
-	// 4. 调用Repository层将视频存入数据库
+	// 5. 调用Repository层将视频存入数据库
 	if err := vs.repo.CreateVideo(ctx, video); err != nil {
+		// 创建失败时释放幂等键，允许客户端重试
+		if idemKey != "" {
+			_ = vs.cache.Del(context.Background(), idemKey)
+		}
 		return err
 	}
+
+	// 6. 清除该ID可能残留的负缓存（自增ID一般不会复用，防御性处理）
+	if vs.cache != nil {
+		_ = vs.cache.Del(context.Background(), DetailCacheKey(video.ID))
+	}
+	// 公开视频会出现在 Feed 流中，广播 Feed 缓存失效
+	if video.Visibility == VisibilityPublic {
+		vs.invalidateAuthorFeeds(ctx, video.AuthorID, "video published")
+	}
+
+	// 7. 记录幂等键对应的视频ID
+	if idemKey != "" {
+		opCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+		_ = vs.cache.SetBytes(opCtx, idemKey, []byte(strconv.FormatUint(uint64(video.ID), 10)), publishIdempotencyTTL)
+	}
 	return nil
 }
 
+// claimPublishKey 抢占发布幂等键
+// 返回：
+//   - *Video: 幂等键已对应一个已创建的视频时返回该视频
+//   - bool: 是否成功抢占（首次请求）
+//   - error: ErrPublishInProgress 表示首次请求仍在处理；其他错误时调用方应退化为普通发布
+func (vs *VideoService) claimPublishKey(ctx context.Context, key string) (*Video, bool, error) {
+	opCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+
+	ok, err := vs.cache.SetNX(opCtx, key, []byte(publishIdempotencyMarker), publishIdempotencyTTL)
+	if err != nil {
+		return nil, false, err
+	}
+	if ok {
+		return nil, true, nil
+	}
+
+	// 幂等键已存在：读取首次请求创建的视频ID
+	b, err := vs.cache.GetBytes(opCtx, key)
+	if err != nil {
+		if rediscache.IsMiss(err) {
+			// 键刚好过期或被释放，按首次请求处理
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	if string(b) == publishIdempotencyMarker {
+		return nil, false, ErrPublishInProgress
+	}
+	id, err := strconv.ParseUint(string(b), 10, 64)
+	if err != nil {
+		return nil, false, err
+	}
+	existing, err := vs.repo.GetByID(ctx, uint(id))
+	if err != nil {
+		return nil, false, err
+	}
+	return existing, false, nil
+}
+
 // Delete 删除视频
 // 业务流程：
 // 1. 查询视频是否存在
@@ -83,18 +288,41 @@ func (vs *VideoService) Publish(ctx context.Context, video *Video) error {
 //   - id: 视频ID
 //   - authorID: 操作者的账户ID
 func (vs *VideoService) Delete(ctx context.Context, id uint, authorID uint) error {
+	return vs.delete(ctx, id, authorID, false)
+}
+
+// AdminDelete 管理员删除视频（内容审核）
+// 与 Delete 走同一条删除路径，但跳过作者校验，并记录操作者
+// 参数：
+//   - ctx: 上下文
+//   - id: 视频ID
+//   - operatorID: 执行操作的管理员账户ID
+func (vs *VideoService) AdminDelete(ctx context.Context, id uint, operatorID uint) error {
+	if err := vs.delete(ctx, id, operatorID, true); err != nil {
+		return err
+	}
+	log.Printf("moderation: admin %d deleted video %d", operatorID, id)
+	return nil
+}
+
+// delete 删除视频的公共实现
+// skipOwnerCheck 为 true 时不校验操作者是否为作者（仅管理员使用）
+func (vs *VideoService) delete(ctx context.Context, id uint, operatorID uint, skipOwnerCheck bool) error {
 	// 1. 查询视频是否存在
 	video, err := vs.repo.GetByID(ctx, id)
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrVideoNotFound
+		}
 		return err
 	}
 	if video == nil {
-		return errors.New("video not found")
+		return ErrVideoNotFound
 	}
 
 	// 2. 校验操作者是否为视频作者
-	if video.AuthorID != authorID {
-		return errors.New("unauthorized")
+	if !skipOwnerCheck && video.AuthorID != operatorID {
+		return ErrNotVideoAuthor
 	}
 
 	// 3. 调用Repository层删除视频
@@ -104,143 +332,383 @@ func (vs *VideoService) Delete(ctx context.Context, id uint, authorID uint) erro
 
 	// 4. 删除Redis缓存中的视频详情
 	if vs.cache != nil {
-		cacheKey := fmt.Sprintf("video:detail:id=%d", id)
+		cacheKey := DetailCacheKey(id)
 		_ = vs.cache.Del(context.Background(), cacheKey)
 	}
+	// 5. 公开视频从 Feed 流中移除，广播 Feed 缓存失效
+	if isPublic(video.Visibility) {
+		vs.invalidateAuthorFeeds(ctx, video.AuthorID, "video deleted")
+	}
+	return nil
+}
+
+// Restore 恢复已删除的视频（管理员操作）
+// 业务流程：
+// 1. 查询已被软删除的视频
+// 2. 校验是否仍在保留期内（超过保留期的视频可能已被清理任务处理）
+// 3. 清空 deleted_at，恢复视频
+// 4. 删除Redis缓存中的视频详情
+// 参数：
+//   - ctx: 上下文
+//   - id: 视频ID
+func (vs *VideoService) Restore(ctx context.Context, id uint) error {
+	// 1. 查询已被软删除的视频
+	video, err := vs.repo.GetDeletedByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrDeletedVideoNotFound
+		}
+		return err
+	}
+
+	// 2. 校验是否仍在保留期内
+	if !video.DeletedAt.Valid || time.Since(video.DeletedAt.Time) > VideoRetention {
+		return ErrRestoreWindowExpired
+	}
+
+	// 3. 恢复视频
+	if err := vs.repo.Restore(ctx, id); err != nil {
+		return err
+	}
+
+	// 4. 删除Redis缓存中的视频详情
+	if vs.cache != nil {
+		_ = vs.cache.Del(context.Background(), DetailCacheKey(id))
+	}
+	// 5. 公开视频重新出现在 Feed 流中，广播 Feed 缓存失效
+	if isPublic(video.Visibility) {
+		vs.invalidateAuthorFeeds(ctx, video.AuthorID, "video restored")
+	}
+	return nil
+}
+
+// SetVisibility 修改视频可见性（仅作者本人）
+// 对定时发布的视频手动设置可见性会取消定时发布
+// 业务流程：
+// 1. 校验可见性取值
+// 2. 查询视频是否存在，并校验操作者是否为视频作者
+// 3. 更新可见性，删除Redis缓存中的视频详情
+// 参数：
+//   - ctx: 上下文
+//   - id: 视频ID
+//   - authorID: 操作者的账户ID
+//   - visibility: 新的可见性
+func (vs *VideoService) SetVisibility(ctx context.Context, id uint, authorID uint, visibility string) error {
+	// 1. 校验可见性取值
+	if !ValidVisibility(visibility) {
+		return ErrInvalidVisibility
+	}
+
+	// 2. 查询视频并校验作者
+	video, err := vs.repo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrVideoNotFound
+		}
+		return err
+	}
+	if video.AuthorID != authorID {
+		return ErrNotVideoAuthor
+	}
+
+	// 3. 更新可见性并删除详情缓存
+	if err := vs.repo.SetVisibility(ctx, id, visibility); err != nil {
+		return err
+	}
+	if vs.cache != nil {
+		_ = vs.cache.Del(context.Background(), DetailCacheKey(id))
+	}
+	// 4. 视频进入或离开 Feed 流时，广播 Feed 缓存失效
+	if isPublic(video.Visibility) != isPublic(visibility) {
+		vs.invalidateAuthorFeeds(ctx, video.AuthorID, "video visibility changed")
+	}
 	return nil
 }
 
 // ListByAuthorID 查询作者的视频列表
 // 业务流程：
-// 1. 调用Repository层查询指定作者的所有视频
-// 2. 返回按创建时间倒序排列的视频列表
+// 1. 调用Repository层查询指定作者的视频（作者本人查看时包含 private/draft，其他人只能看到 public）
+// 2. 返回按创建时间排序的视频列表（默认倒序，order 为 asc 时正序）
 // 参数：
 //   - ctx: 上下文
 //   - authorID: 作者ID
+//   - viewerID: 当前查看者ID（0 表示未登录）
+//   - order: 排序方向 asc/desc（空字符串视为 desc），其他取值返回 ErrInvalidOrder
 // 返回：
-//   - []Video: 视频列表（按创建时间倒序）
+//   - []Video: 视频列表
 //   - error: 错误信息
-func (vs *VideoService) ListByAuthorID(ctx context.Context, authorID uint) ([]Video, error) {
-	// 调用Repository层查询指定作者的所有视频
-	videos, err := vs.repo.ListByAuthorID(ctx, int64(authorID))
+func (vs *VideoService) ListByAuthorID(ctx context.Context, authorID uint, viewerID uint, order string) ([]Video, error) {
+	switch order {
+	case "", OrderDesc, OrderAsc:
+	default:
+		return nil, ErrInvalidOrder
+	}
+
+	// 调用Repository层查询指定作者的视频
+	videos, err := vs.repo.ListByAuthorID(ctx, int64(authorID), viewerID != 0 && viewerID == authorID, order == OrderAsc)
 	if err != nil {
 		return nil, err
 	}
 	return videos, nil
 }
 
-// GetDetail 获取视频详情（含缓存逻辑）
+// GetDetail 获取视频详情
+// 非公开（private/draft）视频只有作者本人可以查看，其他人返回 ErrVideoForbidden
+// 参数：
+//   - ctx: 上下文
+//   - id: 视频ID
+//   - viewerID: 当前查看者ID（0 表示未登录）
+// 返回：
+//   - *Video: 视频详情
+//   - error: 错误信息
+func (vs *VideoService) GetDetail(ctx context.Context, id uint, viewerID uint) (*Video, error) {
+	video, err := vs.getDetail(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !isPublic(video.Visibility) && video.AuthorID != viewerID {
+		return nil, ErrVideoForbidden
+	}
+	return video, nil
+}
+
+// checkVideoAccess 校验当前用户能否访问视频（评论、点赞等互动接口使用，与 GetDetail 的可见性规则一致）
+// 视频不存在返回 ErrVideoNotFound；非公开（private/draft/scheduled）视频只有作者本人可以访问，其他人返回 ErrVideoForbidden
+// 参数：
+//   - ctx: 上下文
+//   - repo: 视频仓储层
+//   - videoID: 视频ID
+//   - viewerID: 当前用户ID（0 表示未登录）
+func checkVideoAccess(ctx context.Context, repo *VideoRepository, videoID, viewerID uint) error {
+	access, err := repo.GetAccess(ctx, videoID)
+	if err != nil {
+		return err
+	}
+	if access == nil {
+		return ErrVideoNotFound
+	}
+	if !isPublic(access.Visibility) && access.AuthorID != viewerID {
+		return ErrVideoForbidden
+	}
+	return nil
+}
+
+// getDetail 获取视频详情（含缓存逻辑，不做可见性校验）
 // 业务流程：
-// 1. 尝试从Redis缓存读取视频详情
-// 2. 如果缓存未命中，使用分布式锁防止缓存击穿
-// 3. 拿到锁的请求从数据库查询并回填缓存
-// 4. 没拿到锁的请求等待并重试读取缓存
-// 5. 如果缓存禁用，直接查询数据库
+// 1. 读取一次Redis缓存，命中直接返回
+// 2. 缓存未命中：获取分布式锁，拿到锁后再检查一次缓存（双重检查），仍未命中才查询数据库并回填
+// 3. 没拿到锁：等待持锁请求回填缓存，超时后降级查询数据库
+// 4. 缓存禁用或Redis出错（非未命中）：直接查询数据库，不再加锁
+// 5. 视频不存在时缓存30秒的负缓存占位值，返回 ErrVideoNotFound
 // 参数：
 //   - ctx: 上下文
 //   - id: 视频ID
 // 返回：
 //   - *Video: 视频详情
 //   - error: 错误信息
-func (vs *VideoService) GetDetail(ctx context.Context, id uint) (*Video, error) {
+func (vs *VideoService) getDetail(ctx context.Context, id uint) (*Video, error) {
+	// 缓存禁用：直接查询数据库
+	if vs.cache == nil {
+		video, err := vs.repo.GetByID(ctx, id)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrVideoNotFound
+		}
+		return video, err
+	}
+
 	// 缓存键格式：video:detail:id={视频ID}
-	cacheKey := fmt.Sprintf("video:detail:id=%d", id)
+	cacheKey := DetailCacheKey(id)
 
-	// 内部函数：从缓存获取视频
-	getCached := func() (*Video, bool) {
-		opCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
-		defer cancel()
+	// 1. 读取缓存（区分未命中和Redis错误；负缓存命中返回 ErrVideoNotFound）
+	v, err := vs.getDetailCache(ctx, cacheKey)
+	if err == nil || errors.Is(err, ErrVideoNotFound) {
+		metrics.ObserveCache(metrics.CacheVideoDetail, metrics.CacheHit)
+		return v, err
+	}
+	if !rediscache.IsMiss(err) {
+		// Redis 不可用或数据损坏：直接查询数据库
+		metrics.ObserveCache(metrics.CacheVideoDetail, metrics.CacheError)
+		return vs.loadDetail(ctx, id, cacheKey)
+	}
+	metrics.ObserveCache(metrics.CacheVideoDetail, metrics.CacheMiss)
 
-		b, err := vs.cache.GetBytes(opCtx, cacheKey)
-		if err != nil {
-			return nil, false
-		}
-		var cached Video
-		if err := json.Unmarshal(b, &cached); err != nil {
-			return nil, false
+	// 2. 缓存未命中：获取分布式锁，防止缓存击穿
+	lockKey := "lock:" + cacheKey
+	lockCtx, lockCancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	token, locked, lockErr := vs.cache.Lock(lockCtx, lockKey, vs.cache.LockPolicy().TTL)
+	lockCancel()
+
+	if lockErr == nil && locked {
+		defer func() { _ = vs.cache.Unlock(context.Background(), lockKey, token) }()
+
+		// 拿到锁后再检查一次：等锁期间可能已被其他请求回填
+		if v, err := vs.getDetailCache(ctx, cacheKey); err == nil || errors.Is(err, ErrVideoNotFound) {
+			return v, err
 		}
-		return &cached, true
+		return vs.loadDetail(ctx, id, cacheKey)
 	}
 
-	// 内部函数：将视频存入缓存
-	setCached := func(video *Video) {
-		b, err := json.Marshal(video)
-		if err != nil {
-			return
+	// 3. 没拿到锁：按击穿保护策略等待别人回填缓存（默认最多5次，每次间隔约20ms并带随机抖动）
+	if vs.cache.LockWait(ctx, func() bool {
+		v, err = vs.getDetailCache(ctx, cacheKey)
+		return err == nil || errors.Is(err, ErrVideoNotFound)
+	}) {
+		metrics.ObserveCache(metrics.CacheVideoDetail, metrics.CacheLockWaitHit)
+		return v, err
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	// 等待超时：降级查询数据库
+	metrics.ObserveCache(metrics.CacheVideoDetail, metrics.CacheLockWaitTimeout)
+	return vs.loadDetail(ctx, id, cacheKey)
+}
+
+// getDetailCache 从缓存读取视频详情
+// 未命中时返回 redis.Nil（可用 rediscache.IsMiss 判断），命中负缓存时返回 ErrVideoNotFound，其他错误原样返回
+func (vs *VideoService) getDetailCache(ctx context.Context, cacheKey string) (*Video, error) {
+	opCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+
+	b, err := vs.cache.GetBytes(opCtx, cacheKey)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeDetailCache(b)
+}
+
+// loadDetail 从数据库查询视频详情并回填缓存（回填失败不影响返回）
+// 视频不存在时写入负缓存并返回 ErrVideoNotFound
+func (vs *VideoService) loadDetail(ctx context.Context, id uint, cacheKey string) (*Video, error) {
+	video, err := vs.repo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			opCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+			defer cancel()
+			_ = vs.cache.SetBytes(opCtx, cacheKey, []byte(detailNotFoundMarker), detailNotFoundTTL)
+			return nil, ErrVideoNotFound
 		}
+		return nil, err
+	}
+	if b, err := json.Marshal(video); err == nil {
 		opCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
 		defer cancel()
 		_ = vs.cache.SetBytes(opCtx, cacheKey, b, vs.cacheTTL)
 	}
+	return video, nil
+}
 
-	// 如果启用了缓存
-	if vs.cache != nil {
-		// 1. 第一次尝试从缓存读取
-		if v, ok := getCached(); ok {
-			return v, nil
+// GetDetails 批量获取视频详情
+// 业务流程：
+// 1. 去重后用一次 MGET 读取 video:detail:id={id} 缓存
+// 2. 未命中（或缓存数据损坏）的ID一次性查询数据库，并回填缓存（不存在的ID写入负缓存）
+// 3. 按请求顺序返回；不存在、已删除以及当前查看者无权查看的视频直接省略，不影响其他视频
+// Redis 不可用或出错时整体查询数据库
+// 参数：
+//   - ctx: 上下文
+//   - ids: 视频ID列表（最多 maxDetailBatch 个，重复的ID只返回一次）
+//   - viewerID: 当前查看者ID（0 表示未登录）
+// 返回：
+//   - []*Video: 视频详情列表（按请求顺序）
+//   - error: ID 数量超过上限时返回 ErrTooManyDetailIDs
+func (vs *VideoService) GetDetails(ctx context.Context, ids []uint, viewerID uint) ([]*Video, error) {
+	// 1. 去重（保留首次出现的顺序），忽略 0
+	uniq := make([]uint, 0, len(ids))
+	seen := make(map[uint]struct{}, len(ids))
+	for _, id := range ids {
+		if _, ok := seen[id]; ok || id == 0 {
+			continue
 		}
+		seen[id] = struct{}{}
+		uniq = append(uniq, id)
+	}
+	if len(uniq) > maxDetailBatch {
+		return nil, ErrTooManyDetailIDs
+	}
+	if len(uniq) == 0 {
+		return []*Video{}, nil
+	}
 
-		// 2. 再次尝试读取（可能已被其他请求回填）
+	// 2. 读取缓存，收集未命中的ID
+	found := make(map[uint]*Video, len(uniq))
+	misses := uniq
+	if vs.cache != nil {
+		keys := make([]string, len(uniq))
+		for i, id := range uniq {
+			keys[i] = DetailCacheKey(id)
+		}
 		opCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
-		b, err := vs.cache.GetBytes(opCtx, cacheKey)
+		vals, err := vs.cache.MGetBytes(opCtx, keys)
 		cancel()
 		if err == nil {
-			var cached Video
-			if err := json.Unmarshal(b, &cached); err == nil {
-				return &cached, nil
-			}
-		} else if rediscache.IsMiss(err) {
-			// 3. 缓存未命中，尝试获取分布式锁
-			lockKey := "lock:" + cacheKey
-
-			lockCtx, lockCancel := context.WithTimeout(ctx, 50*time.Millisecond)
-			token, locked, lockErr := vs.cache.Lock(lockCtx, lockKey, 2*time.Second)
-			lockCancel()
-
-			if lockErr == nil && locked {
-				// 4. 拿到锁：再次检查缓存（防止锁竞争）
-				defer func() { _ = vs.cache.Unlock(context.Background(), lockKey, token) }()
-
-				if v, ok := getCached(); ok {
-					return v, nil
+			misses = make([]uint, 0)
+			for i, b := range vals {
+				if b == nil {
+					metrics.ObserveCache(metrics.CacheVideoDetail, metrics.CacheMiss)
+					misses = append(misses, uniq[i])
+					continue
+				}
+				v, err := DecodeDetailCache(b)
+				if errors.Is(err, ErrVideoNotFound) {
+					metrics.ObserveCache(metrics.CacheVideoDetail, metrics.CacheHit)
+					continue
 				}
-
-				// 5. 从数据库查询视频
-				video, err := vs.repo.GetByID(ctx, id)
 				if err != nil {
-					return nil, err
+					// 缓存数据损坏：从数据库查询
+					metrics.ObserveCache(metrics.CacheVideoDetail, metrics.CacheError)
+					misses = append(misses, uniq[i])
+					continue
 				}
-
-				// 6. 回填缓存
-				setCached(video)
-				return video, nil
+				metrics.ObserveCache(metrics.CacheVideoDetail, metrics.CacheHit)
+				found[v.ID] = v
 			}
+		} else {
+			metrics.ObserveCache(metrics.CacheVideoDetail, metrics.CacheError)
+		}
+	}
 
-			// 7. 没拿到锁：等待别人回填缓存（最多5次，每次间隔20ms）
-			for i := 0; i < 5; i++ {
-				select {
-				case <-ctx.Done():
-					return nil, ctx.Err()
-				case <-time.After(20 * time.Millisecond):
-				}
-				if v, ok := getCached(); ok {
-					return v, nil
-				}
-			}
+	// 3. 查询未命中的视频并回填缓存
+	if len(misses) > 0 {
+		fetched, err := vs.repo.GetByIDs(ctx, misses)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range fetched {
+			found[v.ID] = v
 		}
+		vs.backfillDetails(ctx, misses, found)
 	}
 
-	// 8. 缓存禁用或获取失败，直接查询数据库
-	video, err := vs.repo.GetByID(ctx, id)
-	if err != nil {
-		return nil, err
+	// 4. 按请求顺序返回，省略无权查看的视频
+	videos := make([]*Video, 0, len(uniq))
+	for _, id := range uniq {
+		v, ok := found[id]
+		if !ok || (!isPublic(v.Visibility) && v.AuthorID != viewerID) {
+			continue
+		}
+		videos = append(videos, v)
 	}
+	return videos, nil
+}
 
-	// 9. 回填缓存（如果启用）
-	if vs.cache != nil {
-		setCached(video)
+// backfillDetails 回填批量查询的视频详情缓存（不存在的ID写入负缓存，回填失败不影响返回）
+func (vs *VideoService) backfillDetails(ctx context.Context, ids []uint, found map[uint]*Video) {
+	if vs.cache == nil {
+		return
+	}
+	opCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	for _, id := range ids {
+		v, ok := found[id]
+		if !ok {
+			_ = vs.cache.SetBytes(opCtx, DetailCacheKey(id), []byte(detailNotFoundMarker), detailNotFoundTTL)
+			continue
+		}
+		if b, err := json.Marshal(v); err == nil {
+			_ = vs.cache.SetBytes(opCtx, DetailCacheKey(id), b, vs.cacheTTL)
+		}
 	}
-	return video, nil
 }
 
 // UpdateLikesCount 更新视频点赞数（直接设置为指定值）
@@ -282,7 +750,7 @@ func (vs *VideoService) UpdatePopularity(ctx context.Context, id uint, change in
 	// 3. 如果MQ不可用，直接操作Redis缓存
 	if vs.cache != nil {
 		// 3.1 删除Redis缓存中的视频详情（保证数据一致性）
-		_ = vs.cache.Del(context.Background(), fmt.Sprintf("video:detail:id=%d", id))
+		_ = vs.cache.Del(context.Background(), DetailCacheKey(id))
 
 		// 3.2 将热度变化写入Redis时间窗有序集合（用于热榜统计）
 		// 时间窗格式：hot:video:1m:{YYYYMMDDHHMM}，每分钟一个窗口
@@ -300,3 +768,27 @@ func (vs *VideoService) UpdatePopularity(ctx context.Context, id uint, change in
 	}
 	return nil
 }
+
+// invalidateFeeds 视频进入或离开 Feed 流后，广播最新视频流和关注流的缓存失效
+func invalidateFeeds(ctx context.Context, cache *rediscache.Client, reason string) {
+	invalidation.Publish(ctx, cache, reason, invalidation.FeedLatestPattern, invalidation.FeedFollowingPattern)
+}
+
+// maxTargetedFollowers 定向失效关注流缓存的粉丝数上限，超过时退化为广播失效所有关注流缓存
+const maxTargetedFollowers = 5000
+
+// invalidateAuthorFeeds 单个作者的视频进入或离开 Feed 流后的缓存失效
+// 最新视频流广播失效；关注流只失效该作者粉丝的缓存（递增粉丝的关注流版本号），
+// 没有粉丝时不影响任何人的关注流缓存；查询粉丝失败或粉丝过多时退化为广播失效所有关注流
+func (vs *VideoService) invalidateAuthorFeeds(ctx context.Context, authorID uint, reason string) {
+	if vs.cache == nil {
+		return
+	}
+	followerIDs, err := vs.repo.ListFollowerIDs(ctx, authorID, maxTargetedFollowers+1)
+	if err != nil || len(followerIDs) > maxTargetedFollowers {
+		invalidateFeeds(ctx, vs.cache, reason)
+		return
+	}
+	invalidation.Publish(ctx, vs.cache, reason, invalidation.FeedLatestPattern)
+	invalidation.BumpFollowingFeeds(ctx, vs.cache, reason, followerIDs)
+}
@@ -0,0 +1,95 @@
+package video
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"feedsystem_video_go/internal/config"
+	rediscache "feedsystem_video_go/internal/middleware/redis"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alicebob/miniredis/v2"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newMockDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	sqlDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock: %v", err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	db, err := gorm.Open(mysql.New(mysql.Config{Conn: sqlDB, SkipInitializeWithVersion: true}), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("gorm open: %v", err)
+	}
+	return db, mock
+}
+
+func newTestCache(t *testing.T) (*rediscache.Client, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	port, err := strconv.Atoi(mr.Port())
+	if err != nil {
+		t.Fatalf("miniredis port: %v", err)
+	}
+	cache, err := rediscache.NewFromEnv(&config.RedisConfig{Host: mr.Host(), Port: port})
+	if err != nil {
+		t.Fatalf("redis client: %v", err)
+	}
+	t.Cleanup(func() { _ = cache.Close() })
+	return cache, mr
+}
+
+// 缓存未命中时并发请求同一视频详情，只有拿到锁的请求查询数据库，其余请求等待回填后读取缓存
+func TestGetDetailConcurrentMissQueriesDBOnce(t *testing.T) {
+	const (
+		videoID uint = 42
+		callers      = 20
+	)
+	db, mock := newMockDB(t)
+	cache, _ := newTestCache(t)
+	vs := NewVideoService(NewVideoRepository(db), cache, nil, config.VideoConfig{}, config.StorageConfig{})
+
+	// 只设置一次查询：第二次查询没有对应的期望，sqlmock 会返回错误
+	mock.ExpectQuery("SELECT \\* FROM `videos` WHERE `videos`.`id` = \\?").
+		WithArgs(videoID, 1).
+		WillDelayFor(30 * time.Millisecond).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "author_id", "title", "visibility"}).
+			AddRow(videoID, 7, "hello", VisibilityPublic))
+
+	start := make(chan struct{})
+	errs := make(chan error, callers)
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			v, err := vs.GetDetail(context.Background(), videoID, 0)
+			if err == nil && (v == nil || v.ID != videoID || v.Title != "hello") {
+				t.Errorf("GetDetail = %+v, want video %d", v, videoID)
+			}
+			errs <- err
+		}()
+	}
+	close(start)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("GetDetail: %v", err)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
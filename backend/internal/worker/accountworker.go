@@ -0,0 +1,103 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"feedsystem_video_go/internal/account"
+	"feedsystem_video_go/internal/middleware/rabbitmq"
+	"feedsystem_video_go/internal/middleware/trace"
+	"log"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"gorm.io/gorm"
+)
+
+// AccountWorker 账户事件消费者
+// 职责：消费改名事件，同步视频、评论表中冗余存储的用户名
+type AccountWorker struct {
+	ch       *amqp.Channel
+	accounts *account.AccountRepository
+	queue    string
+	tag   string // 消费者标签（RabbitMQ 管理界面中显示）
+}
+
+func NewAccountWorker(ch *amqp.Channel, accounts *account.AccountRepository, queue string, tag string) *AccountWorker {
+	if tag == "" {
+		tag = ConsumerTag("account-worker")
+	}
+	return &AccountWorker{ch: ch, accounts: accounts, queue: queue, tag: tag}
+}
+
+// Tag 返回消费者标签
+func (w *AccountWorker) Tag() string {
+	return w.tag
+}
+
+func (w *AccountWorker) Run(ctx context.Context) error {
+	if w == nil || w.ch == nil || w.accounts == nil {
+		return errors.New("account worker is not initialized")
+	}
+	if w.queue == "" {
+		return errors.New("queue is required")
+	}
+
+	deliveries, err := w.ch.Consume(
+		w.queue,
+		w.tag,
+		false,
+		false,
+		false,
+		false,
+		nil,
+	)
+	if err != nil {
+		return err
+	}
+
+	// 逐条处理消息；停止时先取消消费者，再在宽限期内处理完已接收的消息
+	return consumeLoop(ctx, w.ch, w.tag, deliveries, w.handleDelivery)
+}
+
+func (w *AccountWorker) handleDelivery(ctx context.Context, d amqp.Delivery) {
+	// 追踪ID写入上下文，并记录消息ID/追踪ID，便于与 API 日志串联
+	ctx = trace.WithID(ctx, d.CorrelationId)
+	log.Printf("account worker: processing message_id=%s trace_id=%s routing_key=%s", d.MessageId, d.CorrelationId, d.RoutingKey)
+
+	if err := w.process(ctx, d.Body); err != nil {
+		log.Printf("account worker: failed to process message message_id=%s trace_id=%s: %v", d.MessageId, d.CorrelationId, err)
+		_ = d.Nack(false, true)
+		return
+	}
+	_ = d.Ack(false)
+}
+
+func (w *AccountWorker) process(ctx context.Context, body []byte) error {
+	var evt rabbitmq.AccountEvent
+	if err := json.Unmarshal(body, &evt); err != nil {
+		return nil
+	}
+	if evt.AccountID == 0 {
+		return nil
+	}
+	switch evt.Action {
+	case "renamed":
+		return w.applyRenamed(ctx, &evt)
+	default:
+		return nil
+	}
+}
+
+// applyRenamed 同步冗余用户名
+// 以数据库中账户的当前用户名为准（而不是事件中的用户名），
+// 这样即使连续改名的事件乱序到达，最终结果也是最新用户名；重复消费也不会产生副作用
+func (w *AccountWorker) applyRenamed(ctx context.Context, evt *rabbitmq.AccountEvent) error {
+	acc, err := w.accounts.FindByID(ctx, evt.AccountID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	return w.accounts.SyncUsername(ctx, acc.ID, acc.Username)
+}
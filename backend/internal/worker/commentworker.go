@@ -5,9 +5,12 @@ import (
 	"encoding/json"
 	"errors"
 	"feedsystem_video_go/internal/middleware/rabbitmq"
+	rediscache "feedsystem_video_go/internal/middleware/redis"
+	"feedsystem_video_go/internal/middleware/trace"
 	"feedsystem_video_go/internal/video"
 	"log"
 	"strings"
+	"time"
 
 	amqp "github.com/rabbitmq/amqp091-go"
 )
@@ -15,16 +18,30 @@ import (
 type CommentWorker struct {
 	ch       *amqp.Channel
 	comments *video.CommentRepository
+	likes    *video.CommentLikeRepository
 	videos   *video.VideoRepository
+	popularity *video.PopularityFlusher // 热度写入合并器
+	filter   *video.CommentFilter
+	maxPerVideo int // 每个视频最多的评论数（0 表示不限制）
+	cache    *rediscache.Client // 可能为 nil（不推送实时评论数）
 	queue    string
+	tag   string // 消费者标签（RabbitMQ 管理界面中显示）
 }
 
-func NewCommentWorker(ch *amqp.Channel, comments *video.CommentRepository, videos *video.VideoRepository, queue string) *CommentWorker {
-	return &CommentWorker{ch: ch, comments: comments, videos: videos, queue: queue}
+func NewCommentWorker(ch *amqp.Channel, comments *video.CommentRepository, likes *video.CommentLikeRepository, videos *video.VideoRepository, popularity *video.PopularityFlusher, filter *video.CommentFilter, maxPerVideo int, cache *rediscache.Client, queue string, tag string) *CommentWorker {
+	if tag == "" {
+		tag = ConsumerTag("comment-worker")
+	}
+	return &CommentWorker{ch: ch, comments: comments, likes: likes, videos: videos, popularity: popularity, filter: filter, maxPerVideo: maxPerVideo, cache: cache, queue: queue, tag: tag}
+}
+
+// Tag 返回消费者标签
+func (w *CommentWorker) Tag() string {
+	return w.tag
 }
 
 func (w *CommentWorker) Run(ctx context.Context) error {
-	if w == nil || w.ch == nil || w.comments == nil || w.videos == nil {
+	if w == nil || w.ch == nil || w.comments == nil || w.likes == nil || w.videos == nil {
 		return errors.New("comment worker is not initialized")
 	}
 	if w.queue == "" {
@@ -33,7 +50,7 @@ func (w *CommentWorker) Run(ctx context.Context) error {
 
 	deliveries, err := w.ch.Consume(
 		w.queue,
-		"",
+		w.tag,
 		false,
 		false,
 		false,
@@ -44,22 +61,17 @@ func (w *CommentWorker) Run(ctx context.Context) error {
 		return err
 	}
 
-	for {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case d, ok := <-deliveries:
-			if !ok {
-				return errors.New("deliveries channel closed")
-			}
-			w.handleDelivery(ctx, d)
-		}
-	}
+	// 逐条处理消息；停止时先取消消费者，再在宽限期内处理完已接收的消息
+	return consumeLoop(ctx, w.ch, w.tag, deliveries, w.handleDelivery)
 }
 
 func (w *CommentWorker) handleDelivery(ctx context.Context, d amqp.Delivery) {
+	// 追踪ID写入上下文，并记录消息ID/追踪ID，便于与 API 日志串联
+	ctx = trace.WithID(ctx, d.CorrelationId)
+	log.Printf("comment worker: processing message_id=%s trace_id=%s routing_key=%s", d.MessageId, d.CorrelationId, d.RoutingKey)
+
 	if err := w.process(ctx, d.Body); err != nil {
-		log.Printf("comment worker: failed to process message: %v", err)
+		log.Printf("comment worker: failed to process message message_id=%s trace_id=%s: %v", d.MessageId, d.CorrelationId, err)
 		_ = d.Nack(false, true)
 		return
 	}
@@ -76,6 +88,10 @@ func (w *CommentWorker) process(ctx context.Context, body []byte) error {
 		return w.applyPublish(ctx, &evt)
 	case "delete":
 		return w.applyDelete(ctx, &evt)
+	case "like":
+		return w.applyLike(ctx, &evt)
+	case "unlike":
+		return w.applyUnlike(ctx, &evt)
 	default:
 		return nil
 	}
@@ -94,16 +110,40 @@ func (w *CommentWorker) applyPublish(ctx context.Context, evt *rabbitmq.CommentE
 		return nil
 	}
 
+	// 敏感词过滤（API 侧已过滤，这里兜底处理旧消息或绕过 API 的消息）
+	content, err := w.filter.Apply(strings.TrimSpace(evt.Content))
+	if err != nil {
+		log.Printf("comment worker: drop blocked comment from account %d on video %d", evt.AuthorID, evt.VideoID)
+		return nil
+	}
+
+	// 评论数上限：API 侧已检查，这里兜底处理并发发布或旧消息，达到上限时丢弃
+	if w.maxPerVideo > 0 {
+		full, err := w.comments.HasAtLeast(ctx, evt.VideoID, w.maxPerVideo)
+		if err != nil {
+			return err
+		}
+		if full {
+			log.Printf("comment worker: drop comment from account %d, video %d reached the comment limit", evt.AuthorID, evt.VideoID)
+			return nil
+		}
+	}
+
 	c := &video.Comment{
 		Username: strings.TrimSpace(evt.Username),
 		VideoID:  evt.VideoID,
 		AuthorID: evt.AuthorID,
-		Content:  strings.TrimSpace(evt.Content),
+		Content:  content,
 	}
 	if err := w.comments.CreateComment(ctx, c); err != nil {
 		return err
 	}
-	return w.videos.ChangePopularity(ctx, evt.VideoID, 1)
+	// 热度变化量由合并器批量写入数据库
+	w.popularity.Add(evt.VideoID, video.CommentPopularityWeight)
+	// 推送最新评论数给正在观看该视频的客户端
+	video.PublishCommentsCount(ctx, w.cache, w.comments, evt.VideoID)
+	// 通知视频作者由通知 Worker 消费同一事件完成（见 NotificationWorker）
+	return nil
 }
 
 func (w *CommentWorker) applyDelete(ctx context.Context, evt *rabbitmq.CommentEvent) error {
@@ -117,6 +157,61 @@ func (w *CommentWorker) applyDelete(ctx context.Context, evt *rabbitmq.CommentEv
 	if c == nil {
 		return nil
 	}
-	return w.comments.DeleteComment(ctx, c)
+	// 软删除：评论作者可以在撤销窗口内恢复
+	deleted, err := w.comments.DeleteComment(ctx, c)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		// 并发删除（如重复点击）：另一条消息已经删除了评论并扣减过热度
+		return nil
+	}
+	// 撤销发布评论时增加的热度（写入时 GREATEST 保证不小于0），只在真正删除评论后执行
+	w.popularity.Add(c.VideoID, -video.CommentPopularityWeight)
+	video.UpdatePopularityCache(ctx, w.cache, c.VideoID, -video.CommentPopularityWeight)
+	// 推送最新评论数给正在观看该视频的客户端
+	video.PublishCommentsCount(ctx, w.cache, w.comments, c.VideoID)
+	return nil
+}
+
+
+func (w *CommentWorker) applyLike(ctx context.Context, evt *rabbitmq.CommentEvent) error {
+	if evt == nil || evt.CommentID == 0 || evt.AccountID == 0 {
+		return nil
+	}
+	ok, err := w.comments.IsExist(ctx, evt.CommentID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return nil
+	}
+
+	// 忽略重复点赞（唯一索引 comment_id + account_id）
+	created, err := w.likes.LikeIgnoreDuplicate(ctx, &video.CommentLike{
+		CommentID: evt.CommentID,
+		AccountID: evt.AccountID,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	if !created {
+		return nil
+	}
+	return w.comments.ChangeLikesCount(ctx, evt.CommentID, 1)
 }
 
+func (w *CommentWorker) applyUnlike(ctx context.Context, evt *rabbitmq.CommentEvent) error {
+	if evt == nil || evt.CommentID == 0 || evt.AccountID == 0 {
+		return nil
+	}
+	deleted, err := w.likes.DeleteByCommentAndAccount(ctx, evt.CommentID, evt.AccountID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return nil
+	}
+	return w.comments.ChangeLikesCount(ctx, evt.CommentID, -1)
+}
@@ -0,0 +1,74 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// shutdownGracePeriod 停止时处理已接收消息的宽限期，超时后未处理的消息留在队列中（未确认，由 RabbitMQ 重新投递）
+const shutdownGracePeriod = 10 * time.Second
+
+// ConsumerTag 生成描述性的消费者标签：<Worker 名>-<主机名>-<进程ID>（如 like-worker-host1-1234）
+// 便于在 RabbitMQ 管理界面中区分消费者属于哪个进程/Worker；停止时也用它调用 Channel.Cancel 取消消费者
+func ConsumerTag(name string) string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s-%s-%d", name, host, os.Getpid())
+}
+
+// consumeLoop 逐条处理消息，直到 ctx 被取消或 deliveries 被关闭
+// 优雅停止流程：
+//  1. ctx 被取消后调用 Channel.Cancel(tag, false)，RabbitMQ 不再投递新消息
+//  2. 继续处理已经接收（预取）的消息，直到 deliveries 关闭或超过宽限期
+//  3. 返回 ctx.Err()
+//
+// 处理消息使用不随 ctx 取消的上下文：正在写数据库的消息不会因为收到停止信号而中途失败，超过宽限期后才取消
+func consumeLoop(ctx context.Context, ch *amqp.Channel, tag string, deliveries <-chan amqp.Delivery, handle func(context.Context, amqp.Delivery)) error {
+	procCtx, cancelProc := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelProc()
+
+	for {
+		select {
+		case <-ctx.Done():
+			drain(procCtx, cancelProc, ch, tag, deliveries, handle)
+			return ctx.Err()
+		case d, ok := <-deliveries:
+			if !ok {
+				return errors.New("deliveries channel closed")
+			}
+			handle(procCtx, d)
+		}
+	}
+}
+
+// drain 取消消费者，并在宽限期内处理完已接收的消息
+func drain(procCtx context.Context, cancelProc context.CancelFunc, ch *amqp.Channel, tag string, deliveries <-chan amqp.Delivery, handle func(context.Context, amqp.Delivery)) {
+	if err := ch.Cancel(tag, false); err != nil {
+		// 通道已关闭时未确认的消息会由 RabbitMQ 重新投递，不需要再处理
+		log.Printf("worker: failed to cancel consumer %s: %v", tag, err)
+		return
+	}
+
+	timer := time.AfterFunc(shutdownGracePeriod, cancelProc)
+	defer timer.Stop()
+	for {
+		select {
+		case <-procCtx.Done():
+			log.Printf("worker: consumer %s stopped after grace period, remaining messages will be redelivered", tag)
+			return
+		case d, ok := <-deliveries:
+			if !ok {
+				return
+			}
+			handle(procCtx, d)
+		}
+	}
+}
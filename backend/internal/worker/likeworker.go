@@ -7,6 +7,8 @@ import (
 	"encoding/json"
 	"errors"
 	"feedsystem_video_go/internal/middleware/rabbitmq"
+	rediscache "feedsystem_video_go/internal/middleware/redis"
+	"feedsystem_video_go/internal/middleware/trace"
 	"feedsystem_video_go/internal/video"
 	"log"
 	amqp "github.com/rabbitmq/amqp091-go"
@@ -14,12 +16,16 @@ import (
 )
 
 // LikeWorker 点赞事件消费者
-// 职责：从队列中获取点赞消息，更新数据库（点赞表 + 视频点赞数 + 视频热度）
+// 职责：从队列中获取点赞消息，更新数据库（点赞表 + 视频点赞数 + 视频热度）和 Redis 热度
+// 点赞记录是否真正插入/删除是计数的唯一依据：重复点赞、重复取消不会修改任何计数
 type LikeWorker struct {
 	ch     *amqp.Channel         // RabbitMQ 通道，用于消费消息
 	likes  *video.LikeRepository // 点赞数据访问层，操作点赞表
-	videos *video.VideoRepository // 视频数据访问层，更新点赞数和热度
+	videos *video.VideoRepository // 视频数据访问层，更新点赞数
+	popularity *video.PopularityFlusher // 热度写入合并器，累加数据库热度变化量
+	cache  *rediscache.Client     // Redis 缓存客户端，更新热度缓存（可能为 nil）
 	queue  string                 // 队列名称，监听哪个队列
+	tag    string                 // 消费者标签，RabbitMQ 管理界面中显示，停止时用于取消消费者
 }
 
 // NewLikeWorker 创建点赞 Worker 实例
@@ -27,9 +33,20 @@ type LikeWorker struct {
 //   ch - RabbitMQ 通道
 //   likes - 点赞仓储（操作数据库）
 //   videos - 视频仓储（更新点赞数）
+//   popularity - 热度写入合并器
+//   cache - Redis 缓存客户端（可能为 nil）
 //   queue - 队列名称
-func NewLikeWorker(ch *amqp.Channel, likes *video.LikeRepository, videos *video.VideoRepository, queue string) *LikeWorker {
-	return &LikeWorker{ch: ch, likes: likes, videos: videos, queue: queue}
+//   tag - 消费者标签（为空时使用 like-worker-{主机名}-{进程ID}）
+func NewLikeWorker(ch *amqp.Channel, likes *video.LikeRepository, videos *video.VideoRepository, popularity *video.PopularityFlusher, cache *rediscache.Client, queue string, tag string) *LikeWorker {
+	if tag == "" {
+		tag = ConsumerTag("like-worker")
+	}
+	return &LikeWorker{ch: ch, likes: likes, videos: videos, popularity: popularity, cache: cache, queue: queue, tag: tag}
+}
+
+// Tag 返回消费者标签
+func (w *LikeWorker) Tag() string {
+	return w.tag
 }
 
 // Run 启动 Worker，开始消费消息
@@ -40,6 +57,7 @@ func NewLikeWorker(ch *amqp.Channel, likes *video.LikeRepository, videos *video.
 //   2. RabbitMQ 推送消息到 deliveries 通道
 //   3. 遍历 deliveries 通道，处理每条消息
 //   4. 处理完成后发送 ACK（确认）或 NACK（拒绝）
+//   5. 收到取消信号后取消消费者，在宽限期内处理完已接收的消息再返回
 //
 // 参数：
 //   ctx - 上下文，用于优雅关闭（收到中断信号时取消）
@@ -60,7 +78,7 @@ func (w *LikeWorker) Run(ctx context.Context) error {
 	// Consume：向 RabbitMQ 注册消费者，开始消费队列中的消息
 	// 参数说明：
 	//   w.queue - 队列名称
-	//   ""      - 消费者标签，空字符串表示自动生成
+	//   tag     - 消费者标签（固定值，停止时用于取消消费者）
 	//   false   - auto-ack：是否自动确认消息（false 表示手动确认）
 	//   false   - exclusive：独占模式，仅此消费者可以消费该队列
 	//   false   - no-local：不允许接收本连接发布的消息
@@ -69,7 +87,7 @@ func (w *LikeWorker) Run(ctx context.Context) error {
 	// 返回值：deliveries 是消息通道，RabbitMQ 会把消息推送到这个通道
 	deliveries, err := w.ch.Consume(
 		w.queue,
-		"",
+		w.tag,
 		false,
 		false,
 		false,
@@ -82,21 +100,8 @@ func (w *LikeWorker) Run(ctx context.Context) error {
 
 	// ========== 3. 消息消费循环 ==========
 
-	for {
-		select {
-		// 收到取消信号（如 Ctrl+C），退出循环
-		case <-ctx.Done():
-			return ctx.Err()
-
-		// 从 RabbitMQ 接收消息
-		case d, ok := <-deliveries:
-			if !ok {
-				return errors.New("deliveries channel closed")
-			}
-			// 处理消息（包括 ACK/NACK）
-			w.handleDelivery(ctx, d)
-		}
-	}
+	// 逐条处理消息；停止时先取消消费者，再在宽限期内处理完已接收的消息
+	return consumeLoop(ctx, w.ch, w.tag, deliveries, w.handleDelivery)
 }
 
 // handleDelivery 处理单条消息
@@ -110,13 +115,17 @@ func (w *LikeWorker) Run(ctx context.Context) error {
 //   ctx - 上下文
 //   d - 消息对象（包含消息体、元数据等）
 func (w *LikeWorker) handleDelivery(ctx context.Context, d amqp.Delivery) {
+	// 追踪ID写入上下文，并记录消息ID/追踪ID，便于与 API 日志串联
+	ctx = trace.WithID(ctx, d.CorrelationId)
+	log.Printf("like worker: processing message_id=%s trace_id=%s routing_key=%s", d.MessageId, d.CorrelationId, d.RoutingKey)
+
 	// 尝试处理消息
 	if err := w.process(ctx, d.Body); err != nil {
 		// 处理失败，发送 NACK
 		// 参数说明：
 		//   false - multiple：是否批量拒绝（false 表示只拒绝当前消息）
 		//   true  - requeue：是否重新入队（true 表示消息重新放回队列，下次再消费）
-		log.Printf("like worker: failed to process message: %v", err)
+		log.Printf("like worker: failed to process message message_id=%s trace_id=%s: %v", d.MessageId, d.CorrelationId, err)
 		_ = d.Nack(false, true)
 		return
 	}
@@ -174,6 +183,8 @@ func (w *LikeWorker) process(ctx context.Context, body []byte) error {
 //   2. 插入点赞记录（忽略重复点赞）
 //   3. 更新视频点赞数（+1）
 //   4. 更新视频热度（+1）
+//   5. 更新 Redis 热度缓存（+1）
+// 通知视频作者由通知 Worker 消费同一事件完成（见 NotificationWorker）
 //
 // 参数：
 //   ctx - 上下文
@@ -216,9 +227,15 @@ func (w *LikeWorker) applyLike(ctx context.Context, userID, videoID uint) error
 		return err
 	}
 
-	// 4. 更新视频热度（+1）
-	// 热度计算规则：点赞+1，评论+1，关注+10
-	return w.videos.ChangePopularity(ctx, videoID, 1)
+	// 4. 累加视频热度（+1），由合并器批量写入数据库
+	// 热度计算规则：点赞+1，评论+5，关注+10（见 video 包中的热度权重常量）
+	w.popularity.Add(videoID, 1)
+
+	// 5. 更新 Redis 热度缓存（只在真正插入点赞记录后执行，避免快速点赞/取消导致热度漂移）
+	video.UpdatePopularityCache(ctx, w.cache, videoID, 1)
+	// 推送最新点赞数给正在观看该视频的客户端
+	video.PublishLikesCount(ctx, w.cache, w.videos, videoID)
+	return nil
 }
 
 // applyUnlike 执行取消点赞业务逻辑
@@ -227,6 +244,7 @@ func (w *LikeWorker) applyLike(ctx context.Context, userID, videoID uint) error
 //   2. 删除点赞记录
 //   3. 更新视频点赞数（-1）
 //   4. 更新视频热度（-1）
+//   5. 更新 Redis 热度缓存（-1）
 //
 // 参数：
 //   ctx - 上下文
@@ -261,6 +279,12 @@ func (w *LikeWorker) applyUnlike(ctx context.Context, userID, videoID uint) erro
 		return err
 	}
 
-	// 4. 更新视频热度（-1）
-	return w.videos.ChangePopularity(ctx, videoID, -1)
+	// 4. 累加视频热度（-1），由合并器批量写入数据库
+	w.popularity.Add(videoID, -1)
+
+	// 5. 更新 Redis 热度缓存（只在真正删除点赞记录后执行）
+	video.UpdatePopularityCache(ctx, w.cache, videoID, -1)
+	// 推送最新点赞数给正在观看该视频的客户端
+	video.PublishLikesCount(ctx, w.cache, w.videos, videoID)
+	return nil
 }
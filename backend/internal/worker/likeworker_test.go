@@ -0,0 +1,115 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"feedsystem_video_go/internal/middleware/rabbitmq"
+	"feedsystem_video_go/internal/video"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	gomysql "github.com/go-sql-driver/mysql"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newMockDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	sqlDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock: %v", err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	db, err := gorm.Open(mysql.New(mysql.Config{Conn: sqlDB, SkipInitializeWithVersion: true}), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("gorm open: %v", err)
+	}
+	return db, mock
+}
+
+// likeStore 模拟 likes 表中一条 (video_id, account_id) 记录，为每次操作设置数据库应收到的 SQL
+// 只有点赞记录真正插入/删除时才应更新 likes_count；重复点赞返回 1062，重复取消删除 0 行
+type likeStore struct {
+	mock       sqlmock.Sqlmock
+	videoID    uint
+	liked      bool
+	likesCount int64
+}
+
+func (s *likeStore) expect(action string) {
+	s.mock.ExpectQuery("SELECT 1 FROM `videos`").WillReturnRows(sqlmock.NewRows([]string{"1"}).AddRow(1))
+
+	var change int64
+	switch action {
+	case "like":
+		s.mock.ExpectBegin()
+		if s.liked {
+			s.mock.ExpectExec("INSERT INTO `likes`").WillReturnError(&gomysql.MySQLError{Number: 1062, Message: "Duplicate entry"})
+			s.mock.ExpectRollback()
+		} else {
+			s.mock.ExpectExec("INSERT INTO `likes`").WillReturnResult(sqlmock.NewResult(1, 1))
+			s.mock.ExpectCommit()
+			s.liked, change = true, 1
+		}
+	case "unlike":
+		s.mock.ExpectBegin()
+		if s.liked {
+			s.mock.ExpectExec("DELETE FROM `likes`").WillReturnResult(sqlmock.NewResult(0, 1))
+			s.liked, change = false, -1
+		} else {
+			s.mock.ExpectExec("DELETE FROM `likes`").WillReturnResult(sqlmock.NewResult(0, 0))
+		}
+		s.mock.ExpectCommit()
+	}
+	if change != 0 {
+		s.mock.ExpectBegin()
+		s.mock.ExpectExec("UPDATE `videos` SET `likes_count`=GREATEST\\(likes_count \\+ \\?, 0\\)").
+			WithArgs(change, sqlmock.AnyArg(), s.videoID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		s.mock.ExpectCommit()
+		s.likesCount += change
+	}
+}
+
+// 快速点赞/取消（含重复点赞、重复取消）后，点赞记录、点赞数和热度的变化量应保持一致
+func TestLikeWorkerRapidToggleKeepsCountsConsistent(t *testing.T) {
+	const (
+		userID  uint = 7
+		videoID uint = 42
+	)
+	db, mock := newMockDB(t)
+	videos := video.NewVideoRepository(db)
+	popularity := video.NewPopularityFlusher(videos, 0)
+	w := NewLikeWorker(nil, video.NewLikeRepository(db), videos, popularity, nil, "like.events", "test")
+
+	store := &likeStore{mock: mock, videoID: videoID}
+	actions := []string{"like", "like", "unlike", "like", "unlike", "unlike", "like", "like", "unlike", "like"}
+	for i, action := range actions {
+		store.expect(action)
+		body, _ := json.Marshal(rabbitmq.LikeEvent{Action: action, UserID: userID, VideoID: videoID})
+		if err := w.process(context.Background(), body); err != nil {
+			t.Fatalf("action #%d %s: %v", i, action, err)
+		}
+	}
+	if !store.liked || store.likesCount != 1 {
+		t.Fatalf("store liked=%v likes_count=%d, want liked with likes_count=1", store.liked, store.likesCount)
+	}
+
+	// 热度变化量合并后写入一次：净变化应与点赞数一致
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE `videos` SET `popularity`=GREATEST\\(popularity \\+ CASE id WHEN \\? THEN \\? ELSE 0 END, 0\\)").
+		WithArgs(videoID, store.likesCount, sqlmock.AnyArg(), videoID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_ = popularity.Run(ctx)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
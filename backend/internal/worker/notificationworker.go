@@ -0,0 +1,155 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"feedsystem_video_go/internal/middleware/rabbitmq"
+	"feedsystem_video_go/internal/middleware/trace"
+	"feedsystem_video_go/internal/notification"
+	"feedsystem_video_go/internal/video"
+	"log"
+	"strings"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"gorm.io/gorm"
+)
+
+// 通知 Worker 关心的路由键（队列只绑定这几个，生产者不需要任何改动）
+const (
+	NotificationLikeRoutingKey    = "like.like"       // 点赞视频 → 通知视频作者
+	NotificationCommentRoutingKey = "comment.publish" // 发布评论 → 通知视频作者
+	NotificationFollowRoutingKey  = "social.follow"   // 关注 → 通知被关注者
+)
+
+// NotificationWorker 通知事件消费者
+// 职责：消费点赞/评论/关注事件，为内容所有者创建通知（自己对自己的操作不通知）
+// 与点赞/评论/关注 Worker 各自消费一份事件副本，互不影响；按事件ID去重，重复投递只产生一条通知
+type NotificationWorker struct {
+	ch          *amqp.Channel
+	videos      *video.VideoRepository   // 查询视频作者
+	filter      *video.CommentFilter     // 评论过滤器（被拦截的评论不通知，可能为 nil）
+	comments    *video.CommentRepository // 评论仓储（达到评论数上限被丢弃的评论不通知）
+	maxPerVideo int                      // 每个视频最多的评论数（0 表示不限制）
+	notifier    *notification.NotificationService
+	queue       string
+	tag         string // 消费者标签（RabbitMQ 管理界面中显示）
+}
+
+func NewNotificationWorker(ch *amqp.Channel, videos *video.VideoRepository, filter *video.CommentFilter, comments *video.CommentRepository, maxPerVideo int, notifier *notification.NotificationService, queue string, tag string) *NotificationWorker {
+	if tag == "" {
+		tag = ConsumerTag("notification-worker")
+	}
+	return &NotificationWorker{ch: ch, videos: videos, filter: filter, comments: comments, maxPerVideo: maxPerVideo, notifier: notifier, queue: queue, tag: tag}
+}
+
+// Tag 返回消费者标签
+func (w *NotificationWorker) Tag() string {
+	return w.tag
+}
+
+func (w *NotificationWorker) Run(ctx context.Context) error {
+	if w == nil || w.ch == nil || w.videos == nil || w.comments == nil || w.notifier == nil {
+		return errors.New("notification worker is not initialized")
+	}
+	if w.queue == "" {
+		return errors.New("queue is required")
+	}
+
+	deliveries, err := w.ch.Consume(
+		w.queue,
+		w.tag,
+		false,
+		false,
+		false,
+		false,
+		nil,
+	)
+	if err != nil {
+		return err
+	}
+
+	// 逐条处理消息；停止时先取消消费者，再在宽限期内处理完已接收的消息
+	return consumeLoop(ctx, w.ch, w.tag, deliveries, w.handleDelivery)
+}
+
+func (w *NotificationWorker) handleDelivery(ctx context.Context, d amqp.Delivery) {
+	// 追踪ID写入上下文，并记录消息ID/追踪ID，便于与 API 日志串联
+	ctx = trace.WithID(ctx, d.CorrelationId)
+	log.Printf("notification worker: processing message_id=%s trace_id=%s routing_key=%s", d.MessageId, d.CorrelationId, d.RoutingKey)
+
+	if err := w.process(ctx, d.RoutingKey, d.Body); err != nil {
+		log.Printf("notification worker: failed to process message message_id=%s trace_id=%s: %v", d.MessageId, d.CorrelationId, err)
+		_ = d.Nack(false, true)
+		return
+	}
+	_ = d.Ack(false)
+}
+
+// process 按路由键解析事件（三个交换机的事件结构不同）
+// 解析失败或参数不合法的消息直接丢弃
+func (w *NotificationWorker) process(ctx context.Context, routingKey string, body []byte) error {
+	switch routingKey {
+	case NotificationLikeRoutingKey:
+		var evt rabbitmq.LikeEvent
+		if err := json.Unmarshal(body, &evt); err != nil {
+			return nil
+		}
+		if evt.UserID == 0 || evt.VideoID == 0 {
+			return nil
+		}
+		return w.notifyVideoAuthor(ctx, evt.EventID, notification.TypeLike, evt.UserID, evt.VideoID)
+
+	case NotificationCommentRoutingKey:
+		var evt rabbitmq.CommentEvent
+		if err := json.Unmarshal(body, &evt); err != nil {
+			return nil
+		}
+		if evt.AuthorID == 0 || evt.VideoID == 0 || strings.TrimSpace(evt.Content) == "" {
+			return nil
+		}
+		// 与评论 Worker 使用同一过滤器：被拦截（不会写入）的评论不通知
+		if w.filter != nil {
+			if _, err := w.filter.Apply(strings.TrimSpace(evt.Content)); err != nil {
+				return nil
+			}
+		}
+		// 与评论 Worker 使用同一上限：达到评论数上限（不会写入）的评论不通知
+		// 两个 Worker 并行消费，只统计事件发生之前的评论，不把本条评论算进去
+		if w.maxPerVideo > 0 {
+			full, err := w.comments.HasAtLeastBefore(ctx, evt.VideoID, w.maxPerVideo, evt.OccurredAt)
+			if err != nil {
+				return err
+			}
+			if full {
+				return nil
+			}
+		}
+		return w.notifyVideoAuthor(ctx, evt.EventID, notification.TypeComment, evt.AuthorID, evt.VideoID)
+
+	case NotificationFollowRoutingKey:
+		var evt rabbitmq.SocialEvent
+		if err := json.Unmarshal(body, &evt); err != nil {
+			return nil
+		}
+		if evt.FollowerID == 0 || evt.VloggerID == 0 {
+			return nil
+		}
+		return w.notifier.NotifyEvent(ctx, evt.EventID, evt.VloggerID, notification.TypeFollow, evt.FollowerID, 0)
+
+	default:
+		return nil
+	}
+}
+
+// notifyVideoAuthor 通知视频作者（视频已删除时丢弃事件）
+func (w *NotificationWorker) notifyVideoAuthor(ctx context.Context, eventID string, typ string, actorID, videoID uint) error {
+	v, err := w.videos.GetByID(ctx, videoID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	return w.notifier.NotifyEvent(ctx, eventID, v.AuthorID, typ, actorID, videoID)
+}
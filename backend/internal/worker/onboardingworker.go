@@ -0,0 +1,106 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"feedsystem_video_go/internal/account"
+	"feedsystem_video_go/internal/middleware/rabbitmq"
+	"feedsystem_video_go/internal/middleware/trace"
+	"feedsystem_video_go/internal/notification"
+	"log"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"gorm.io/gorm"
+)
+
+// OnboardingWorker 账户生命周期事件消费者
+// 职责：消费 account.created 事件，执行新账户的引导操作（目前为发送欢迎通知）
+// 每个引导步骤都必须可以重复执行：处理失败时消息会重新入队，已完成的步骤会再执行一次
+type OnboardingWorker struct {
+	ch       *amqp.Channel
+	accounts *account.AccountRepository
+	notifier *notification.NotificationService // 可能为 nil（不发送欢迎通知）
+	queue    string
+	tag      string // 消费者标签（RabbitMQ 管理界面中显示）
+}
+
+func NewOnboardingWorker(ch *amqp.Channel, accounts *account.AccountRepository, notifier *notification.NotificationService, queue string, tag string) *OnboardingWorker {
+	if tag == "" {
+		tag = ConsumerTag("onboarding-worker")
+	}
+	return &OnboardingWorker{ch: ch, accounts: accounts, notifier: notifier, queue: queue, tag: tag}
+}
+
+// Tag 返回消费者标签
+func (w *OnboardingWorker) Tag() string {
+	return w.tag
+}
+
+func (w *OnboardingWorker) Run(ctx context.Context) error {
+	if w == nil || w.ch == nil || w.accounts == nil {
+		return errors.New("onboarding worker is not initialized")
+	}
+	if w.queue == "" {
+		return errors.New("queue is required")
+	}
+
+	deliveries, err := w.ch.Consume(
+		w.queue,
+		w.tag,
+		false,
+		false,
+		false,
+		false,
+		nil,
+	)
+	if err != nil {
+		return err
+	}
+
+	// 逐条处理消息；停止时先取消消费者，再在宽限期内处理完已接收的消息
+	return consumeLoop(ctx, w.ch, w.tag, deliveries, w.handleDelivery)
+}
+
+func (w *OnboardingWorker) handleDelivery(ctx context.Context, d amqp.Delivery) {
+	// 追踪ID写入上下文，并记录消息ID/追踪ID，便于与 API 日志串联
+	ctx = trace.WithID(ctx, d.CorrelationId)
+	log.Printf("onboarding worker: processing message_id=%s trace_id=%s routing_key=%s", d.MessageId, d.CorrelationId, d.RoutingKey)
+
+	if err := w.process(ctx, d.Body); err != nil {
+		log.Printf("onboarding worker: failed to process message message_id=%s trace_id=%s: %v", d.MessageId, d.CorrelationId, err)
+		_ = d.Nack(false, true)
+		return
+	}
+	_ = d.Ack(false)
+}
+
+func (w *OnboardingWorker) process(ctx context.Context, body []byte) error {
+	var evt rabbitmq.AccountEvent
+	if err := json.Unmarshal(body, &evt); err != nil {
+		return nil
+	}
+	if evt.AccountID == 0 {
+		return nil
+	}
+	switch evt.Action {
+	case "created":
+		return w.applyCreated(ctx, &evt)
+	default:
+		return nil
+	}
+}
+
+// applyCreated 执行新账户的引导操作
+// 账户不存在（如注册后立即被删除）时直接丢弃事件
+func (w *OnboardingWorker) applyCreated(ctx context.Context, evt *rabbitmq.AccountEvent) error {
+	if _, err := w.accounts.FindByID(ctx, evt.AccountID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	// 1. 欢迎通知（已存在时跳过）
+	return w.notifier.Welcome(ctx, evt.AccountID)
+}
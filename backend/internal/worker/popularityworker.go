@@ -6,6 +6,7 @@ import (
 	"errors"
 	"feedsystem_video_go/internal/middleware/rabbitmq"
 	rediscache "feedsystem_video_go/internal/middleware/redis"
+	"feedsystem_video_go/internal/middleware/trace"
 	"feedsystem_video_go/internal/video"
 	"log"
 
@@ -13,13 +14,23 @@ import (
 )
 
 type PopularityWorker struct {
-	ch    *amqp.Channel
-	cache *rediscache.Client
-	queue string
+	ch     *amqp.Channel
+	cache  *rediscache.Client
+	videos *video.VideoRepository // 可能为 nil（批量事件不过滤已删除的视频）
+	queue  string
+	tag    string // 消费者标签（RabbitMQ 管理界面中显示）
 }
 
-func NewPopularityWorker(ch *amqp.Channel, cache *rediscache.Client, queue string) *PopularityWorker {
-	return &PopularityWorker{ch: ch, cache: cache, queue: queue}
+func NewPopularityWorker(ch *amqp.Channel, cache *rediscache.Client, videos *video.VideoRepository, queue string, tag string) *PopularityWorker {
+	if tag == "" {
+		tag = ConsumerTag("popularity-worker")
+	}
+	return &PopularityWorker{ch: ch, cache: cache, videos: videos, queue: queue, tag: tag}
+}
+
+// Tag 返回消费者标签
+func (w *PopularityWorker) Tag() string {
+	return w.tag
 }
 
 func (w *PopularityWorker) Run(ctx context.Context) error {
@@ -32,7 +43,7 @@ func (w *PopularityWorker) Run(ctx context.Context) error {
 
 	deliveries, err := w.ch.Consume(
 		w.queue,
-		"",
+		w.tag,
 		false,
 		false,
 		false,
@@ -43,22 +54,17 @@ func (w *PopularityWorker) Run(ctx context.Context) error {
 		return err
 	}
 
-	for {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case d, ok := <-deliveries:
-			if !ok {
-				return errors.New("deliveries channel closed")
-			}
-			w.handleDelivery(ctx, d)
-		}
-	}
+	// 逐条处理消息；停止时先取消消费者，再在宽限期内处理完已接收的消息
+	return consumeLoop(ctx, w.ch, w.tag, deliveries, w.handleDelivery)
 }
 
 func (w *PopularityWorker) handleDelivery(ctx context.Context, d amqp.Delivery) {
+	// 追踪ID写入上下文，并记录消息ID/追踪ID，便于与 API 日志串联
+	ctx = trace.WithID(ctx, d.CorrelationId)
+	log.Printf("popularity worker: processing message_id=%s trace_id=%s routing_key=%s", d.MessageId, d.CorrelationId, d.RoutingKey)
+
 	if err := w.process(ctx, d.Body); err != nil {
-		log.Printf("popularity worker: failed to process message: %v", err)
+		log.Printf("popularity worker: failed to process message message_id=%s trace_id=%s: %v", d.MessageId, d.CorrelationId, err)
 		_ = d.Nack(false, true)
 		return
 	}
@@ -70,6 +76,20 @@ func (w *PopularityWorker) process(ctx context.Context, body []byte) error {
 	if err := json.Unmarshal(body, &evt); err != nil {
 		return nil
 	}
+	// 批量事件：合并同一视频的变化量后，在一次 Pipeline 中写入
+	if len(evt.Changes) > 0 {
+		changes := make(map[uint]int64, len(evt.Changes))
+		for _, c := range evt.Changes {
+			if c.VideoID == 0 || c.Change == 0 {
+				continue
+			}
+			changes[c.VideoID] += c.Change
+		}
+		w.dropMissingVideos(ctx, changes)
+		video.UpdatePopularityCacheBatch(ctx, w.cache, changes)
+		return nil
+	}
+
 	if evt.VideoID == 0 || evt.Change == 0 {
 		return nil
 	}
@@ -77,3 +97,25 @@ func (w *PopularityWorker) process(ctx context.Context, body []byte) error {
 	return nil
 }
 
+
+// dropMissingVideos 用一次批量查询去掉已删除/不存在的视频，避免它们继续出现在热榜中
+// 查询失败时只记录日志并保留全部变化量（热榜读取时会再次过滤已删除的视频）
+func (w *PopularityWorker) dropMissingVideos(ctx context.Context, changes map[uint]int64) {
+	if w.videos == nil || len(changes) == 0 {
+		return
+	}
+	ids := make([]uint, 0, len(changes))
+	for id := range changes {
+		ids = append(ids, id)
+	}
+	exists, err := w.videos.BatchExists(ctx, ids)
+	if err != nil {
+		log.Printf("popularity worker: failed to check videos exist: %v", err)
+		return
+	}
+	for id := range changes {
+		if !exists[id] {
+			delete(changes, id)
+		}
+	}
+}
@@ -4,24 +4,37 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"feedsystem_video_go/internal/account"
+	"feedsystem_video_go/internal/invalidation"
 	"feedsystem_video_go/internal/middleware/rabbitmq"
+	rediscache "feedsystem_video_go/internal/middleware/redis"
+	"feedsystem_video_go/internal/middleware/trace"
 	"feedsystem_video_go/internal/social"
 	"feedsystem_video_go/internal/video"
 	"log"
 
-	"github.com/go-sql-driver/mysql"
 	amqp "github.com/rabbitmq/amqp091-go"
 )
 
 type SocialWorker struct {
-	ch    *amqp.Channel
-	repo  *social.SocialRepository
+	ch        *amqp.Channel
+	repo      *social.SocialRepository
 	videoRepo *video.VideoRepository
-	queue string
+	cache     *rediscache.Client // 可能为 nil（Redis 不可用时只更新数据库热度）
+	queue     string
+	tag   string // 消费者标签（RabbitMQ 管理界面中显示）
 }
 
-func NewSocialWorker(ch *amqp.Channel, repo *social.SocialRepository, videoRepo *video.VideoRepository,queue string) *SocialWorker {
-	return &SocialWorker{ch: ch, repo: repo,videoRepo: videoRepo ,queue: queue}
+func NewSocialWorker(ch *amqp.Channel, repo *social.SocialRepository, videoRepo *video.VideoRepository, cache *rediscache.Client, queue string, tag string) *SocialWorker {
+	if tag == "" {
+		tag = ConsumerTag("social-worker")
+	}
+	return &SocialWorker{ch: ch, repo: repo, videoRepo: videoRepo, cache: cache, queue: queue, tag: tag}
+}
+
+// Tag 返回消费者标签
+func (w *SocialWorker) Tag() string {
+	return w.tag
 }
 
 func (w *SocialWorker) Run(ctx context.Context) error {
@@ -34,7 +47,7 @@ func (w *SocialWorker) Run(ctx context.Context) error {
 
 	deliveries, err := w.ch.Consume(
 		w.queue,
-		"",
+		w.tag,
 		false,
 		false,
 		false,
@@ -45,22 +58,17 @@ func (w *SocialWorker) Run(ctx context.Context) error {
 		return err
 	}
 
-	for {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case d, ok := <-deliveries:
-			if !ok {
-				return errors.New("deliveries channel closed")
-			}
-			w.handleDelivery(ctx, d)
-		}
-	}
+	// 逐条处理消息；停止时先取消消费者，再在宽限期内处理完已接收的消息
+	return consumeLoop(ctx, w.ch, w.tag, deliveries, w.handleDelivery)
 }
 
 func (w *SocialWorker) handleDelivery(ctx context.Context, d amqp.Delivery) {
+	// 追踪ID写入上下文，并记录消息ID/追踪ID，便于与 API 日志串联
+	ctx = trace.WithID(ctx, d.CorrelationId)
+	log.Printf("social worker: processing message_id=%s trace_id=%s routing_key=%s", d.MessageId, d.CorrelationId, d.RoutingKey)
+
 	if err := w.process(ctx, d.Body); err != nil {
-		log.Printf("social worker: failed to process message: %v", err)
+		log.Printf("social worker: failed to process message message_id=%s trace_id=%s: %v", d.MessageId, d.CorrelationId, err)
 		// 重新入队，稍后重试
 		_ = d.Nack(false, true)
 		return
@@ -80,57 +88,55 @@ func (w *SocialWorker) process(ctx context.Context, body []byte) error {
 
 	switch evt.Action {
 	case "follow":
-		err := w.repo.Follow(ctx, &social.Social{
+		// 插入关注记录并维护粉丝数/关注数（重复关注时不修改计数）
+		created, err := w.repo.FollowWithCounts(ctx, &social.Social{
 			FollowerID: evt.FollowerID,
 			VloggerID:  evt.VloggerID,
 		})
-		if err!=nil{
-			var mysqlErr *mysql.MySQLError
-			if errors.As(err,&mysqlErr) && mysqlErr.Number==1062{
-				return nil
-			}
+		if err != nil {
 			return err
 		}
-		// 查询被关注者的最新视频并更新热度（+10）
-		latestVideo, err := w.videoRepo.GetLatestByAuthorID(ctx, evt.VloggerID)
-		if err != nil {
-			log.Printf("social worker: failed to get latest video for vlogger %d: %v", evt.VloggerID, err)
+		if !created {
 			return nil
 		}
-
-		if latestVideo != nil {
-			if err := w.videoRepo.ChangePopularity(ctx, latestVideo.ID, 10); err != nil {
-				log.Printf("social worker: failed to update popularity for video %d: %v", latestVideo.ID, err)
-			}
-		}
-
-	  return nil
-
-	case "unfollow":
-	err := w.repo.Unfollow(ctx, &social.Social{
-		FollowerID: evt.FollowerID,
-		VloggerID:  evt.VloggerID,
-	})
-	if err != nil {
-		return err
-	}
-
-	// 查询被关注者的最新视频并更新热度（-10）
-	latestVideo, err := w.videoRepo.GetLatestByAuthorID(ctx, evt.VloggerID)
-	if err != nil {
-		log.Printf("social worker: failed to get latest video for vlogger %d: %v", evt.VloggerID, err)
+		// 粉丝数/关注数已变化，删除双方的公开资料缓存
+		account.InvalidateProfileCache(ctx, w.cache, evt.FollowerID, evt.VloggerID)
+		// 关注列表已变化，递增关注者的关注流版本号，使其关注流缓存失效
+		invalidation.BumpFollowingFeeds(ctx, w.cache, "following changed", []uint{evt.FollowerID})
+		// 被关注者最新视频热度+10（没有视频时跳过）
+		w.bumpLatestVideo(ctx, evt.VloggerID, video.FollowPopularityWeight)
+		// 通知被关注者由通知 Worker 消费同一事件完成（见 NotificationWorker）
 		return nil
-	}
 
-	if latestVideo != nil {
-		if err := w.videoRepo.ChangePopularity(ctx, latestVideo.ID, -10); err != nil {
-			log.Printf("social worker: failed to update popularity for video %d: %v", latestVideo.ID, err)
+	case "unfollow":
+		// 删除关注记录并维护粉丝数/关注数（记录不存在时不修改计数）
+		deleted, err := w.repo.UnfollowWithCounts(ctx, &social.Social{
+			FollowerID: evt.FollowerID,
+			VloggerID:  evt.VloggerID,
+		})
+		if err != nil {
+			return err
 		}
-	}
-
-	return nil
+		if !deleted {
+			return nil
+		}
+		// 粉丝数/关注数已变化，删除双方的公开资料缓存
+		account.InvalidateProfileCache(ctx, w.cache, evt.FollowerID, evt.VloggerID)
+		// 关注列表已变化，递增关注者的关注流版本号，使其关注流缓存失效
+		invalidation.BumpFollowingFeeds(ctx, w.cache, "following changed", []uint{evt.FollowerID})
+		// 被关注者最新视频热度-10（没有视频时跳过）
+		w.bumpLatestVideo(ctx, evt.VloggerID, -video.FollowPopularityWeight)
+		return nil
 
 	default:
 		return nil
 	}
 }
+
+// bumpLatestVideo 调整博主最新视频的热度
+// 关注关系已经写入，这里失败只记录日志不重试（重试会因关注记录已存在而被跳过）
+func (w *SocialWorker) bumpLatestVideo(ctx context.Context, vloggerID uint, change int64) {
+	if err := video.BumpLatestVideoPopularity(ctx, w.videoRepo, w.cache, vloggerID, change); err != nil {
+		log.Printf("social worker: failed to update latest video popularity for vlogger %d: %v", vloggerID, err)
+	}
+}
@@ -1,5 +1,5 @@
 import { postJson } from './client'
-import type { Account, MessageResponse, TokenResponse } from './types'
+import type { Account, MessageResponse, TokenResponse, VerifyPasswordResponse } from './types'
 
 export function register(username: string, password: string) {
   return postJson<MessageResponse>('/account/register', { username, password })
@@ -13,6 +13,30 @@ export function logout() {
   return postJson<MessageResponse>('/account/logout', {}, { authRequired: true })
 }
 
+// 当前登录用户信息（含最近登录时间、邮箱，公开资料接口不返回这些字段）
+export function me() {
+  return postJson<Account & { last_login_at: string | null; email: string | null; email_verified: boolean }>(
+    '/account/me',
+    {},
+    { authRequired: true },
+  )
+}
+
+// 敏感操作前重新输入密码，换取 5 分钟内有效的一次性 reauth_token
+export function verifyPassword(password: string) {
+  return postJson<VerifyPasswordResponse>('/account/verifyPassword', { password }, { authRequired: true })
+}
+
+// 设置邮箱（设置后为未验证状态）；需要先调用 verifyPassword 获取 reauth_token
+export function setEmail(email: string, reauthToken: string) {
+  return postJson<MessageResponse>('/account/setEmail', { email, reauth_token: reauthToken }, { authRequired: true })
+}
+
+// 使用验证 token 完成邮箱验证（不需要登录）
+export function verifyEmail(token: string) {
+  return postJson<MessageResponse>('/account/verifyEmail', { token })
+}
+
 export function rename(newUsername: string) {
   return postJson<TokenResponse>('/account/rename', { new_username: newUsername }, { authRequired: true })
 }
@@ -29,6 +53,10 @@ export function findById(id: number) {
   return postJson<Account>('/account/findByID', { id })
 }
 
+export function findByIds(ids: number[]) {
+  return postJson<Account[]>('/account/findByIDs', { ids })
+}
+
 export function findByUsername(username: string) {
   return postJson<Account>('/account/findByUsername', { username })
 }
@@ -2,20 +2,33 @@ import { useAuthStore } from '../stores/auth'
 
 export class ApiError extends Error {
   status: number
+  code?: string
+  fields?: Record<string, string>
   payload?: unknown
 
-  constructor(message: string, status: number, payload?: unknown) {
+  constructor(message: string, status: number, payload?: unknown, code?: string, fields?: Record<string, string>) {
     super(message)
     this.name = 'ApiError'
     this.status = status
     this.payload = payload
+    this.code = code
+    this.fields = fields
   }
 }
 
-type ApiErrorBody = { error?: string }
+// 后端统一错误响应：{"code": "VIDEO_NOT_FOUND", "message": "video not found"}
+// 参数校验失败时附带 fields：{"code": "INVALID_ARGUMENT", "message": "invalid request", "fields": {"video_id": "is required"}}
+type ApiErrorBody = { code?: string; message?: string; fields?: Record<string, string> }
 
 const API_BASE = (import.meta.env.VITE_API_BASE as string | undefined) ?? '/api'
 
+// WebSocket 地址：与 API_BASE 同源，http(s) 换成 ws(s)
+export function wsUrl(path: string) {
+  const base = new URL(API_BASE, window.location.href)
+  base.protocol = base.protocol === 'https:' ? 'wss:' : 'ws:'
+  return `${base.toString().replace(/\/$/, '')}${path}`
+}
+
 export async function postJson<T>(path: string, body: unknown, options?: { authRequired?: boolean }): Promise<T> {
   const auth = useAuthStore()
   const token = auth.token
@@ -47,11 +60,9 @@ export async function postJson<T>(path: string, body: unknown, options?: { authR
     if (res.status === 401) {
       auth.clearToken()
     }
-    const msg =
-      data && typeof data === 'object' && (data as ApiErrorBody).error
-        ? String((data as ApiErrorBody).error)
-        : `请求失败 (${res.status})`
-    throw new ApiError(msg, res.status, data)
+    const errBody = data && typeof data === 'object' ? (data as ApiErrorBody) : undefined
+    const msg = errBody?.message ? String(errBody.message) : `请求失败 (${res.status})`
+    throw new ApiError(msg, res.status, data, errBody?.code, errBody?.fields)
   }
 
   return data as T
@@ -88,11 +99,9 @@ export async function postForm<T>(path: string, body: FormData, options?: { auth
     if (res.status === 401) {
       auth.clearToken()
     }
-    const msg =
-      data && typeof data === 'object' && (data as ApiErrorBody).error
-        ? String((data as ApiErrorBody).error)
-        : `请求失败 (${res.status})`
-    throw new ApiError(msg, res.status, data)
+    const errBody = data && typeof data === 'object' ? (data as ApiErrorBody) : undefined
+    const msg = errBody?.message ? String(errBody.message) : `请求失败 (${res.status})`
+    throw new ApiError(msg, res.status, data, errBody?.code, errBody?.fields)
   }
 
   return data as T
@@ -1,5 +1,5 @@
 import { postJson } from './client'
-import type { Comment, MessageResponse } from './types'
+import type { AuthorComment, Comment, MessageResponse } from './types'
 
 export function listAll(videoId: number) {
   return postJson<Comment[]>('/comment/listAll', { video_id: videoId })
@@ -12,3 +12,17 @@ export function publish(videoId: number, content: string) {
 export function remove(commentId: number) {
   return postJson<MessageResponse>('/comment/delete', { comment_id: commentId }, { authRequired: true })
 }
+
+// 撤销删除评论（仅作者，删除后短时间内有效）
+export function restore(commentId: number) {
+  return postJson<MessageResponse>('/comment/restore', { comment_id: commentId }, { authRequired: true })
+}
+
+// 分页查询用户的评论历史
+export function listByAuthor(authorId: number, limit = 10, beforeId = 0) {
+  return postJson<{ comments: AuthorComment[]; next_before_id: number; has_more: boolean }>('/comment/listByAuthor', {
+    author_id: authorId,
+    limit,
+    before_id: beforeId,
+  })
+}
@@ -1,12 +1,13 @@
 import { postJson } from './client'
-import type { ListByFollowingResponse, ListByPopularityResponse, ListLatestResponse, ListLikesCountResponse } from './types'
+import type { HomeFeedResponse, ListByFollowingResponse, ListByPopularityResponse, ListLatestResponse, ListLikesCountResponse } from './types'
 
-export function listLatest(input: { limit: number; latest_time: number }) {
+export function listLatest(input: { limit: number; latest_time: number; latest_time_ms?: number; latest_id?: number; author_id?: number; since?: number; updated_since?: number; min_likes?: number; min_popularity?: number }) {
   return postJson<ListLatestResponse>('/feed/listLatest', input)
 }
 
-export function listLikesCount(input: { limit: number; likes_count_before?: number; id_before?: number }) {
+export function listLikesCount(input: { limit: number; likes_count_before?: number; id_before?: number; min_likes?: number }) {
   const body: Record<string, unknown> = { limit: input.limit }
+  if (typeof input.min_likes === 'number') body.min_likes = input.min_likes
   if (typeof input.likes_count_before === 'number' || typeof input.id_before === 'number') {
     body.likes_count_before = input.likes_count_before ?? 0
     body.id_before = input.id_before ?? 0
@@ -14,10 +15,15 @@ export function listLikesCount(input: { limit: number; likes_count_before?: numb
   return postJson<ListLikesCountResponse>('/feed/listLikesCount', body)
 }
 
-export function listByPopularity(input: { limit: number; as_of: number; offset: number }) {
+export function listByPopularity(input: { limit: number; as_of: number; offset: number; min_likes?: number; min_popularity?: number }) {
   return postJson<ListByPopularityResponse>('/feed/listByPopularity', input)
 }
 
-export function listByFollowing(input: { limit: number; latest_time: number }) {
+export function listByFollowing(input: { limit: number; latest_time: number; latest_time_ms?: number; latest_id?: number }) {
   return postJson<ListByFollowingResponse>('/feed/listByFollowing', input, { authRequired: true })
 }
+
+// 首页：已登录时关注流 + 热榜补足，未登录时为最新视频；游标字段原样传回上一页响应的值
+export function home(input: { limit: number; latest_time_ms?: number; latest_id?: number; following_done?: boolean; as_of?: number; offset?: number }) {
+  return postJson<HomeFeedResponse>('/feed/home', input)
+}
@@ -1,5 +1,5 @@
 import { postJson } from './client'
-import type { IsLikedResponse, MessageResponse, Video } from './types'
+import type { BatchLikeStatusResponse, IsLikedResponse, MessageResponse, Video } from './types'
 
 export function like(videoId: number) {
   return postJson<MessageResponse>('/like/like', { video_id: videoId }, { authRequired: true })
@@ -13,6 +13,10 @@ export function isLiked(videoId: number) {
   return postJson<IsLikedResponse>('/like/isLiked', { video_id: videoId }, { authRequired: true })
 }
 
+export function batchLikeStatus(videoIds: number[]) {
+  return postJson<BatchLikeStatusResponse>('/like/batchStatus', { video_ids: videoIds }, { authRequired: true })
+}
+
 export function listMyLikedVideos() {
   return postJson<Video[]>('/like/listMyLikedVideos', {}, { authRequired: true })
 }
@@ -0,0 +1,18 @@
+import { postJson } from './client'
+import type { ListNotificationsResponse, MarkReadResponse, UnreadCountResponse } from './types'
+
+export function list(input: { limit?: number; before_id?: number; unread_only?: boolean } = {}) {
+  return postJson<ListNotificationsResponse>('/notification/list', input, { authRequired: true })
+}
+
+export function unreadCount() {
+  return postJson<UnreadCountResponse>('/notification/unreadCount', {}, { authRequired: true })
+}
+
+export function markRead(ids: number[]) {
+  return postJson<MarkReadResponse>('/notification/markRead', { ids }, { authRequired: true })
+}
+
+export function markAllRead() {
+  return postJson<MarkReadResponse>('/notification/markRead', { all: true }, { authRequired: true })
+}
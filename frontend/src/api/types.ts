@@ -2,9 +2,13 @@ export type MessageResponse = { message: string }
 
 export type TokenResponse = { token: string }
 
+export type VerifyPasswordResponse = { reauth_token: string; expires_at: string }
+
 export type Account = {
   id: number
   username: string
+  followers_count?: number
+  following_count?: number
 }
 
 export type Video = {
@@ -16,7 +20,32 @@ export type Video = {
   play_url: string
   cover_url: string
   create_time: string
+  updated_at?: string
   likes_count: number
+  visibility?: VideoVisibility
+  publish_at?: string
+}
+
+export type VideoDetailAuthor = {
+  id: number
+  username: string
+  followers_count: number
+  following_count: number
+}
+
+export type AuthorStats = {
+  author_id: number
+  total_videos: number
+  total_likes: number
+}
+
+export type VideoVisibility = 'public' | 'private' | 'draft' | 'scheduled'
+
+export type LiveCountsEvent = {
+  video_id: number
+  likes_count?: number
+  comments_count?: number
+  popularity_delta?: number
 }
 
 export type Comment = {
@@ -26,6 +55,13 @@ export type Comment = {
   author_id: number
   content: string
   created_at: string
+  likes_count: number
+  is_liked: boolean
+}
+
+// 用户评论历史列表项（/comment/listByAuthor）
+export type AuthorComment = Omit<Comment, 'is_liked'> & {
+  video_title: string
 }
 
 export type FeedAuthor = {
@@ -41,14 +77,19 @@ export type FeedVideoItem = {
   play_url: string
   cover_url: string
   create_time: number
+  updated_at?: number
   likes_count: number
   is_liked: boolean
+  is_following?: boolean
 }
 
 export type ListLatestResponse = {
   video_list: FeedVideoItem[]
   next_time: number
+  next_time_ms: number
+  next_id: number
   has_more: boolean
+  algo: string
 }
 
 export type ListLikesCountResponse = {
@@ -56,6 +97,7 @@ export type ListLikesCountResponse = {
   next_likes_count_before?: number
   next_id_before?: number
   has_more: boolean
+  algo: string
 }
 
 export type ListByPopularityResponse = {
@@ -63,25 +105,77 @@ export type ListByPopularityResponse = {
   as_of: number
   next_offset: number
   has_more: boolean
+  algo: string
   next_latest_popularity?: number
   next_latest_before?: string
   next_latest_id_before?: number
 }
 
+export type HomeFeedResponse = {
+  video_list: FeedVideoItem[]
+  following_count: number
+  trending_count: number
+  latest_count: number
+  next_time_ms: number
+  next_id: number
+  following_done: boolean
+  as_of: number
+  next_offset: number
+  has_more: boolean
+  algo: string
+}
+
 export type ListByFollowingResponse = {
   video_list: FeedVideoItem[]
   next_time: number
+  next_time_ms: number
+  next_id: number
   has_more: boolean
+  algo: string
 }
 
 export type IsLikedResponse = {
   is_liked: boolean
 }
 
+export type BatchLikeStatusResponse = {
+  is_liked: Record<string, boolean>
+}
+
+export type FollowAccount = Account & {
+  is_following: boolean
+}
+
 export type GetAllFollowersResponse = {
-  followers: Account[]
+  followers: FollowAccount[]
 }
 
 export type GetAllVloggersResponse = {
-  vloggers: Account[]
+  vloggers: FollowAccount[]
+}
+
+export type NotificationType = 'like' | 'comment' | 'follow' | 'welcome'
+
+export type Notification = {
+  id: number
+  recipient_id: number
+  type: NotificationType
+  actor_id: number
+  target_id: number
+  read: boolean
+  created_at: string
+}
+
+export type ListNotificationsResponse = {
+  notifications: Notification[]
+  next_before_id: number
+  has_more: boolean
+}
+
+export type UnreadCountResponse = {
+  unread: number
+}
+
+export type MarkReadResponse = MessageResponse & {
+  updated: number
 }
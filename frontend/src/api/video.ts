@@ -1,10 +1,22 @@
-import { postForm, postJson } from './client'
-import type { Video } from './types'
+import { postForm, postJson, wsUrl } from './client'
+import type { AuthorStats, LiveCountsEvent, MessageResponse, Video, VideoDetailAuthor, VideoVisibility } from './types'
 
-export function publishVideo(input: { title: string; description: string; play_url: string; cover_url: string }) {
+export function publishVideo(input: {
+  title: string
+  description: string
+  play_url: string
+  cover_url: string
+  visibility?: VideoVisibility
+  publish_at?: string
+  idempotency_key?: string
+}) {
   return postJson<Video>('/video/publish', input, { authRequired: true })
 }
 
+export function setVisibility(id: number, visibility: VideoVisibility) {
+  return postJson<MessageResponse>('/video/setVisibility', { id, visibility }, { authRequired: true })
+}
+
 export type UploadResponse = { url: string; play_url?: string; cover_url?: string }
 
 export function uploadVideo(file: File) {
@@ -19,10 +31,39 @@ export function uploadCover(file: File) {
   return postForm<UploadResponse>('/video/uploadCover', fd, { authRequired: true })
 }
 
-export function listByAuthorId(authorId: number) {
-  return postJson<Video[]>('/video/listByAuthorID', { author_id: authorId })
+export function listByAuthorId(authorId: number, order?: 'asc' | 'desc') {
+  return postJson<Video[]>('/video/listByAuthorID', { author_id: authorId, order })
+}
+
+// 作者统计：公开视频数和点赞总数（服务端缓存 60 秒）
+export function authorStats(authorId: number) {
+  return postJson<AuthorStats>('/video/authorStats', { author_id: authorId })
 }
 
 export function getDetail(id: number) {
   return postJson<Video>('/video/getDetail', { id })
 }
+
+// 获取视频详情并附带作者公开资料（author 查询失败时为 null）
+export function getDetailWithAuthor(id: number) {
+  return postJson<Video & { author: VideoDetailAuthor | null }>('/video/getDetail', { id, include_author: true })
+}
+
+// 批量获取视频详情（最多 50 个，按请求顺序返回，不存在或无权查看的视频会被省略）
+export function getDetails(ids: number[]) {
+  return postJson<{ videos: Video[] }>('/video/getDetails', { ids })
+}
+
+// 订阅视频实时计数（点赞数/评论数/热度变化），返回取消订阅函数
+// 后端 Redis 不可用时连接会失败，页面保持刷新后更新计数即可
+export function subscribeCounts(id: number, onEvent: (evt: LiveCountsEvent) => void) {
+  const ws = new WebSocket(wsUrl(`/ws/video/${id}`))
+  ws.onmessage = (e) => {
+    try {
+      onEvent(JSON.parse(e.data as string) as LiveCountsEvent)
+    } catch {
+      // 忽略无法解析的消息
+    }
+  }
+  return () => ws.close()
+}
@@ -10,6 +10,7 @@ export default defineConfig({
         // Force IPv4 to avoid Windows resolving `localhost` -> `::1` (IPv6) and causing ECONNREFUSED
         target: 'http://127.0.0.1:8080',
         changeOrigin: true,
+        ws: true, // 代理 /ws/video/:id 的 WebSocket 升级请求
         rewrite: (path) => path.replace(/^\/api/, ''),
       },
     },